@@ -0,0 +1,39 @@
+package ynal
+
+import "time"
+
+// Acceptance records that someone accepted a license or document, like an
+// organization's internal terms presented as a custom document.
+type Acceptance struct {
+	// ID is assigned when the acceptance is recorded.
+	ID int64 `json:"id"`
+
+	// License is the ID of what was accepted, and Digest the SHA-256 of its
+	// text at the time, so a later change to the text doesn't change what
+	// was agreed to.
+	License string `json:"license"`
+	Digest  string `json:"digest"`
+
+	// Subject identifies who accepted it, in whatever form the organization
+	// uses: an email address, an employee ID, and so on.
+	Subject string `json:"subject"`
+
+	// Time is when it was accepted.
+	Time time.Time `json:"time"`
+
+	// RecordedBy is the name of whoever recorded it, from the credentials
+	// they used.
+	RecordedBy string `json:"recorded_by,omitempty"`
+}
+
+// AcceptanceFilter narrows down which acceptances to list. Empty fields match
+// everything.
+type AcceptanceFilter struct {
+	Subject string
+	License string
+}
+
+// Matches reports whether a is one the filter lets through.
+func (f AcceptanceFilter) Matches(a Acceptance) bool {
+	return (f.Subject == "" || f.Subject == a.Subject) && (f.License == "" || f.License == a.License)
+}
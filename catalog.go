@@ -0,0 +1,115 @@
+package ynal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Catalog is an in-memory LicenseStore whose licenses can be replaced while
+// ynal is running (after a sync, say). Stores that load licenses from
+// somewhere else keep them in a Catalog to get Watch for free.
+type Catalog struct {
+	mu        sync.RWMutex
+	licenses  []LicenseData
+	listeners []func([]LicenseData)
+}
+
+func NewCatalog(licenses []LicenseData) *Catalog {
+	return &Catalog{licenses: licenses}
+}
+
+// List returns the current licenses. Callers must not modify the slice.
+func (c *Catalog) List() []LicenseData {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.licenses
+}
+
+// Get returns the current license with the given ID, if there is one.
+func (c *Catalog) Get(id string) (LicenseData, bool) {
+	return FindLicense(c.List(), id)
+}
+
+// Replace swaps in a new set of licenses and notifies every Watch listener.
+func (c *Catalog) Replace(licenses []LicenseData) {
+	c.mu.Lock()
+	c.licenses = licenses
+	listeners := c.listeners
+	c.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(licenses)
+	}
+}
+
+// Watch registers fn to be called with the new licenses after every Replace.
+func (c *Catalog) Watch(fn func([]LicenseData)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.listeners = append(c.listeners, fn)
+}
+
+// Revision identifies a set of licenses by their IDs and texts, so two
+// instances serving the same catalog report the same revision, and any change
+// to it gives a new one.
+func Revision(licenses []LicenseData) string {
+	lines := []string{}
+	for _, l := range licenses {
+		lines = append(lines, l.ID+" "+l.Digest.SHA256+"\n")
+	}
+
+	slices.Sort(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "")))
+
+	return hex.EncodeToString(sum[:6])
+}
+
+// Changes lists the IDs of licenses that differ between two catalogs.
+type Changes struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Empty reports whether nothing changed.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// Diff compares two sets of licenses by ID, digest, and title. Each list of
+// IDs is sorted, and empty rather than nil when nothing's in it.
+func Diff(before []LicenseData, after []LicenseData) Changes {
+	c := Changes{Added: []string{}, Removed: []string{}, Changed: []string{}}
+
+	old := map[string]LicenseData{}
+	for _, l := range before {
+		old[l.ID] = l
+	}
+
+	for _, l := range after {
+		prev, ok := old[l.ID]
+		switch {
+		case !ok:
+			c.Added = append(c.Added, l.ID)
+		case prev.Digest != l.Digest || prev.Title != l.Title:
+			c.Changed = append(c.Changed, l.ID)
+		}
+
+		delete(old, l.ID)
+	}
+
+	for id := range old {
+		c.Removed = append(c.Removed, id)
+	}
+
+	slices.Sort(c.Added)
+	slices.Sort(c.Removed)
+	slices.Sort(c.Changed)
+
+	return c
+}
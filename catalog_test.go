@@ -0,0 +1,57 @@
+package ynal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRevision(t *testing.T) {
+	licenses, err := Embedded()
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	rev := Revision(licenses)
+	if len(rev) != 12 {
+		t.Fatalf("expected a 12 character revision, got %q", rev)
+	}
+
+	reversed := slices.Clone(licenses)
+	slices.Reverse(reversed)
+	if Revision(reversed) != rev {
+		t.Errorf("expected order not to matter")
+	}
+
+	changed := slices.Clone(licenses)
+	changed[0].Digest = digestOf(changed[0].Text + "more\n")
+	if Revision(changed) == rev {
+		t.Errorf("expected a changed text to change the revision")
+	}
+
+	if Revision(licenses[1:]) == rev {
+		t.Errorf("expected a removed license to change the revision")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := []LicenseData{
+		{ID: "a", Title: "A", Digest: digestOf("a\n")},
+		{ID: "b", Title: "B", Digest: digestOf("b\n")},
+		{ID: "c", Title: "C", Digest: digestOf("c\n")},
+	}
+
+	after := []LicenseData{
+		{ID: "d", Title: "D", Digest: digestOf("d\n")},
+		{ID: "b", Title: "B", Digest: digestOf("b, revised\n")},
+		{ID: "c", Title: "C", Digest: digestOf("c\n")},
+	}
+
+	got := Diff(before, after)
+	if !slices.Equal(got.Added, []string{"d"}) || !slices.Equal(got.Removed, []string{"a"}) || !slices.Equal(got.Changed, []string{"b"}) {
+		t.Errorf("unexpected changes: %+v", got)
+	}
+
+	if !Diff(before, before).Empty() {
+		t.Errorf("expected no changes between identical catalogs")
+	}
+}
@@ -0,0 +1,117 @@
+// Command spdx-import copies licenses from the SPDX license list into
+// licenses/, normalized and with their metadata, so adding one to the embedded
+// catalog is a single command:
+//
+//	go run ./cmd/spdx-import MPL-2.0
+//
+// A license can be given a title other than its SPDX identifier with
+// ID=Title, e.g. MPL-2.0=MPL_2. Everything imported is recorded in the
+// manifest, and running it without arguments (which `go generate` does)
+// imports everything in the manifest again.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/packrat386/ynal/spdx"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "spdx-import:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("spdx-import", flag.ContinueOnError)
+	dir := fs.String("dir", "licenses", "directory to write licenses to")
+	manifest := fs.String("manifest", "spdx-imports.txt", "file listing every imported license")
+	listURL := fs.String("list-url", spdx.DefaultListURL, "where to fetch the SPDX license list from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	recorded, err := readManifest(*manifest)
+	if err != nil {
+		return err
+	}
+
+	imports := recorded
+	if fs.NArg() > 0 {
+		imports = []spdx.Import{}
+		for _, arg := range fs.Args() {
+			id, title, _ := strings.Cut(arg, "=")
+			imports = append(imports, spdx.Import{ID: id, Title: title})
+		}
+	}
+
+	if len(imports) == 0 {
+		fmt.Fprintf(out, "nothing to import, pass SPDX identifiers or list them in %s\n", *manifest)
+		return nil
+	}
+
+	s := &spdx.Syncer{ListURL: *listURL}
+	if err := s.Import(context.Background(), *dir, imports); err != nil {
+		return err
+	}
+
+	for _, imp := range imports {
+		fmt.Fprintf(out, "imported %s\n", imp.ID)
+	}
+
+	return recordImports(*manifest, recorded, imports)
+}
+
+func readManifest(path string) ([]spdx.Import, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not open manifest: %w", err)
+	}
+	defer f.Close()
+
+	return spdx.ReadManifest(f)
+}
+
+// recordImports appends the imports the manifest doesn't have yet.
+func recordImports(path string, recorded []spdx.Import, imports []spdx.Import) error {
+	buf := new(bytes.Buffer)
+
+	for _, imp := range imports {
+		if slices.ContainsFunc(recorded, func(r spdx.Import) bool { return strings.EqualFold(r.ID, imp.ID) }) {
+			continue
+		}
+
+		buf.WriteString(imp.ID)
+		if imp.Title != "" {
+			buf.WriteString(" " + imp.Title)
+		}
+		buf.WriteString("\n")
+	}
+
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open manifest: %w", err)
+	}
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("could not update manifest: %w", err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/licenses.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"licenses": [{"licenseId": "ISC", "name": "ISC License", "detailsUrl": "./ISC.json"}]}`))
+	})
+	mux.HandleFunc("/ISC.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"licenseId": "ISC", "licenseText": "Copyright (c) <year> <owner>\r\n"}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "imports.txt")
+	flags := []string{"-dir", dir, "-manifest", manifest, "-list-url", srv.URL + "/licenses.json"}
+
+	if err := run(append(flags, "ISC=ISC_License"), io.Discard); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	text, err := os.ReadFile(filepath.Join(dir, "ISC_License.txt"))
+	if err != nil || string(text) != "Copyright (c) <YEAR> <COPYRIGHT HOLDER>\n" {
+		t.Fatalf("unexpected license text %q: %v", text, err)
+	}
+
+	// running again with no arguments imports what the manifest lists, and
+	// doesn't list it twice
+	os.Remove(filepath.Join(dir, "ISC_License.txt"))
+
+	if err := run(flags, io.Discard); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "ISC_License.txt")); err != nil {
+		t.Fatalf("expected the license to be imported again: %s", err)
+	}
+
+	m, err := os.ReadFile(manifest)
+	if err != nil || string(m) != "ISC ISC_License\n" {
+		t.Fatalf("unexpected manifest %q: %v", m, err)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+//go:embed shim.js
+var shim string
+
+// page holds everything inline, so it works when opened straight from disk.
+var page = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+  <head>
+    <meta charset="utf-8"/>
+    <title>YNAL: You Need A License</title>
+  </head>
+  <body>
+    <p>Loading...</p>
+    <script>{{ .WasmExec }}</script>
+    <script>const ynalWasm = "{{ .Wasm }}";</script>
+    <script>{{ .Shim }}</script>
+  </body>
+</html>
+`))
+
+// bundle writes the page with wasm, wasmExec, and the shim in it.
+func bundle(w io.Writer, wasm []byte, wasmExec []byte) error {
+	// the scripts are inlined as is, so they can't close their own tag
+	for _, script := range []string{string(wasmExec), shim} {
+		if strings.Contains(strings.ToLower(script), "</script") {
+			return errors.New("could not inline a script containing </script>")
+		}
+	}
+
+	err := page.Execute(w, struct {
+		WasmExec string
+		Wasm     string
+		Shim     string
+	}{
+		WasmExec: string(wasmExec),
+		Wasm:     base64.StdEncoding.EncodeToString(wasm),
+		Shim:     shim,
+	})
+	if err != nil {
+		return fmt.Errorf("could not render page: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	wasm := []byte("\x00asm fake module")
+	buf := new(bytes.Buffer)
+
+	if err := bundle(buf, wasm, []byte("class Go {}")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, want := range []string{
+		"<script>class Go {}</script>",
+		`const ynalWasm = "` + base64.StdEncoding.EncodeToString(wasm) + `";`,
+		"ynalServe(",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected the page to contain %q", want)
+		}
+	}
+
+	if err := bundle(new(bytes.Buffer), wasm, []byte(`document.write("</SCRIPT>")`)); err == nil {
+		t.Fatalf("expected an error for a script that closes its own tag")
+	}
+}
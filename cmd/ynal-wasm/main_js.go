@@ -0,0 +1,32 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+func main() {
+	h, err := ynalhttp.New()
+	if err != nil {
+		fmt.Println("ynal-wasm:", err)
+		return
+	}
+
+	js.Global().Set("ynalServe", js.FuncOf(func(this js.Value, args []js.Value) any {
+		res := serve(h, args[0].String(), args[1].String())
+
+		return map[string]any{
+			"status":      res.Status,
+			"contentType": res.ContentType,
+			"location":    res.Location,
+			"body":        res.Body,
+		}
+	}))
+
+	// keep running so the shim can keep calling in
+	select {}
+}
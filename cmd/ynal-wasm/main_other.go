@@ -0,0 +1,86 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	out := flag.String("o", "ynal.html", "where to write the page")
+	flag.Parse()
+
+	if err := build(*out); err != nil {
+		fmt.Fprintln(os.Stderr, "ynal-wasm:", err)
+		os.Exit(1)
+	}
+}
+
+// build compiles this command to WebAssembly and bundles it, with Go's
+// wasm_exec.js and the shim, into a single page at out.
+func build(out string) error {
+	dir, err := os.MkdirTemp("", "ynal-wasm")
+	if err != nil {
+		return fmt.Errorf("could not create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	wasmPath := filepath.Join(dir, "ynal.wasm")
+
+	cmd := exec.Command("go", "build", "-trimpath", "-ldflags=-s -w", "-o", wasmPath, "github.com/packrat386/ynal/cmd/ynal-wasm")
+	cmd.Env = append(os.Environ(), "GOOS=js", "GOARCH=wasm")
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("could not build wasm: %w", err)
+	}
+
+	wasm, err := os.ReadFile(wasmPath)
+	if err != nil {
+		return fmt.Errorf("could not read wasm: %w", err)
+	}
+
+	wasmExec, err := readWasmExec()
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := bundle(buf, wasm, wasmExec); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(out, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("could not write page: %w", err)
+	}
+
+	fmt.Printf("wrote %s (%d KiB)\n", out, buf.Len()/1024)
+
+	return nil
+}
+
+// readWasmExec reads the JS support file for the Go toolchain that built the
+// wasm, which moved to lib/wasm in Go 1.24.
+func readWasmExec() ([]byte, error) {
+	goroot, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not find GOROOT: %w", err)
+	}
+
+	root := strings.TrimSpace(string(goroot))
+
+	for _, p := range []string{"lib/wasm/wasm_exec.js", "misc/wasm/wasm_exec.js"} {
+		if b, err := os.ReadFile(filepath.Join(root, p)); err == nil {
+			return b, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find wasm_exec.js in %s", root)
+}
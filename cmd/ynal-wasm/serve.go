@@ -0,0 +1,39 @@
+// Command ynal-wasm is the license browser compiled to WebAssembly, so the
+// whole thing works as a single static page with no server, like on an
+// air-gapped network. Run natively, it builds itself for WebAssembly and
+// bundles the result into one HTML file:
+//
+//	go run ./cmd/ynal-wasm -o ynal.html
+//
+// In the browser, shim.js hands every link and search to the same handler
+// ynal serves with and renders what comes back.
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+// response is what the shim gets back for each request.
+type response struct {
+	Status      int
+	ContentType string
+	Location    string
+	Body        string
+}
+
+// serve runs a GET for path through h, as the browser would send it.
+func serve(h http.Handler, path string, accept string) response {
+	r := httptest.NewRequest("GET", path, nil)
+	r.Header.Set("Accept", accept)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return response{
+		Status:      w.Code,
+		ContentType: w.Header().Get("Content-Type"),
+		Location:    w.Header().Get("Location"),
+		Body:        w.Body.String(),
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+func TestServe(t *testing.T) {
+	h, err := ynalhttp.New()
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		name        string
+		path        string
+		accept      string
+		status      int
+		contentType string
+		location    string
+		body        string
+	}{
+		{name: "license", path: "/mit", accept: "text/html", status: 200, contentType: "text/html", body: "<h2>License: MIT</h2>"},
+		{name: "text", path: "/raw/mit", accept: "text/html", status: 200, contentType: "text/plain", body: "Permission is hereby granted"},
+		{name: "redirect", path: "/mit/", accept: "text/html", status: 301, location: "/mit"},
+		{name: "search", path: "/search?q=warranty", accept: "text/html", status: 200, contentType: "text/html", body: "MIT"},
+		{name: "missing", path: "/nope", accept: "text/html", status: 404, contentType: "text/html"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			res := serve(h, tc.path, tc.accept)
+
+			if res.Status != tc.status {
+				t.Fatalf("expected %d, got %d", tc.status, res.Status)
+			}
+
+			if !strings.HasPrefix(res.ContentType, tc.contentType) {
+				t.Fatalf("expected %s, got %s", tc.contentType, res.ContentType)
+			}
+
+			if res.Location != tc.location {
+				t.Fatalf("expected location %q, got %q", tc.location, res.Location)
+			}
+
+			if !strings.Contains(res.Body, tc.body) {
+				t.Fatalf("expected the body to contain %q, got:\n%s", tc.body, res.Body)
+			}
+		})
+	}
+}
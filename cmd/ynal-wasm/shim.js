@@ -0,0 +1,86 @@
+// shim.js runs ynal's handler, compiled to WebAssembly, in place of a server.
+// The Go side sets ynalServe(path, accept), which returns what the server
+// would have: {status, contentType, location, body}. Pages navigate by
+// changing the hash, so everything works from file:// with no server at all.
+(async () => {
+  const bytes = Uint8Array.from(atob(ynalWasm), (c) => c.charCodeAt(0));
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiate(bytes, go.importObject);
+  go.run(instance);
+
+  function current() {
+    return location.hash.slice(1) || "/";
+  }
+
+  function show(path) {
+    // follow redirects, like aliases and trailing slashes, but not forever
+    for (let i = 0; i < 10; i++) {
+      const res = ynalServe(path, "text/html");
+      if (res.status >= 300 && res.status < 400 && res.location) {
+        path = res.location;
+        continue;
+      }
+
+      if (res.contentType.startsWith("text/html")) {
+        render(res.body);
+      } else {
+        render("<pre></pre>");
+        document.querySelector("pre").textContent = res.body;
+      }
+
+      return;
+    }
+  }
+
+  function render(html) {
+    document.open();
+    document.write(html);
+    document.close();
+
+    // stylesheets come from the handler too, so inline them
+    for (const link of document.querySelectorAll('link[rel="stylesheet"]')) {
+      const style = document.createElement("style");
+      style.textContent = ynalServe(link.getAttribute("href"), "text/css").body;
+      link.replaceWith(style);
+    }
+
+    // writing the document can drop handlers, so set them every time
+    window.onhashchange = () => show(current());
+
+    window.onclick = (e) => {
+      const a = e.target.closest("a");
+      const href = a && a.getAttribute("href");
+
+      // links off the site work as usual
+      if (!href || !(href.startsWith("/") || href.startsWith("#"))) {
+        return;
+      }
+
+      e.preventDefault();
+
+      // the hash is the page, so links within one just scroll
+      if (href.startsWith("#")) {
+        const target = document.getElementById(href.slice(1));
+        if (target) {
+          target.scrollIntoView();
+        }
+
+        return;
+      }
+
+      location.hash = href;
+    };
+
+    window.onsubmit = (e) => {
+      e.preventDefault();
+
+      // there's nothing to POST to offline, so only searches work
+      const form = e.target;
+      if (form.method.toLowerCase() === "get") {
+        location.hash = form.getAttribute("action") + "?" + new URLSearchParams(new FormData(form));
+      }
+    };
+  }
+
+  show(current());
+})();
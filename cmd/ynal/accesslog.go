@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/packrat386/ynal/middleware"
+)
+
+// accessLogFormats are the formats the access log can be written in. "text"
+// goes through the standard logger like everything else ynal logs; the rest
+// are meant for log processors and carry their own timestamps.
+var accessLogFormats = []string{"text", "common", "combined", "json"}
+
+// clfTime is the timestamp format of the common and combined log formats.
+const clfTime = "02/Jan/2006:15:04:05 -0700"
+
+// accessEntry is everything logged about a request.
+type accessEntry struct {
+	Time      time.Time `json:"time"`
+	Remote    string    `json:"remote"`
+	Method    string    `json:"method"`
+	URI       string    `json:"uri"`
+	Proto     string    `json:"proto"`
+	Status    int       `json:"status"`
+	Bytes     int64     `json:"bytes"`
+	Duration  float64   `json:"duration_ms"`
+	Referer   string    `json:"referer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// accessLog writes one entry per request in one of accessLogFormats.
+type accessLog struct {
+	format string
+
+	// file is where entries go when the log is written to a file.
+	// Otherwise they go to stdout, or the standard logger for "text".
+	file *rotatingFile
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// openAccessLog sets up the access log cfg describes. Close it when done to
+// close its file, if it has one.
+func openAccessLog(cfg LogConfig) (*accessLog, error) {
+	a := &accessLog{format: cfg.Format, out: os.Stdout}
+	if a.format == "" {
+		a.format = "text"
+	}
+
+	if cfg.File != "" {
+		f, err := openRotatingFile(cfg.File, int64(cfg.MaxSize)<<20, cfg.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+
+		a.file, a.out = f, f
+	}
+
+	return a, nil
+}
+
+func (a *accessLog) Close() error {
+	if a.file == nil {
+		return nil
+	}
+
+	return a.file.Close()
+}
+
+func (a *accessLog) log(e accessEntry) {
+	if a.format == "text" && a.file == nil {
+		log.Printf("%s %s [%d] %s %dB %s", e.Remote, e.Method, e.Status, e.URI, e.Bytes, e.latency())
+		return
+	}
+
+	var line []byte
+
+	switch a.format {
+	case "json":
+		b, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("could not encode access log entry: %s", err)
+			return
+		}
+
+		line = append(b, '\n')
+	case "common", "combined":
+		line = fmt.Appendf(nil, "%s - - [%s] %q %d %s", e.Remote, e.Time.Format(clfTime), e.Method+" "+e.URI+" "+e.Proto, e.Status, clfBytes(e.Bytes))
+		if a.format == "combined" {
+			line = fmt.Appendf(line, " %q %q", clfField(e.Referer), clfField(e.UserAgent))
+		}
+
+		line = append(line, '\n')
+	default:
+		line = fmt.Appendf(nil, "%s %s %s [%d] %s %dB %s\n", e.Time.Format(time.DateTime), e.Remote, e.Method, e.Status, e.URI, e.Bytes, e.latency())
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := a.out.Write(line); err != nil {
+		log.Printf("could not write access log: %s", err)
+	}
+}
+
+// latency is how long the request took, to the microsecond.
+func (e accessEntry) latency() time.Duration {
+	return time.Duration(e.Duration * float64(time.Millisecond)).Round(time.Microsecond)
+}
+
+// clfBytes is the response size as the common log format writes it, with "-"
+// for nothing.
+func clfBytes(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+
+	return strconv.FormatInt(n, 10)
+}
+
+func clfField(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
+
+func withLogging(a *accessLog, next http.Handler) http.Handler {
+	return middleware.Logging(next, middleware.WithLogFunc(func(e middleware.Entry) {
+		a.log(accessEntry{
+			Time:      e.Time,
+			Remote:    e.Remote,
+			Method:    e.Method,
+			URI:       e.URI,
+			Proto:     e.Proto,
+			Status:    e.Status,
+			Bytes:     e.Bytes,
+			Referer:   e.Referer,
+			UserAgent: e.UserAgent,
+			Duration:  float64(e.Duration) / float64(time.Millisecond),
+			RequestID: e.RequestID,
+		})
+	}))
+}
+
+// rotatingFile is an append-only log file that's rotated once it grows past
+// maxSize: path becomes path.1, path.1 becomes path.2, and so on, keeping at
+// most maxBackups old files.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// openRotatingFile opens path for appending. A maxSize of zero never
+// rotates.
+func openRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	rf := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open access log: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("could not stat access log: %w", err)
+	}
+
+	rf.f = f
+	rf.size = info.Size()
+
+	return nil
+}
+
+func (rf *rotatingFile) Write(b []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(b)) > rf.maxSize {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.f.Write(b)
+	rf.size += int64(n)
+
+	return n, err
+}
+
+func (rf *rotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		return fmt.Errorf("could not close access log: %w", err)
+	}
+
+	if rf.maxBackups == 0 {
+		if err := os.Remove(rf.path); err != nil {
+			return fmt.Errorf("could not remove access log: %w", err)
+		}
+
+		return rf.open()
+	}
+
+	for i := rf.maxBackups - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", rf.path, i), fmt.Sprintf("%s.%d", rf.path, i+1))
+	}
+
+	if err := os.Rename(rf.path, rf.path+".1"); err != nil {
+		return fmt.Errorf("could not rotate access log: %w", err)
+	}
+
+	return rf.open()
+}
+
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	return rf.f.Close()
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAccessLogFormats(t *testing.T) {
+	tt := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   "common",
+			expected: `] "GET /mit?x=1 HTTP/1.1" 418 5` + "\n",
+		},
+		{
+			format:   "combined",
+			expected: `] "GET /mit?x=1 HTTP/1.1" 418 5 "https://example.com/" "curl/8.0"` + "\n",
+		},
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("hello"))
+	})
+
+	for _, tc := range tt {
+		t.Run(tc.format, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			a := &accessLog{format: tc.format, out: buf}
+
+			r := httptest.NewRequest("GET", "/mit?x=1", nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+			r.Header.Set("Referer", "https://example.com/")
+			r.Header.Set("User-Agent", "curl/8.0")
+
+			withLogging(a, h).ServeHTTP(httptest.NewRecorder(), r)
+
+			if !strings.HasPrefix(buf.String(), "192.0.2.1 - - [") || !strings.HasSuffix(buf.String(), tc.expected) {
+				t.Fatalf("expected %q in %q", tc.expected, buf.String())
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		a := &accessLog{format: "json", out: buf}
+
+		r := httptest.NewRequest("GET", "/mit", nil)
+		r.Header.Set("User-Agent", "curl/8.0")
+
+		withLogging(a, h).ServeHTTP(httptest.NewRecorder(), r)
+
+		e := accessEntry{}
+		if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+			t.Fatalf("could not parse %q: %s", buf.String(), err)
+		}
+
+		if e.Method != "GET" || e.URI != "/mit" || e.Status != http.StatusTeapot || e.Bytes != 5 || e.UserAgent != "curl/8.0" || e.Duration < 0 {
+			t.Fatalf("unexpected entry: %+v", e)
+		}
+	})
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+
+	rf, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("could not open log: %s", err)
+	}
+	defer rf.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := rf.Write([]byte(line)); err != nil {
+			t.Fatalf("could not write: %s", err)
+		}
+	}
+
+	expected := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+
+	for p, want := range expected {
+		got, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("could not read %s: %s", p, err)
+		}
+
+		if string(got) != want {
+			t.Errorf("expected %s to hold %q, got %q", filepath.Base(p), want, got)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Fatalf("expected at most 2 backups")
+	}
+}
+
+func TestLoggingResponseWriterPassthrough(t *testing.T) {
+	// with a file set, text lines go to out rather than the standard logger
+	buf := new(bytes.Buffer)
+	a := &accessLog{format: "text", file: &rotatingFile{}, out: buf}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello "))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("could not flush: %s", err)
+		}
+
+		rf, ok := w.(io.ReaderFrom)
+		if !ok {
+			t.Fatalf("expected the writer to implement io.ReaderFrom")
+		}
+
+		rf.ReadFrom(strings.NewReader("world"))
+	})
+
+	w := httptest.NewRecorder()
+	withLogging(a, h).ServeHTTP(w, httptest.NewRequest("GET", "/all.zip", nil))
+
+	if !w.Flushed {
+		t.Fatalf("expected the flush to reach the underlying writer")
+	}
+
+	if w.Body.String() != "hello world" {
+		t.Fatalf("unexpected body: %q", w.Body.String())
+	}
+
+	if !strings.Contains(buf.String(), "GET [200] /all.zip 11B ") {
+		t.Fatalf("expected the size in the log line, got %q", buf.String())
+	}
+}
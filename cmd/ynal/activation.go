@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// sdListenFdsStart is the first file descriptor systemd passes to a
+// socket-activated service. See sd_listen_fds(3).
+const sdListenFdsStart = 3
+
+// inheritedSockets are the sockets passed in by systemd socket activation,
+// or by the ynal this one is replacing, keyed by their FileDescriptorName (or
+// "" when unnamed). Stream sockets are listeners; datagram sockets, which
+// HTTP/3 serves on, are packet connections.
+type inheritedSockets struct {
+	listeners map[string]net.Listener
+	packets   map[string]net.PacketConn
+
+	// upgradedFrom is the PID of the ynal this one is replacing, which
+	// serves until takeOver stops it, or 0.
+	upgradedFrom int
+}
+
+// activationListeners returns the sockets ynal was started with. Both maps
+// are empty when it wasn't socket activated or upgraded into.
+func activationListeners() (inheritedSockets, error) {
+	listenPid := inheritedPid(os.Getenv("LISTEN_PID"), os.Getenv(upgradeEnv), os.Getpid(), os.Getppid())
+	upgraded := os.Getenv("LISTEN_PID") == "" && listenPid != ""
+	names, ok := listenFds(os.Getpid(), listenPid, os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES"))
+
+	// don't leak the activation environment to anything we might exec
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	os.Unsetenv(upgradeEnv)
+
+	inherited := inheritedSockets{listeners: map[string]net.Listener{}, packets: map[string]net.PacketConn{}}
+	if !ok {
+		return inherited, nil
+	}
+
+	if upgraded {
+		inherited.upgradedFrom = os.Getppid()
+	}
+
+	for i, name := range names {
+		f := os.NewFile(uintptr(sdListenFdsStart+i), name)
+
+		_, listener := inherited.listeners[name]
+		_, packet := inherited.packets[name]
+		if listener || packet {
+			return inherited, fmt.Errorf("more than one inherited socket named %q", name)
+		}
+
+		// FileListener and FilePacketConn dup the descriptor, so the
+		// original can go
+		if l, err := net.FileListener(f); err == nil {
+			inherited.listeners[name] = l
+		} else if c, perr := net.FilePacketConn(f); perr == nil {
+			inherited.packets[name] = c
+		} else {
+			f.Close()
+			return inherited, fmt.Errorf("could not use inherited socket %d: %w", sdListenFdsStart+i, err)
+		}
+
+		f.Close()
+	}
+
+	return inherited, nil
+}
+
+// inheritedPid returns the PID the inherited sockets are meant for. A ynal
+// being upgraded can't know the PID of its replacement before starting it, so
+// it names itself in upgradeFrom instead, and the sockets are meant for
+// whichever process it started.
+func inheritedPid(listenPid string, upgradeFrom string, pid int, ppid int) string {
+	if listenPid == "" && upgradeFrom != "" && upgradeFrom == strconv.Itoa(ppid) {
+		return strconv.Itoa(pid)
+	}
+
+	return listenPid
+}
+
+// listenFds parses the socket activation environment, returning one name per
+// inherited descriptor. ok is false if the descriptors aren't meant for us.
+func listenFds(pid int, listenPid string, listenFds string, fdNames string) ([]string, bool) {
+	if p, err := strconv.Atoi(listenPid); err != nil || p != pid {
+		return nil, false
+	}
+
+	n, err := strconv.Atoi(listenFds)
+	if err != nil || n < 1 {
+		return nil, false
+	}
+
+	names := make([]string, n)
+
+	if fdNames != "" {
+		for i, name := range strings.SplitN(fdNames, ":", n) {
+			names[i] = name
+		}
+	}
+
+	return names, true
+}
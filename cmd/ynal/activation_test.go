@@ -0,0 +1,92 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestListenFds(t *testing.T) {
+	tt := []struct {
+		name      string
+		listenPid string
+		listenFds string
+		fdNames   string
+		expected  []string
+		ok        bool
+	}{
+		{
+			name: "not activated",
+		},
+		{
+			name:      "other pid",
+			listenPid: "42",
+			listenFds: "1",
+		},
+		{
+			name:      "unnamed",
+			listenPid: "1234",
+			listenFds: "1",
+			expected:  []string{""},
+			ok:        true,
+		},
+		{
+			name:      "named",
+			listenPid: "1234",
+			listenFds: "2",
+			fdNames:   "http:grpc",
+			expected:  []string{"http", "grpc"},
+			ok:        true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			names, ok := listenFds(1234, tc.listenPid, tc.listenFds, tc.fdNames)
+
+			if ok != tc.ok || !slices.Equal(names, tc.expected) {
+				t.Fatalf("expected %v %v, got %v %v", tc.expected, tc.ok, names, ok)
+			}
+		})
+	}
+}
+
+func TestInheritedPid(t *testing.T) {
+	tt := []struct {
+		name        string
+		listenPid   string
+		upgradeFrom string
+		expected    string
+	}{
+		{
+			name: "neither",
+		},
+		{
+			name:      "socket activated",
+			listenPid: "1234",
+			expected:  "1234",
+		},
+		{
+			name:        "upgraded by the parent",
+			upgradeFrom: "1",
+			expected:    "1234",
+		},
+		{
+			name:        "upgrade meant for another process",
+			upgradeFrom: "42",
+		},
+		{
+			name:        "socket activation wins",
+			listenPid:   "42",
+			upgradeFrom: "1",
+			expected:    "42",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := inheritedPid(tc.listenPid, tc.upgradeFrom, 1234, 1); got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+// auditLog is where administrative actions are recorded. A nil *auditLog
+// records nothing.
+type auditLog struct {
+	*ynalhttp.AuditLog
+
+	// file is closed along with the log, unless entries go to stdout.
+	file *os.File
+}
+
+// openAuditLog opens the audit log at path, "stdout" for the standard output,
+// for appending. It returns nil if path is empty.
+func openAuditLog(path string) (*auditLog, error) {
+	switch path {
+	case "":
+		return nil, nil
+	case "stdout":
+		return &auditLog{AuditLog: ynalhttp.NewAuditLog(os.Stdout)}, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log: %w", err)
+	}
+
+	return &auditLog{AuditLog: ynalhttp.NewAuditLog(f), file: f}, nil
+}
+
+func (a *auditLog) Close() error {
+	if a == nil || a.file == nil {
+		return nil
+	}
+
+	return a.file.Close()
+}
+
+// record writes e to the log, if there is one.
+func (a *auditLog) record(e ynalhttp.AuditEntry) {
+	if a == nil {
+		return
+	}
+
+	if err := a.Record(e); err != nil {
+		log.Print(err)
+	}
+}
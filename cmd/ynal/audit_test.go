@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+func TestAuditLogAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	for _, detail := range []string{"first", "second"} {
+		a, err := openAuditLog(path)
+		if err != nil {
+			t.Fatalf("could not open audit log: %s", err)
+		}
+
+		a.record(ynalhttp.AuditEntry{Action: ynalhttp.AuditConfig, Actor: "test", Detail: detail})
+
+		if err := a.Close(); err != nil {
+			t.Fatalf("could not close audit log: %s", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read audit log: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"detail":"first"`) || !strings.Contains(lines[1], `"detail":"second"`) {
+		t.Fatalf("expected both entries in order, got:\n%s", data)
+	}
+}
+
+func TestAuditLogDisabled(t *testing.T) {
+	a, err := openAuditLog("")
+	if err != nil || a != nil {
+		t.Fatalf("expected no audit log, got %v, %v", a, err)
+	}
+
+	// a disabled log quietly records nothing
+	a.record(ynalhttp.AuditEntry{Action: ynalhttp.AuditConfig})
+	if err := a.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
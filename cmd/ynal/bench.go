@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// benchMediaTypes are the representations every license page is fetched in.
+var benchMediaTypes = []string{"text/html", "text/plain", "application/json", "text/x-rst", "text/asciidoc"}
+
+// benchPercentiles are the latencies reported for each route.
+var benchPercentiles = []float64{0.5, 0.9, 0.99}
+
+// benchRequest is one request the benchmark makes over and over, reported
+// under name with the rest of its kind. It fails unless it gets status, or
+// 200 if that's zero.
+type benchRequest struct {
+	name   string
+	url    string
+	accept string
+	status int
+}
+
+// benchResult is every latency seen for one kind of request.
+type benchResult struct {
+	name      string
+	latencies []time.Duration
+	errors    int
+}
+
+func runBench(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	target := fs.String("target", "http://localhost:8080", "the URL of the ynal to benchmark, including any base path")
+	concurrency := fs.Int("concurrency", 16, "how many requests to keep in flight")
+	duration := fs.Duration("duration", 10*time.Second, "how long to run for")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *concurrency < 1 {
+		return errors.New("bench: --concurrency must be at least 1")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return bench(ctx, *target, *concurrency, *duration, w)
+}
+
+// bench fetches every route on target, in every content type, from
+// concurrency clients at once for duration or until ctx is done, then writes
+// the latency percentiles of each kind of request to w.
+func bench(ctx context.Context, target string, concurrency int, duration time.Duration, w io.Writer) error {
+	client := &http.Client{
+		Transport: &http.Transport{MaxIdleConnsPerHost: concurrency},
+		Timeout:   30 * time.Second,
+		// redirects are measured as they are, not followed
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	requests, err := benchRequests(ctx, client, target)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	fmt.Fprintf(w, "benchmarking %s: %d routes, %d clients, %s\n", target, len(requests), concurrency, duration)
+
+	results := make([][]benchResult, concurrency)
+	start := time.Now()
+
+	wg := sync.WaitGroup{}
+	for i := range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			results[i] = benchClient(ctx, client, requests, i)
+		}()
+	}
+
+	wg.Wait()
+
+	writeBenchReport(w, mergeBenchResults(results), time.Since(start))
+
+	return nil
+}
+
+// benchRequests lists every request to make, finding the licenses from
+// target's JSON index.
+func benchRequests(ctx context.Context, client *http.Client, target string) ([]benchRequest, error) {
+	base, err := url.Parse(strings.TrimSuffix(target, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse target: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", base.String()+"/", nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch index: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch index: %s", resp.Status)
+	}
+
+	var index struct {
+		Licenses []struct {
+			ID  string `json:"id"`
+			URL string `json:"url"`
+		} `json:"licenses"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		return nil, fmt.Errorf("could not decode index: %w", err)
+	}
+
+	if len(index.Licenses) == 0 {
+		return nil, errors.New("no licenses to benchmark")
+	}
+
+	at := func(path string) string {
+		return base.String() + path
+	}
+
+	requests := []benchRequest{}
+	for _, mediatype := range []string{"text/html", "text/plain", "application/json"} {
+		requests = append(requests, benchRequest{name: "index " + mediatype, url: at("/"), accept: mediatype})
+	}
+
+	ids := []string{}
+	for _, l := range index.Licenses {
+		ids = append(ids, l.ID)
+		page := base.ResolveReference(&url.URL{Path: l.URL}).String()
+
+		for _, mediatype := range benchMediaTypes {
+			requests = append(requests, benchRequest{name: "license " + mediatype, url: page, accept: mediatype})
+		}
+
+		// what browsers send, so negotiation has something to weigh
+		requests = append(requests,
+			benchRequest{name: "license negotiated", url: page, accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"},
+			benchRequest{name: "raw", url: at("/raw/" + l.ID)},
+			benchRequest{name: "raw rewrapped", url: at("/raw/" + l.ID + "?width=66&eol=crlf")},
+			benchRequest{name: "download", url: at("/download/" + l.ID)},
+			benchRequest{name: "header", url: at("/header/" + l.ID + "?lang=go&year=2024&holder=Bench")},
+		)
+	}
+
+	requests = append(requests,
+		benchRequest{name: "search", url: at("/search?q=" + url.QueryEscape(ids[0])), accept: "text/html"},
+		benchRequest{name: "bundle", url: at("/bundle?format=tar.gz&licenses=" + strings.Join(ids[:min(len(ids), 5)], ","))},
+		benchRequest{name: "not found", url: at("/no-such-license"), accept: "text/html", status: http.StatusNotFound},
+	)
+
+	return requests, nil
+}
+
+// benchClient makes requests one after another, starting at the offset'th,
+// until ctx is done.
+func benchClient(ctx context.Context, client *http.Client, requests []benchRequest, offset int) []benchResult {
+	results := map[string]*benchResult{}
+
+	for i := offset; ctx.Err() == nil; i++ {
+		br := requests[i%len(requests)]
+
+		res, ok := results[br.name]
+		if !ok {
+			res = &benchResult{name: br.name}
+			results[br.name] = res
+		}
+
+		start := time.Now()
+		err := benchFetch(ctx, client, br)
+		elapsed := time.Since(start)
+
+		// a request cut off by the end of the run doesn't count either way
+		if ctx.Err() != nil {
+			break
+		}
+
+		if err != nil {
+			res.errors++
+			continue
+		}
+
+		res.latencies = append(res.latencies, elapsed)
+	}
+
+	all := []benchResult{}
+	for _, res := range results {
+		all = append(all, *res)
+	}
+
+	return all
+}
+
+// benchFetch makes br and reads the whole response.
+func benchFetch(ctx context.Context, client *http.Client, br benchRequest) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", br.url, nil)
+	if err != nil {
+		return err
+	}
+
+	if br.accept != "" {
+		req.Header.Set("Accept", br.accept)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return err
+	}
+
+	expected := br.status
+	if expected == 0 {
+		expected = http.StatusOK
+	}
+
+	if resp.StatusCode != expected {
+		return fmt.Errorf("%s: expected %d, got %s", br.url, expected, resp.Status)
+	}
+
+	return nil
+}
+
+// mergeBenchResults combines every client's results by name, sorted by name.
+func mergeBenchResults(results [][]benchResult) []benchResult {
+	byName := map[string]*benchResult{}
+
+	for _, client := range results {
+		for _, res := range client {
+			merged, ok := byName[res.name]
+			if !ok {
+				merged = &benchResult{name: res.name}
+				byName[res.name] = merged
+			}
+
+			merged.latencies = append(merged.latencies, res.latencies...)
+			merged.errors += res.errors
+		}
+	}
+
+	merged := []benchResult{}
+	for _, res := range byName {
+		merged = append(merged, *res)
+	}
+
+	slices.SortFunc(merged, func(a, b benchResult) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return merged
+}
+
+// writeBenchReport writes a table of the count, errors, and latency
+// percentiles of every kind of request, then of all of them together.
+func writeBenchReport(w io.Writer, results []benchResult, elapsed time.Duration) {
+	total := benchResult{name: "total"}
+	for _, res := range results {
+		total.latencies = append(total.latencies, res.latencies...)
+		total.errors += res.errors
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "route\trequests\terrors\tp50\tp90\tp99\tmax\t")
+
+	for _, res := range append(results, total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t", res.name, len(res.latencies), res.errors)
+
+		for _, p := range benchPercentiles {
+			fmt.Fprintf(tw, "%s\t", formatLatency(percentile(res.latencies, p)))
+		}
+
+		fmt.Fprintf(tw, "%s\t\n", formatLatency(percentile(res.latencies, 1)))
+	}
+
+	tw.Flush()
+
+	fmt.Fprintf(w, "%.1f requests/s\n", float64(len(total.latencies))/elapsed.Seconds())
+}
+
+// percentile returns the latency p of latencies are at or under, sorting
+// latencies in place. It's zero if there are none.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+
+	slices.Sort(latencies)
+
+	i := int(math.Ceil(p*float64(len(latencies)))) - 1
+
+	return latencies[max(i, 0)]
+}
+
+func formatLatency(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
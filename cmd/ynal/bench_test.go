@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{}
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	tt := []struct {
+		p        float64
+		expected time.Duration
+	}{
+		{p: 0.5, expected: 50 * time.Millisecond},
+		{p: 0.9, expected: 90 * time.Millisecond},
+		{p: 0.99, expected: 99 * time.Millisecond},
+		{p: 1, expected: 100 * time.Millisecond},
+		{p: 0, expected: time.Millisecond},
+	}
+
+	for _, tc := range tt {
+		if got := percentile(latencies, tc.p); got != tc.expected {
+			t.Errorf("p%v: expected %s, got %s", tc.p*100, tc.expected, got)
+		}
+	}
+
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Errorf("expected no latencies to be 0, got %s", got)
+	}
+}
+
+func TestBench(t *testing.T) {
+	h, err := ynalhttp.New(ynalhttp.WithLicenses([]ynal.LicenseData{ynal.NewLicense("MIT", "mit text\n"), ynal.NewLicense("Zlib", "zlib text\n")}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	out := &bytes.Buffer{}
+	if err := bench(context.Background(), srv.URL, 4, 200*time.Millisecond, out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	report := out.String()
+
+	for _, want := range []string{"p50", "p99", "license text/html", "license application/json", "raw rewrapped", "bundle", "requests/s"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("expected the report to contain %q, got:\n%s", want, report)
+		}
+	}
+
+	for _, line := range strings.Split(report, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 4 && fields[0] == "total" && fields[2] != "0" {
+			t.Errorf("expected no errors against a healthy server, got:\n%s", report)
+		}
+	}
+}
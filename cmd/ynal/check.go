@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+// checkAccepts are the Accept headers every license page is checked with, one
+// per representation.
+var checkAccepts = []string{"text/html", "application/json", "text/plain"}
+
+// check does everything serving would short of listening: it loads the
+// licenses, parses the templates, renders every page in every representation,
+// and loads the TLS certificate. It reports every problem it finds.
+func check(cfg Config, w io.Writer) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, err := openStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if c, ok := store.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	errs := []error{}
+
+	if cfg.TLS.Enabled() {
+		if _, err := tls.LoadX509KeyPair(cfg.TLS.Cert, cfg.TLS.Key); err != nil {
+			errs = append(errs, fmt.Errorf("tls: %w", err))
+		}
+	}
+
+	custom, err := loadCustom(cfg)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	shared := []ynalhttp.Option{
+		ynalhttp.WithBasePath(cfg.BasePath),
+		ynalhttp.WithSiteURL(cfg.SiteURL),
+		ynalhttp.WithTheme(cfg.Theme),
+		ynalhttp.WithCustom(custom),
+	}
+
+	h, err := ynalhttp.New(append(shared, ynalhttp.WithStore(store))...)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	// building each host's handler prerenders its pages, which is check
+	// enough for them
+	if _, err := newHostRouter(cfg.Hosts, shared, h); err != nil {
+		errs = append(errs, fmt.Errorf("hosts: %w", err))
+	}
+
+	exceptions, err := ynal.EmbeddedExceptions()
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
+	paths := []string{"/", "/api/v1/licenses", "/compatibility", "/stats", "/version"}
+	for _, l := range store.List() {
+		paths = append(paths, l.URL, "/raw/"+l.ID, "/api/v1/licenses/"+l.ID, "/header/"+l.ID)
+	}
+
+	for _, e := range exceptions {
+		paths = append(paths, e.URL)
+	}
+
+	for _, d := range custom {
+		paths = append(paths, d.URL, "/raw/custom/"+d.ID)
+	}
+
+	checked := 0
+	for _, path := range paths {
+		for _, accept := range checkAccepts {
+			r := httptest.NewRequest("GET", cfg.BasePath+path, nil)
+			r.Header.Set("Accept", accept)
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, r)
+			checked++
+
+			// some routes only answer in some formats, which is fine
+			if rec.Code >= 500 {
+				errs = append(errs, fmt.Errorf("GET %s as %s: %d %s", path, accept, rec.Code, rec.Body.String()))
+			} else if rec.Code != http.StatusOK && accept == "text/html" {
+				errs = append(errs, fmt.Errorf("GET %s: %d", path, rec.Code))
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "ok: %d licenses, %d responses rendered\n", len(store.List()), checked)
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	cfg := defaultConfig()
+	cfg.BasePath = "/licenses"
+
+	if err := check(cfg, buf); err != nil {
+		t.Fatalf("expected the embedded catalog to pass, got: %s", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "ok: 7 licenses") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestCheckFailures(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range map[string]string{"Foo.txt": "foo\n", "foo.txt": "also foo\n", "Empty.txt": ""} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatalf("could not write license: %s", err)
+		}
+	}
+
+	cfg := defaultConfig()
+	cfg.LicenseDir = dir
+	cfg.TLS.Cert = filepath.Join(dir, "missing.pem")
+	cfg.TLS.Key = filepath.Join(dir, "missing.key")
+
+	err := check(cfg, new(bytes.Buffer))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	for _, want := range []string{"tls:", "served at /foo", "Empty: empty text"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestCheckCustom(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Corp-EULA.txt"), []byte("corp eula\n"), 0644); err != nil {
+		t.Fatalf("could not write document: %s", err)
+	}
+
+	cfg := defaultConfig()
+	cfg.CustomDir = dir
+
+	buf := new(bytes.Buffer)
+	if err := check(cfg, buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "Empty.txt"), nil, 0644); err != nil {
+		t.Fatalf("could not write document: %s", err)
+	}
+
+	if err := check(cfg, buf); err == nil || !strings.Contains(err.Error(), "invalid custom documents") {
+		t.Fatalf("expected the empty document to fail, got: %v", err)
+	}
+}
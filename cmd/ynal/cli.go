@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/spdx"
+)
+
+const usage = `usage: ynal [command]
+
+With no command, ynal serves licenses over HTTP.
+
+commands:
+  serve [--config FILE] [--dev]           serve licenses over HTTP
+        [--check]                         or just check everything would
+                                          serve, without listening
+  list                                    print the supported licenses
+  get <id> [--format txt|json|md]         print a license to stdout
+  init <id> [--year Y] [--holder NAME]    write LICENSE (and NOTICE where
+        [--project NAME] [--dir DIR]      applicable) into a project
+        [--force]
+  notice [--project NAME]                 print an Apache-style NOTICE file
+        [--copyright LINE]...
+        [--component NAME=ID]...
+  validate <expression>                   check an SPDX license expression
+  export <dir> [--config FILE]            render every page into dir as a
+        [--url URL]                       static site
+  bench [--target URL]                    load a running ynal with every
+        [--concurrency N]                 route and content type, and
+        [--duration D]                    report latency percentiles
+`
+
+// noticeRequired lists licenses that expect a NOTICE file to accompany them.
+var noticeRequired = map[string]bool{
+	"apache_2": true,
+}
+
+func runCommand(args []string, w io.Writer) error {
+	switch args[0] {
+	case "serve":
+		return runServe(args[1:])
+	case "list":
+		return runList(args[1:], w)
+	case "get":
+		return runGet(args[1:], w)
+	case "init":
+		return runInit(args[1:], w)
+	case "notice":
+		return runNotice(args[1:], w)
+	case "validate":
+		return runValidate(args[1:], w)
+	case "export":
+		return runExport(args[1:], w)
+	case "bench":
+		return runBench(args[1:], w)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(w, usage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command: %s\n\n%s", args[0], usage)
+	}
+}
+
+func runList(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, l := range licenses {
+		fmt.Fprintf(tw, "%s\t%s\n", l.ID, l.Title)
+	}
+
+	return tw.Flush()
+}
+
+func runGet(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	format := fs.String("format", "txt", "output format: txt, json, or md")
+
+	// allow flags both before and after the license ID
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("get: missing license ID")
+	}
+	id := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return err
+	}
+
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		return err
+	}
+
+	l, ok := ynal.FindLicense(licenses, id)
+	if !ok {
+		return fmt.Errorf("get: no such license: %s", id)
+	}
+
+	var data []byte
+
+	switch *format {
+	case "txt":
+		data = []byte(l.Text)
+	case "json":
+		data, err = json.Marshal(l)
+		if err != nil {
+			return fmt.Errorf("get: could not marshal JSON: %w", err)
+		}
+		data = append(data, '\n')
+	case "md":
+		data = toMarkdown(l)
+	default:
+		return fmt.Errorf("get: unrecognized format: %s", *format)
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func runInit(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	year := fs.String("year", strconv.Itoa(time.Now().Year()), "copyright year")
+	holder := fs.String("holder", "", "copyright holder (defaults to git config user.name)")
+	project := fs.String("project", "", "project name for NOTICE files (defaults to the directory name)")
+	dir := fs.String("dir", ".", "directory to write files into")
+	force := fs.Bool("force", false, "overwrite existing files")
+
+	// allow flags both before and after the license ID
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("init: missing license ID")
+	}
+	id := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return err
+	}
+
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		return err
+	}
+
+	l, ok := ynal.FindLicense(licenses, id)
+	if !ok {
+		return fmt.Errorf("init: no such license: %s", id)
+	}
+
+	if *holder == "" {
+		*holder = gitConfig(*dir, "user.name")
+	}
+
+	if *project == "" {
+		abs, err := filepath.Abs(*dir)
+		if err != nil {
+			return fmt.Errorf("init: could not resolve directory: %w", err)
+		}
+		*project = filepath.Base(abs)
+	}
+
+	subs := map[string]string{"YEAR": *year}
+	if *holder != "" {
+		subs["COPYRIGHT HOLDER"] = *holder
+	}
+
+	files := map[string]string{
+		"LICENSE": ynal.Substitute(l.Text, subs),
+	}
+
+	if noticeRequired[l.ID] {
+		notice, err := ynal.Notice{Project: *project, Copyright: []string{*year + " " + *holder}}.Render(licenses)
+		if err != nil {
+			return fmt.Errorf("init: could not render NOTICE: %w", err)
+		}
+
+		files["NOTICE"] = notice
+	}
+
+	if !*force {
+		for name := range files {
+			if _, err := os.Stat(filepath.Join(*dir, name)); err == nil {
+				return fmt.Errorf("init: %s already exists, pass --force to overwrite", name)
+			}
+		}
+	}
+
+	for _, name := range []string{"LICENSE", "NOTICE"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+
+		if err := os.WriteFile(filepath.Join(*dir, name), []byte(content), 0644); err != nil {
+			return fmt.Errorf("init: could not write %s: %w", name, err)
+		}
+
+		fmt.Fprintf(w, "wrote %s\n", filepath.Join(*dir, name))
+	}
+
+	return nil
+}
+
+func runNotice(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("notice", flag.ContinueOnError)
+	project := fs.String("project", "", "project name (defaults to the current directory's name)")
+
+	notice := ynal.Notice{}
+
+	fs.Func("copyright", "a copyright line, may be repeated (defaults to this year and git config user.name)", func(s string) error {
+		notice.Copyright = append(notice.Copyright, s)
+		return nil
+	})
+
+	fs.Func("component", "a bundled component as NAME=LICENSE, may be repeated", func(s string) error {
+		name, license, _ := strings.Cut(s, "=")
+		notice.Components = append(notice.Components, ynal.Component{Name: name, License: license})
+		return nil
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *project == "" {
+		abs, err := filepath.Abs(".")
+		if err != nil {
+			return fmt.Errorf("notice: could not resolve directory: %w", err)
+		}
+		*project = filepath.Base(abs)
+	}
+	notice.Project = *project
+
+	if len(notice.Copyright) == 0 {
+		notice.Copyright = []string{strings.TrimSpace(strconv.Itoa(time.Now().Year()) + " " + gitConfig(".", "user.name"))}
+	}
+
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		return err
+	}
+
+	text, err := notice.Render(licenses)
+	if err != nil {
+		return fmt.Errorf("notice: %w", err)
+	}
+
+	_, err = io.WriteString(w, text)
+	return err
+}
+
+func runValidate(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// the expression is usually quoted, but doesn't have to be
+	if fs.NArg() == 0 {
+		return errors.New("validate: missing expression")
+	}
+
+	e, err := spdx.Parse(strings.Join(fs.Args(), " "))
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		return err
+	}
+
+	exceptions, err := ynal.EmbeddedExceptions()
+	if err != nil {
+		return err
+	}
+
+	found, unknown := e.Resolve(licenses)
+
+	foundExceptions, unknownExceptions := e.ResolveExceptions(exceptions)
+	found = append(found, foundExceptions...)
+	unknown = append(unknown, unknownExceptions...)
+
+	fmt.Fprintln(w, e.String())
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, l := range found {
+		fmt.Fprintf(tw, "%s\t%s\n", l.SPDXID(), l.ID)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("validate: not in the catalog: %s", strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// gitConfig returns the value of a git config key, or the empty string if git
+// isn't available or the key isn't set.
+func gitConfig(dir string, key string) string {
+	cmd := exec.Command("git", "config", "--get", key)
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+func toMarkdown(l ynal.LicenseData) []byte {
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "# %s\n\n", l.Title)
+
+	// escape angle brackets so placeholders like <YEAR> aren't swallowed as
+	// inline HTML
+	buf.WriteString(strings.NewReplacer("<", "\\<", ">", "\\>").Replace(l.Text))
+
+	return buf.Bytes()
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestCommandGet(t *testing.T) {
+	expected, err := fs.ReadFile(ynal.Licenses, "licenses/MIT.txt")
+	if err != nil {
+		t.Fatalf("error reading license: %s", err)
+	}
+
+	tt := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "default format",
+			args: []string{"get", "mit"},
+		},
+		{
+			name: "flag after id",
+			args: []string{"get", "mit", "--format", "txt"},
+		},
+		{
+			name: "flag before id",
+			args: []string{"get", "--format", "txt", "mit"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+
+			if err := runCommand(tc.args, buf); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if buf.String() != string(expected) {
+				t.Fatalf("expected:\n---\n%s\n---\ngot:\n---\n%s\n---\n", string(expected), buf.String())
+			}
+		})
+	}
+}
+
+func TestCommandGetMarkdown(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	if err := runCommand([]string{"get", "mit", "--format", "md"}, buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "# MIT\n\nCopyright \\<YEAR\\> \\<COPYRIGHT HOLDER\\>\n") {
+		t.Fatalf("unexpected markdown: %q", buf.String()[:60])
+	}
+}
+
+func TestCommandGetUnknown(t *testing.T) {
+	if err := runCommand([]string{"get", "nope"}, new(bytes.Buffer)); err == nil {
+		t.Fatalf("expected error for unknown license")
+	}
+}
+
+func TestCommandList(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	if err := runCommand([]string{"list"}, buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !regexp.MustCompile(`(?m)^mit\s+MIT$`).MatchString(buf.String()) {
+		t.Fatalf("expected mit in listing, got:\n%s", buf.String())
+	}
+}
+
+func TestCommandInit(t *testing.T) {
+	dir := t.TempDir()
+
+	err := runCommand([]string{"init", "mit", "--dir", dir, "--year", "2024", "--holder", "Jane Doe"}, new(bytes.Buffer))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "LICENSE"))
+	if err != nil {
+		t.Fatalf("could not read LICENSE: %s", err)
+	}
+
+	if !strings.HasPrefix(string(got), "Copyright 2024 Jane Doe\n") {
+		t.Fatalf("placeholders not filled: %q", string(got)[:40])
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "NOTICE")); err == nil {
+		t.Fatalf("did not expect a NOTICE file for mit")
+	}
+
+	err = runCommand([]string{"init", "mit", "--dir", dir}, new(bytes.Buffer))
+	if err == nil {
+		t.Fatalf("expected an error when LICENSE already exists")
+	}
+
+	err = runCommand([]string{"init", "apache_2", "--dir", dir, "--force", "--year", "2024", "--holder", "Jane Doe", "--project", "widget"}, new(bytes.Buffer))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	notice, err := os.ReadFile(filepath.Join(dir, "NOTICE"))
+	if err != nil {
+		t.Fatalf("could not read NOTICE: %s", err)
+	}
+
+	if string(notice) != "widget\nCopyright 2024 Jane Doe\n" {
+		t.Fatalf("unexpected NOTICE: %q", string(notice))
+	}
+}
+
+func TestCommandNotice(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	err := runCommand([]string{"notice", "--project", "widget", "--copyright", "2024 Jane Doe", "--component", "gizmo=mit"}, buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "widget\nCopyright 2024 Jane Doe\n\nThis product bundles the following components:\n\ngizmo\nLicensed under MIT\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected NOTICE: %q", buf.String())
+	}
+
+	err = runCommand([]string{"notice", "--project", "widget", "--component", "gizmo=nope"}, new(bytes.Buffer))
+	if err == nil {
+		t.Fatalf("expected an error for an unknown license")
+	}
+}
+
+func TestCommandValidate(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	if err := runCommand([]string{"validate", "mit OR (apache_2 AND bsd-3-clause)"}, buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "MIT OR Apache-2.0 AND BSD-3-Clause\nMIT           mit\nApache-2.0    apache_2\nBSD-3-Clause  bsd_3\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	buf.Reset()
+
+	if err := runCommand([]string{"validate", "gpl_3 with classpath-exception-2.0"}, buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "GPL-3.0-or-later WITH Classpath-exception-2.0\n") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	if err := runCommand([]string{"validate", "MIT", "AND", "Nope-1.0"}, new(bytes.Buffer)); err == nil {
+		t.Fatalf("expected an error for an unknown license")
+	}
+
+	if err := runCommand([]string{"validate", "MIT OR"}, new(bytes.Buffer)); err == nil {
+		t.Fatalf("expected an error for a malformed expression")
+	}
+}
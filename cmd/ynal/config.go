@@ -0,0 +1,878 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/packrat386/ynal/spdx"
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+// Config controls how ynal serves. It is loaded from an optional TOML file and
+// then overridden by YNAL_* environment variables, so a container can tweak a
+// single setting without shipping a whole file.
+type Config struct {
+	// Addr is the address the plain HTTP server listens on. It may be empty
+	// when TLS is enabled to serve HTTPS only.
+	Addr string `toml:"addr"`
+
+	// H2C lets the plain HTTP server speak HTTP/2 without TLS, for load
+	// balancers and clients that use it.
+	H2C bool `toml:"h2c"`
+
+	// TrustedProxies are the CIDRs (or single IPs) of reverse proxies whose
+	// Forwarded and X-Forwarded-For headers are believed when working out the
+	// client's IP.
+	TrustedProxies []string `toml:"trusted_proxies"`
+
+	// GRPCAddr enables the gRPC service on the given address when set.
+	GRPCAddr string `toml:"grpc_addr"`
+
+	// DebugAddr enables a separate listener for pprof and runtime stats when
+	// set. Anyone who can reach it can profile the server, so keep it private.
+	DebugAddr string `toml:"debug_addr"`
+
+	// Tracing exports a span for every request over OTLP, configured by the
+	// standard OTEL_* environment variables.
+	Tracing bool `toml:"tracing"`
+
+	TLS TLSConfig `toml:"tls"`
+	Log LogConfig `toml:"log"`
+
+	// BasePath serves everything under a prefix, like /licenses, for when ynal
+	// doesn't own the whole host.
+	BasePath string `toml:"base_path"`
+
+	// SiteURL is the URL ynal is reached at, which pages link to absolutely,
+	// like in canonical links. The base path goes after it.
+	SiteURL string `toml:"site_url"`
+
+	// Theme is the theme pages are shown in until a visitor picks another.
+	Theme string `toml:"theme"`
+
+	Index   IndexConfig   `toml:"index"`
+	Signing SigningConfig `toml:"signing"`
+	Render  RenderConfig  `toml:"render"`
+
+	Negotiation NegotiationConfig `toml:"negotiation"`
+
+	// CacheControl is sent as the Cache-Control header on every response.
+	CacheControl string `toml:"cache_control"`
+
+	// LicenseDir serves *.txt licenses from a directory on disk instead of
+	// the embedded catalog.
+	LicenseDir string `toml:"license_dir"`
+
+	// CustomDir serves the organization's own licenses and policies from a
+	// directory, under /custom/ and apart from the open source licenses.
+	CustomDir string `toml:"custom_dir"`
+
+	SPDX   SPDXConfig   `toml:"spdx"`
+	SQLite SQLiteConfig `toml:"sqlite"`
+	S3     S3Config     `toml:"s3"`
+	Admin  AdminConfig  `toml:"admin"`
+
+	Acceptances AcceptancesConfig `toml:"acceptances"`
+
+	Webhooks    WebhookConfig     `toml:"webhooks"`
+	Stats       StatsConfig       `toml:"stats"`
+	Maintenance MaintenanceConfig `toml:"maintenance"`
+	Scan        ScanConfig        `toml:"scan"`
+	GitHub      GitHubConfig      `toml:"github"`
+
+	// Hosts serve other catalogs, with other templates, to requests for other
+	// host names. Requests for any host not listed get everything above.
+	Hosts []HostConfig `toml:"hosts"`
+}
+
+// HostConfig is a separately branded instance, served to requests whose Host
+// header is one of Names.
+type HostConfig struct {
+	Names []string `toml:"names"`
+
+	// LicenseDir serves *.txt licenses from a directory. The embedded catalog
+	// is served when it's empty.
+	LicenseDir string `toml:"license_dir"`
+
+	// TemplateDir holds *.tmpl files that replace the embedded templates of
+	// the same name.
+	TemplateDir string `toml:"template_dir"`
+
+	// Theme defaults to the top-level theme.
+	Theme string `toml:"theme"`
+
+	// SiteURL defaults to https:// and the first of Names.
+	SiteURL string `toml:"site_url"`
+}
+
+// IndexConfig sets the order licenses are listed in on the index.
+type IndexConfig struct {
+	// Order is "alphabetical", "priority", or "popular". See
+	// ynalhttp.IndexOrder.
+	Order string `toml:"order"`
+
+	// Priority is the license IDs listed first in the priority order.
+	Priority []string `toml:"priority"`
+}
+
+// SigningConfig signs the plain text of every license. See
+// ynalhttp.WithSigningKey.
+type SigningConfig struct {
+	// Key is a PEM file holding an Ed25519 private key.
+	Key string `toml:"key"`
+}
+
+// RenderConfig bounds the memory taken by rendered license pages. See
+// ynalhttp.WithRenderBudget.
+type RenderConfig struct {
+	// CacheSize keeps at most this many megabytes of rendered pages, per
+	// host. Zero keeps every page.
+	CacheSize int `toml:"cache_size"`
+}
+
+// NegotiationConfig controls how the Accept header picks a representation.
+type NegotiationConfig struct {
+	// Strict rejects a malformed Accept header with a 400 rather than ignoring
+	// the parts that can't be read. See ynalhttp.WithStrictAccept.
+	Strict bool `toml:"strict"`
+
+	// Prefer is the media types that win ties, like the one */* makes of
+	// everything, in order. BrowserPrefer overrides it for browsers. See
+	// ynalhttp.WithPreferredTypes.
+	Prefer        []string `toml:"prefer"`
+	BrowserPrefer []string `toml:"browser_prefer"`
+}
+
+// AcceptancesConfig records who has accepted which licenses and custom
+// documents, at /api/v1/acceptances. See ynalhttp.WithAcceptances.
+type AcceptancesConfig struct {
+	// Enabled serves the API in admin mode, authenticated with the admin
+	// credentials. Acceptances are kept in the sqlite.path database.
+	Enabled bool `toml:"enabled"`
+}
+
+// MaintenanceConfig answers every request but the health check with a 503.
+// Sending ynal SIGUSR1 toggles it while running.
+type MaintenanceConfig struct {
+	// Enabled starts ynal in maintenance mode.
+	Enabled bool `toml:"enabled"`
+
+	// RetryAfter is how long clients are told to wait before trying again.
+	RetryAfter time.Duration `toml:"retry_after"`
+}
+
+// ScanConfig looks up the licenses of dependencies in manifests uploaded to
+// /api/v1/scan that don't declare them. See the manifest package.
+type ScanConfig struct {
+	// Registry enables lookups when set. It's the URL of a deps.dev
+	// compatible API, like https://api.deps.dev.
+	Registry string `toml:"registry"`
+}
+
+func (s ScanConfig) Enabled() bool {
+	return s.Registry != ""
+}
+
+// GitHubConfig serves /detect, which looks up the license GitHub detects in a
+// repository. See the github package.
+type GitHubConfig struct {
+	// Enabled serves /detect.
+	Enabled bool `toml:"enabled"`
+
+	// Token authenticates lookups, which GitHub allows far more of than
+	// anonymous ones.
+	Token string `toml:"token"`
+
+	// URL is the API, for GitHub Enterprise. Defaults to the public one.
+	URL string `toml:"url"`
+
+	// CacheTTL is how long a repository's license is remembered. Defaults to
+	// an hour.
+	CacheTTL time.Duration `toml:"cache_ttl"`
+}
+
+// StatsConfig keeps the per-license hit counts served at /stats across
+// restarts. They're only kept in memory otherwise.
+type StatsConfig struct {
+	// Path enables saving when set.
+	Path string `toml:"path"`
+}
+
+func (s StatsConfig) Enabled() bool {
+	return s.Path != ""
+}
+
+// WebhookConfig POSTs an event to other services whenever the licenses being
+// served change. See the webhook package for what's sent.
+type WebhookConfig struct {
+	// URLs enables webhooks when set.
+	URLs []string `toml:"urls"`
+
+	// Secret signs every event when set.
+	Secret string `toml:"secret"`
+}
+
+func (w WebhookConfig) Enabled() bool {
+	return len(w.URLs) > 0
+}
+
+// S3Config serves licenses from an S3-compatible bucket. See the s3store
+// package for how objects are laid out.
+type S3Config struct {
+	// Bucket enables S3 when set.
+	Bucket   string `toml:"bucket"`
+	Prefix   string `toml:"prefix"`
+	Endpoint string `toml:"endpoint"`
+	Region   string `toml:"region"`
+
+	AccessKeyID     string `toml:"access_key_id"`
+	SecretAccessKey string `toml:"secret_access_key"`
+
+	// Refresh is how often to check the bucket for changes.
+	Refresh time.Duration `toml:"refresh"`
+}
+
+func (s S3Config) Enabled() bool {
+	return s.Bucket != ""
+}
+
+// SQLiteConfig serves licenses from a SQLite database, which the admin API
+// writes licenses and aliases to.
+type SQLiteConfig struct {
+	// Path enables the database when set. It is created, and filled with the
+	// embedded licenses, if it doesn't exist.
+	Path string `toml:"path"`
+}
+
+func (s SQLiteConfig) Enabled() bool {
+	return s.Path != ""
+}
+
+// AdminConfig configures the admin API for adding and removing licenses at
+// runtime. Changes are written to license_dir, which is required. The API is
+// only served in admin mode, with --admin.
+type AdminConfig struct {
+	// Serve is set by --admin. Without it, ynal runs read-only and serves no
+	// endpoint that changes anything, however the rest of AdminConfig is set.
+	Serve bool `toml:"-"`
+
+	// Token is a single bearer token, logged as "admin" in the audit log.
+	Token string `toml:"token"`
+
+	// Tokens maps a name for each token holder, used in the audit log, to
+	// their bearer token. Requests must send one of them as
+	// `Authorization: Bearer <token>`.
+	Tokens map[string]string `toml:"tokens"`
+
+	// ClientCerts maps a name for each administrator, as in Tokens, to the
+	// identity in their client certificate: its subject's common name, or a
+	// DNS name, email address, or URI it's issued to. Requires tls.client_ca.
+	ClientCerts map[string]string `toml:"client_certs"`
+}
+
+// Enabled reports whether any credentials for the admin API are configured.
+func (a AdminConfig) Enabled() bool {
+	return len(a.AllTokens()) > 0 || len(a.ClientCerts) > 0
+}
+
+// setAdminMode switches the admin API on, for --admin, or off. Admin mode
+// needs credentials to authenticate with.
+func (a *AdminConfig) setAdminMode(on bool) error {
+	if on && !a.Enabled() {
+		return errors.New("--admin requires admin.token, admin.tokens, or admin.client_certs")
+	}
+
+	a.Serve = on
+
+	return nil
+}
+
+// AllTokens merges Token into Tokens.
+func (a AdminConfig) AllTokens() map[string]string {
+	tokens := map[string]string{}
+	for name, token := range a.Tokens {
+		tokens[name] = token
+	}
+
+	if a.Token != "" {
+		tokens["admin"] = a.Token
+	}
+
+	return tokens
+}
+
+type TLSConfig struct {
+	// Addr is the address the HTTPS server listens on.
+	Addr string `toml:"addr"`
+	Cert string `toml:"cert"`
+	Key  string `toml:"key"`
+
+	// Redirect makes the plain HTTP listener redirect everything to HTTPS
+	// instead of serving licenses itself.
+	Redirect bool `toml:"redirect"`
+
+	// HTTP3 serves HTTP/3 over QUIC on the same port as Addr, over UDP.
+	HTTP3 bool `toml:"http3"`
+
+	// ClientCA is a PEM file of the CAs client certificates are verified
+	// against. Setting it requires HTTPS clients to present one, unless
+	// ClientAuth says otherwise.
+	ClientCA string `toml:"client_ca"`
+
+	// ClientAuth is "require" (the default) to turn away HTTPS clients
+	// without a certificate signed by ClientCA, or "optional" to only verify
+	// the ones that present one, so they can use the admin API.
+	ClientAuth string `toml:"client_auth"`
+}
+
+func (t TLSConfig) Enabled() bool {
+	return t.Cert != "" || t.Key != ""
+}
+
+// SPDXConfig mirrors the official SPDX license list and serves it in place of
+// the embedded catalog.
+type SPDXConfig struct {
+	// Dir enables syncing when set. Licenses are cached here so a restart
+	// without network access still serves the last sync.
+	Dir string `toml:"dir"`
+
+	// ListURL is where the SPDX license list is fetched from.
+	ListURL string `toml:"list_url"`
+
+	// Refresh is how often to sync.
+	Refresh time.Duration `toml:"refresh"`
+}
+
+func (s SPDXConfig) Enabled() bool {
+	return s.Dir != ""
+}
+
+type LogConfig struct {
+	// Access logs one line per request.
+	Access bool `toml:"access"`
+
+	// Format is one of "text", "common", "combined" (the Apache log formats),
+	// or "json" for one JSON object per line.
+	Format string `toml:"format"`
+
+	// File writes the access log to a file instead of the standard output.
+	File string `toml:"file"`
+
+	// MaxSize rotates File once it grows past this many megabytes, keeping
+	// MaxBackups old files. Zero never rotates.
+	MaxSize    int `toml:"max_size"`
+	MaxBackups int `toml:"max_backups"`
+
+	// Audit appends a JSON line for every administrative action to this
+	// file, or to the standard output if it's "stdout". Empty disables it.
+	Audit string `toml:"audit"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Addr: "localhost:8080",
+		TLS: TLSConfig{
+			Addr: "localhost:8443",
+		},
+		Log: LogConfig{
+			Access:     true,
+			Format:     "text",
+			MaxBackups: 3,
+		},
+		SPDX: SPDXConfig{
+			ListURL: spdx.DefaultListURL,
+			Refresh: 24 * time.Hour,
+		},
+		S3: S3Config{
+			Endpoint: "https://s3.amazonaws.com",
+			Region:   "us-east-1",
+			Refresh:  5 * time.Minute,
+		},
+		Maintenance: MaintenanceConfig{
+			RetryAfter: 5 * time.Minute,
+		},
+	}
+}
+
+// loadConfig reads the config file at path (if path is non-empty), applies
+// environment overrides, and validates the result.
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+
+	if path != "" {
+		md, err := toml.DecodeFile(path, &cfg)
+		if err != nil {
+			return Config{}, fmt.Errorf("could not read config: %w", err)
+		}
+
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return Config{}, fmt.Errorf("unrecognized config keys: %v", undecoded)
+		}
+	}
+
+	if err := applyEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func applyEnv(cfg *Config) error {
+	strs := map[string]*string{
+		"YNAL_ADDR":                 &cfg.Addr,
+		"YNAL_GRPC_ADDR":            &cfg.GRPCAddr,
+		"YNAL_DEBUG_ADDR":           &cfg.DebugAddr,
+		"YNAL_TLS_ADDR":             &cfg.TLS.Addr,
+		"YNAL_TLS_CERT":             &cfg.TLS.Cert,
+		"YNAL_TLS_KEY":              &cfg.TLS.Key,
+		"YNAL_TLS_CLIENT_CA":        &cfg.TLS.ClientCA,
+		"YNAL_TLS_CLIENT_AUTH":      &cfg.TLS.ClientAuth,
+		"YNAL_CACHE_CONTROL":        &cfg.CacheControl,
+		"YNAL_BASE_PATH":            &cfg.BasePath,
+		"YNAL_SITE_URL":             &cfg.SiteURL,
+		"YNAL_THEME":                &cfg.Theme,
+		"YNAL_INDEX_ORDER":          &cfg.Index.Order,
+		"YNAL_SIGNING_KEY":          &cfg.Signing.Key,
+		"YNAL_LICENSE_DIR":          &cfg.LicenseDir,
+		"YNAL_CUSTOM_DIR":           &cfg.CustomDir,
+		"YNAL_SPDX_DIR":             &cfg.SPDX.Dir,
+		"YNAL_SPDX_LIST_URL":        &cfg.SPDX.ListURL,
+		"YNAL_SQLITE_PATH":          &cfg.SQLite.Path,
+		"YNAL_S3_BUCKET":            &cfg.S3.Bucket,
+		"YNAL_S3_PREFIX":            &cfg.S3.Prefix,
+		"YNAL_S3_ENDPOINT":          &cfg.S3.Endpoint,
+		"YNAL_S3_REGION":            &cfg.S3.Region,
+		"YNAL_S3_ACCESS_KEY_ID":     &cfg.S3.AccessKeyID,
+		"YNAL_S3_SECRET_ACCESS_KEY": &cfg.S3.SecretAccessKey,
+		"YNAL_ADMIN_TOKEN":          &cfg.Admin.Token,
+		"YNAL_WEBHOOK_SECRET":       &cfg.Webhooks.Secret,
+		"YNAL_STATS_PATH":           &cfg.Stats.Path,
+		"YNAL_SCAN_REGISTRY":        &cfg.Scan.Registry,
+		"YNAL_GITHUB_TOKEN":         &cfg.GitHub.Token,
+		"YNAL_ACCESS_LOG_FORMAT":    &cfg.Log.Format,
+		"YNAL_ACCESS_LOG_FILE":      &cfg.Log.File,
+		"YNAL_AUDIT_LOG":            &cfg.Log.Audit,
+	}
+
+	for env, dst := range strs {
+		if val, ok := os.LookupEnv(env); ok {
+			*dst = val
+		}
+	}
+
+	lists := map[string]*[]string{
+		"YNAL_TRUSTED_PROXIES":            &cfg.TrustedProxies,
+		"YNAL_WEBHOOK_URLS":               &cfg.Webhooks.URLs,
+		"YNAL_INDEX_PRIORITY":             &cfg.Index.Priority,
+		"YNAL_NEGOTIATION_PREFER":         &cfg.Negotiation.Prefer,
+		"YNAL_NEGOTIATION_BROWSER_PREFER": &cfg.Negotiation.BrowserPrefer,
+	}
+
+	for env, dst := range lists {
+		if val, ok := os.LookupEnv(env); ok {
+			*dst = nil
+			for _, v := range strings.Split(val, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					*dst = append(*dst, v)
+				}
+			}
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"YNAL_SPDX_REFRESH":            &cfg.SPDX.Refresh,
+		"YNAL_S3_REFRESH":              &cfg.S3.Refresh,
+		"YNAL_MAINTENANCE_RETRY_AFTER": &cfg.Maintenance.RetryAfter,
+	}
+
+	for env, dst := range durations {
+		if val, ok := os.LookupEnv(env); ok {
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return fmt.Errorf("could not parse %s: %w", env, err)
+			}
+
+			*dst = d
+		}
+	}
+
+	ints := map[string]*int{
+		"YNAL_ACCESS_LOG_MAX_SIZE":    &cfg.Log.MaxSize,
+		"YNAL_ACCESS_LOG_MAX_BACKUPS": &cfg.Log.MaxBackups,
+		"YNAL_RENDER_CACHE_SIZE":      &cfg.Render.CacheSize,
+	}
+
+	for env, dst := range ints {
+		if val, ok := os.LookupEnv(env); ok {
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return fmt.Errorf("could not parse %s: %w", env, err)
+			}
+
+			*dst = n
+		}
+	}
+
+	bools := map[string]*bool{
+		"YNAL_ACCESS_LOG":         &cfg.Log.Access,
+		"YNAL_TLS_REDIRECT":       &cfg.TLS.Redirect,
+		"YNAL_H2C":                &cfg.H2C,
+		"YNAL_TLS_HTTP3":          &cfg.TLS.HTTP3,
+		"YNAL_TRACING":            &cfg.Tracing,
+		"YNAL_MAINTENANCE":        &cfg.Maintenance.Enabled,
+		"YNAL_ACCEPTANCES":        &cfg.Acceptances.Enabled,
+		"YNAL_NEGOTIATION_STRICT": &cfg.Negotiation.Strict,
+	}
+
+	for env, dst := range bools {
+		if val, ok := os.LookupEnv(env); ok {
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return fmt.Errorf("could not parse %s: %w", env, err)
+			}
+
+			*dst = b
+		}
+	}
+
+	return nil
+}
+
+// validate reports every problem with the config rather than just the first.
+func (cfg Config) validate() error {
+	errs := []error{}
+
+	if cfg.Addr == "" && !cfg.TLS.Enabled() {
+		errs = append(errs, errors.New("addr: must be set unless TLS is enabled"))
+	} else if cfg.Addr != "" {
+		if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("addr: %w", err))
+		}
+	}
+
+	if cfg.BasePath != "" {
+		if u, err := url.Parse(cfg.BasePath); err != nil || !strings.HasPrefix(cfg.BasePath, "/") || u.Path != cfg.BasePath {
+			errs = append(errs, fmt.Errorf("base_path: must be a plain path starting with /, got %q", cfg.BasePath))
+		}
+	}
+
+	if cfg.SiteURL != "" && !validSiteURL(cfg.SiteURL) {
+		errs = append(errs, fmt.Errorf("site_url: must be an absolute http or https URL, got %q", cfg.SiteURL))
+	}
+
+	orders := []string{}
+	for _, o := range ynalhttp.IndexOrders() {
+		orders = append(orders, string(o))
+	}
+
+	if cfg.Index.Order != "" && !slices.Contains(orders, cfg.Index.Order) {
+		errs = append(errs, fmt.Errorf("index.order: must be one of %s, got %q", strings.Join(orders, ", "), cfg.Index.Order))
+	}
+
+	for _, t := range cfg.Negotiation.Prefer {
+		if !slices.Contains(ynalhttp.MediaTypes(), t) {
+			errs = append(errs, fmt.Errorf("negotiation.prefer: must be some of %s, got %q", strings.Join(ynalhttp.MediaTypes(), ", "), t))
+		}
+	}
+
+	for _, t := range cfg.Negotiation.BrowserPrefer {
+		if !slices.Contains(ynalhttp.MediaTypes(), t) {
+			errs = append(errs, fmt.Errorf("negotiation.browser_prefer: must be some of %s, got %q", strings.Join(ynalhttp.MediaTypes(), ", "), t))
+		}
+	}
+
+	if len(cfg.Index.Priority) > 0 && cfg.Index.Order != string(ynalhttp.OrderPriority) {
+		errs = append(errs, fmt.Errorf("index.priority: requires index.order to be %q", ynalhttp.OrderPriority))
+	}
+
+	if cfg.Signing.Key != "" {
+		if _, err := loadSigningKey(cfg.Signing.Key); err != nil {
+			errs = append(errs, fmt.Errorf("signing.key: %w", err))
+		}
+	}
+
+	if cfg.Render.CacheSize < 0 {
+		errs = append(errs, errors.New("render.cache_size: can't be negative"))
+	}
+
+	if cfg.Theme != "" && !slices.Contains(ynalhttp.ThemeNames(), cfg.Theme) {
+		errs = append(errs, fmt.Errorf("theme: must be one of %s, got %q", strings.Join(ynalhttp.ThemeNames(), ", "), cfg.Theme))
+	}
+
+	if !slices.Contains(accessLogFormats, cfg.Log.Format) {
+		errs = append(errs, fmt.Errorf("log.format: must be one of %s, got %q", strings.Join(accessLogFormats, ", "), cfg.Log.Format))
+	}
+
+	if cfg.Log.MaxSize < 0 || cfg.Log.MaxBackups < 0 {
+		errs = append(errs, errors.New("log: max_size and max_backups can't be negative"))
+	}
+
+	if cfg.Log.MaxSize > 0 && cfg.Log.File == "" {
+		errs = append(errs, errors.New("log.max_size: requires log.file"))
+	}
+
+	if cfg.Maintenance.RetryAfter < time.Second {
+		errs = append(errs, fmt.Errorf("maintenance.retry_after: must be at least a second, got %s", cfg.Maintenance.RetryAfter))
+	}
+
+	if _, err := parseTrustedProxies(cfg.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("trusted_proxies: %w", err))
+	}
+
+	if cfg.GRPCAddr != "" {
+		if _, _, err := net.SplitHostPort(cfg.GRPCAddr); err != nil {
+			errs = append(errs, fmt.Errorf("grpc_addr: %w", err))
+		}
+	}
+
+	if cfg.DebugAddr != "" {
+		if _, _, err := net.SplitHostPort(cfg.DebugAddr); err != nil {
+			errs = append(errs, fmt.Errorf("debug_addr: %w", err))
+		}
+	}
+
+	if cfg.TLS.Enabled() {
+		if cfg.TLS.Cert == "" || cfg.TLS.Key == "" {
+			errs = append(errs, errors.New("tls: both cert and key must be set"))
+		}
+
+		if _, _, err := net.SplitHostPort(cfg.TLS.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("tls.addr: %w", err))
+		}
+
+		if cfg.TLS.ClientCA != "" {
+			if _, err := loadClientCAs(cfg.TLS.ClientCA); err != nil {
+				errs = append(errs, fmt.Errorf("tls.client_ca: %w", err))
+			}
+		}
+
+		if !slices.Contains(clientAuthModes, cfg.TLS.ClientAuth) {
+			errs = append(errs, fmt.Errorf("tls.client_auth: must be one of %s, got %q", strings.Join(clientAuthModes, ", "), cfg.TLS.ClientAuth))
+		} else if cfg.TLS.ClientAuth != "" && cfg.TLS.ClientCA == "" {
+			errs = append(errs, errors.New("tls.client_auth: requires tls.client_ca"))
+		}
+
+		for _, f := range []string{cfg.TLS.Cert, cfg.TLS.Key} {
+			if f == "" {
+				continue
+			}
+
+			if _, err := os.Stat(f); err != nil {
+				errs = append(errs, fmt.Errorf("tls: %w", err))
+			}
+		}
+	} else {
+		if cfg.TLS.Redirect {
+			errs = append(errs, errors.New("tls.redirect: requires TLS to be enabled"))
+		}
+
+		if cfg.TLS.HTTP3 {
+			errs = append(errs, errors.New("tls.http3: requires TLS to be enabled"))
+		}
+
+		if cfg.TLS.ClientCA != "" {
+			errs = append(errs, errors.New("tls.client_ca: requires TLS to be enabled"))
+		}
+	}
+
+	if cfg.LicenseDir != "" {
+		if info, err := os.Stat(cfg.LicenseDir); err != nil {
+			errs = append(errs, fmt.Errorf("license_dir: %w", err))
+		} else if !info.IsDir() {
+			errs = append(errs, fmt.Errorf("license_dir: %s is not a directory", cfg.LicenseDir))
+		}
+	}
+
+	if cfg.CustomDir != "" {
+		if info, err := os.Stat(cfg.CustomDir); err != nil {
+			errs = append(errs, fmt.Errorf("custom_dir: %w", err))
+		} else if !info.IsDir() {
+			errs = append(errs, fmt.Errorf("custom_dir: %s is not a directory", cfg.CustomDir))
+		}
+	}
+
+	errs = append(errs, validateHosts(cfg.Hosts)...)
+
+	sources := []string{}
+	for name, enabled := range map[string]bool{
+		"license_dir": cfg.LicenseDir != "",
+		"spdx.dir":    cfg.SPDX.Enabled(),
+		"sqlite.path": cfg.SQLite.Enabled(),
+		"s3.bucket":   cfg.S3.Enabled(),
+	} {
+		if enabled {
+			sources = append(sources, name)
+		}
+	}
+
+	if len(sources) > 1 {
+		sort.Strings(sources)
+		errs = append(errs, fmt.Errorf("%s: only one license source can be set", strings.Join(sources, ", ")))
+	}
+
+	if cfg.SPDX.Enabled() {
+		if u, err := url.Parse(cfg.SPDX.ListURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("spdx.list_url: not an absolute URL: %q", cfg.SPDX.ListURL))
+		}
+
+		if cfg.SPDX.Refresh < time.Minute {
+			errs = append(errs, fmt.Errorf("spdx.refresh: must be at least a minute, got %s", cfg.SPDX.Refresh))
+		}
+	}
+
+	if cfg.S3.Enabled() {
+		if u, err := url.Parse(cfg.S3.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("s3.endpoint: not an absolute URL: %q", cfg.S3.Endpoint))
+		}
+
+		if cfg.S3.Region == "" {
+			errs = append(errs, errors.New("s3.region: must be set"))
+		}
+
+		if (cfg.S3.AccessKeyID == "") != (cfg.S3.SecretAccessKey == "") {
+			errs = append(errs, errors.New("s3: both access_key_id and secret_access_key must be set, or neither"))
+		}
+
+		if cfg.S3.Refresh < time.Minute {
+			errs = append(errs, fmt.Errorf("s3.refresh: must be at least a minute, got %s", cfg.S3.Refresh))
+		}
+	}
+
+	if cfg.Admin.Enabled() && cfg.LicenseDir == "" && !cfg.SQLite.Enabled() {
+		errs = append(errs, errors.New("admin: requires license_dir or sqlite.path to store licenses in"))
+	}
+
+	if cfg.Acceptances.Enabled && !cfg.SQLite.Enabled() {
+		errs = append(errs, errors.New("acceptances: requires sqlite.path to store acceptances in"))
+	}
+
+	if cfg.Acceptances.Enabled && !cfg.Admin.Enabled() {
+		errs = append(errs, errors.New("acceptances: requires admin.token, admin.tokens, or admin.client_certs"))
+	}
+
+	if _, ok := cfg.Admin.Tokens["admin"]; ok && cfg.Admin.Token != "" {
+		errs = append(errs, errors.New("admin.tokens: \"admin\" is reserved for admin.token"))
+	}
+
+	if len(cfg.Admin.ClientCerts) > 0 && cfg.TLS.ClientCA == "" {
+		errs = append(errs, errors.New("admin.client_certs: requires tls.client_ca"))
+	}
+
+	for name, id := range cfg.Admin.ClientCerts {
+		if id == "" {
+			errs = append(errs, fmt.Errorf("admin.client_certs: %s has an empty identity", name))
+		}
+	}
+
+	seen := map[string]string{}
+	for name, token := range cfg.Admin.AllTokens() {
+		if token == "" {
+			errs = append(errs, fmt.Errorf("admin.tokens: %s has an empty token", name))
+		} else if other, ok := seen[token]; ok {
+			errs = append(errs, fmt.Errorf("admin.tokens: %s and %s share a token", other, name))
+		}
+
+		seen[token] = name
+	}
+
+	if cfg.Stats.Enabled() {
+		if info, err := os.Stat(filepath.Dir(cfg.Stats.Path)); err != nil {
+			errs = append(errs, fmt.Errorf("stats.path: %w", err))
+		} else if !info.IsDir() {
+			errs = append(errs, fmt.Errorf("stats.path: %s is not a directory", filepath.Dir(cfg.Stats.Path)))
+		}
+	}
+
+	if cfg.Scan.Enabled() {
+		if u, err := url.Parse(cfg.Scan.Registry); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("scan.registry: not an absolute http or https URL: %q", cfg.Scan.Registry))
+		}
+	}
+
+	if cfg.GitHub.URL != "" {
+		if u, err := url.Parse(cfg.GitHub.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("github.url: not an absolute http or https URL: %q", cfg.GitHub.URL))
+		}
+	}
+
+	if cfg.GitHub.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("github.cache_ttl: must not be negative: %s", cfg.GitHub.CacheTTL))
+	}
+
+	for _, raw := range cfg.Webhooks.URLs {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("webhooks.urls: not an absolute http or https URL: %q", raw))
+		}
+	}
+
+	if cfg.Webhooks.Secret != "" && !cfg.Webhooks.Enabled() {
+		errs = append(errs, errors.New("webhooks.secret: requires webhooks.urls"))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateHosts(hosts []HostConfig) []error {
+	errs := []error{}
+	seen := map[string]bool{}
+
+	for i, h := range hosts {
+		if len(h.Names) == 0 {
+			errs = append(errs, fmt.Errorf("hosts[%d].names: at least one is required", i))
+		}
+
+		for _, name := range h.Names {
+			name = strings.ToLower(name)
+
+			if name == "" || strings.ContainsAny(name, ":/ ") {
+				errs = append(errs, fmt.Errorf("hosts[%d].names: not a host name: %q", i, name))
+			} else if seen[name] {
+				errs = append(errs, fmt.Errorf("hosts[%d].names: %s is listed more than once", i, name))
+			}
+
+			seen[name] = true
+		}
+
+		for key, dir := range map[string]string{"license_dir": h.LicenseDir, "template_dir": h.TemplateDir} {
+			if dir == "" {
+				continue
+			}
+
+			if info, err := os.Stat(dir); err != nil {
+				errs = append(errs, fmt.Errorf("hosts[%d].%s: %w", i, key, err))
+			} else if !info.IsDir() {
+				errs = append(errs, fmt.Errorf("hosts[%d].%s: %s is not a directory", i, key, dir))
+			}
+		}
+
+		if h.Theme != "" && !slices.Contains(ynalhttp.ThemeNames(), h.Theme) {
+			errs = append(errs, fmt.Errorf("hosts[%d].theme: must be one of %s, got %q", i, strings.Join(ynalhttp.ThemeNames(), ", "), h.Theme))
+		}
+
+		if h.SiteURL != "" && !validSiteURL(h.SiteURL) {
+			errs = append(errs, fmt.Errorf("hosts[%d].site_url: must be an absolute http or https URL, got %q", i, h.SiteURL))
+		}
+	}
+
+	return errs
+}
+
+// validSiteURL reports whether site can be given to ynalhttp.WithSiteURL.
+func validSiteURL(site string) bool {
+	u, err := url.Parse(site)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.RawQuery == "" && u.Fragment == ""
+}
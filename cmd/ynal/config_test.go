@@ -0,0 +1,700 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/packrat386/ynal/spdx"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "ynal.toml")
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("could not write config: %s", err)
+	}
+
+	return path
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	cfg, err := loadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.Addr != "localhost:8080" || !cfg.Log.Access {
+		t.Fatalf("unexpected defaults: %+v", cfg)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := writeConfig(t, `
+addr = "0.0.0.0:9000"
+cache_control = "public, max-age=60"
+
+[log]
+access = false
+`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.Addr != "0.0.0.0:9000" || cfg.CacheControl != "public, max-age=60" || cfg.Log.Access {
+		t.Fatalf("config not applied: %+v", cfg)
+	}
+}
+
+func TestLoadConfigEnvOverrides(t *testing.T) {
+	path := writeConfig(t, `addr = "0.0.0.0:9000"`)
+
+	t.Setenv("YNAL_ADDR", "127.0.0.1:7000")
+	t.Setenv("YNAL_ACCESS_LOG", "false")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.Addr != "127.0.0.1:7000" || cfg.Log.Access {
+		t.Fatalf("env not applied: %+v", cfg)
+	}
+}
+
+func TestLoadConfigValidation(t *testing.T) {
+	path := writeConfig(t, `
+addr = "nope"
+debug_addr = "6060"
+license_dir = "/does/not/exist"
+
+[tls]
+cert = "cert.pem"
+`)
+
+	_, err := loadConfig(path)
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+
+	for _, want := range []string{"addr:", "debug_addr:", "license_dir:", "tls: both cert and key"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got: %s", want, err)
+		}
+	}
+}
+
+func TestLoadConfigBasePath(t *testing.T) {
+	for path, valid := range map[string]bool{"/licenses": true, "/a/b/": true, "licenses": false, "/x?y=1": false} {
+		t.Setenv("YNAL_BASE_PATH", path)
+
+		if _, err := loadConfig(""); (err == nil) != valid {
+			t.Errorf("expected base path %q valid=%v, got: %v", path, valid, err)
+		}
+	}
+}
+
+func TestLoadConfigTheme(t *testing.T) {
+	for theme, valid := range map[string]bool{"": true, "light": true, "dark": true, "plaid": false} {
+		t.Setenv("YNAL_THEME", theme)
+
+		if _, err := loadConfig(""); (err == nil) != valid {
+			t.Errorf("expected theme %q valid=%v, got: %v", theme, valid, err)
+		}
+	}
+}
+
+func TestLoadConfigUnknownKey(t *testing.T) {
+	path := writeConfig(t, `adr = "localhost:8080"`)
+
+	if _, err := loadConfig(path); err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+}
+
+func TestLoadConfigSPDX(t *testing.T) {
+	path := writeConfig(t, `
+[spdx]
+dir = "/var/lib/ynal/spdx"
+refresh = "6h"
+`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !cfg.SPDX.Enabled() || cfg.SPDX.Refresh != 6*time.Hour || cfg.SPDX.ListURL != spdx.DefaultListURL {
+		t.Fatalf("spdx config not applied: %+v", cfg.SPDX)
+	}
+
+	t.Setenv("YNAL_SPDX_REFRESH", "1s")
+
+	if _, err := loadConfig(path); err == nil {
+		t.Fatalf("expected error for too-short refresh")
+	}
+}
+
+func TestLoadConfigAdminRequiresLicenseDir(t *testing.T) {
+	t.Setenv("YNAL_ADMIN_TOKEN", "s3cret")
+
+	_, err := loadConfig("")
+	if err == nil || !strings.Contains(err.Error(), "admin: requires license_dir") {
+		t.Fatalf("expected admin validation error, got: %v", err)
+	}
+
+	t.Setenv("YNAL_LICENSE_DIR", t.TempDir())
+
+	if _, err := loadConfig(""); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestLoadConfigAdminTokens(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, fmt.Sprintf(`
+license_dir = %q
+
+[admin]
+token = "s3cret"
+
+[admin.tokens]
+alice = "hunter2"
+`, dir))
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := map[string]string{"admin": "s3cret", "alice": "hunter2"}
+	if got := cfg.Admin.AllTokens(); !maps.Equal(got, want) {
+		t.Fatalf("expected tokens %v, got %v", want, got)
+	}
+
+	path = writeConfig(t, fmt.Sprintf(`
+license_dir = %q
+
+[admin.tokens]
+alice = "hunter2"
+bob = "hunter2"
+carol = ""
+`, dir))
+
+	_, err = loadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "share a token") || !strings.Contains(err.Error(), "carol has an empty token") {
+		t.Fatalf("expected token validation errors, got: %v", err)
+	}
+}
+
+func TestLoadConfigSQLite(t *testing.T) {
+	t.Setenv("YNAL_SQLITE_PATH", filepath.Join(t.TempDir(), "ynal.db"))
+	t.Setenv("YNAL_ADMIN_TOKEN", "s3cret")
+
+	if _, err := loadConfig(""); err != nil {
+		t.Fatalf("expected sqlite to satisfy admin, got: %s", err)
+	}
+
+	t.Setenv("YNAL_LICENSE_DIR", t.TempDir())
+
+	_, err := loadConfig("")
+	if err == nil || !strings.Contains(err.Error(), "license_dir, sqlite.path: only one license source") {
+		t.Fatalf("expected sqlite validation error, got: %v", err)
+	}
+}
+
+func TestLoadConfigS3(t *testing.T) {
+	path := writeConfig(t, `
+[s3]
+bucket = "licenses"
+endpoint = "http://localhost:9000"
+access_key_id = "AKID"
+`)
+
+	t.Setenv("YNAL_S3_REFRESH", "30s")
+
+	_, err := loadConfig(path)
+	if err == nil {
+		t.Fatalf("expected validation errors")
+	}
+
+	for _, want := range []string{"s3: both access_key_id and secret_access_key", "s3.refresh: must be at least a minute"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+
+	t.Setenv("YNAL_S3_REFRESH", "10m")
+	t.Setenv("YNAL_S3_SECRET_ACCESS_KEY", "secret")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !cfg.S3.Enabled() || cfg.S3.Region != "us-east-1" || cfg.S3.Refresh != 10*time.Minute {
+		t.Fatalf("s3 config not applied: %+v", cfg.S3)
+	}
+}
+
+func TestLoadConfigWebhooks(t *testing.T) {
+	path := writeConfig(t, `
+[webhooks]
+urls = ["https://cache.example.com/hook", "ftp://example.com"]
+secret = "hunter2"
+`)
+
+	_, err := loadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), `webhooks.urls: not an absolute http or https URL: "ftp://example.com"`) {
+		t.Fatalf("expected webhook validation error, got: %v", err)
+	}
+
+	t.Setenv("YNAL_WEBHOOK_URLS", "https://a.example.com/hook, https://b.example.com/hook")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(cfg.Webhooks.URLs) != 2 || cfg.Webhooks.URLs[1] != "https://b.example.com/hook" || cfg.Webhooks.Secret != "hunter2" {
+		t.Fatalf("webhook config not applied: %+v", cfg.Webhooks)
+	}
+}
+
+func TestLoadConfigScan(t *testing.T) {
+	path := writeConfig(t, `
+[scan]
+registry = "api.deps.dev"
+`)
+
+	_, err := loadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), `scan.registry: not an absolute http or https URL: "api.deps.dev"`) {
+		t.Fatalf("expected scan validation error, got: %v", err)
+	}
+
+	t.Setenv("YNAL_SCAN_REGISTRY", "https://api.deps.dev")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !cfg.Scan.Enabled() || cfg.Scan.Registry != "https://api.deps.dev" {
+		t.Fatalf("scan config not applied: %+v", cfg.Scan)
+	}
+}
+
+func TestLoadConfigGitHub(t *testing.T) {
+	path := writeConfig(t, `
+[github]
+enabled = true
+url = "api.github.com"
+`)
+
+	_, err := loadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), `github.url: not an absolute http or https URL: "api.github.com"`) {
+		t.Fatalf("expected github validation error, got: %v", err)
+	}
+
+	path = writeConfig(t, `
+[github]
+enabled = true
+cache_ttl = "10m"
+`)
+
+	t.Setenv("YNAL_GITHUB_TOKEN", "hunter2")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !cfg.GitHub.Enabled || cfg.GitHub.Token != "hunter2" || cfg.GitHub.CacheTTL != 10*time.Minute {
+		t.Fatalf("github config not applied: %+v", cfg.GitHub)
+	}
+}
+
+func TestLoadConfigStats(t *testing.T) {
+	t.Setenv("YNAL_STATS_PATH", "/nonexistent/dir/stats.json")
+
+	_, err := loadConfig("")
+	if err == nil || !strings.Contains(err.Error(), "stats.path:") {
+		t.Fatalf("expected stats validation error, got: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "stats.json")
+	t.Setenv("YNAL_STATS_PATH", path)
+
+	cfg, err := loadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !cfg.Stats.Enabled() || cfg.Stats.Path != path {
+		t.Fatalf("stats config not applied: %+v", cfg.Stats)
+	}
+}
+
+func TestLoadConfigAccessLog(t *testing.T) {
+	path := writeConfig(t, `
+[log]
+format = "apache"
+max_size = 10
+`)
+
+	_, err := loadConfig(path)
+	if err == nil {
+		t.Fatalf("expected validation errors")
+	}
+
+	for _, want := range []string{`log.format: must be one of text, common, combined, json, got "apache"`, "log.max_size: requires log.file"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+
+	t.Setenv("YNAL_ACCESS_LOG_FORMAT", "combined")
+	t.Setenv("YNAL_ACCESS_LOG_FILE", filepath.Join(t.TempDir(), "access.log"))
+	t.Setenv("YNAL_ACCESS_LOG_MAX_BACKUPS", "5")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.Log.Format != "combined" || cfg.Log.MaxSize != 10 || cfg.Log.MaxBackups != 5 {
+		t.Fatalf("log config not applied: %+v", cfg.Log)
+	}
+}
+
+func TestLoadConfigTracing(t *testing.T) {
+	path := writeConfig(t, `tracing = true`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !cfg.Tracing {
+		t.Fatalf("expected tracing to be enabled")
+	}
+
+	t.Setenv("YNAL_TRACING", "false")
+
+	cfg, err = loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.Tracing {
+		t.Fatalf("expected YNAL_TRACING to override the file")
+	}
+}
+
+func TestLoadConfigMaintenance(t *testing.T) {
+	cfg, err := loadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.Maintenance.Enabled || cfg.Maintenance.RetryAfter != 5*time.Minute {
+		t.Fatalf("unexpected maintenance defaults: %+v", cfg.Maintenance)
+	}
+
+	t.Setenv("YNAL_MAINTENANCE", "true")
+	t.Setenv("YNAL_MAINTENANCE_RETRY_AFTER", "30s")
+
+	cfg, err = loadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !cfg.Maintenance.Enabled || cfg.Maintenance.RetryAfter != 30*time.Second {
+		t.Fatalf("maintenance config not applied: %+v", cfg.Maintenance)
+	}
+
+	t.Setenv("YNAL_MAINTENANCE_RETRY_AFTER", "0s")
+
+	if _, err := loadConfig(""); err == nil || !strings.Contains(err.Error(), "maintenance.retry_after:") {
+		t.Fatalf("expected retry_after validation error, got: %v", err)
+	}
+}
+
+func TestLoadConfigHosts(t *testing.T) {
+	dir := t.TempDir()
+
+	path := writeConfig(t, `
+[[hosts]]
+names = ["internal.corp.example"]
+license_dir = "`+dir+`"
+
+[[hosts]]
+names = ["INTERNAL.corp.example", "bad:8080"]
+template_dir = "/does/not/exist"
+theme = "neon"
+
+[[hosts]]
+names = []
+`)
+
+	_, err := loadConfig(path)
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+
+	for _, want := range []string{
+		"hosts[1].names: internal.corp.example is listed more than once",
+		`hosts[1].names: not a host name: "bad:8080"`,
+		"hosts[1].template_dir:",
+		"hosts[1].theme: must be one of",
+		"hosts[2].names: at least one is required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got: %s", want, err)
+		}
+	}
+
+	path = writeConfig(t, `
+[[hosts]]
+names = ["internal.corp.example"]
+license_dir = "`+dir+`"
+`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(cfg.Hosts) != 1 || cfg.Hosts[0].LicenseDir != dir {
+		t.Fatalf("hosts not applied: %+v", cfg.Hosts)
+	}
+}
+
+func TestLoadConfigClientCertificates(t *testing.T) {
+	cert, key := writeCert(t)
+	dir := t.TempDir()
+
+	path := writeConfig(t, fmt.Sprintf(`
+license_dir = %q
+
+[tls]
+cert = %q
+key = %q
+client_ca = %q
+client_auth = "optional"
+
+[admin.client_certs]
+alice = "alice@example.com"
+`, dir, cert, key, cert))
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !cfg.Admin.Enabled() || cfg.Admin.ClientCerts["alice"] != "alice@example.com" {
+		t.Fatalf("unexpected admin config: %+v", cfg.Admin)
+	}
+
+	path = writeConfig(t, fmt.Sprintf(`
+license_dir = %q
+
+[tls]
+cert = %q
+key = %q
+client_ca = %q
+client_auth = "sometimes"
+
+[admin.client_certs]
+bob = ""
+`, dir, cert, key, key))
+
+	_, err = loadConfig(path)
+	for _, want := range []string{"tls.client_ca:", "tls.client_auth: must be one of", "bob has an empty identity"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got: %v", want, err)
+		}
+	}
+
+	path = writeConfig(t, fmt.Sprintf(`
+license_dir = %q
+
+[admin.client_certs]
+alice = "alice@example.com"
+`, dir))
+
+	_, err = loadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), "admin.client_certs: requires tls.client_ca") {
+		t.Fatalf("expected client_certs validation error, got: %v", err)
+	}
+}
+
+func TestAdminMode(t *testing.T) {
+	a := AdminConfig{Token: "s3cret"}
+
+	if err := a.setAdminMode(false); err != nil || a.Serve {
+		t.Fatalf("expected read-only mode, got %t, %v", a.Serve, err)
+	}
+
+	if err := a.setAdminMode(true); err != nil || !a.Serve {
+		t.Fatalf("expected admin mode, got %t, %v", a.Serve, err)
+	}
+
+	a = AdminConfig{}
+	if err := a.setAdminMode(true); err == nil || a.Serve {
+		t.Fatalf("expected admin mode without credentials to fail, got %t, %v", a.Serve, err)
+	}
+}
+
+func TestLoadConfigIndexOrder(t *testing.T) {
+	path := writeConfig(t, `
+[index]
+order = "priority"
+priority = ["mit", "apache-2.0"]
+`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.Index.Order != "priority" || len(cfg.Index.Priority) != 2 {
+		t.Fatalf("unexpected index config: %+v", cfg.Index)
+	}
+
+	t.Setenv("YNAL_INDEX_ORDER", "random")
+
+	_, err = loadConfig(path)
+	for _, want := range []string{"index.order: must be one of", "index.priority: requires"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestLoadConfigAcceptances(t *testing.T) {
+	path := writeConfig(t, `
+[acceptances]
+enabled = true
+`)
+
+	_, err := loadConfig(path)
+	for _, want := range []string{"acceptances: requires sqlite.path", "acceptances: requires admin.token"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got: %v", want, err)
+		}
+	}
+
+	t.Setenv("YNAL_SQLITE_PATH", filepath.Join(t.TempDir(), "ynal.db"))
+	t.Setenv("YNAL_ADMIN_TOKEN", "s3cret")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !cfg.Acceptances.Enabled {
+		t.Fatalf("expected acceptances to be enabled")
+	}
+}
+
+func TestLoadConfigRenderCacheSize(t *testing.T) {
+	path := writeConfig(t, `
+[render]
+cache_size = 64
+`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.Render.CacheSize != 64 {
+		t.Errorf("expected 64, got %d", cfg.Render.CacheSize)
+	}
+
+	t.Setenv("YNAL_RENDER_CACHE_SIZE", "-1")
+
+	if _, err := loadConfig(path); err == nil || !strings.Contains(err.Error(), "render.cache_size: can't be negative") {
+		t.Errorf("expected a negative cache size to be rejected, got: %v", err)
+	}
+}
+
+func TestLoadConfigNegotiation(t *testing.T) {
+	path := writeConfig(t, `
+[negotiation]
+strict = true
+`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !cfg.Negotiation.Strict {
+		t.Errorf("expected strict negotiation")
+	}
+
+	t.Setenv("YNAL_NEGOTIATION_STRICT", "false")
+	t.Setenv("YNAL_NEGOTIATION_BROWSER_PREFER", "text/html,application/json")
+
+	cfg, err = loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.Negotiation.Strict {
+		t.Errorf("expected the env to turn strict negotiation off")
+	}
+
+	if !slices.Equal(cfg.Negotiation.BrowserPrefer, []string{"text/html", "application/json"}) {
+		t.Errorf("expected browsers to prefer HTML then JSON, got %v", cfg.Negotiation.BrowserPrefer)
+	}
+
+	t.Setenv("YNAL_NEGOTIATION_PREFER", "image/png")
+
+	if _, err := loadConfig(path); err == nil || !strings.Contains(err.Error(), `negotiation.prefer: must be some of`) {
+		t.Errorf("expected an unknown media type to be rejected, got: %v", err)
+	}
+}
+
+func TestLoadConfigSiteURL(t *testing.T) {
+	path := writeConfig(t, `
+site_url = "https://licenses.example.com"
+
+[[hosts]]
+names = ["internal.example.com"]
+site_url = "internal.example.com"
+`)
+
+	_, err := loadConfig(path)
+	if err == nil || !strings.Contains(err.Error(), `hosts[0].site_url: must be an absolute http or https URL, got "internal.example.com"`) {
+		t.Fatalf("expected a bad host site URL to be rejected, got: %v", err)
+	}
+
+	path = writeConfig(t, `
+site_url = "https://licenses.example.com"
+`)
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if cfg.SiteURL != "https://licenses.example.com" {
+		t.Errorf("expected the site URL from the file, got %q", cfg.SiteURL)
+	}
+
+	t.Setenv("YNAL_SITE_URL", "ftp://licenses.example.com")
+
+	if _, err := loadConfig(path); err == nil || !strings.Contains(err.Error(), "site_url: must be an absolute http or https URL") {
+		t.Errorf("expected a bad site URL to be rejected, got: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// started is when the process started, give or take, for the uptime in
+// /debug/vars.
+var started = time.Now()
+
+func init() {
+	expvar.Publish("runtime", expvar.Func(runtimeStats))
+}
+
+// runtimeStats is published alongside expvar's own memstats and cmdline.
+func runtimeStats() any {
+	return map[string]any{
+		"go_version":     runtime.Version(),
+		"goroutines":     runtime.NumGoroutine(),
+		"cpus":           runtime.NumCPU(),
+		"gomaxprocs":     runtime.GOMAXPROCS(0),
+		"uptime_seconds": int64(time.Since(started).Seconds()),
+	}
+}
+
+// debugHandler serves pprof and runtime stats. It's only ever mounted on the
+// debug listener, never alongside the licenses.
+func debugHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+
+	return mux
+}
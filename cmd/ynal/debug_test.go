@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugHandler(t *testing.T) {
+	h := debugHandler()
+
+	tt := []struct {
+		name     string
+		path     string
+		code     int
+		expected string
+	}{
+		{name: "pprof index", path: "/debug/pprof/", code: 200, expected: "goroutine"},
+		{name: "heap profile", path: "/debug/pprof/heap?debug=1", code: 200, expected: "heap profile"},
+		{name: "vars", path: "/debug/vars", code: 200, expected: `"memstats"`},
+		{name: "licenses aren't served", path: "/mit", code: 404},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d", tc.code, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Errorf("expected body to contain %q, got: %s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDebugVarsRuntime(t *testing.T) {
+	w := httptest.NewRecorder()
+	debugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/vars", nil))
+
+	vars := struct {
+		Runtime map[string]any `json:"runtime"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &vars); err != nil {
+		t.Fatalf("could not decode vars: %s", err)
+	}
+
+	for _, key := range []string{"go_version", "goroutines", "gomaxprocs", "uptime_seconds"} {
+		if _, ok := vars.Runtime[key]; !ok {
+			t.Errorf("expected runtime stats to include %s, got: %v", key, vars.Runtime)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+// defaultSiteURL is where the sitemap and pages link to unless --url or
+// site_url says otherwise.
+const defaultSiteURL = "https://ynal.packrat386.com"
+
+func runExport(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("YNAL_CONFIG"), "path to a TOML config file")
+	siteURL := fs.String("url", "", "the URL the site will be hosted at, for the sitemap and links in pages (default site_url, or "+defaultSiteURL+")")
+
+	// allow flags both before and after the directory
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("export: missing directory")
+	}
+	dir := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	site := *siteURL
+	if site == "" {
+		site = cmp.Or(cfg.SiteURL, defaultSiteURL)
+	}
+
+	return export(cfg, dir, site, w)
+}
+
+// export renders every page ynal would serve with cfg into dir. See
+// ynalhttp.Export.
+func export(cfg Config, dir string, siteURL string, w io.Writer) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, err := openStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if c, ok := store.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	custom, err := loadCustom(cfg)
+	if err != nil {
+		return err
+	}
+
+	n, err := ynalhttp.Export(dir, siteURL,
+		ynalhttp.WithStore(store),
+		ynalhttp.WithBasePath(cfg.BasePath),
+		ynalhttp.WithTheme(cfg.Theme),
+		ynalhttp.WithCustom(custom),
+	)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	fmt.Fprintf(w, "exported %d files to %s\n", n, dir)
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCommandExport(t *testing.T) {
+	dir := t.TempDir()
+	buf := new(bytes.Buffer)
+
+	if err := runCommand([]string{"export", dir, "--url", "https://licenses.example"}, buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "exported ") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	for _, name := range []string{"index.html", "mit.html", "mit.json", "mit.txt", "raw/mit", "sitemap.xml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be exported: %s", name, err)
+		}
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "sitemap.xml"))
+	if err != nil {
+		t.Fatalf("could not read sitemap: %s", err)
+	}
+
+	if !strings.Contains(string(b), "<loc>https://licenses.example/mit</loc>") {
+		t.Fatalf("expected the sitemap to link under --url, got:\n%s", b)
+	}
+
+	if err := runCommand([]string{"export"}, new(bytes.Buffer)); err == nil {
+		t.Fatalf("expected an error without a directory")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative ynalpb/ynal.proto
+
+import (
+	"context"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/ynalpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newGRPCServer(store ynal.LicenseStore) *grpc.Server {
+	srv := grpc.NewServer()
+	ynalpb.RegisterLicenseServiceServer(srv, newLicenseServer(store))
+
+	return srv
+}
+
+type licenseServer struct {
+	ynalpb.UnimplementedLicenseServiceServer
+
+	store ynal.LicenseStore
+}
+
+func newLicenseServer(store ynal.LicenseStore) *licenseServer {
+	return &licenseServer{store: store}
+}
+
+func (s *licenseServer) ListLicenses(ctx context.Context, req *ynalpb.ListLicensesRequest) (*ynalpb.ListLicensesResponse, error) {
+	resp := &ynalpb.ListLicensesResponse{}
+
+	for _, l := range s.store.List() {
+		resp.Licenses = append(resp.Licenses, toProto(l))
+	}
+
+	return resp, nil
+}
+
+func (s *licenseServer) GetLicense(ctx context.Context, req *ynalpb.GetLicenseRequest) (*ynalpb.License, error) {
+	l, ok := s.store.Get(req.GetId())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no such license: %s", req.GetId())
+	}
+
+	return toProto(l), nil
+}
+
+func (s *licenseServer) RenderLicense(ctx context.Context, req *ynalpb.RenderLicenseRequest) (*ynalpb.RenderLicenseResponse, error) {
+	l, ok := s.store.Get(req.GetId())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no such license: %s", req.GetId())
+	}
+
+	return &ynalpb.RenderLicenseResponse{Content: ynal.Substitute(l.Text, req.GetSubstitutions())}, nil
+}
+
+func toProto(l ynal.LicenseData) *ynalpb.License {
+	return &ynalpb.License{
+		Id:      l.ID,
+		Title:   l.Title,
+		Content: l.Text,
+		Url:     l.URL,
+	}
+}
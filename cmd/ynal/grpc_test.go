@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/ynalpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func mustLicenseServer(t *testing.T) *licenseServer {
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	return newLicenseServer(ynal.NewCatalog(licenses))
+}
+
+func TestGRPCGetLicense(t *testing.T) {
+	s := mustLicenseServer(t)
+
+	l, err := s.GetLicense(context.Background(), &ynalpb.GetLicenseRequest{Id: "mit"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if l.GetTitle() != "MIT" || l.GetUrl() != "/mit" {
+		t.Fatalf("unexpected license: %v", l)
+	}
+
+	_, err = s.GetLicense(context.Background(), &ynalpb.GetLicenseRequest{Id: "nope"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got: %v", err)
+	}
+}
+
+func TestGRPCRenderLicense(t *testing.T) {
+	s := mustLicenseServer(t)
+
+	resp, err := s.RenderLicense(context.Background(), &ynalpb.RenderLicenseRequest{
+		Id: "mit",
+		Substitutions: map[string]string{
+			"YEAR":             "2024",
+			"COPYRIGHT HOLDER": "Jane Doe",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !strings.HasPrefix(resp.GetContent(), "Copyright 2024 Jane Doe\n") {
+		t.Fatalf("substitutions not applied: %q", resp.GetContent()[:40])
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+// hostRouter picks a handler by the request's Host header.
+type hostRouter struct {
+	hosts    map[string]http.Handler
+	fallback http.Handler
+}
+
+// newHostRouter builds a handler for every host in hosts, each with shared
+// plus its own licenses, templates, and theme. Requests for any other host
+// go to fallback.
+func newHostRouter(hosts []HostConfig, shared []ynalhttp.Option, fallback http.Handler) (*hostRouter, error) {
+	hr := &hostRouter{hosts: map[string]http.Handler{}, fallback: fallback}
+
+	for _, hc := range hosts {
+		opts := slices.Clone(shared)
+
+		if hc.LicenseDir != "" {
+			store, err := ynal.NewDirStore(hc.LicenseDir)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", hc.Names[0], err)
+			}
+
+			opts = append(opts, ynalhttp.WithStore(store))
+		}
+
+		if hc.TemplateDir != "" {
+			opts = append(opts, ynalhttp.WithTemplates(os.DirFS(hc.TemplateDir)))
+		}
+
+		if hc.Theme != "" {
+			opts = append(opts, ynalhttp.WithTheme(hc.Theme))
+		}
+
+		site := hc.SiteURL
+		if site == "" {
+			site = "https://" + strings.ToLower(hc.Names[0])
+		}
+		opts = append(opts, ynalhttp.WithSiteURL(site))
+
+		h, err := ynalhttp.New(opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", hc.Names[0], err)
+		}
+
+		for _, name := range hc.Names {
+			hr.hosts[strings.ToLower(name)] = h
+		}
+	}
+
+	return hr, nil
+}
+
+func (hr *hostRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h, ok := hr.hosts[hostName(r.Host)]; ok {
+		h.ServeHTTP(w, r)
+		return
+	}
+
+	hr.fallback.ServeHTTP(w, r)
+}
+
+// hostName is host without its port or a trailing dot, lowercased.
+func hostName(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
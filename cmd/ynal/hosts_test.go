@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+func TestHostRouter(t *testing.T) {
+	licenses := t.TempDir()
+	if err := os.WriteFile(filepath.Join(licenses, "Internal.txt"), []byte("internal use only\n"), 0644); err != nil {
+		t.Fatalf("could not write license: %s", err)
+	}
+
+	templates := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templates, "index.html.tmpl"), []byte("<h1>Internal licenses</h1>\n"), 0644); err != nil {
+		t.Fatalf("could not write template: %s", err)
+	}
+
+	fallback, err := ynalhttp.New()
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	h, err := newHostRouter([]HostConfig{
+		{Names: []string{"internal.corp.example", "INTERNAL"}, LicenseDir: licenses, TemplateDir: templates},
+		{Names: []string{"oss.corp.example"}, Theme: "dark"},
+		{Names: []string{"Legal.Corp.Example"}, SiteURL: "https://corp.example/legal"},
+	}, nil, fallback)
+	if err != nil {
+		t.Fatalf("could not build host router: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		host     string
+		path     string
+		code     int
+		expected string
+	}{
+		{name: "internal index", host: "internal.corp.example", path: "/", code: 200, expected: "<h1>Internal licenses</h1>"},
+		{name: "internal license", host: "internal.corp.example:8080", path: "/internal", code: 200, expected: "internal use only"},
+		{name: "internal doesn't have mit", host: "internal.corp.example", path: "/mit", code: 404},
+		{name: "other name, any case", host: "Internal", path: "/internal", code: 200, expected: "internal use only"},
+		{name: "trailing dot", host: "internal.corp.example.", path: "/internal", code: 200},
+		{name: "oss theme", host: "oss.corp.example", path: "/", code: 200, expected: "dark"},
+		{name: "oss has mit", host: "oss.corp.example", path: "/mit", code: 200},
+		{name: "oss links to itself", host: "oss.corp.example", path: "/mit", code: 200, expected: `<link rel="canonical" href="https://oss.corp.example/mit"/>`},
+		{name: "site URL", host: "legal.corp.example", path: "/mit", code: 200, expected: `<link rel="canonical" href="https://corp.example/legal/mit"/>`},
+		{name: "unknown host", host: "licenses.example", path: "/mit", code: 200},
+		{name: "unknown host doesn't have internal", host: "licenses.example", path: "/internal", code: 404},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Host = tc.host
+			r.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d", tc.code, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
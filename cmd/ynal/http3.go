@@ -0,0 +1,63 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// altSvcMaxAge is how long, in seconds, clients may remember that HTTP/3 is
+// available.
+const altSvcMaxAge = 24 * 60 * 60
+
+// newHTTP3Server listens on the UDP side of the TLS address, unless it
+// inherited a socket named "http3", and serves h over HTTP/3 with the same
+// certificate, and client certificate checks, as HTTPS.
+func newHTTP3Server(cfg Config, h http.Handler, inherited map[string]net.PacketConn) (server, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.TLS.Cert, cfg.TLS.Key)
+	if err != nil {
+		return server{}, fmt.Errorf("could not load TLS certificate: %w", err)
+	}
+
+	tlsConfig, err := clientTLSConfig(cfg.TLS)
+	if err != nil {
+		return server{}, err
+	}
+
+	tlsConfig.Certificates = []tls.Certificate{cert}
+
+	conn, ok := inherited["http3"]
+	if !ok {
+		if conn, err = net.ListenPacket("udp", cfg.TLS.Addr); err != nil {
+			return server{}, fmt.Errorf("could not listen for http3: %w", err)
+		}
+	}
+
+	srv := &http3.Server{
+		Handler:   h,
+		TLSConfig: http3.ConfigureTLSConfig(tlsConfig),
+	}
+
+	return server{
+		name:     "http3",
+		addr:     conn.LocalAddr(),
+		socket:   conn,
+		serve:    func() error { return srv.Serve(conn) },
+		shutdown: srv.Shutdown,
+	}, nil
+}
+
+// withAltSvc advertises the HTTP/3 server listening on addr, so clients that
+// support it can switch over.
+func withAltSvc(addr net.Addr, h http.Handler) http.Handler {
+	_, port, _ := net.SplitHostPort(addr.String())
+	altSvc := fmt.Sprintf(`h3=":%s"; ma=%d`, port, altSvcMaxAge)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Alt-Svc", altSvc)
+		h.ServeHTTP(w, r)
+	})
+}
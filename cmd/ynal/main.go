@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	if err := runCommand(args, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "ynal:", err)
+		os.Exit(1)
+	}
+}
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("YNAL_CONFIG"), "path to a TOML config file")
+	dev := fs.Bool("dev", false, "read templates and public assets from the current directory on every request, and log verbosely")
+	checkOnly := fs.Bool("check", false, "load the config and licenses and render every page, then exit without serving")
+	admin := fs.Bool("admin", false, "serve the admin API for managing licenses, which needs admin credentials in the config; without it nothing can be changed over HTTP")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Admin.setAdminMode(*admin); err != nil {
+		return err
+	}
+
+	if !*admin && cfg.Admin.Enabled() {
+		log.Println("read-only mode: admin credentials are configured, but the admin API is off without --admin")
+	}
+
+	if *checkOnly {
+		return check(cfg, os.Stdout)
+	}
+
+	if *dev {
+		if _, err := os.Stat("templates"); err != nil {
+			return fmt.Errorf("--dev must be run from the root of the ynal repo: %w", err)
+		}
+
+		log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
+		cfg.Log.Access = true
+
+		log.Println("dev mode: serving templates and public assets from disk")
+	}
+
+	return serve(cfg, *dev)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// clientAuthModes are the values tls.client_auth can take; empty means
+// require.
+var clientAuthModes = []string{"", "require", "optional"}
+
+// loadClientCAs reads a PEM file of CA certificates.
+func loadClientCAs(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read client CAs: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.New("could not read client CAs: no PEM certificates found")
+	}
+
+	return pool, nil
+}
+
+// clientTLSConfig returns the TLS config that verifies client certificates
+// against tls.client_ca, or an empty one if it isn't set.
+func clientTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	if cfg.ClientCA == "" {
+		return &tls.Config{}, nil
+	}
+
+	pool, err := loadClientCAs(cfg.ClientCA)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := tls.RequireAndVerifyClientCert
+	if cfg.ClientAuth == "optional" {
+		auth = tls.VerifyClientCertIfGiven
+	}
+
+	return &tls.Config{ClientCAs: pool, ClientAuth: auth}, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientTLSConfig(t *testing.T) {
+	ca, key := writeCert(t)
+
+	tt := []struct {
+		name     string
+		cfg      TLSConfig
+		expected tls.ClientAuthType
+	}{
+		{
+			name:     "no client CA",
+			cfg:      TLSConfig{},
+			expected: tls.NoClientCert,
+		},
+		{
+			name:     "required by default",
+			cfg:      TLSConfig{ClientCA: ca},
+			expected: tls.RequireAndVerifyClientCert,
+		},
+		{
+			name:     "optional",
+			cfg:      TLSConfig{ClientCA: ca, ClientAuth: "optional"},
+			expected: tls.VerifyClientCertIfGiven,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := clientTLSConfig(tc.cfg)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got.ClientAuth != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got.ClientAuth)
+			}
+
+			if (got.ClientCAs != nil) != (tc.cfg.ClientCA != "") {
+				t.Errorf("unexpected client CAs: %v", got.ClientCAs)
+			}
+		})
+	}
+
+	if _, err := clientTLSConfig(TLSConfig{ClientCA: key}); err == nil {
+		t.Errorf("expected an error for a file with no certificates")
+	}
+}
+
+func TestClientCertificateRequired(t *testing.T) {
+	certPath, keyPath := writeCert(t)
+
+	cfg, err := clientTLSConfig(TLSConfig{ClientCA: certPath})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.VerifiedChains[0][0].Subject.CommonName))
+	}))
+	srv.TLS = cfg
+	srv.StartTLS()
+	defer srv.Close()
+
+	if _, err := srv.Client().Get(srv.URL); err == nil {
+		t.Fatalf("expected the handshake to fail without a client certificate")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatalf("could not load certificate: %s", err)
+	}
+
+	client := srv.Client()
+	client.Transport.(*http.Transport).TLSClientConfig.Certificates = []tls.Certificate{cert}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, got %d", resp.StatusCode)
+	}
+}
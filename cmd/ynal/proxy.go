@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// parseTrustedProxies parses CIDRs, or bare IPs meaning just that address.
+func parseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := []netip.Prefix{}
+
+	for _, v := range values {
+		if ip, err := netip.ParseAddr(v); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(ip, ip.BitLen()))
+			continue
+		}
+
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, err
+		}
+
+		prefixes = append(prefixes, p.Masked())
+	}
+
+	return prefixes, nil
+}
+
+// withTrustedProxies sets r.RemoteAddr to the IP (without a port) of the
+// client behind any trusted proxies, so everything downstream logs the real
+// client. Forwarding headers are only believed when they were added by a
+// trusted proxy, and are read right to left for as long as each hop is
+// trusted.
+func withTrustedProxies(trusted []netip.Prefix, next http.Handler) http.Handler {
+	if len(trusted) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip, ok := clientIP(r, trusted); ok {
+			r.RemoteAddr = ip.String()
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func clientIP(r *http.Request, trusted []netip.Prefix) (netip.Addr, bool) {
+	ap, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	peer := ap.Addr().Unmap()
+	client := peer
+
+	hops := forwardedFor(r.Header)
+	for i := len(hops) - 1; i >= 0 && isTrusted(client, trusted); i-- {
+		ip, ok := parseHop(hops[i])
+		if !ok {
+			break
+		}
+
+		client = ip
+	}
+
+	return client, client != peer
+}
+
+func isTrusted(ip netip.Addr, trusted []netip.Prefix) bool {
+	for _, p := range trusted {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forwardedFor returns the chain of client addresses a request passed
+// through, nearest last. The standard Forwarded header (RFC 7239) wins over
+// X-Forwarded-For when both are present.
+func forwardedFor(h http.Header) []string {
+	hops := []string{}
+
+	if values := h.Values("Forwarded"); len(values) > 0 {
+		for _, elem := range strings.Split(strings.Join(values, ","), ",") {
+			for _, pair := range strings.Split(elem, ";") {
+				k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(k, "for") {
+					hops = append(hops, strings.Trim(v, `"`))
+				}
+			}
+		}
+
+		return hops
+	}
+
+	for _, v := range strings.Split(strings.Join(h.Values("X-Forwarded-For"), ","), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			hops = append(hops, v)
+		}
+	}
+
+	return hops
+}
+
+// parseHop parses an address from a forwarding header, which may have a port
+// and (for IPv6) brackets. Obfuscated identifiers like "unknown" don't parse.
+func parseHop(hop string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		hop = host
+	}
+
+	ip, err := netip.ParseAddr(strings.Trim(hop, "[]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return ip.Unmap(), true
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithTrustedProxies(t *testing.T) {
+	trusted, err := parseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		remote   string
+		header   map[string]string
+		expected string
+	}{
+		{
+			name:     "direct client",
+			remote:   "203.0.113.7:5555",
+			expected: "203.0.113.7:5555",
+		},
+		{
+			name:     "untrusted peer can't spoof",
+			remote:   "203.0.113.7:5555",
+			header:   map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			expected: "203.0.113.7:5555",
+		},
+		{
+			name:     "x-forwarded-for",
+			remote:   "10.1.2.3:5555",
+			header:   map[string]string{"X-Forwarded-For": "198.51.100.1"},
+			expected: "198.51.100.1",
+		},
+		{
+			name:     "chain of trusted proxies",
+			remote:   "10.1.2.3:5555",
+			header:   map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.1, 192.0.2.1, 10.9.9.9"},
+			expected: "198.51.100.1",
+		},
+		{
+			name:     "forwarded wins over x-forwarded-for",
+			remote:   "[2001:db8::1]:5555",
+			header:   map[string]string{"Forwarded": `for="[2001:db8:cafe::17]:4711", for=198.51.100.2;proto=https`, "X-Forwarded-For": "6.6.6.6"},
+			expected: "198.51.100.2",
+		},
+		{
+			name:     "obfuscated hop stops the walk",
+			remote:   "10.1.2.3:5555",
+			header:   map[string]string{"Forwarded": "for=198.51.100.1, for=unknown"},
+			expected: "10.1.2.3:5555",
+		},
+		{
+			name:     "all hops trusted",
+			remote:   "10.1.2.3:5555",
+			header:   map[string]string{"X-Forwarded-For": "10.0.0.1"},
+			expected: "10.0.0.1",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/mit", nil)
+			r.RemoteAddr = tc.remote
+			for k, v := range tc.header {
+				r.Header.Set(k, v)
+			}
+
+			var got string
+			withTrustedProxies(trusted, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			})).ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxiesInvalid(t *testing.T) {
+	if _, err := parseTrustedProxies([]string{"10.0.0.0/99"}); err == nil {
+		t.Fatalf("expected an error for an invalid CIDR")
+	}
+}
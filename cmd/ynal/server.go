@@ -0,0 +1,566 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/github"
+	"github.com/packrat386/ynal/manifest"
+	"github.com/packrat386/ynal/middleware"
+	"github.com/packrat386/ynal/s3store"
+	"github.com/packrat386/ynal/spdx"
+	"github.com/packrat386/ynal/sqlitestore"
+	"github.com/packrat386/ynal/webhook"
+	"github.com/packrat386/ynal/ynalhttp"
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout bounds how long in-flight requests get to finish once ynal
+// is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
+// statsInterval is how often hit counts are saved, when they're kept.
+const statsInterval = time.Minute
+
+// server is one listener ynal serves on. Every server is started together
+// and shut down together.
+type server struct {
+	name string
+	addr net.Addr
+
+	// socket is the net.Listener or net.PacketConn it serves on, which is
+	// handed to the new process on an upgrade.
+	socket any
+
+	serve    func() error
+	shutdown func(context.Context) error
+}
+
+func serve(cfg Config, dev bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := openStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if c, ok := store.(io.Closer); ok {
+		defer c.Close()
+	}
+
+	if cfg.Webhooks.Enabled() {
+		n := &webhook.Notifier{URLs: cfg.Webhooks.URLs, Secret: cfg.Webhooks.Secret}
+		n.Watch(store)
+	}
+
+	custom, err := loadCustom(cfg)
+	if err != nil {
+		return err
+	}
+
+	// shared applies to every host, opts just to the default one
+	shared := []ynalhttp.Option{
+		ynalhttp.WithCacheControl(cfg.CacheControl),
+		ynalhttp.WithBasePath(cfg.BasePath),
+		ynalhttp.WithSiteURL(cfg.SiteURL),
+		ynalhttp.WithTheme(cfg.Theme),
+		ynalhttp.WithCustom(custom),
+	}
+
+	if cfg.Index.Order != "" {
+		shared = append(shared, ynalhttp.WithIndexOrder(ynalhttp.IndexOrder(cfg.Index.Order), cfg.Index.Priority...))
+	}
+
+	if cfg.Negotiation.Strict {
+		shared = append(shared, ynalhttp.WithStrictAccept())
+	}
+
+	if len(cfg.Negotiation.Prefer) > 0 {
+		shared = append(shared, ynalhttp.WithPreferredTypes(cfg.Negotiation.Prefer...))
+	}
+
+	if len(cfg.Negotiation.BrowserPrefer) > 0 {
+		shared = append(shared, ynalhttp.WithBrowserPreferredTypes(cfg.Negotiation.BrowserPrefer...))
+	}
+
+	if cfg.Render.CacheSize > 0 {
+		shared = append(shared, ynalhttp.WithRenderBudget(int64(cfg.Render.CacheSize)<<20))
+	}
+
+	if cfg.Signing.Key != "" {
+		key, err := loadSigningKey(cfg.Signing.Key)
+		if err != nil {
+			return err
+		}
+
+		shared = append(shared, ynalhttp.WithSigningKey(key))
+	}
+
+	if cfg.Scan.Enabled() {
+		shared = append(shared, ynalhttp.WithResolver(&manifest.DepsDev{URL: cfg.Scan.Registry}))
+	}
+
+	if cfg.GitHub.Enabled {
+		shared = append(shared, ynalhttp.WithDetector(&github.Client{URL: cfg.GitHub.URL, Token: cfg.GitHub.Token, TTL: cfg.GitHub.CacheTTL}))
+	}
+
+	opts := []ynalhttp.Option{ynalhttp.WithStore(store)}
+
+	if dev {
+		opts = append(opts, ynalhttp.WithDevMode(os.DirFS(".")))
+	}
+
+	if cfg.Stats.Enabled() {
+		stats, err := ynalhttp.LoadStats(cfg.Stats.Path)
+		if err != nil {
+			return err
+		}
+
+		opts = append(opts, ynalhttp.WithStats(stats))
+
+		go saveStats(ctx, stats, cfg.Stats.Path)
+		defer func() {
+			if err := stats.Save(cfg.Stats.Path); err != nil {
+				log.Printf("could not save stats: %s", err)
+			}
+		}()
+	}
+
+	if cfg.Tracing && !tracingDisabled() {
+		tp, err := newTracerProvider(ctx)
+		if err != nil {
+			return err
+		}
+
+		shared = append(shared, ynalhttp.WithTracerProvider(tp))
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+
+			if err := tp.Shutdown(shutdownCtx); err != nil {
+				log.Printf("could not flush traces: %s", err)
+			}
+		}()
+	}
+
+	maintenance := ynalhttp.NewMaintenance(cfg.Maintenance.RetryAfter)
+	maintenance.Set(cfg.Maintenance.Enabled)
+	shared = append(shared, ynalhttp.WithMaintenance(maintenance))
+
+	audit, err := openAuditLog(cfg.Log.Audit)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+
+	if audit != nil {
+		opts = append(opts, ynalhttp.WithAuditLog(audit.AuditLog))
+	}
+
+	mode := "read-only"
+	if cfg.Admin.Serve {
+		mode = "admin"
+	}
+
+	audit.record(ynalhttp.AuditEntry{Action: ynalhttp.AuditConfig, Actor: "startup", Detail: fmt.Sprintf("started in %s mode, maintenance mode: %t", mode, maintenance.Enabled())})
+
+	go toggleMaintenance(ctx, maintenance, audit)
+
+	if cfg.Admin.Serve {
+		admin, ok := store.(ynalhttp.AdminStore)
+		if !ok {
+			return errors.New("the configured license store doesn't support the admin API")
+		}
+
+		opts = append(opts, ynalhttp.WithAdmin(admin), ynalhttp.WithTokens(cfg.Admin.AllTokens()), ynalhttp.WithClientCertificates(cfg.Admin.ClientCerts))
+
+		if cfg.Acceptances.Enabled {
+			acceptances, ok := store.(ynalhttp.AcceptanceStore)
+			if !ok {
+				return errors.New("the configured license store can't record acceptances")
+			}
+
+			opts = append(opts, ynalhttp.WithAcceptances(acceptances))
+		}
+	}
+
+	var h http.Handler
+
+	h, err = ynalhttp.New(slices.Concat(shared, opts)...)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("%s, serving %d licenses at catalog revision %s", ynalhttp.ReadBuildInfo(), len(store.List()), ynal.Revision(store.List()))
+
+	if len(cfg.Hosts) > 0 {
+		if h, err = newHostRouter(cfg.Hosts, shared, h); err != nil {
+			return err
+		}
+	}
+
+	// under systemd socket activation, sockets are matched up by name:
+	// "http", "https", and "grpc". A single unnamed socket serves HTTP.
+	inherited, err := activationListeners()
+	if err != nil {
+		return err
+	}
+
+	if l, ok := inherited.listeners[""]; ok {
+		inherited.listeners["http"] = l
+	}
+
+	trusted, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	access, err := openAccessLog(cfg.Log)
+	if err != nil {
+		return err
+	}
+	defer access.Close()
+
+	// wrap is applied to every HTTP server's handler
+	wrap := func(h http.Handler) http.Handler {
+		if cfg.Log.Access {
+			h = withLogging(access, h)
+		}
+
+		return withTrustedProxies(trusted, middleware.RequestID(h))
+	}
+
+	servers := []server{}
+
+	if cfg.Addr != "" || inherited.listeners["http"] != nil {
+		lis, err := listen(inherited.listeners, "http", cfg.Addr)
+		if err != nil {
+			return err
+		}
+
+		plain := h
+		if cfg.TLS.Redirect {
+			plain = redirectToHTTPS(cfg.TLS.Addr)
+		}
+
+		srv := &http.Server{Handler: wrap(plain), Protocols: plainProtocols(cfg)}
+		servers = append(servers, server{
+			name:     "http",
+			addr:     lis.Addr(),
+			socket:   lis,
+			serve:    func() error { return srv.Serve(lis) },
+			shutdown: srv.Shutdown,
+		})
+	}
+
+	if cfg.TLS.Enabled() {
+		lis, err := listen(inherited.listeners, "https", cfg.TLS.Addr)
+		if err != nil {
+			return err
+		}
+
+		secure := h
+
+		// HTTP/3 shares the TLS port, over UDP, and is advertised to
+		// clients on every HTTPS response
+		if cfg.TLS.HTTP3 {
+			h3, err := newHTTP3Server(cfg, wrap(h), inherited.packets)
+			if err != nil {
+				return err
+			}
+
+			servers = append(servers, h3)
+			secure = withAltSvc(h3.addr, h)
+		}
+
+		tlsConfig, err := clientTLSConfig(cfg.TLS)
+		if err != nil {
+			return err
+		}
+
+		srv := &http.Server{Handler: wrap(secure), TLSConfig: tlsConfig}
+		servers = append(servers, server{
+			name:     "https",
+			addr:     lis.Addr(),
+			socket:   lis,
+			serve:    func() error { return srv.ServeTLS(lis, cfg.TLS.Cert, cfg.TLS.Key) },
+			shutdown: srv.Shutdown,
+		})
+	}
+
+	if cfg.GRPCAddr != "" || inherited.listeners["grpc"] != nil {
+		lis, err := listen(inherited.listeners, "grpc", cfg.GRPCAddr)
+		if err != nil {
+			return err
+		}
+
+		srv := newGRPCServer(store)
+		servers = append(servers, server{
+			name:     "grpc",
+			addr:     lis.Addr(),
+			socket:   lis,
+			serve:    func() error { return srv.Serve(lis) },
+			shutdown: gracefulStop(srv),
+		})
+	}
+
+	if cfg.DebugAddr != "" || inherited.listeners["debug"] != nil {
+		lis, err := listen(inherited.listeners, "debug", cfg.DebugAddr)
+		if err != nil {
+			return err
+		}
+
+		srv := &http.Server{Handler: debugHandler()}
+		servers = append(servers, server{
+			name:     "debug",
+			addr:     lis.Addr(),
+			socket:   lis,
+			serve:    func() error { return srv.Serve(lis) },
+			shutdown: srv.Shutdown,
+		})
+	}
+
+	go upgradeOnSignal(ctx, servers, audit)
+
+	// the ynal this one is replacing keeps serving until now
+	if inherited.upgradedFrom != 0 {
+		if err := takeOver(inherited.upgradedFrom); err != nil {
+			log.Printf("%s", err)
+		}
+	}
+
+	return runServers(ctx, servers)
+}
+
+// toggleMaintenance switches maintenance mode on or off every time ynal gets
+// maintenanceSignal, until ctx is done.
+func toggleMaintenance(ctx context.Context, m *ynalhttp.Maintenance, a *auditLog) {
+	if maintenanceSignal == nil {
+		return
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, maintenanceSignal)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			m.Set(!m.Enabled())
+			log.Printf("maintenance mode: %t", m.Enabled())
+			a.record(ynalhttp.AuditEntry{Action: ynalhttp.AuditConfig, Actor: "signal", Detail: fmt.Sprintf("maintenance mode: %t", m.Enabled())})
+		}
+	}
+}
+
+// saveStats saves stats to path every statsInterval until ctx is done. serve
+// saves once more on the way out.
+func saveStats(ctx context.Context, stats *ynalhttp.Stats, path string) {
+	t := time.NewTicker(statsInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := stats.Save(path); err != nil {
+				log.Printf("could not save stats: %s", err)
+			}
+		}
+	}
+}
+
+// plainProtocols is what the plain HTTP server speaks: HTTP/1 always, and
+// HTTP/2 over cleartext (h2c) if it's enabled.
+func plainProtocols(cfg Config) *http.Protocols {
+	p := &http.Protocols{}
+	p.SetHTTP1(true)
+	p.SetUnencryptedHTTP2(cfg.H2C)
+
+	return p
+}
+
+func listen(inherited map[string]net.Listener, name string, addr string) (net.Listener, error) {
+	if l, ok := inherited[name]; ok {
+		return l, nil
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen for %s: %w", name, err)
+	}
+
+	return l, nil
+}
+
+// runServers serves on every server until ctx is done or one of them fails,
+// then shuts all of them down.
+func runServers(ctx context.Context, servers []server) error {
+	errs := make(chan error, len(servers))
+
+	for _, s := range servers {
+		log.Printf("%s listening on: %s", s.name, s.addr)
+
+		go func() {
+			err := s.serve()
+			if errors.Is(err, http.ErrServerClosed) || errors.Is(err, grpc.ErrServerStopped) {
+				err = nil
+			}
+			if err != nil {
+				err = fmt.Errorf("%s: %w", s.name, err)
+			}
+
+			errs <- err
+		}()
+	}
+
+	var serveErr error
+
+	select {
+	case <-ctx.Done():
+		log.Println("shutting down")
+	case serveErr = <-errs:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	shutdownErrs := []error{serveErr}
+	for _, s := range servers {
+		if err := s.shutdown(shutdownCtx); err != nil {
+			shutdownErrs = append(shutdownErrs, fmt.Errorf("could not shut down %s: %w", s.name, err))
+		}
+	}
+
+	return errors.Join(shutdownErrs...)
+}
+
+func gracefulStop(srv *grpc.Server) func(context.Context) error {
+	return func(ctx context.Context) error {
+		done := make(chan struct{})
+
+		go func() {
+			srv.GracefulStop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			srv.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// redirectToHTTPS sends every request to the same host and path on the HTTPS
+// listener.
+func redirectToHTTPS(tlsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(tlsAddr)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// openStore opens the license store to serve from. With SPDX syncing on, it
+// starts with the last successful sync if there is one, falling back to the
+// embedded licenses until the first sync finishes, and syncs in the
+// background until ctx is done.
+func openStore(ctx context.Context, cfg Config) (ynal.LicenseStore, error) {
+	if cfg.LicenseDir != "" {
+		return ynal.NewDirStore(cfg.LicenseDir)
+	}
+
+	if cfg.SQLite.Enabled() {
+		seed, err := ynal.Embedded()
+		if err != nil {
+			return nil, err
+		}
+
+		return sqlitestore.Open(cfg.SQLite.Path, seed)
+	}
+
+	if cfg.S3.Enabled() {
+		store, err := s3store.Open(ctx, s3store.Bucket{
+			Endpoint:        cfg.S3.Endpoint,
+			Name:            cfg.S3.Bucket,
+			Prefix:          cfg.S3.Prefix,
+			Region:          cfg.S3.Region,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		go store.Run(ctx, cfg.S3.Refresh)
+
+		return store, nil
+	}
+
+	if cfg.SPDX.Enabled() {
+		syncer := &spdx.Syncer{ListURL: cfg.SPDX.ListURL, Dir: cfg.SPDX.Dir}
+
+		licenses, err := syncer.Load()
+		if err != nil || len(licenses) == 0 {
+			log.Println("no previous spdx sync, serving embedded licenses until one finishes")
+
+			if licenses, err = ynal.Embedded(); err != nil {
+				return nil, err
+			}
+		}
+
+		catalog := ynal.NewCatalog(licenses)
+		go syncer.Run(ctx, catalog, cfg.SPDX.Refresh)
+
+		return spdxStore{catalog, syncer}, nil
+	}
+
+	return ynal.EmbeddedStore()
+}
+
+// loadCustom loads the documents in custom_dir, if it's set.
+func loadCustom(cfg Config) ([]ynal.LicenseData, error) {
+	if cfg.CustomDir == "" {
+		return nil, nil
+	}
+
+	return ynal.LoadCustom(os.DirFS(cfg.CustomDir))
+}
+
+// spdxStore is a catalog kept in sync with the SPDX license list, which is
+// healthy as long as the syncing is.
+type spdxStore struct {
+	*ynal.Catalog
+	syncer *spdx.Syncer
+}
+
+func (s spdxStore) Health(ctx context.Context) error {
+	return s.syncer.Health(ctx)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRedirectToHTTPS(t *testing.T) {
+	tt := []struct {
+		name     string
+		tlsAddr  string
+		host     string
+		expected string
+	}{
+		{
+			name:     "default port",
+			tlsAddr:  ":443",
+			host:     "ynal.example.com",
+			expected: "https://ynal.example.com/mit?x=1",
+		},
+		{
+			name:     "custom port",
+			tlsAddr:  "localhost:8443",
+			host:     "localhost:8080",
+			expected: "https://localhost:8443/mit?x=1",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/mit?x=1", nil)
+			r.Host = tc.host
+
+			w := httptest.NewRecorder()
+
+			redirectToHTTPS(tc.tlsAddr).ServeHTTP(w, r)
+
+			if w.Code != http.StatusPermanentRedirect {
+				t.Fatalf("expected 308, got %d", w.Code)
+			}
+
+			if got := w.Header().Get("Location"); got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestH2C(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Proto))
+		}))
+		srv.Config.Protocols = plainProtocols(Config{H2C: enabled})
+		srv.Start()
+		defer srv.Close()
+
+		protocols := &http.Protocols{}
+		protocols.SetUnencryptedHTTP2(true)
+
+		client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+
+		resp, err := client.Get(srv.URL)
+
+		if enabled {
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.ProtoMajor != 2 {
+				t.Fatalf("expected HTTP/2, got %s", resp.Proto)
+			}
+		} else if err == nil {
+			resp.Body.Close()
+			t.Fatalf("expected an HTTP/2-only client to fail without h2c, got %s", resp.Proto)
+		}
+	}
+}
+
+func TestRunServersShutsDownTogether(t *testing.T) {
+	servers := []server{}
+
+	for _, name := range []string{"one", "two"} {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("could not listen: %s", err)
+		}
+
+		srv := &http.Server{Handler: http.NotFoundHandler()}
+		servers = append(servers, server{name: name, addr: lis.Addr(), serve: func() error { return srv.Serve(lis) }, shutdown: srv.Shutdown})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- runServers(ctx, servers)
+	}()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("servers did not shut down")
+	}
+}
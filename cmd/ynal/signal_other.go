@@ -0,0 +1,13 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// maintenanceSignal toggles maintenance mode. There's no SIGUSR1 here, so it
+// can only be set from the config.
+var maintenanceSignal os.Signal
+
+// upgradeSignal starts a new ynal to take over serving from this one. Sockets
+// can't be handed over here, so there's no upgrading in place.
+var upgradeSignal os.Signal
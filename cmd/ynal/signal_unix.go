@@ -0,0 +1,14 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// maintenanceSignal toggles maintenance mode.
+var maintenanceSignal os.Signal = syscall.SIGUSR1
+
+// upgradeSignal starts a new ynal to take over serving from this one.
+var upgradeSignal os.Signal = syscall.SIGUSR2
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSigningKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %s", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("could not marshal key: %s", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "signing.pem")
+	os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+
+	got, err := loadSigningKey(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !got.Equal(key) {
+		t.Errorf("expected the key that was written")
+	}
+
+	// an ECDSA key can't sign licenses
+	_, ecKey := writeCert(t)
+	if _, err := loadSigningKey(ecKey); err == nil {
+		t.Errorf("expected an error for a key that isn't Ed25519")
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/packrat386/ynal/ynalhttp"
+)
+
+// upgradeEnv names the PID of the ynal that started this one to take over its
+// sockets.
+const upgradeEnv = "YNAL_UPGRADE_PID"
+
+// upgradeOnSignal starts a new ynal every time this one gets upgradeSignal,
+// until ctx is done. The new process runs the executable on disk now, with
+// the same arguments, so it picks up a new binary and a changed config. It
+// inherits every socket in servers the same way it would from systemd socket
+// activation, so no connection is refused in between, and once it's ready it
+// sends this one SIGTERM, which stops accepting connections and finishes the
+// requests in flight. If it fails to start, this one keeps serving.
+func upgradeOnSignal(ctx context.Context, servers []server, a *auditLog) {
+	if upgradeSignal == nil {
+		return
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, upgradeSignal)
+	defer signal.Stop(sig)
+
+	// exited is the running upgrade's exit status, if one is running
+	var exited <-chan error
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-exited:
+			exited = nil
+			log.Printf("upgrade failed, the new process exited: %v", err)
+		case <-sig:
+			if exited != nil {
+				log.Println("already upgrading")
+				continue
+			}
+
+			a.record(ynalhttp.AuditEntry{Action: ynalhttp.AuditConfig, Actor: "signal", Detail: "upgrade started"})
+
+			var err error
+			if exited, err = upgrade(servers); err != nil {
+				log.Printf("could not upgrade: %s", err)
+			}
+		}
+	}
+}
+
+// upgrade starts a new ynal with every socket in servers, returning its exit
+// status once it's done.
+func upgrade(servers []server) (<-chan error, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("could not find executable: %w", err)
+	}
+
+	cmd, err := upgradeCommand(exe, os.Args[1:], os.Getpid(), servers)
+	if err != nil {
+		return nil, err
+	}
+
+	// the new process has its own copies now
+	defer closeFiles(cmd.ExtraFiles)
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not start new process: %w", err)
+	}
+
+	log.Printf("upgrading, started new process %d", cmd.Process.Pid)
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	return exited, nil
+}
+
+// upgradeCommand returns the command that runs exe with args, passing it the
+// sockets in servers, named after them, like systemd socket activation does.
+func upgradeCommand(exe string, args []string, pid int, servers []server) (*exec.Cmd, error) {
+	cmd := exec.Command(exe, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	names := []string{}
+
+	for _, s := range servers {
+		socket, ok := s.socket.(interface{ File() (*os.File, error) })
+		if !ok {
+			closeFiles(cmd.ExtraFiles)
+			return nil, fmt.Errorf("could not hand over %s socket: %T can't be passed to another process", s.name, s.socket)
+		}
+
+		f, err := socket.File()
+		if err != nil {
+			closeFiles(cmd.ExtraFiles)
+			return nil, fmt.Errorf("could not hand over %s socket: %w", s.name, err)
+		}
+
+		cmd.ExtraFiles = append(cmd.ExtraFiles, f)
+		names = append(names, s.name)
+	}
+
+	cmd.Env = append(os.Environ(),
+		"LISTEN_FDS="+strconv.Itoa(len(names)),
+		"LISTEN_FDNAMES="+strings.Join(names, ":"),
+		upgradeEnv+"="+strconv.Itoa(pid),
+	)
+
+	return cmd, nil
+}
+
+// takeOver tells the ynal that started this one to stop, now that this one
+// is serving on its sockets.
+func takeOver(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("could not find process %d: %w", pid, err)
+	}
+
+	if err := p.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("could not stop process %d: %w", pid, err)
+	}
+
+	return nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
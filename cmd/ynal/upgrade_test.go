@@ -0,0 +1,49 @@
+//go:build unix
+
+package main
+
+import (
+	"net"
+	"slices"
+	"testing"
+)
+
+func TestUpgradeCommand(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %s", err)
+	}
+	defer lis.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %s", err)
+	}
+	defer conn.Close()
+
+	servers := []server{{name: "http", socket: lis}, {name: "http3", socket: conn}}
+
+	cmd, err := upgradeCommand("/usr/bin/ynal", []string{"serve", "--admin"}, 42, servers)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer closeFiles(cmd.ExtraFiles)
+
+	if !slices.Equal(cmd.Args, []string{"/usr/bin/ynal", "serve", "--admin"}) {
+		t.Errorf("unexpected args: %v", cmd.Args)
+	}
+
+	if len(cmd.ExtraFiles) != 2 {
+		t.Fatalf("expected 2 sockets, got %d", len(cmd.ExtraFiles))
+	}
+
+	for _, env := range []string{"LISTEN_FDS=2", "LISTEN_FDNAMES=http:http3", upgradeEnv + "=42"} {
+		if !slices.Contains(cmd.Env, env) {
+			t.Errorf("expected %s in the environment", env)
+		}
+	}
+
+	if _, err := upgradeCommand("/usr/bin/ynal", nil, 42, []server{{name: "http", socket: "nope"}}); err == nil {
+		t.Errorf("expected an error handing over a socket that isn't one")
+	}
+}
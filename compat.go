@@ -0,0 +1,98 @@
+package ynal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Verdict is the answer to whether licenses can be combined.
+type Verdict string
+
+const (
+	Compatible   Verdict = "compatible"
+	Conditional  Verdict = "conditional"
+	Incompatible Verdict = "incompatible"
+	Unknown      Verdict = "unknown"
+)
+
+// verdictRank orders verdicts from worst to best.
+var verdictRank = map[Verdict]int{
+	Incompatible: 0,
+	Unknown:      1,
+	Conditional:  2,
+	Compatible:   3,
+}
+
+// Better reports whether v is a better outcome than o.
+func (v Verdict) Better(o Verdict) bool {
+	return verdictRank[v] > verdictRank[o]
+}
+
+// CompatibilityRule says whether code under the From license can be used in a
+// project under the Into license, and why. Licenses are named by their SPDX
+// identifiers.
+type CompatibilityRule struct {
+	From    string  `json:"from"`
+	Into    string  `json:"into"`
+	Verdict Verdict `json:"verdict"`
+	Reason  string  `json:"reason"`
+}
+
+// Compatibility is a curated set of rules about which licenses can be used
+// together. It's deliberately conservative, and any pair of licenses it
+// doesn't know about is Unknown.
+type Compatibility struct {
+	rules map[[2]string]CompatibilityRule
+}
+
+// LoadCompatibility parses a JSON list of rules.
+func LoadCompatibility(data []byte) (*Compatibility, error) {
+	rules := []CompatibilityRule{}
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("could not parse compatibility rules: %w", err)
+	}
+
+	c := &Compatibility{rules: map[[2]string]CompatibilityRule{}}
+
+	for _, r := range rules {
+		if _, ok := verdictRank[r.Verdict]; !ok {
+			return nil, fmt.Errorf("unknown verdict %q for %s into %s", r.Verdict, r.From, r.Into)
+		}
+
+		c.rules[ruleKey(r.From, r.Into)] = r
+	}
+
+	return c, nil
+}
+
+// EmbeddedCompatibility returns the compatibility rules embedded in the
+// binary.
+func EmbeddedCompatibility() (*Compatibility, error) {
+	return LoadCompatibility(CompatibilityData)
+}
+
+// Check says whether code under the license from can be used in a project
+// under the license into, both given as SPDX identifiers in any case.
+func (c *Compatibility) Check(from string, into string) CompatibilityRule {
+	if strings.EqualFold(from, into) {
+		return CompatibilityRule{From: from, Into: into, Verdict: Compatible, Reason: "They're the same license."}
+	}
+
+	if r, ok := c.rules[ruleKey(from, into)]; ok {
+		return r
+	}
+
+	return CompatibilityRule{
+		From:    from,
+		Into:    into,
+		Verdict: Unknown,
+		Reason:  fmt.Sprintf("There's no rule for using %s code in a %s project. Read both licenses, or ask a lawyer.", from, into),
+	}
+}
+
+// ruleKey is where the rule for from into into is kept. SPDX identifiers
+// aren't case sensitive.
+func ruleKey(from string, into string) [2]string {
+	return [2]string{strings.ToLower(from), strings.ToLower(into)}
+}
@@ -0,0 +1,59 @@
+package ynal
+
+import (
+	"testing"
+)
+
+func TestCompatibilityCheck(t *testing.T) {
+	c, err := EmbeddedCompatibility()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tt := []struct {
+		from     string
+		into     string
+		expected Verdict
+	}{
+		{"MIT", "GPL-3.0-or-later", Compatible},
+		{"GPL-3.0-or-later", "MIT", Incompatible},
+		{"Apache-2.0", "GPL-2.0-only", Incompatible},
+		{"MPL-2.0", "MIT", Conditional},
+		{"MIT", "MIT", Compatible},
+		{"MIT", "Nope-1.0", Unknown},
+		{"mpl-2.0", "mit", Conditional},
+	}
+
+	for _, tc := range tt {
+		r := c.Check(tc.from, tc.into)
+		if r.Verdict != tc.expected || r.Reason == "" {
+			t.Errorf("%s into %s: expected %s, got %+v", tc.from, tc.into, tc.expected, r)
+		}
+	}
+}
+
+func TestCompatibilityCoversEmbedded(t *testing.T) {
+	c, err := EmbeddedCompatibility()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	licenses, err := Embedded()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, from := range licenses {
+		for _, into := range licenses {
+			if r := c.Check(from.SPDXID(), into.SPDXID()); r.Verdict == Unknown {
+				t.Errorf("no rule for %s into %s", from.SPDXID(), into.SPDXID())
+			}
+		}
+	}
+}
+
+func TestLoadCompatibilityBadVerdict(t *testing.T) {
+	if _, err := LoadCompatibility([]byte(`[{"from": "MIT", "into": "GPL-3.0-or-later", "verdict": "probably"}]`)); err == nil {
+		t.Fatalf("expected an error for an unknown verdict")
+	}
+}
@@ -0,0 +1,24 @@
+package ynal
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// LoadCustom reads an organization's own licenses and policy documents from
+// the root of fsys, just like LoadLicenses. They're kept apart from the
+// licenses: their URLs are under /custom/ and they're marked Custom, since
+// they aren't open source licenses.
+func LoadCustom(fsys fs.FS) ([]LicenseData, error) {
+	docs, err := LoadLicenses(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("could not load custom documents: %w", err)
+	}
+
+	for i := range docs {
+		docs[i].URL = "/custom/" + docs[i].ID
+		docs[i].Custom = true
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,36 @@
+package ynal
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadCustom(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Acceptable-Use.txt":  {Data: []byte("be nice\n")},
+		"Acceptable-Use.json": {Data: []byte(`{"family": "Policies"}`)},
+		"Corp-EULA.txt":       {Data: []byte("no reverse engineering\n")},
+	}
+
+	docs, err := LoadCustom(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 documents, got %+v", docs)
+	}
+
+	d, ok := FindLicense(docs, "acceptable-use")
+	if !ok {
+		t.Fatalf("expected acceptable-use in %+v", docs)
+	}
+
+	if d.URL != "/custom/acceptable-use" || !d.Custom || d.Family != "Policies" {
+		t.Fatalf("unexpected document: %+v", d)
+	}
+
+	if _, err := LoadCustom(fstest.MapFS{"Bad.txt": {Data: []byte("x")}, "Bad.json": {Data: []byte("{")}}); err == nil {
+		t.Fatalf("expected an error for bad metadata")
+	}
+}
@@ -0,0 +1,137 @@
+package ynal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DirStore keeps licenses as *.txt files (and their metadata) in a directory
+// and lets them be added and removed at runtime. Every change is reloaded
+// from disk, so what is served always matches what is on disk.
+type DirStore struct {
+	dir     string
+	catalog *Catalog
+
+	mu sync.Mutex
+}
+
+// NewDirStore loads the licenses in dir.
+func NewDirStore(dir string) (*DirStore, error) {
+	d := &DirStore{dir: dir, catalog: NewCatalog(nil)}
+
+	if err := d.reload(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *DirStore) List() []LicenseData {
+	return d.catalog.List()
+}
+
+func (d *DirStore) Get(id string) (LicenseData, bool) {
+	return d.catalog.Get(id)
+}
+
+// Watch registers fn to be called after every Put and Delete. Changes made to
+// the directory by anything else aren't noticed.
+func (d *DirStore) Watch(fn func([]LicenseData)) {
+	d.catalog.Watch(fn)
+}
+
+// Put creates or replaces the license with the given title. Its ID is the
+// title lowercased.
+func (d *DirStore) Put(title string, text string) (LicenseData, error) {
+	if err := ValidateLicense(title, text); err != nil {
+		return LicenseData{}, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	id := strings.ToLower(title)
+
+	// the title might be changing case, which changes the filename
+	if existing, ok := d.catalog.Get(id); ok && existing.Title != title {
+		if err := os.Remove(d.path(existing.Title)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return LicenseData{}, fmt.Errorf("could not remove old license: %w", err)
+		}
+
+		// the metadata goes along with it
+		if err := os.Rename(MetadataPath(d.path(existing.Title)), MetadataPath(d.path(title))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return LicenseData{}, fmt.Errorf("could not move metadata: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(d.dir, ".put-*")
+	if err != nil {
+		return LicenseData{}, fmt.Errorf("could not create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(text); err != nil {
+		tmp.Close()
+		return LicenseData{}, fmt.Errorf("could not write license: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return LicenseData{}, fmt.Errorf("could not write license: %w", err)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return LicenseData{}, fmt.Errorf("could not write license: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), d.path(title)); err != nil {
+		return LicenseData{}, fmt.Errorf("could not write license: %w", err)
+	}
+
+	if err := d.reload(); err != nil {
+		return LicenseData{}, err
+	}
+
+	l, _ := d.catalog.Get(id)
+	return l, nil
+}
+
+// Delete removes the license with the given ID, returning ErrNotFound if
+// there isn't one.
+func (d *DirStore) Delete(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	existing, ok := d.catalog.Get(id)
+	if !ok {
+		return ErrNotFound
+	}
+
+	if err := os.Remove(d.path(existing.Title)); err != nil {
+		return fmt.Errorf("could not remove license: %w", err)
+	}
+
+	if err := os.Remove(MetadataPath(d.path(existing.Title))); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not remove metadata: %w", err)
+	}
+
+	return d.reload()
+}
+
+func (d *DirStore) path(title string) string {
+	return filepath.Join(d.dir, title+".txt")
+}
+
+func (d *DirStore) reload() error {
+	licenses, err := LoadLicenses(os.DirFS(d.dir))
+	if err != nil {
+		return fmt.Errorf("could not reload licenses: %w", err)
+	}
+
+	d.catalog.Replace(licenses)
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package ynal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirStore(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "Bar.txt"), []byte("bar text\n"), 0644); err != nil {
+		t.Fatalf("could not write license: %s", err)
+	}
+
+	d, err := NewDirStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, ok := d.Get("bar"); !ok {
+		t.Fatalf("expected existing license to be loaded")
+	}
+
+	watched := 0
+	d.Watch(func([]LicenseData) { watched++ })
+
+	l, err := d.Put("Foo-1.0", "foo text\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if l.ID != "foo-1.0" || l.URL != "/foo-1.0" || l.Text != "foo text\n" {
+		t.Fatalf("unexpected license: %+v", l)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "Foo-1.0.txt")); err != nil {
+		t.Fatalf("expected license on disk: %s", err)
+	}
+
+	// changing the case of the title renames the file
+	if _, err := d.Put("FOO-1.0", "foo text\n"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "Foo-1.0.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected old file to be removed, got: %v", err)
+	}
+
+	if len(d.List()) != 2 {
+		t.Fatalf("expected two licenses, got %+v", d.List())
+	}
+
+	if err := d.Delete("foo-1.0"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, ok := d.Get("foo-1.0"); ok {
+		t.Fatalf("expected license to be gone")
+	}
+
+	if watched != 3 {
+		t.Fatalf("expected a change for every put and delete, got %d", watched)
+	}
+
+	if err := d.Delete("foo-1.0"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestDirStoreRejectsBadTitles(t *testing.T) {
+	d, err := NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, title := range []string{"", "../etc/passwd", ".hidden", "a b"} {
+		if _, err := d.Put(title, "text"); err == nil {
+			t.Errorf("expected %q to be rejected", title)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package ynal
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// EmbeddedExceptions returns the license exceptions embedded in the binary,
+// like Classpath-exception-2.0. They're loaded just like licenses, but their
+// URLs are under /exceptions/ and their titles are their SPDX identifiers.
+func EmbeddedExceptions() ([]LicenseData, error) {
+	sub, err := fs.Sub(Exceptions, "exceptions")
+	if err != nil {
+		return nil, fmt.Errorf("could not subsystem exceptions: %w", err)
+	}
+
+	exceptions, err := LoadLicenses(sub)
+	if err != nil {
+		return nil, fmt.Errorf("could not load exceptions: %w", err)
+	}
+
+	for i := range exceptions {
+		exceptions[i].URL = "/exceptions/" + exceptions[i].ID
+		exceptions[i].SPDX = exceptions[i].Title
+	}
+
+	return exceptions, nil
+}
+
+// WithException returns l combined with the exception e, as the SPDX
+// expression "l WITH e" means: l's text with e's appended. Its ID is the two
+// IDs joined by a +.
+func (l LicenseData) WithException(e LicenseData) LicenseData {
+	c := NewLicense(l.Title+" WITH "+e.Title, strings.TrimRight(l.Text, "\n")+"\n\n"+e.Text)
+
+	c.ID = l.ID + "+" + e.ID
+	c.URL = "/" + c.ID
+	c.SPDX = l.SPDXID() + " WITH " + e.SPDXID()
+	c.Deprecated = l.Deprecated
+	c.Successor = l.Successor
+
+	return c
+}
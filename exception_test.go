@@ -0,0 +1,48 @@
+package ynal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedExceptions(t *testing.T) {
+	exceptions, err := EmbeddedExceptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	e, ok := FindLicense(exceptions, "classpath-exception-2.0")
+	if !ok {
+		t.Fatalf("expected the Classpath exception in %+v", exceptions)
+	}
+
+	if e.URL != "/exceptions/classpath-exception-2.0" || e.SPDXID() != "Classpath-exception-2.0" {
+		t.Fatalf("unexpected exception: %+v", e)
+	}
+}
+
+func TestWithException(t *testing.T) {
+	l := NewLicense("GPL_2", "gpl text\n\n")
+	l.SPDX = "GPL-2.0-only"
+
+	e := NewLicense("Classpath-exception-2.0", "exception text\n")
+	e.SPDX = e.Title
+
+	c := l.WithException(e)
+
+	if c.ID != "gpl_2+classpath-exception-2.0" || c.URL != "/gpl_2+classpath-exception-2.0" {
+		t.Fatalf("unexpected combination: %+v", c)
+	}
+
+	if c.Text != "gpl text\n\nexception text\n" {
+		t.Fatalf("unexpected text: %q", c.Text)
+	}
+
+	if c.SPDXID() != "GPL-2.0-only WITH Classpath-exception-2.0" || !strings.HasPrefix(c.Title, "GPL_2 WITH") {
+		t.Fatalf("unexpected combination: %+v", c)
+	}
+
+	if c.Digest == l.Digest {
+		t.Fatalf("expected the digest to cover the exception")
+	}
+}
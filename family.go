@@ -0,0 +1,121 @@
+package ynal
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Family is a group of related licenses, like every version and variant of
+// the GPL.
+type Family struct {
+	ID       string
+	Name     string
+	URL      string
+	Licenses []LicenseData
+}
+
+// FamilyID returns the ID of the family with the given name: the name
+// lowercased.
+func FamilyID(name string) string {
+	return strings.ToLower(name)
+}
+
+// Families groups licenses by family, sorted by name. Licenses keep their
+// order within a family, and licenses without one aren't in any.
+func Families(licenses []LicenseData) []Family {
+	byID := map[string]*Family{}
+	ids := []string{}
+
+	for _, l := range licenses {
+		if l.Family == "" {
+			continue
+		}
+
+		id := FamilyID(l.Family)
+
+		f, ok := byID[id]
+		if !ok {
+			f = &Family{ID: id, Name: l.Family, URL: "/family/" + id}
+			byID[id] = f
+			ids = append(ids, id)
+		}
+
+		f.Licenses = append(f.Licenses, l)
+	}
+
+	sort.Strings(ids)
+
+	families := []Family{}
+	for _, id := range ids {
+		families = append(families, *byID[id])
+	}
+
+	return families
+}
+
+// Versioned reports whether any license in the family has a version.
+func (f Family) Versioned() bool {
+	for _, l := range f.Licenses {
+		if l.Version != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Latest returns the newest version in the family that isn't deprecated. Of
+// several variants of the newest version, the first wins. Families without
+// versions have no latest.
+func (f Family) Latest() (LicenseData, bool) {
+	latest := LicenseData{}
+
+	for _, l := range f.Licenses {
+		if l.Version == "" || l.Deprecated {
+			continue
+		}
+
+		if latest.Version == "" || CompareVersions(l.Version, latest.Version) > 0 {
+			latest = l
+		}
+	}
+
+	return latest, latest.Version != ""
+}
+
+// CompareVersions compares dotted versions like "2.0" and "2.1" part by part,
+// numerically where both parts are numbers, returning -1, 0, or 1.
+func CompareVersions(a string, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		// missing parts count as 0, so "2" == "2.0"
+		ap, bp := "0", "0"
+		if i < len(as) {
+			ap = as[i]
+		}
+		if i < len(bs) {
+			bp = bs[i]
+		}
+
+		an, aerr := strconv.Atoi(ap)
+		bn, berr := strconv.Atoi(bp)
+
+		var c int
+		if aerr == nil && berr == nil {
+			c = an - bn
+		} else {
+			c = strings.Compare(ap, bp)
+		}
+
+		if c < 0 {
+			return -1
+		} else if c > 0 {
+			return 1
+		}
+	}
+
+	return 0
+}
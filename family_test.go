@@ -0,0 +1,49 @@
+package ynal
+
+import (
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tt := []struct {
+		a, b     string
+		expected int
+	}{
+		{"2.0", "3.0", -1},
+		{"10.0", "9.0", 1},
+		{"2", "2.0", 0},
+		{"1.1", "1.0.9", 1},
+		{"1.0a", "1.0b", -1},
+	}
+
+	for _, tc := range tt {
+		if got := CompareVersions(tc.a, tc.b); got != tc.expected {
+			t.Errorf("CompareVersions(%q, %q): expected %d, got %d", tc.a, tc.b, tc.expected, got)
+		}
+	}
+}
+
+func TestFamilies(t *testing.T) {
+	gpl2 := NewLicense("GPL_2", "gpl 2\n")
+	gpl2.Family = "GPL"
+
+	gpl3 := NewLicense("GPL_3", "gpl 3\n")
+	gpl3.Family = "GPL"
+
+	bsd := NewLicense("BSD_3", "bsd\n")
+	bsd.Family = "BSD"
+
+	families := Families([]LicenseData{gpl2, NewLicense("MIT", "mit\n"), bsd, gpl3})
+
+	if len(families) != 2 {
+		t.Fatalf("expected two families, got %+v", families)
+	}
+
+	if f := families[0]; f.ID != "bsd" || f.URL != "/family/bsd" || len(f.Licenses) != 1 {
+		t.Fatalf("unexpected family: %+v", f)
+	}
+
+	if f := families[1]; f.ID != "gpl" || f.Name != "GPL" || len(f.Licenses) != 2 || f.Licenses[0].ID != "gpl_2" {
+		t.Fatalf("unexpected family: %+v", f)
+	}
+}
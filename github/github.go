@@ -0,0 +1,202 @@
+// Package github looks up the licenses GitHub detects for repositories, so
+// ynal can send someone from a repository straight to its license.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultURL is the public GitHub API.
+const DefaultURL = "https://api.github.com"
+
+// DefaultTTL is how long a Client remembers a repository's license by
+// default.
+const DefaultTTL = time.Hour
+
+// maxCached is how many repositories a Client remembers before starting over.
+const maxCached = 10000
+
+var (
+	// ErrInvalidRepo is the error for a repository that isn't owner/name.
+	ErrInvalidRepo = errors.New("not a repository, expected owner/name")
+
+	// ErrNotFound is the error for a repository that doesn't exist, or that
+	// GitHub didn't find a license in.
+	ErrNotFound = errors.New("no license found")
+
+	// ErrUnrecognized is the error for a license GitHub found but couldn't
+	// match to an SPDX ID.
+	ErrUnrecognized = errors.New("license isn't one GitHub recognizes")
+
+	// ErrRateLimited is the error for a lookup GitHub refused because too
+	// many have been made.
+	ErrRateLimited = errors.New("rate limited by GitHub")
+)
+
+// repoPattern is what GitHub allows in owner and repository names.
+var repoPattern = regexp.MustCompile(`^[A-Za-z0-9-]+/[A-Za-z0-9._-]+$`)
+
+// Detector looks up the license of a repository.
+type Detector interface {
+	// Detect returns the SPDX ID of the license of repo, given as
+	// owner/name.
+	Detect(ctx context.Context, repo string) (string, error)
+}
+
+// ParseRepo returns the owner/name of a repository given either that way or
+// as its github.com URL.
+func ParseRepo(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	for _, prefix := range []string{"https://", "http://", "github.com/", "www.github.com/"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+
+	if !repoPattern.MatchString(s) {
+		return "", ErrInvalidRepo
+	}
+
+	return s, nil
+}
+
+// Client detects licenses with the GitHub API. Anonymous lookups are limited
+// to 60 an hour, so it remembers what it found, including what it didn't,
+// for TTL.
+type Client struct {
+	// URL is where the API is. Defaults to DefaultURL.
+	URL string
+
+	// Token authenticates requests when set, which GitHub allows far more
+	// of.
+	Token string
+
+	// TTL is how long a lookup is remembered. Defaults to DefaultTTL.
+	TTL time.Duration
+
+	// Client is used for every request. Defaults to a client with a
+	// reasonable timeout.
+	Client *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+type cached struct {
+	license string
+	err     error
+	expires time.Time
+}
+
+func (c *Client) url() string {
+	if c.URL != "" {
+		return strings.TrimSuffix(c.URL, "/")
+	}
+
+	return DefaultURL
+}
+
+func (c *Client) ttl() time.Duration {
+	if c.TTL > 0 {
+		return c.TTL
+	}
+
+	return DefaultTTL
+}
+
+func (c *Client) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+type repoLicense struct {
+	License struct {
+		SPDXID string `json:"spdx_id"`
+	} `json:"license"`
+}
+
+// Detect returns the SPDX ID of the license GitHub detected in repo.
+// Repositories without one are an ErrNotFound, and ones whose license GitHub
+// couldn't identify an ErrUnrecognized, both of which are remembered like
+// licenses are.
+func (c *Client) Detect(ctx context.Context, repo string) (string, error) {
+	repo, err := ParseRepo(repo)
+	if err != nil {
+		return "", err
+	}
+
+	key := strings.ToLower(repo)
+
+	c.mu.Lock()
+	hit, ok := c.cache[key]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(hit.expires) {
+		return hit.license, hit.err
+	}
+
+	license, err := c.fetch(ctx, repo)
+	if err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrUnrecognized) {
+		return "", err
+	}
+
+	c.mu.Lock()
+	if c.cache == nil || len(c.cache) >= maxCached {
+		c.cache = map[string]cached{}
+	}
+	c.cache[key] = cached{license: license, err: err, expires: time.Now().Add(c.ttl())}
+	c.mu.Unlock()
+
+	return license, err
+}
+
+func (c *Client) fetch(ctx context.Context, repo string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.url()+"/repos/"+repo+"/license", nil)
+	if err != nil {
+		return "", fmt.Errorf("could not build request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch license of %s: %w", repo, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%s: %w", repo, ErrNotFound)
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		return "", ErrRateLimited
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("could not fetch license of %s: %s", repo, resp.Status)
+	}
+
+	var l repoLicense
+	if err := json.NewDecoder(resp.Body).Decode(&l); err != nil {
+		return "", fmt.Errorf("could not decode license of %s: %w", repo, err)
+	}
+
+	if l.License.SPDXID == "" || l.License.SPDXID == "NOASSERTION" {
+		return "", fmt.Errorf("%s: %w", repo, ErrUnrecognized)
+	}
+
+	return l.License.SPDXID, nil
+}
@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseRepo(t *testing.T) {
+	tt := []struct {
+		in       string
+		expected string
+		err      error
+	}{
+		{in: "packrat386/ynal", expected: "packrat386/ynal"},
+		{in: "https://github.com/packrat386/ynal", expected: "packrat386/ynal"},
+		{in: "github.com/packrat386/ynal.git", expected: "packrat386/ynal"},
+		{in: "https://github.com/packrat386/ynal/", expected: "packrat386/ynal"},
+		{in: "packrat386", err: ErrInvalidRepo},
+		{in: "packrat386/ynal/tree/main", err: ErrInvalidRepo},
+		{in: "../etc/passwd", err: ErrInvalidRepo},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseRepo(tc.in)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	fetches := atomic.Int32{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+
+		if got := r.Header.Get("Authorization"); got != "Bearer hunter2" {
+			t.Errorf("expected token, got %q", got)
+		}
+
+		switch r.URL.Path {
+		case "/repos/packrat386/ynal/license":
+			w.Write([]byte(`{"name": "LICENSE", "license": {"key": "mit", "spdx_id": "MIT"}}`))
+		case "/repos/someone/custom/license":
+			w.Write([]byte(`{"name": "LICENSE", "license": {"key": "other", "spdx_id": "NOASSERTION"}}`))
+		case "/repos/someone/busy/license":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{URL: srv.URL, Token: "hunter2"}
+
+	tt := []struct {
+		repo     string
+		expected string
+		err      error
+	}{
+		{repo: "packrat386/ynal", expected: "MIT"},
+		{repo: "someone/custom", err: ErrUnrecognized},
+		{repo: "someone/nothing", err: ErrNotFound},
+		{repo: "someone/busy", err: ErrRateLimited},
+		{repo: "nope", err: ErrInvalidRepo},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.repo, func(t *testing.T) {
+			got, err := c.Detect(context.Background(), tc.repo)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	before := fetches.Load()
+
+	// found and missing licenses are remembered, rate limits aren't
+	c.Detect(context.Background(), "Packrat386/YNAL")
+	c.Detect(context.Background(), "someone/nothing")
+	c.Detect(context.Background(), "someone/busy")
+
+	if got := fetches.Load() - before; got != 1 {
+		t.Errorf("expected 1 fetch, got %d", got)
+	}
+}
@@ -0,0 +1,82 @@
+package ynal
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// How a license's text can be laid out in HTML. See LicenseData.Layout.
+const (
+	// LayoutPreserve shows the text as is, with its original line breaks.
+	LayoutPreserve = "preserve"
+
+	// LayoutReflow joins the lines of each paragraph so they wrap to fit
+	// the page.
+	LayoutReflow = "reflow"
+)
+
+// Layouts are every valid layout, the default first.
+var Layouts = []string{LayoutPreserve, LayoutReflow}
+
+// Paragraphs reflows text into paragraphs: runs of lines separated by blank
+// lines, with each one's lines joined by spaces.
+func Paragraphs(text string) []string {
+	paragraphs := []string{}
+	lines := []string{}
+
+	flush := func() {
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, strings.Join(lines, " "))
+			lines = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			flush()
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+	flush()
+
+	return paragraphs
+}
+
+// Wrap rewraps each paragraph of text, as Paragraphs finds them, to lines of
+// at most width characters, with a blank line between paragraphs. Lines only
+// break between words, so a word longer than width gets a line to itself.
+// Indentation and line breaks within paragraphs are lost.
+func Wrap(text string, width int) string {
+	buf := new(strings.Builder)
+
+	for i, p := range Paragraphs(text) {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+
+		line := 0
+		for _, w := range strings.Fields(p) {
+			n := utf8.RuneCountInString(w)
+
+			switch {
+			case line == 0:
+			case line+1+n > width:
+				buf.WriteString("\n")
+				line = 0
+			default:
+				buf.WriteString(" ")
+				line++
+			}
+
+			buf.WriteString(w)
+			line += n
+		}
+
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,86 @@
+package ynal
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParagraphs(t *testing.T) {
+	tt := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "wrapped lines",
+			text:     "  The quick brown\nfox jumps over\n   the lazy dog.\n",
+			expected: []string{"The quick brown fox jumps over the lazy dog."},
+		},
+		{
+			name:     "several paragraphs",
+			text:     "one\ntwo\n\n\n  three\r\n \nfour",
+			expected: []string{"one two", "three", "four"},
+		},
+		{
+			name:     "empty",
+			text:     "\n\n",
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Paragraphs(tc.text); !slices.Equal(got, tc.expected) {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tt := []struct {
+		name     string
+		text     string
+		width    int
+		expected string
+	}{
+		{
+			name:     "rewrapped",
+			text:     "The quick brown fox\njumps over the lazy dog.\n",
+			width:    10,
+			expected: "The quick\nbrown fox\njumps over\nthe lazy\ndog.\n",
+		},
+		{
+			name:     "several paragraphs",
+			text:     "one two\nthree\n\n\nfour five\n",
+			width:    80,
+			expected: "one two three\n\nfour five\n",
+		},
+		{
+			name:     "long words",
+			text:     "a supercalifragilistic b\n",
+			width:    5,
+			expected: "a\nsupercalifragilistic\nb\n",
+		},
+		{
+			name:     "counts characters",
+			text:     "née née\n",
+			width:    7,
+			expected: "née née\n",
+		},
+		{
+			name:     "empty",
+			text:     "\n\n",
+			width:    72,
+			expected: "",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Wrap(tc.text, tc.width); got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package ynal
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// Logo returns the embedded mark called name, like "gpl", as SVG.
+func Logo(name string) ([]byte, error) {
+	b, err := fs.ReadFile(Logos, "logos/"+name+".svg")
+	if err != nil {
+		return nil, fmt.Errorf("could not read logo %q: %w", name, err)
+	}
+
+	return b, nil
+}
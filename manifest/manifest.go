@@ -0,0 +1,250 @@
+// Package manifest reads the dependencies listed in package manifests and
+// lockfiles, and resolves the licenses they're declared under.
+//
+// Some lockfiles, like package-lock.json, record each package's license
+// themselves. The rest are looked up in a registry with a Resolver, like
+// DepsDev.
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// The ecosystems dependencies come from, named the way deps.dev names them.
+const (
+	EcosystemGo   = "go"
+	EcosystemNPM  = "npm"
+	EcosystemPyPI = "pypi"
+)
+
+// ErrUnsupported is returned for a manifest Parse doesn't know how to read.
+var ErrUnsupported = errors.New("unsupported manifest")
+
+// Dependency is a package a manifest depends on.
+type Dependency struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+
+	// Version is the exact version depended on. It's empty for a dependency
+	// that isn't pinned to one, like a bare name in requirements.txt.
+	Version string `json:"version,omitempty"`
+
+	// License is the SPDX expression the manifest itself declares for the
+	// dependency, if it declares one.
+	License string `json:"license,omitempty"`
+}
+
+// parsers are the manifests Parse reads, by file name.
+var parsers = map[string]func([]byte) ([]Dependency, error){
+	"go.mod":            parseGoMod,
+	"go.sum":            parseGoSum,
+	"package-lock.json": parsePackageLock,
+	"requirements.txt":  parseRequirements,
+}
+
+// Supported returns the file names of every manifest Parse reads, sorted.
+func Supported() []string {
+	names := []string{}
+	for name := range parsers {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Parse returns the dependencies listed in the manifest with the given file
+// name, in the order they're listed. Only the base of the name matters.
+func Parse(name string, data []byte) ([]Dependency, error) {
+	parse, ok := parsers[path.Base(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s (try one of %s)", ErrUnsupported, name, strings.Join(Supported(), ", "))
+	}
+
+	deps, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path.Base(name), err)
+	}
+
+	return deps, nil
+}
+
+// parseGoMod reads the require directives of a go.mod, both single lines and
+// blocks.
+func parseGoMod(data []byte) ([]Dependency, error) {
+	deps := []Dependency{}
+	inBlock := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case !inBlock && fields[0] == "require":
+			if len(fields) == 2 && fields[1] == "(" {
+				inBlock = true
+				continue
+			}
+
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: malformed require", n)
+		}
+
+		deps = append(deps, Dependency{Ecosystem: EcosystemGo, Name: strings.Trim(fields[0], `"`), Version: fields[1]})
+	}
+
+	return deps, scanner.Err()
+}
+
+// parseGoSum reads the modules checksummed in a go.sum. Modules listed only
+// for their go.mod weren't downloaded, so they're left out.
+func parseGoSum(data []byte) ([]Dependency, error) {
+	deps := []Dependency{}
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: malformed checksum", n)
+		}
+
+		if strings.HasSuffix(fields[1], "/go.mod") || seen[fields[0]+"@"+fields[1]] {
+			continue
+		}
+
+		seen[fields[0]+"@"+fields[1]] = true
+		deps = append(deps, Dependency{Ecosystem: EcosystemGo, Name: fields[0], Version: fields[1]})
+	}
+
+	return deps, scanner.Err()
+}
+
+type packageLock struct {
+	// Packages is how lockfile versions 2 and 3 list packages, keyed by
+	// their path under node_modules.
+	Packages map[string]lockedPackage `json:"packages"`
+
+	// Dependencies is how version 1 lists them, nested by name.
+	Dependencies map[string]lockedPackage `json:"dependencies"`
+}
+
+type lockedPackage struct {
+	Version      string                   `json:"version"`
+	License      string                   `json:"license"`
+	Link         bool                     `json:"link"`
+	Dependencies map[string]lockedPackage `json:"dependencies"`
+}
+
+// parsePackageLock reads every package installed by a package-lock.json,
+// sorted by name, along with the license npm recorded for it.
+func parsePackageLock(data []byte) ([]Dependency, error) {
+	var lock packageLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	deps := []Dependency{}
+	seen := map[string]bool{}
+
+	add := func(name string, p lockedPackage) {
+		if name == "" || p.Link || p.Version == "" || seen[name+"@"+p.Version] {
+			return
+		}
+
+		seen[name+"@"+p.Version] = true
+		deps = append(deps, Dependency{Ecosystem: EcosystemNPM, Name: name, Version: p.Version, License: p.License})
+	}
+
+	if lock.Packages != nil {
+		for key, p := range lock.Packages {
+			// nested packages are keyed by their whole path, like
+			// node_modules/a/node_modules/b, and the project itself and its
+			// workspaces aren't under node_modules at all
+			i := strings.LastIndex(key, "node_modules/")
+			if i == -1 {
+				continue
+			}
+
+			add(key[i+len("node_modules/"):], p)
+		}
+	} else {
+		var walk func(map[string]lockedPackage)
+		walk = func(ps map[string]lockedPackage) {
+			for name, p := range ps {
+				add(name, p)
+				walk(p.Dependencies)
+			}
+		}
+
+		walk(lock.Dependencies)
+	}
+
+	slices.SortFunc(deps, func(a, b Dependency) int {
+		return strings.Compare(a.Name+"@"+a.Version, b.Name+"@"+b.Version)
+	})
+
+	return deps, nil
+}
+
+// requirement matches a requirement's name, with any extras, and the version
+// it's pinned to with ==, if it is.
+var requirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(?:===?\s*([^\s;,]+))?`)
+
+// parseRequirements reads the packages in a pip requirements.txt. Options,
+// like -r and --hash, and anything that isn't a package name, like a URL, are
+// skipped.
+func parseRequirements(data []byte) ([]Dependency, error) {
+	deps := []Dependency{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), " #")
+		line = strings.TrimSpace(line)
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		m := requirement.FindStringSubmatch(line)
+		if m == nil || strings.Contains(line, "://") {
+			continue
+		}
+
+		deps = append(deps, Dependency{Ecosystem: EcosystemPyPI, Name: normalizePyPI(m[1]), Version: m[2]})
+	}
+
+	return deps, scanner.Err()
+}
+
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePyPI normalizes a Python package name the way PyPI does, so
+// Foo_Bar and foo-bar are the same package.
+func normalizePyPI(name string) string {
+	return pypiSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
@@ -0,0 +1,130 @@
+package manifest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		name     string
+		file     string
+		data     string
+		expected []Dependency
+	}{
+		{
+			name: "go.mod",
+			file: "go.mod",
+			data: `module example.com/widget
+
+go 1.25
+
+require github.com/BurntSushi/toml v1.6.0
+
+require (
+	golang.org/x/mod v0.38.0 // indirect
+	// a comment
+	"example.com/quoted" v1.0.0
+)
+
+replace (
+	example.com/old => example.com/new v1.0.0
+)
+`,
+			expected: []Dependency{
+				{Ecosystem: EcosystemGo, Name: "github.com/BurntSushi/toml", Version: "v1.6.0"},
+				{Ecosystem: EcosystemGo, Name: "golang.org/x/mod", Version: "v0.38.0"},
+				{Ecosystem: EcosystemGo, Name: "example.com/quoted", Version: "v1.0.0"},
+			},
+		},
+		{
+			name: "go.sum",
+			file: "path/to/go.sum",
+			data: `github.com/BurntSushi/toml v1.6.0 h1:abc=
+github.com/BurntSushi/toml v1.6.0/go.mod h1:def=
+golang.org/x/mod v0.37.0/go.mod h1:ghi=
+`,
+			expected: []Dependency{
+				{Ecosystem: EcosystemGo, Name: "github.com/BurntSushi/toml", Version: "v1.6.0"},
+			},
+		},
+		{
+			name: "package-lock.json",
+			file: "package-lock.json",
+			data: `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "widget", "version": "1.0.0"},
+    "node_modules/left-pad": {"version": "1.3.0", "license": "WTFPL"},
+    "node_modules/@scope/thing": {"version": "2.0.0"},
+    "node_modules/left-pad/node_modules/tiny": {"version": "0.1.0", "license": "MIT"},
+    "node_modules/local": {"resolved": "packages/local", "link": true}
+  }
+}`,
+			expected: []Dependency{
+				{Ecosystem: EcosystemNPM, Name: "@scope/thing", Version: "2.0.0"},
+				{Ecosystem: EcosystemNPM, Name: "left-pad", Version: "1.3.0", License: "WTFPL"},
+				{Ecosystem: EcosystemNPM, Name: "tiny", Version: "0.1.0", License: "MIT"},
+			},
+		},
+		{
+			name: "package-lock.json v1",
+			file: "package-lock.json",
+			data: `{
+  "lockfileVersion": 1,
+  "dependencies": {
+    "left-pad": {"version": "1.3.0", "dependencies": {"tiny": {"version": "0.1.0"}}}
+  }
+}`,
+			expected: []Dependency{
+				{Ecosystem: EcosystemNPM, Name: "left-pad", Version: "1.3.0"},
+				{Ecosystem: EcosystemNPM, Name: "tiny", Version: "0.1.0"},
+			},
+		},
+		{
+			name: "requirements.txt",
+			file: "requirements.txt",
+			data: `# pinned
+Django==5.0.1
+requests[socks] == 2.31.0 ; python_version >= "3.8"
+Foo_Bar>=1.0  # not pinned
+-r other.txt
+--hash=sha256:abc
+https://example.com/pkg.tar.gz
+`,
+			expected: []Dependency{
+				{Ecosystem: EcosystemPyPI, Name: "django", Version: "5.0.1"},
+				{Ecosystem: EcosystemPyPI, Name: "requests", Version: "2.31.0"},
+				{Ecosystem: EcosystemPyPI, Name: "foo-bar"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Parse(tc.file, []byte(tc.data))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse("Gemfile.lock", []byte("GEM\n")); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+
+	if _, err := Parse("package-lock.json", []byte("{")); err == nil {
+		t.Errorf("expected an error for a malformed lockfile")
+	}
+
+	if _, err := Parse("go.mod", []byte("require example.com/widget\n")); err == nil {
+		t.Errorf("expected an error for a require without a version")
+	}
+}
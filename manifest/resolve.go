@@ -0,0 +1,202 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDepsDevURL is the public deps.dev API.
+const DefaultDepsDevURL = "https://api.deps.dev"
+
+// lookups is how many dependencies are resolved at once.
+const lookups = 8
+
+// maxCached is how many resolved licenses a DepsDev keeps before starting
+// over.
+const maxCached = 10000
+
+// errNotPinned is the error for a dependency without an exact version, which
+// no registry can say the license of.
+var errNotPinned = errors.New("not pinned to a version")
+
+// Resolver looks up the license a registry declares for a dependency.
+type Resolver interface {
+	// Resolve returns d's license as an SPDX expression.
+	Resolve(ctx context.Context, d Dependency) (string, error)
+}
+
+// Result is a dependency along with why its license couldn't be resolved, if
+// it couldn't.
+type Result struct {
+	Dependency
+	Error string `json:"error,omitempty"`
+}
+
+// Resolve fills in the license of every dependency that doesn't declare one
+// with r, a few at a time, keeping their order. r may be nil to only use
+// the licenses manifests declare. A dependency that can't be resolved is left
+// without a license and with the reason in its Error.
+func Resolve(ctx context.Context, deps []Dependency, r Resolver) []Result {
+	results := make([]Result, len(deps))
+	sem := make(chan struct{}, lookups)
+	wg := sync.WaitGroup{}
+
+	for i, d := range deps {
+		results[i] = Result{Dependency: d}
+
+		if d.License != "" {
+			continue
+		}
+
+		if r == nil {
+			results[i].Error = "no registry to look up licenses in"
+			continue
+		}
+
+		if d.Version == "" {
+			results[i].Error = errNotPinned.Error()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			license, err := r.Resolve(ctx, d)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+
+			results[i].License = license
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// DepsDev resolves licenses with the deps.dev API, which knows the licenses
+// of packages in every ecosystem Parse reads. Licenses never change for a
+// version, so they're cached.
+type DepsDev struct {
+	// URL is where the API is. Defaults to DefaultDepsDevURL.
+	URL string
+
+	// Client is used for every request. Defaults to a client with a
+	// reasonable timeout.
+	Client *http.Client
+
+	mu    sync.Mutex
+	cache map[Dependency]string
+}
+
+func (d *DepsDev) url() string {
+	if d.URL != "" {
+		return strings.TrimSuffix(d.URL, "/")
+	}
+
+	return DefaultDepsDevURL
+}
+
+func (d *DepsDev) client() *http.Client {
+	if d.Client != nil {
+		return d.Client
+	}
+
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+type depsDevVersion struct {
+	Licenses []string `json:"licenses"`
+}
+
+// Resolve looks up the licenses deps.dev has for dep, joined with AND if
+// there's more than one.
+func (d *DepsDev) Resolve(ctx context.Context, dep Dependency) (string, error) {
+	if dep.Version == "" {
+		return "", errNotPinned
+	}
+
+	d.mu.Lock()
+	license, ok := d.cache[dep]
+	d.mu.Unlock()
+
+	if ok {
+		return license, nil
+	}
+
+	u := fmt.Sprintf("%s/v3/systems/%s/packages/%s/versions/%s", d.url(), dep.Ecosystem, url.PathEscape(dep.Name), url.PathEscape(dep.Version))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return "", fmt.Errorf("could not build request: %w", err)
+	}
+
+	resp, err := d.client().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch %s@%s: %w", dep.Name, dep.Version, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%s@%s isn't in the registry", dep.Name, dep.Version)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("could not fetch %s@%s: %s", dep.Name, dep.Version, resp.Status)
+	}
+
+	var v depsDevVersion
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return "", fmt.Errorf("could not decode %s@%s: %w", dep.Name, dep.Version, err)
+	}
+
+	license, err = joinLicenses(v.Licenses)
+	if err != nil {
+		return "", fmt.Errorf("%s@%s %w", dep.Name, dep.Version, err)
+	}
+
+	d.mu.Lock()
+	if d.cache == nil || len(d.cache) >= maxCached {
+		d.cache = map[Dependency]string{}
+	}
+	d.cache[dep] = license
+	d.mu.Unlock()
+
+	return license, nil
+}
+
+// joinLicenses joins the licenses deps.dev lists for a package into one
+// expression. It calls a license it can't map to SPDX "non-standard".
+func joinLicenses(licenses []string) (string, error) {
+	if len(licenses) == 0 {
+		return "", errors.New("doesn't declare a license")
+	}
+
+	parts := []string{}
+	for _, l := range licenses {
+		if l == "non-standard" {
+			return "", errors.New("declares a license that isn't an SPDX expression")
+		}
+
+		if len(licenses) > 1 && strings.Contains(l, " ") {
+			l = "(" + l + ")"
+		}
+
+		parts = append(parts, l)
+	}
+
+	return strings.Join(parts, " AND "), nil
+}
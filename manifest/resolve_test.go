@@ -0,0 +1,90 @@
+package manifest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDepsDev(t *testing.T) {
+	fetches := atomic.Int32{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+
+		switch r.URL.EscapedPath() {
+		case "/v3/systems/go/packages/github.com%2FBurntSushi%2Ftoml/versions/v1.6.0":
+			w.Write([]byte(`{"licenses": ["MIT"]}`))
+		case "/v3/systems/npm/packages/@scope%2Fthing/versions/2.0.0":
+			w.Write([]byte(`{"licenses": ["MIT OR Apache-2.0", "BSD-3-Clause"]}`))
+		case "/v3/systems/pypi/packages/odd/versions/1.0":
+			w.Write([]byte(`{"licenses": ["non-standard"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	d := &DepsDev{URL: srv.URL}
+
+	deps := []Dependency{
+		{Ecosystem: EcosystemGo, Name: "github.com/BurntSushi/toml", Version: "v1.6.0"},
+		{Ecosystem: EcosystemNPM, Name: "@scope/thing", Version: "2.0.0"},
+		{Ecosystem: EcosystemNPM, Name: "left-pad", Version: "1.3.0", License: "WTFPL"},
+		{Ecosystem: EcosystemPyPI, Name: "odd", Version: "1.0"},
+		{Ecosystem: EcosystemPyPI, Name: "missing", Version: "1.0"},
+		{Ecosystem: EcosystemPyPI, Name: "unpinned"},
+	}
+
+	results := Resolve(context.Background(), deps, d)
+
+	expected := []struct {
+		license string
+		failed  bool
+	}{
+		{license: "MIT"},
+		{license: "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+		{license: "WTFPL"},
+		{failed: true},
+		{failed: true},
+		{failed: true},
+	}
+
+	for i, r := range results {
+		if r.Name != deps[i].Name {
+			t.Fatalf("expected results in order, got %s at %d", r.Name, i)
+		}
+
+		if r.License != expected[i].license || (r.Error != "") != expected[i].failed {
+			t.Errorf("%s: expected license %q (failed: %t), got %q (error: %q)", r.Name, expected[i].license, expected[i].failed, r.License, r.Error)
+		}
+	}
+
+	// the declared license and the unpinned dependency aren't looked up
+	if got := fetches.Load(); got != 4 {
+		t.Errorf("expected 4 lookups, got %d", got)
+	}
+
+	Resolve(context.Background(), deps[:2], d)
+
+	if got := fetches.Load(); got != 4 {
+		t.Errorf("expected resolved licenses to be cached, got %d lookups", got)
+	}
+}
+
+func TestResolveWithoutRegistry(t *testing.T) {
+	results := Resolve(context.Background(), []Dependency{
+		{Ecosystem: EcosystemNPM, Name: "left-pad", Version: "1.3.0", License: "WTFPL"},
+		{Ecosystem: EcosystemGo, Name: "example.com/widget", Version: "v1.0.0"},
+	}, nil)
+
+	if results[0].License != "WTFPL" || results[0].Error != "" {
+		t.Errorf("expected the declared license, got %+v", results[0])
+	}
+
+	if results[1].License != "" || results[1].Error == "" {
+		t.Errorf("expected an error without a registry, got %+v", results[1])
+	}
+}
@@ -0,0 +1,103 @@
+package ynal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// Metadata is what's known about a license beyond its text. Stores that keep
+// licenses as files keep it next to each one as <title>.json; every field is
+// optional.
+type Metadata struct {
+	// Family groups related licenses, like every version of the GPL.
+	Family string `json:"family,omitempty"`
+
+	// Version orders the licenses in a family, like "2.0" and "3.0".
+	Version string `json:"version,omitempty"`
+
+	// Deprecated marks a license that shouldn't be used for new projects.
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	// Successor is the ID (or title) of the license to use instead of a
+	// deprecated one.
+	Successor string `json:"successor,omitempty"`
+
+	// SPDX is the license's SPDX identifier, like "Apache-2.0".
+	SPDX string `json:"spdx,omitempty"`
+
+	// Header is the notice to put at the top of each source file, which may
+	// use the same placeholders as license texts.
+	Header string `json:"header,omitempty"`
+
+	// Layout is how the text reads best in HTML: "preserve" its line breaks
+	// or "reflow" it into paragraphs.
+	Layout string `json:"layout,omitempty"`
+
+	// Deed is a plain-language summary of the license, for licenses like
+	// Creative Commons ones that come with both.
+	Deed string `json:"deed,omitempty"`
+
+	// Summary is a one-line tl;dr of the license, like "do what you want,
+	// keep the notice".
+	Summary string `json:"summary,omitempty"`
+
+	// Tags are categories like "copyleft" and "permissive". See Tags.
+	Tags []string `json:"tags,omitempty"`
+
+	// Obligations are what the license asks of its users, like
+	// "include-copyright". See Obligations.
+	Obligations []string `json:"obligations,omitempty"`
+
+	// Template is the HTML template to render the license's page with
+	// instead of license.html.tmpl, like "license_cc.html.tmpl".
+	Template string `json:"template,omitempty"`
+
+	// Logo is the name of an embedded mark to show with the license, like
+	// "gpl". See Logos.
+	Logo string `json:"logo,omitempty"`
+}
+
+// Apply copies m onto l.
+func (m Metadata) Apply(l *LicenseData) {
+	l.Family = m.Family
+	l.Version = m.Version
+	l.Deprecated = m.Deprecated
+	l.Successor = strings.ToLower(m.Successor)
+	l.SPDX = m.SPDX
+	l.Header = m.Header
+	l.Layout = m.Layout
+	l.Deed = m.Deed
+	l.Summary = m.Summary
+	l.Tags = m.Tags
+	l.Obligations = m.Obligations
+	l.Template = m.Template
+	l.Logo = m.Logo
+}
+
+// MetadataPath returns the path of the metadata for the license at lpath.
+func MetadataPath(lpath string) string {
+	return strings.TrimSuffix(lpath, ".txt") + ".json"
+}
+
+// readMetadata reads the metadata for the license at lpath. A license without
+// any has the zero Metadata.
+func readMetadata(fsys fs.FS, lpath string) (Metadata, error) {
+	m := Metadata{}
+
+	b, err := fs.ReadFile(fsys, MetadataPath(lpath))
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, fmt.Errorf("could not read metadata: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &m); err != nil {
+		return m, fmt.Errorf("could not parse metadata for %s: %w", lpath, err)
+	}
+
+	return m, nil
+}
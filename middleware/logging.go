@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Entry is everything Logging records about a request.
+type Entry struct {
+	Time      time.Time
+	Remote    string
+	Method    string
+	URI       string
+	Proto     string
+	Status    int
+	Bytes     int64
+	Duration  time.Duration
+	Referer   string
+	UserAgent string
+
+	// RequestID is the ID RequestID gave the request, if it went through
+	// RequestID first.
+	RequestID string
+}
+
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	code  int
+	bytes int64
+}
+
+func (l *loggingResponseWriter) WriteHeader(code int) {
+	l.code = code
+	l.ResponseWriter.WriteHeader(code)
+}
+
+func (l *loggingResponseWriter) Write(b []byte) (int, error) {
+	n, err := l.ResponseWriter.Write(b)
+	l.bytes += int64(n)
+
+	return n, err
+}
+
+// Flush passes through to the underlying writer, so streamed responses
+// aren't held up by the wrapper.
+func (l *loggingResponseWriter) Flush() {
+	if f, ok := l.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// ReadFrom lets the underlying writer use sendfile and friends when it can,
+// which http.ServeContent relies on for large files.
+func (l *loggingResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	var n int64
+	var err error
+
+	if rf, ok := l.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		// hide our own ReadFrom from io.Copy, or it'd call straight back
+		n, err = io.Copy(struct{ io.Writer }{l.ResponseWriter}, src)
+	}
+
+	l.bytes += n
+
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (l *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return l.ResponseWriter
+}
+
+// remoteHost is the client's IP, without the port if there is one.
+func remoteHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+
+	return r.RemoteAddr
+}
+
+// Logging records an Entry for every request once it's been served, written
+// to the logger as a line of text or handed to the function set with
+// WithLogFunc.
+func Logging(next http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
+	log := o.logFunc
+	if log == nil {
+		log = func(e Entry) {
+			o.logger.Printf("%s %s [%d] %s %dB %s", e.Remote, e.Method, e.Status, e.URI, e.Bytes, e.Duration.Round(time.Microsecond))
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		lrw := &loggingResponseWriter{ResponseWriter: w, code: 200}
+
+		next.ServeHTTP(lrw, r)
+
+		log(Entry{
+			Time:      start,
+			Remote:    remoteHost(r),
+			Method:    r.Method,
+			URI:       r.URL.String(),
+			Proto:     r.Proto,
+			Status:    lrw.code,
+			Bytes:     lrw.bytes,
+			Duration:  time.Since(start),
+			Referer:   r.Referer(),
+			UserAgent: r.UserAgent(),
+			RequestID: RequestIDFrom(r.Context()),
+		})
+	})
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogging(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("hello"))
+	})
+
+	t.Run("entry", func(t *testing.T) {
+		var e Entry
+		wrapped := Wrap(h, WithLogFunc(func(got Entry) { e = got }))
+
+		r := httptest.NewRequest("GET", "/mit?x=1", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		r.Header.Set("User-Agent", "curl/8.0")
+		r.Header.Set(DefaultRequestIDHeader, "abc")
+
+		wrapped.ServeHTTP(httptest.NewRecorder(), r)
+
+		if e.Remote != "192.0.2.1" || e.Method != "GET" || e.URI != "/mit?x=1" || e.Status != http.StatusTeapot || e.Bytes != 5 || e.UserAgent != "curl/8.0" || e.RequestID != "abc" || e.Duration < 0 {
+			t.Fatalf("unexpected entry: %+v", e)
+		}
+	})
+
+	t.Run("logger", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		wrapped := Logging(h, WithLogger(log.New(buf, "", 0)))
+
+		r := httptest.NewRequest("GET", "/mit", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+
+		wrapped.ServeHTTP(httptest.NewRecorder(), r)
+
+		if !strings.HasPrefix(buf.String(), "192.0.2.1 GET [418] /mit 5B ") {
+			t.Fatalf("unexpected log %q", buf.String())
+		}
+	})
+}
@@ -0,0 +1,83 @@
+// Package middleware is the instrumentation ynal wraps its handlers in, for
+// services that embed ynalhttp and want the same: request IDs, access logs,
+// and recovery from panics.
+package middleware
+
+import (
+	"log"
+	"net/http"
+)
+
+// Option configures a middleware. Each middleware reads the options that
+// apply to it and ignores the rest, so one set can be passed to all of them.
+type Option func(*options)
+
+type options struct {
+	logger    *log.Logger
+	logFunc   func(Entry)
+	onPanic   http.Handler
+	idHeader  string
+	trustedID bool
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		logger:    log.Default(),
+		idHeader:  DefaultRequestIDHeader,
+		trustedID: true,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithLogger sets where Logging and Recovery write to. The default is the
+// standard logger.
+func WithLogger(l *log.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithLogFunc has Logging hand each Entry to f instead of writing it to the
+// logger, for access logs in some other format.
+func WithLogFunc(f func(Entry)) Option {
+	return func(o *options) {
+		o.logFunc = f
+	}
+}
+
+// WithPanicHandler sets what Recovery responds with after a panic, if the
+// response hasn't started. The default is a plain 500.
+func WithPanicHandler(h http.Handler) Option {
+	return func(o *options) {
+		o.onPanic = h
+	}
+}
+
+// WithRequestIDHeader sets the header RequestID reads and writes request IDs
+// in. The default is DefaultRequestIDHeader.
+func WithRequestIDHeader(name string) Option {
+	return func(o *options) {
+		o.idHeader = name
+	}
+}
+
+// WithTrustedRequestIDs sets whether RequestID keeps a request ID the client
+// sent, as it does by default, or always makes a new one. Turn it off if
+// clients can't be trusted to send unique IDs.
+func WithTrustedRequestIDs(trusted bool) Option {
+	return func(o *options) {
+		o.trustedID = trusted
+	}
+}
+
+// Wrap wraps next in all of the middleware in this package, in the order
+// they work best in: each request gets an ID, is logged with it, and a panic
+// is logged as a 500.
+func Wrap(next http.Handler, opts ...Option) http.Handler {
+	return RequestID(Logging(Recovery(next, opts...), opts...), opts...)
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"runtime/debug"
+)
+
+type recoveryResponseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (rw *recoveryResponseWriter) WriteHeader(code int) {
+	rw.wroteHeader = true
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recoveryResponseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rw *recoveryResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Recovery turns a panicking handler into a logged stack trace and a 500,
+// rather than a dropped connection with nothing in the logs. The 500 is what
+// WithPanicHandler sets.
+func Recovery(next http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recoveryResponseWriter{ResponseWriter: w}
+
+		defer func() {
+			err := recover()
+			if err == nil {
+				return
+			}
+
+			// net/http uses this to abort a response on purpose
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+
+			if id := RequestIDFrom(r.Context()); id != "" {
+				o.logger.Printf("panic serving %s %s (request %s): %v\n%s", r.Method, r.URL.String(), id, err, debug.Stack())
+			} else {
+				o.logger.Printf("panic serving %s %s: %v\n%s", r.Method, r.URL.String(), err, debug.Stack())
+			}
+
+			// too late to change the status if the response has started
+			if rw.wroteHeader {
+				return
+			}
+
+			if o.onPanic != nil {
+				o.onPanic.ServeHTTP(w, r)
+				return
+			}
+
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
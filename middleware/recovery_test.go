@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecovery(t *testing.T) {
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("oh no")
+	})
+
+	t.Run("default", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		h := RequestID(Recovery(panicking, WithLogger(log.New(buf, "", 0))))
+
+		r := httptest.NewRequest("GET", "/mit", nil)
+		r.Header.Set(DefaultRequestIDHeader, "abc")
+
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("expected 500, got %d", w.Code)
+		}
+
+		if !strings.HasPrefix(buf.String(), "panic serving GET /mit (request abc): oh no\n") {
+			t.Errorf("unexpected log %q", buf.String())
+		}
+	})
+
+	t.Run("panic handler", func(t *testing.T) {
+		h := Recovery(panicking, WithLogger(log.New(new(bytes.Buffer), "", 0)), WithPanicHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})))
+
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != http.StatusTeapot {
+			t.Fatalf("expected 418, got %d", w.Code)
+		}
+	})
+
+	t.Run("response started", func(t *testing.T) {
+		h := Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("partial"))
+			panic("oh no")
+		}), WithLogger(log.New(new(bytes.Buffer), "", 0)))
+
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+		if w.Code != http.StatusOK || w.Body.String() != "partial" {
+			t.Fatalf("expected the partial response alone, got %d %q", w.Code, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// DefaultRequestIDHeader is the header request IDs are read from and written
+// to by default.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// maxRequestID is the longest request ID a client can send that's kept.
+const maxRequestID = 128
+
+type requestIDKey struct{}
+
+// RequestID gives every request an ID, the one the client sent in the
+// request ID header if it's reasonable and otherwise a random one. The ID is
+// set on the response's request ID header and in the request's context for
+// RequestIDFrom.
+func RequestID(next http.Handler, opts ...Option) http.Handler {
+	o := newOptions(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(o.idHeader)
+		if !o.trustedID || !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(o.idHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestIDFrom returns the ID RequestID gave the request ctx is from, or ""
+// if it didn't go through RequestID.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID reports whether id is short and printable ASCII, so it's
+// safe to log and echo back.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
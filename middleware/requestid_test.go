@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	tt := []struct {
+		name     string
+		sent     string
+		opts     []Option
+		header   string
+		expected string
+	}{
+		{
+			name:   "generated",
+			header: DefaultRequestIDHeader,
+		},
+		{
+			name:     "kept",
+			sent:     "abc-123",
+			header:   DefaultRequestIDHeader,
+			expected: "abc-123",
+		},
+		{
+			name:   "too long",
+			sent:   strings.Repeat("a", maxRequestID+1),
+			header: DefaultRequestIDHeader,
+		},
+		{
+			name:   "unprintable",
+			sent:   "abc 123",
+			header: DefaultRequestIDHeader,
+		},
+		{
+			name:   "untrusted",
+			sent:   "abc-123",
+			opts:   []Option{WithTrustedRequestIDs(false)},
+			header: DefaultRequestIDHeader,
+		},
+		{
+			name:     "other header",
+			sent:     "abc-123",
+			opts:     []Option{WithRequestIDHeader("X-Correlation-Id")},
+			header:   "X-Correlation-Id",
+			expected: "abc-123",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var seen string
+			h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = RequestIDFrom(r.Context())
+			}), tc.opts...)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			if tc.sent != "" {
+				r.Header.Set(tc.header, tc.sent)
+			}
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			got := w.Header().Get(tc.header)
+			if got != seen {
+				t.Errorf("expected the handler to see %q, saw %q", got, seen)
+			}
+
+			if tc.expected != "" && got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+
+			if tc.expected == "" && (len(got) != 32 || got == tc.sent) {
+				t.Errorf("expected a new ID, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+// Package negotiate picks which of the media types a server can respond with
+// suits a request's Accept header best, following RFC 9110.
+package negotiate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrNotAcceptable is the error for an Accept header that none of the
+// offered media types satisfy.
+var ErrNotAcceptable = errors.New("no offered media type is acceptable")
+
+// ErrMalformed is the error for an Accept header ParseStrict can't read.
+var ErrMalformed = errors.New("malformed Accept header")
+
+// Range is one media range from an Accept header, like text/html or text/*.
+type Range struct {
+	// Type and Subtype are lowercase, and either can be "*".
+	Type    string
+	Subtype string
+
+	// Params are the range's media type parameters, keyed by lowercase name.
+	// Extension parameters after q aren't included.
+	Params map[string]string
+
+	// Q is the range's weight, from 0 to 1. A range with Q 0 is one the
+	// client refuses.
+	Q float64
+}
+
+// Matches reports whether the media type t is in r. Parameters of r must be
+// the same in t, except ones t doesn't have at all; parameter values are
+// compared case-insensitively.
+func (r Range) Matches(t Range) bool {
+	if r.Type != "*" && r.Type != t.Type {
+		return false
+	}
+
+	if r.Subtype != "*" && r.Subtype != t.Subtype {
+		return false
+	}
+
+	for k, v := range r.Params {
+		if tv, ok := t.Params[k]; ok && !strings.EqualFold(v, tv) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// specificity ranks how specific r is, so that text/html;level=1 beats
+// text/html, which beats text/*, which beats */*.
+func (r Range) specificity() int {
+	switch {
+	case r.Type == "*":
+		return 0
+	case r.Subtype == "*":
+		return 1
+	default:
+		return 2 + len(r.Params)
+	}
+}
+
+// Parse reads every well-formed media range in an Accept header, in the
+// order they're listed. Malformed ranges are skipped rather than failing the
+// whole header, since clients send all sorts, and weights outside 0 to 1 are
+// clamped to it.
+func Parse(header string) []Range {
+	ranges := []Range{}
+
+	for _, part := range split(header, ',') {
+		r, err := parseRange(part, true, false)
+		if err != nil {
+			continue
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges
+}
+
+// ParseStrict is Parse for clients that should know better: any malformed
+// range or weight outside 0 to 1 fails the whole header with an error
+// wrapping ErrMalformed. Empty ranges, like the one a trailing comma leaves,
+// are still allowed, as RFC 9110 says they must be.
+func ParseStrict(header string) ([]Range, error) {
+	ranges := []Range{}
+
+	for _, part := range split(header, ',') {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		r, err := parseRange(part, true, true)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges, nil
+}
+
+// ParseMediaType reads a single media type, like an offer to Negotiate. It
+// can't have wildcards or a q parameter.
+func ParseMediaType(s string) (Range, error) {
+	r, err := parseRange(s, false, true)
+	if err != nil {
+		return r, err
+	}
+
+	if r.Type == "*" || r.Subtype == "*" {
+		return r, fmt.Errorf("%q is a range, not a media type", s)
+	}
+
+	return r, nil
+}
+
+// parseRange reads one media range, or a media type unless accept is set.
+// Weights outside 0 to 1 are an error if strict is set, and clamped if not.
+func parseRange(s string, accept bool, strict bool) (Range, error) {
+	parts := split(s, ';')
+
+	r := Range{Params: map[string]string{}, Q: 1}
+
+	full := strings.ToLower(strings.TrimSpace(parts[0]))
+
+	// some clients send a bare * for */*
+	if full == "*" && accept {
+		full = "*/*"
+	}
+
+	typ, subtype, ok := strings.Cut(full, "/")
+	if !ok || !isToken(typ) || !isToken(subtype) || (typ == "*" && subtype != "*") {
+		return r, fmt.Errorf("invalid media type %q", s)
+	}
+
+	r.Type, r.Subtype = typ, subtype
+
+	for _, p := range parts[1:] {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(p, "=")
+		k = strings.ToLower(strings.TrimSpace(k))
+		if !ok || !isToken(k) {
+			return r, fmt.Errorf("invalid parameter %q", p)
+		}
+
+		v, err := unquote(strings.TrimSpace(v))
+		if err != nil {
+			return r, err
+		}
+
+		if k == "q" && accept {
+			q, err := strconv.ParseFloat(v, 64)
+			if err != nil || math.IsNaN(q) || (strict && (q < 0 || q > 1)) {
+				return r, fmt.Errorf("invalid weight %q", v)
+			}
+
+			r.Q = min(max(q, 0), 1)
+
+			// everything after q is an extension, not part of the range
+			break
+		}
+
+		if _, dup := r.Params[k]; dup {
+			return r, fmt.Errorf("duplicate parameter %q", k)
+		}
+
+		r.Params[k] = v
+	}
+
+	return r, nil
+}
+
+// Negotiate returns whichever of offered the Accept header prefers. Each
+// offer gets the weight of the most specific range that matches it, and the
+// heaviest wins; ties go to the offer matched by the range listed first, and
+// then to the offer listed first. A missing or unreadable header accepts
+// anything, so the first offer wins. It returns ErrNotAcceptable if the
+// header refuses every offer.
+func Negotiate(header string, offered []string) (string, error) {
+	return negotiate(Parse(header), offered)
+}
+
+// NegotiateStrict is Negotiate with the header read by ParseStrict, so a
+// malformed one is an error wrapping ErrMalformed instead of accepting
+// anything.
+func NegotiateStrict(header string, offered []string) (string, error) {
+	ranges, err := ParseStrict(header)
+	if err != nil {
+		return "", err
+	}
+
+	return negotiate(ranges, offered)
+}
+
+func negotiate(ranges []Range, offered []string) (string, error) {
+	offers := make([]Range, len(offered))
+	for i, o := range offered {
+		r, err := ParseMediaType(o)
+		if err != nil {
+			return "", fmt.Errorf("could not parse offer: %w", err)
+		}
+
+		offers[i] = r
+	}
+
+	if len(offers) == 0 {
+		return "", ErrNotAcceptable
+	}
+
+	if len(ranges) == 0 {
+		return offered[0], nil
+	}
+
+	best, bestQ, bestIndex := -1, 0.0, 0
+	for i, o := range offers {
+		match := bestMatch(ranges, o)
+		if match == -1 || ranges[match].Q == 0 {
+			continue
+		}
+
+		q := ranges[match].Q
+		if best == -1 || q > bestQ || (q == bestQ && match < bestIndex) {
+			best, bestQ, bestIndex = i, q, match
+		}
+	}
+
+	if best == -1 {
+		return "", ErrNotAcceptable
+	}
+
+	return offered[best], nil
+}
+
+// Refuses reports whether the Accept header refuses mediatype outright,
+// because the most specific range that matches it has a weight of 0. A
+// mediatype the header doesn't mention at all isn't refused.
+func Refuses(header string, mediatype string) bool {
+	t, err := ParseMediaType(mediatype)
+	if err != nil {
+		return false
+	}
+
+	ranges := Parse(header)
+	match := bestMatch(ranges, t)
+
+	return match != -1 && ranges[match].Q == 0
+}
+
+// bestMatch returns the index of the most specific range matching t, or -1 if
+// none do.
+func bestMatch(ranges []Range, t Range) int {
+	match := -1
+	for j, r := range ranges {
+		if r.Matches(t) && (match == -1 || r.specificity() > ranges[match].specificity()) {
+			match = j
+		}
+	}
+
+	return match
+}
+
+// split splits s on sep, except inside quoted strings.
+func split(s string, sep byte) []string {
+	parts := []string{}
+
+	quoted, escaped, start := false, false, 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// unquote returns the value of a parameter, which is a token or a quoted
+// string.
+func unquote(v string) (string, error) {
+	if !strings.HasPrefix(v, `"`) {
+		if !isToken(v) {
+			return "", fmt.Errorf("invalid parameter value %q", v)
+		}
+
+		return v, nil
+	}
+
+	if len(v) < 2 || !strings.HasSuffix(v, `"`) {
+		return "", fmt.Errorf("unterminated quoted string %s", v)
+	}
+
+	buf := new(strings.Builder)
+
+	inner := v[1 : len(v)-1]
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		if c == '"' {
+			return "", fmt.Errorf("invalid quoted string %s", v)
+		}
+
+		if c == '\\' {
+			i++
+			if i == len(inner) {
+				return "", fmt.Errorf("unterminated quoted string %s", v)
+			}
+
+			c = inner[i]
+		}
+
+		buf.WriteByte(c)
+	}
+
+	return buf.String(), nil
+}
+
+// isToken reports whether s is an RFC 9110 token, or "*".
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,263 @@
+package negotiate
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	offered := []string{"text/plain", "text/html", "application/json"}
+
+	tt := []struct {
+		name     string
+		header   string
+		offered  []string
+		expected string
+		err      error
+	}{
+		{
+			name:     "no header",
+			header:   "",
+			expected: "text/plain",
+		},
+		{
+			name:     "exact",
+			header:   "application/json",
+			expected: "application/json",
+		},
+		{
+			name:     "weights",
+			header:   "text/html;q=0.5, application/json;q=0.8",
+			expected: "application/json",
+		},
+		{
+			name:     "ties go to the range listed first",
+			header:   "application/json, text/html",
+			expected: "application/json",
+		},
+		{
+			name:     "wildcard ties go to the offer listed first",
+			header:   "*/*",
+			expected: "text/plain",
+		},
+		{
+			name:     "browser",
+			header:   "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			expected: "text/html",
+		},
+		{
+			name:     "subtype wildcard",
+			header:   "application/*",
+			expected: "application/json",
+		},
+		{
+			name:     "most specific range wins",
+			header:   "text/*;q=0.9, text/plain;q=0.1, application/json;q=0.5",
+			expected: "text/html",
+		},
+		{
+			name:   "refused",
+			header: "text/*;q=0, application/json;q=0",
+			err:    ErrNotAcceptable,
+		},
+		{
+			name:     "refused specifically",
+			header:   "*/*, text/plain;q=0",
+			expected: "text/html",
+		},
+		{
+			name:   "nothing matches",
+			header: "image/png",
+			err:    ErrNotAcceptable,
+		},
+		{
+			name:     "bare star",
+			header:   "*; q=.2, application/json",
+			expected: "application/json",
+		},
+		{
+			name:     "parameters must match",
+			header:   "application/json;profile=a, text/html;q=0.1",
+			offered:  []string{"text/html", "application/json;profile=b"},
+			expected: "text/html",
+		},
+		{
+			name:     "parameters the offer doesn't have are ignored",
+			header:   "text/html;charset=utf-8",
+			expected: "text/html",
+		},
+		{
+			name:     "more parameters are more specific",
+			header:   "text/plain;format=flowed;q=0.2, text/plain",
+			offered:  []string{"text/plain;format=flowed", "text/plain;format=fixed"},
+			expected: "text/plain;format=fixed",
+		},
+		{
+			name:     "extensions after q",
+			header:   "text/html;q=0.2;level=1, application/json;q=0.1",
+			expected: "text/html",
+		},
+		{
+			name:     "quoted commas",
+			header:   `application/json;profile="a,b";q=0.5, text/html;q=0.4`,
+			expected: "application/json",
+		},
+		{
+			name:     "malformed ranges are skipped",
+			header:   "text/, ;;, application/json;q=2, text/html;q=x, application/json",
+			expected: "application/json",
+		},
+		{
+			name:     "weights above 1 are clamped",
+			header:   "application/json;q=0.9, text/html;q=5",
+			expected: "text/html",
+		},
+		{
+			name:     "weights below 0 refuse",
+			header:   "text/plain;q=-1, */*;q=0.5",
+			expected: "text/html",
+		},
+		{
+			name:     "only malformed ranges",
+			header:   "garbage",
+			expected: "text/plain",
+		},
+		{
+			name:    "no offers",
+			header:  "*/*",
+			offered: []string{},
+			err:     ErrNotAcceptable,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			o := offered
+			if tc.offered != nil {
+				o = tc.offered
+			}
+
+			got, err := Negotiate(tc.header, o)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestNegotiateBadOffer(t *testing.T) {
+	for _, offer := range []string{"text/*", "*/*", "text", "text/plain;charset"} {
+		if _, err := Negotiate("*/*", []string{offer}); err == nil {
+			t.Errorf("expected an error for offer %q", offer)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	got := Parse(`Text/HTML; Level="1 \"a\""; q=0.5; ext=x, */*`)
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 ranges, got %v", got)
+	}
+
+	if got[0].Type != "text" || got[0].Subtype != "html" || got[0].Q != 0.5 {
+		t.Errorf("unexpected range %+v", got[0])
+	}
+
+	if got[0].Params["level"] != `1 "a"` || len(got[0].Params) != 1 {
+		t.Errorf("unexpected params %v", got[0].Params)
+	}
+
+	if got[1].Type != "*" || got[1].Subtype != "*" || got[1].Q != 1 {
+		t.Errorf("unexpected range %+v", got[1])
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	tt := []struct {
+		header string
+		valid  bool
+	}{
+		{header: "", valid: true},
+		{header: "text/html, application/json;q=0.5,", valid: true},
+		{header: `application/json;profile="a,b";q=1.000`, valid: true},
+		{header: "*", valid: true},
+		{header: "text/", valid: false},
+		{header: "text/html;q=x", valid: false},
+		{header: "text/html;q=2", valid: false},
+		{header: "text/html;q=-0.5", valid: false},
+		{header: "text/html;level", valid: false},
+		{header: "text/html, garbage", valid: false},
+	}
+
+	for _, tc := range tt {
+		_, err := ParseStrict(tc.header)
+		if tc.valid && err != nil {
+			t.Errorf("%q: unexpected error: %s", tc.header, err)
+		}
+
+		if !tc.valid && !errors.Is(err, ErrMalformed) {
+			t.Errorf("%q: expected ErrMalformed, got %v", tc.header, err)
+		}
+	}
+
+	if _, err := NegotiateStrict("text/html;q=2", []string{"text/html"}); !errors.Is(err, ErrMalformed) {
+		t.Errorf("expected ErrMalformed, got %v", err)
+	}
+
+	if got, err := NegotiateStrict("application/json, text/html;q=0.1", []string{"text/html", "application/json"}); err != nil || got != "application/json" {
+		t.Errorf("expected application/json, got %q, %v", got, err)
+	}
+}
+
+func TestRefuses(t *testing.T) {
+	tt := []struct {
+		header    string
+		mediatype string
+		expected  bool
+	}{
+		{header: "", mediatype: "text/plain", expected: false},
+		{header: "text/plain;q=0, */*", mediatype: "text/plain", expected: true},
+		{header: "text/plain;q=0, */*", mediatype: "text/html", expected: false},
+		{header: "text/*;q=0, text/html", mediatype: "text/html", expected: false},
+		{header: "text/*;q=0, text/html", mediatype: "text/plain", expected: true},
+		{header: "application/json", mediatype: "text/plain", expected: false},
+		{header: "*/*;q=0", mediatype: "application/json", expected: true},
+	}
+
+	for _, tc := range tt {
+		if got := Refuses(tc.header, tc.mediatype); got != tc.expected {
+			t.Errorf("%q refusing %s: expected %t, got %t", tc.header, tc.mediatype, tc.expected, got)
+		}
+	}
+}
+
+func FuzzNegotiate(f *testing.F) {
+	f.Add("text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	f.Add(`application/json;profile="a,b\"";q=0.5;ext, text/*;q=0`)
+	f.Add("*; q=.2, text/plain;q=1.000")
+
+	offered := []string{"text/plain", "text/html", "application/json;profile=a"}
+
+	f.Fuzz(func(t *testing.T, header string) {
+		for _, r := range Parse(header) {
+			if r.Q < 0 || r.Q > 1 {
+				t.Errorf("weight out of range: %+v", r)
+			}
+		}
+
+		got, err := Negotiate(header, offered)
+		if err != nil && !errors.Is(err, ErrNotAcceptable) {
+			t.Errorf("unexpected error: %s", err)
+		}
+
+		if err == nil && !slices.Contains(offered, got) {
+			t.Errorf("negotiated %q, which wasn't offered", got)
+		}
+	})
+}
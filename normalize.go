@@ -0,0 +1,31 @@
+package ynal
+
+import (
+	"regexp"
+	"strings"
+)
+
+// copyrightLine matches lines that are copyright notices, which differ between
+// every copy of a license and so aren't part of it. Lines that only mention
+// copyright, like "the above copyright notice", are terms, and so are long
+// lines that happen to start with it, like a whole license reflowed onto one.
+var copyrightLine = regexp.MustCompile(`(?im)^[^a-z0-9(©]*(copyright|\(c\)|©).{0,200}$`)
+
+// equivalents are the characters the SPDX matching guidelines treat as the
+// same, like curly and straight quotes, and what they're all written as.
+var equivalents = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'",
+	"“", "'", "”", "'", "„", "'", "‟", "'", "«", "'", "»", "'", "\"", "'", "`", "'",
+	"‐", "-", "‑", "-", "‒", "-", "–", "-", "—", "-", "―", "-", "−", "-",
+)
+
+// Normalize returns text in the canonical form the SPDX matching guidelines
+// compare licenses in: without copyright notices, with quotes and dashes made
+// alike, folded to lowercase, and with every run of whitespace a single space.
+// Copies of a license that only differ in those ways normalize the same.
+func Normalize(text string) string {
+	text = copyrightLine.ReplaceAllString(text, "")
+	text = equivalents.Replace(strings.ToLower(text))
+
+	return strings.Join(strings.Fields(text), " ")
+}
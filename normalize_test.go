@@ -0,0 +1,42 @@
+package ynal
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tt := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "copyright lines",
+			text:     "Copyright (c) 2024 Someone\n(C) 2023 Someone Else\n © 2022 Another\n\nPermission is granted.\n",
+			expected: "permission is granted.",
+		},
+		{
+			name:     "terms that mention copyright",
+			text:     "The above copyright notice shall be included.\n",
+			expected: "the above copyright notice shall be included.",
+		},
+		{
+			name:     "whitespace and case",
+			text:     "  THE SOFTWARE IS\n\tPROVIDED    \"AS IS\"\r\n",
+			expected: "the software is provided 'as is'",
+		},
+		{
+			name:     "quotes and dashes",
+			text:     "the “Software” — or ‘Work’ – non‐infringing",
+			expected: "the 'software' - or 'work' - non-infringing",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Normalize(tc.text); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
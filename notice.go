@@ -0,0 +1,86 @@
+package ynal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Notice is an Apache-style NOTICE file: the project's name and copyright
+// lines, followed by attributions for the components it bundles.
+type Notice struct {
+	Project    string      `json:"project"`
+	Copyright  []string    `json:"copyright,omitempty"`
+	Components []Component `json:"components,omitempty"`
+}
+
+// Component is a third-party work bundled with a project.
+type Component struct {
+	Name      string   `json:"name"`
+	URL       string   `json:"url,omitempty"`
+	Copyright []string `json:"copyright,omitempty"`
+
+	// License is the ID of the license the component is under, if known.
+	License string `json:"license,omitempty"`
+}
+
+// Render returns the text of the NOTICE file. Component licenses are looked up
+// in licenses so they can be named by title; an unknown one is an error.
+func (n Notice) Render(licenses []LicenseData) (string, error) {
+	if strings.TrimSpace(n.Project) == "" {
+		return "", errors.New("missing project name")
+	}
+
+	buf := new(strings.Builder)
+
+	buf.WriteString(n.Project + "\n")
+	writeCopyright(buf, n.Copyright)
+
+	if len(n.Components) == 0 {
+		return buf.String(), nil
+	}
+
+	buf.WriteString("\nThis product bundles the following components:\n")
+
+	for _, c := range n.Components {
+		if strings.TrimSpace(c.Name) == "" {
+			return "", errors.New("missing component name")
+		}
+
+		buf.WriteString("\n" + c.Name)
+		if c.URL != "" {
+			buf.WriteString(" (" + c.URL + ")")
+		}
+		buf.WriteString("\n")
+
+		writeCopyright(buf, c.Copyright)
+
+		if c.License != "" {
+			l, ok := FindLicense(licenses, strings.ToLower(c.License))
+			if !ok {
+				return "", fmt.Errorf("could not find license for %s: %w", c.Name, ErrNotFound)
+			}
+
+			fmt.Fprintf(buf, "Licensed under %s\n", l.Title)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// writeCopyright writes one copyright line per line, adding "Copyright" in
+// front of any that don't already say it.
+func writeCopyright(buf *strings.Builder, lines []string) {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(strings.ToLower(line), "copyright") && !strings.HasPrefix(line, "©") {
+			line = "Copyright " + line
+		}
+
+		buf.WriteString(line + "\n")
+	}
+}
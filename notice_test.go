@@ -0,0 +1,65 @@
+package ynal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNoticeRender(t *testing.T) {
+	licenses := []LicenseData{NewLicense("MIT", "mit\n"), NewLicense("Apache_2", "apache\n")}
+
+	tt := []struct {
+		name     string
+		notice   Notice
+		expected string
+	}{
+		{
+			name:     "project only",
+			notice:   Notice{Project: "widget", Copyright: []string{"2024 Jane Doe"}},
+			expected: "widget\nCopyright 2024 Jane Doe\n",
+		},
+		{
+			name: "components",
+			notice: Notice{
+				Project:   "widget",
+				Copyright: []string{"Copyright 2024 Jane Doe", "© 2025 Widget Co."},
+				Components: []Component{
+					{Name: "gizmo", URL: "https://example.com/gizmo", Copyright: []string{"2019 Gizmo Authors"}, License: "mit"},
+					{Name: "sprocket", License: "Apache_2"},
+				},
+			},
+			expected: "widget\nCopyright 2024 Jane Doe\n© 2025 Widget Co.\n" +
+				"\nThis product bundles the following components:\n" +
+				"\ngizmo (https://example.com/gizmo)\nCopyright 2019 Gizmo Authors\nLicensed under MIT\n" +
+				"\nsprocket\nLicensed under Apache_2\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.notice.Render(licenses)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tc.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestNoticeRenderErrors(t *testing.T) {
+	if _, err := (Notice{}).Render(nil); err == nil {
+		t.Fatalf("expected an error for a missing project")
+	}
+
+	if _, err := (Notice{Project: "widget", Components: []Component{{}}}).Render(nil); err == nil {
+		t.Fatalf("expected an error for a missing component name")
+	}
+
+	_, err := Notice{Project: "widget", Components: []Component{{Name: "gizmo", License: "nope"}}}.Render(nil)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for an unknown license, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package ynal
+
+import "slices"
+
+// The obligations a license can put on people who use it. See
+// LicenseData.Obligations.
+const (
+	ObligationIncludeCopyright = "include-copyright"
+	ObligationIncludeNotice    = "include-notice"
+	ObligationDocumentChanges  = "document-changes"
+	ObligationDiscloseSource   = "disclose-source"
+	ObligationSameLicense      = "same-license"
+	ObligationNetworkUse       = "network-use-disclose"
+)
+
+// Obligations are every valid obligation, in the order they're reported.
+var Obligations = []string{
+	ObligationIncludeCopyright,
+	ObligationIncludeNotice,
+	ObligationDocumentChanges,
+	ObligationDiscloseSource,
+	ObligationSameLicense,
+	ObligationNetworkUse,
+}
+
+// obligationDescriptions say what each obligation asks, in English.
+var obligationDescriptions = map[string]string{
+	ObligationIncludeCopyright: "Include the copyright notice and the license text with every copy.",
+	ObligationIncludeNotice:    "Pass on any NOTICE file that came with it.",
+	ObligationDocumentChanges:  "Say what you changed in any files you modified.",
+	ObligationDiscloseSource:   "Make the source code available when you distribute it.",
+	ObligationSameLicense:      "License your changes, and works built on it, under the same license.",
+	ObligationNetworkUse:       "Offer the source code to people who use it over a network, too.",
+}
+
+// Obligation is one of Obligations, what it asks, and every license that
+// asks it.
+type Obligation struct {
+	Name        string
+	Description string
+	Licenses    []LicenseData
+}
+
+// ObligationReport consolidates what using licenses together asks of
+// someone, in the order of Obligations. Unlike TagIndex, only obligations at
+// least one of them imposes are there.
+func ObligationReport(licenses []LicenseData) []Obligation {
+	report := []Obligation{}
+
+	for _, name := range Obligations {
+		o := Obligation{Name: name, Description: obligationDescriptions[name], Licenses: []LicenseData{}}
+
+		for _, l := range licenses {
+			if l.HasObligation(name) {
+				o.Licenses = append(o.Licenses, l)
+			}
+		}
+
+		if len(o.Licenses) > 0 {
+			report = append(report, o)
+		}
+	}
+
+	return report
+}
+
+// HasObligation reports whether l imposes obligation.
+func (l LicenseData) HasObligation(obligation string) bool {
+	return slices.Contains(l.Obligations, obligation)
+}
@@ -0,0 +1,39 @@
+package ynal
+
+import (
+	"testing"
+)
+
+func TestObligationReport(t *testing.T) {
+	mit := NewLicense("MIT", "mit\n")
+	mit.Obligations = []string{ObligationIncludeCopyright}
+
+	gpl := NewLicense("GPL_3", "gpl\n")
+	gpl.Obligations = []string{ObligationIncludeCopyright, ObligationDocumentChanges, ObligationDiscloseSource, ObligationSameLicense}
+
+	report := ObligationReport([]LicenseData{mit, NewLicense("Unlicense", "free\n"), gpl})
+
+	if len(report) != 4 {
+		t.Fatalf("expected only the obligations imposed, got %+v", report)
+	}
+
+	if report[0].Name != ObligationIncludeCopyright || len(report[0].Licenses) != 2 || report[0].Licenses[0].ID != "mit" || report[0].Licenses[1].ID != "gpl_3" {
+		t.Errorf("expected MIT then GPL_3 to ask for the copyright notice, got %+v", report[0])
+	}
+
+	if report[3].Name != ObligationSameLicense || len(report[3].Licenses) != 1 || report[3].Description == "" {
+		t.Errorf("expected only GPL_3 to ask for the same license, got %+v", report[3])
+	}
+
+	if got := ObligationReport([]LicenseData{NewLicense("Unlicense", "free\n")}); len(got) != 0 {
+		t.Errorf("expected no obligations, got %+v", got)
+	}
+}
+
+func TestObligationDescriptions(t *testing.T) {
+	for _, o := range Obligations {
+		if obligationDescriptions[o] == "" {
+			t.Errorf("no description for %s", o)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Package reuse finds the SPDX-License-Identifier headers that the REUSE
+// specification (https://reuse.software) asks every file in a project to
+// carry, in a single file or every file in an archive.
+package reuse
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// maxSize bounds how much is read out of an archive, so a small one can't
+// unpack to something huge, and maxFiles how many files. They're variables so
+// tests don't need huge archives.
+var (
+	maxSize  int64 = 256 << 20
+	maxFiles       = 50000
+)
+
+// tag is what starts a license header.
+const tag = "SPDX-License-Identifier:"
+
+// ErrTooLarge is the error for an archive that unpacks to more than can be
+// checked.
+var ErrTooLarge = errors.New("archive is too large to check")
+
+// commentEnds are what close comments on the same line as a header, which
+// aren't part of its expression.
+var commentEnds = []string{"*/", "-->", "--%>", "%>", "#}", "*)", "-}", "\"\"\"", "'''"}
+
+// File is a file and the license headers found in it.
+type File struct {
+	Path string `json:"path"`
+
+	// Identifiers are the expressions of the file's SPDX-License-Identifier
+	// headers, or of its .license sidecar's if it has one.
+	Identifiers []string `json:"identifiers"`
+}
+
+// Read returns every file in the file called name, which is an archive if
+// its name ends in .zip, .tar, .tar.gz, or .tgz and a single source file
+// otherwise. Files REUSE doesn't need headers in, like the ones under
+// LICENSES/ and LICENSE files themselves, are left out, and .license
+// sidecars are read as the headers of the file they're next to.
+func Read(name string, data []byte) ([]File, error) {
+	lower := strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return readZip(data)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("could not decompress %s: %w", name, err)
+		}
+		defer gz.Close()
+
+		return readTar(gz)
+	case strings.HasSuffix(lower, ".tar"):
+		return readTar(bytes.NewReader(data))
+	}
+
+	return []File{{Path: path.Base(name), Identifiers: Identifiers(data)}}, nil
+}
+
+func readZip(data []byte) ([]File, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("could not open zip: %w", err)
+	}
+
+	files := map[string][]byte{}
+	size := int64(0)
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || ignored(f.Name) {
+			continue
+		}
+
+		if len(files) >= maxFiles {
+			return nil, ErrTooLarge
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("could not open %s: %w", f.Name, err)
+		}
+
+		b, err := io.ReadAll(io.LimitReader(rc, maxSize-size+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", f.Name, err)
+		}
+
+		size += int64(len(b))
+		if size > maxSize {
+			return nil, ErrTooLarge
+		}
+
+		files[f.Name] = b
+	}
+
+	return collect(files), nil
+}
+
+func readTar(r io.Reader) ([]File, error) {
+	tr := tar.NewReader(r)
+	files := map[string][]byte{}
+	size := int64(0)
+
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("could not read tar: %w", err)
+		}
+
+		if h.Typeflag != tar.TypeReg || ignored(h.Name) {
+			continue
+		}
+
+		if len(files) >= maxFiles {
+			return nil, ErrTooLarge
+		}
+
+		b, err := io.ReadAll(io.LimitReader(tr, maxSize-size+1))
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", h.Name, err)
+		}
+
+		size += int64(len(b))
+		if size > maxSize {
+			return nil, ErrTooLarge
+		}
+
+		files[h.Name] = b
+	}
+
+	return collect(files), nil
+}
+
+// collect finds the headers of every file, taking them from sidecars where
+// there are some, sorted by path.
+func collect(contents map[string][]byte) []File {
+	files := []File{}
+
+	for name, b := range contents {
+		if strings.HasSuffix(name, ".license") {
+			if _, ok := contents[strings.TrimSuffix(name, ".license")]; ok {
+				continue
+			}
+		}
+
+		ids := Identifiers(b)
+		if sidecar, ok := contents[name+".license"]; ok {
+			ids = Identifiers(sidecar)
+		}
+
+		files = append(files, File{Path: strings.TrimPrefix(name, "./"), Identifiers: ids})
+	}
+
+	slices.SortFunc(files, func(a, b File) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return files
+}
+
+// ignored reports whether REUSE leaves the file at name alone: licenses,
+// version control, and REUSE's own configuration.
+func ignored(name string) bool {
+	name = strings.TrimPrefix(name, "./")
+
+	for _, dir := range strings.Split(path.Dir(name), "/") {
+		switch dir {
+		case "LICENSES", ".git", ".hg", ".svn", ".reuse":
+			return true
+		}
+	}
+
+	base := path.Base(name)
+	for _, prefix := range []string{"LICENSE", "LICENCE", "COPYING"} {
+		if strings.HasPrefix(strings.ToUpper(base), prefix) {
+			return true
+		}
+	}
+
+	return base == "REUSE.toml"
+}
+
+// Identifiers returns the expressions of every SPDX-License-Identifier header
+// in data, skipping any between REUSE-IgnoreStart and REUSE-IgnoreEnd. Binary
+// files have none.
+func Identifiers(data []byte) []string {
+	ids := []string{}
+
+	if bytes.IndexByte(data, 0) != -1 {
+		return ids
+	}
+
+	skipping := false
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, len(data)+1)
+
+	for s.Scan() {
+		line := s.Text()
+
+		if strings.Contains(line, "REUSE-IgnoreStart") {
+			skipping = true
+		}
+
+		if strings.Contains(line, "REUSE-IgnoreEnd") {
+			skipping = false
+			continue
+		}
+
+		if skipping {
+			continue
+		}
+
+		_, expr, ok := strings.Cut(line, tag)
+		if !ok {
+			continue
+		}
+
+		expr = strings.TrimSpace(expr)
+		for _, end := range commentEnds {
+			expr = strings.TrimSpace(strings.TrimSuffix(expr, end))
+		}
+
+		if expr != "" {
+			ids = append(ids, expr)
+		}
+	}
+
+	return ids
+}
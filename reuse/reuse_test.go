@@ -0,0 +1,145 @@
+package reuse
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"slices"
+	"testing"
+)
+
+var project = map[string]string{
+	"main.go":             "// SPDX-FileCopyrightText: 2024 Someone\n// SPDX-License-Identifier: MIT\n\npackage main\n",
+	"style.css":           "/* SPDX-License-Identifier: Apache-2.0 OR MIT */\nbody {}\n",
+	"README.md":           "# widget\n",
+	"logo.png":            "\x89PNG\x00\x00",
+	"logo.png.license":    "SPDX-License-Identifier: CC0-1.0\n",
+	"orphan.license":      "SPDX-License-Identifier: MIT\n",
+	"LICENSE":             "MIT License...\n",
+	"LICENSES/MIT.txt":    "MIT License...\n",
+	".reuse/dep5":         "Format: ...\n",
+	"docs/example.py":     "# SPDX-License-Identifier: GPL-3.0-or-later\n# REUSE-IgnoreStart\n# SPDX-License-Identifier: Nope\n# REUSE-IgnoreEnd\n",
+	"docs/page.html":      "<!-- SPDX-License-Identifier: CC-BY-4.0 -->\n",
+	"docs/LICENSE-THIRDS": "whatever\n",
+}
+
+var expected = []File{
+	{Path: "README.md", Identifiers: []string{}},
+	{Path: "docs/example.py", Identifiers: []string{"GPL-3.0-or-later"}},
+	{Path: "docs/page.html", Identifiers: []string{"CC-BY-4.0"}},
+	{Path: "logo.png", Identifiers: []string{"CC0-1.0"}},
+	{Path: "main.go", Identifiers: []string{"MIT"}},
+	{Path: "orphan.license", Identifiers: []string{"MIT"}},
+	{Path: "style.css", Identifiers: []string{"Apache-2.0 OR MIT"}},
+}
+
+func tarball(t *testing.T, compress bool) []byte {
+	buf := new(bytes.Buffer)
+
+	var gz *gzip.Writer
+	tw := tar.NewWriter(buf)
+	if compress {
+		gz = gzip.NewWriter(buf)
+		tw = tar.NewWriter(gz)
+	}
+
+	tw.WriteHeader(&tar.Header{Name: "./docs/", Typeflag: tar.TypeDir, Mode: 0755})
+
+	for name, content := range project {
+		if err := tw.WriteHeader(&tar.Header{Name: "./" + name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatalf("could not write header: %s", err)
+		}
+
+		tw.Write([]byte(content))
+	}
+
+	tw.Close()
+	if gz != nil {
+		gz.Close()
+	}
+
+	return buf.Bytes()
+}
+
+func zipball(t *testing.T) []byte {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	for name, content := range project {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("could not create %s: %s", name, err)
+		}
+
+		w.Write([]byte(content))
+	}
+
+	zw.Close()
+
+	return buf.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	tt := []struct {
+		name string
+		data []byte
+	}{
+		{name: "widget.tar", data: tarball(t, false)},
+		{name: "widget.tar.gz", data: tarball(t, true)},
+		{name: "widget.zip", data: zipball(t)},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Read(tc.name, tc.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !slices.EqualFunc(got, expected, func(a, b File) bool {
+				return a.Path == b.Path && slices.Equal(a.Identifiers, b.Identifiers)
+			}) {
+				t.Errorf("expected %+v, got %+v", expected, got)
+			}
+		})
+	}
+}
+
+func TestReadSingleFile(t *testing.T) {
+	got, err := Read("src/main.go", []byte(project["main.go"]))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(got) != 1 || got[0].Path != "main.go" || !slices.Equal(got[0].Identifiers, []string{"MIT"}) {
+		t.Errorf("unexpected files: %+v", got)
+	}
+}
+
+func TestReadCorrupt(t *testing.T) {
+	for _, name := range []string{"widget.zip", "widget.tar.gz"} {
+		if _, err := Read(name, []byte("not an archive")); err == nil {
+			t.Errorf("expected an error reading %s", name)
+		}
+	}
+}
+
+func TestReadTooLarge(t *testing.T) {
+	defer func(size int64) { maxSize = size }(maxSize)
+	maxSize = 1024
+
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	tw.WriteHeader(&tar.Header{Name: "big.txt", Typeflag: tar.TypeReg, Mode: 0644, Size: maxSize + 1})
+	tw.Write(make([]byte, maxSize+1))
+	tw.Close()
+	gz.Close()
+
+	if _, err := Read("big.tar.gz", buf.Bytes()); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,320 @@
+// Package s3store serves licenses kept in an S3-compatible bucket, for teams
+// that manage an internal license catalog in object storage.
+//
+// Every <title>.txt object under the configured prefix is a license. Its
+// title can be overridden with the x-amz-meta-title object metadata,
+// x-amz-meta-aliases holds a comma separated list of aliases,
+// x-amz-meta-family and x-amz-meta-version place it in a family, and
+// x-amz-meta-deprecated and x-amz-meta-successor mark it as deprecated.
+package s3store
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+// maxObjectSize bounds how much of an object is read, in case something that
+// isn't a license ends up in the bucket.
+const maxObjectSize = 1 << 20
+
+// Bucket says where licenses are kept and how to get at them.
+type Bucket struct {
+	// Endpoint is the base URL of the S3 API, e.g.
+	// https://s3.us-east-1.amazonaws.com or http://localhost:9000 for MinIO.
+	// Buckets are addressed by path, which every S3-compatible service
+	// supports.
+	Endpoint string
+
+	Name   string
+	Prefix string
+	Region string
+
+	// AccessKeyID and SecretAccessKey sign every request. Requests are
+	// anonymous when both are empty.
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// Client is used for every request. Defaults to a client with a
+	// reasonable timeout.
+	Client *http.Client
+}
+
+type object struct {
+	etag    string
+	license ynal.LicenseData
+}
+
+// Store is a read-only ynal.LicenseStore serving the licenses in a Bucket.
+// Licenses are kept in memory and only refetched when their ETag changes.
+type Store struct {
+	bucket  Bucket
+	catalog *ynal.Catalog
+
+	mu      sync.Mutex
+	objects map[string]object
+
+	// failed is why the last refresh failed, if it did
+	failedMu sync.Mutex
+	failed   error
+}
+
+// Open fetches every license in bucket before returning, so ynal never starts
+// serving an empty catalog.
+func Open(ctx context.Context, bucket Bucket) (*Store, error) {
+	s := &Store{
+		bucket:  bucket,
+		catalog: ynal.NewCatalog(nil),
+		objects: map[string]object{},
+	}
+
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Store) List() []ynal.LicenseData {
+	return s.catalog.List()
+}
+
+func (s *Store) Get(id string) (ynal.LicenseData, bool) {
+	return s.catalog.Get(id)
+}
+
+// Watch registers fn to be called after every Refresh that changes anything.
+func (s *Store) Watch(fn func([]ynal.LicenseData)) {
+	s.catalog.Watch(fn)
+}
+
+// Run refreshes every interval until ctx is done. Failures are logged and the
+// previous licenses keep being served.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Refresh(ctx); err != nil {
+			log.Printf("s3 refresh failed: %s", err)
+		}
+	}
+}
+
+// Health returns why the last refresh failed, while the bucket can't be
+// reached and the licenses being served might be out of date.
+func (s *Store) Health(ctx context.Context) error {
+	s.failedMu.Lock()
+	defer s.failedMu.Unlock()
+
+	if s.failed != nil {
+		return fmt.Errorf("last refresh failed: %w", s.failed)
+	}
+
+	return nil
+}
+
+// Refresh lists the bucket and fetches any license that is new or has
+// changed. Nothing is replaced unless every fetch succeeds.
+func (s *Store) Refresh(ctx context.Context) error {
+	err := s.refresh(ctx)
+
+	s.failedMu.Lock()
+	s.failed = err
+	s.failedMu.Unlock()
+
+	return err
+}
+
+func (s *Store) refresh(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listed, err := s.list(ctx)
+	if err != nil {
+		return fmt.Errorf("could not list bucket: %w", err)
+	}
+
+	objects := map[string]object{}
+	changed := len(listed) != len(s.objects)
+
+	for key, etag := range listed {
+		if o, ok := s.objects[key]; ok && o.etag == etag {
+			objects[key] = o
+			continue
+		}
+
+		l, err := s.fetch(ctx, key)
+		if err != nil {
+			return fmt.Errorf("could not fetch %s: %w", key, err)
+		}
+
+		objects[key] = object{etag: etag, license: l}
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	licenses := []ynal.LicenseData{}
+	for _, o := range objects {
+		licenses = append(licenses, o.license)
+	}
+
+	sort.Slice(licenses, func(i, j int) bool {
+		return licenses[i].Title < licenses[j].Title
+	})
+
+	s.objects = objects
+	s.catalog.Replace(licenses)
+
+	return nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		ETag string `xml:"ETag"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list returns the ETag of every license object in the bucket, by key.
+func (s *Store) list(ctx context.Context) (map[string]string, error) {
+	etags := map[string]string{}
+	token := ""
+
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.bucket.Prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, "", query)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not decode listing: %w", err)
+		}
+
+		for _, c := range result.Contents {
+			// only licenses at the top level of the prefix, like
+			// ynal.LoadLicenses
+			rest := strings.TrimPrefix(c.Key, s.bucket.Prefix)
+			if path.Ext(rest) != ".txt" || strings.Contains(rest, "/") {
+				continue
+			}
+
+			etags[c.Key] = c.ETag
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return etags, nil
+		}
+
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *Store) fetch(ctx context.Context, key string) (ynal.LicenseData, error) {
+	resp, err := s.do(ctx, key, nil)
+	if err != nil {
+		return ynal.LicenseData{}, err
+	}
+	defer resp.Body.Close()
+
+	text, err := io.ReadAll(io.LimitReader(resp.Body, maxObjectSize))
+	if err != nil {
+		return ynal.LicenseData{}, fmt.Errorf("could not read object: %w", err)
+	}
+
+	title := resp.Header.Get("X-Amz-Meta-Title")
+	if title == "" {
+		title = strings.TrimSuffix(path.Base(key), ".txt")
+	}
+
+	if err := ynal.ValidateLicense(title, string(text)); err != nil {
+		return ynal.LicenseData{}, err
+	}
+
+	l := ynal.NewLicense(title, string(text))
+	l.Family = resp.Header.Get("X-Amz-Meta-Family")
+	l.Version = resp.Header.Get("X-Amz-Meta-Version")
+	l.Deprecated = resp.Header.Get("X-Amz-Meta-Deprecated") == "true"
+	l.Successor = strings.ToLower(resp.Header.Get("X-Amz-Meta-Successor"))
+
+	for _, alias := range strings.Split(resp.Header.Get("X-Amz-Meta-Aliases"), ",") {
+		if alias = strings.ToLower(strings.TrimSpace(alias)); alias != "" && alias != l.ID {
+			l.Aliases = append(l.Aliases, alias)
+		}
+	}
+
+	return l, nil
+}
+
+// do sends a GET for key (or the bucket itself when key is empty) and returns
+// the response if it was successful.
+func (s *Store) do(ctx context.Context, key string, query url.Values) (*http.Response, error) {
+	u, err := url.Parse(s.bucket.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse endpoint: %w", err)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket.Name + "/" + key
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not build request: %w", err)
+	}
+
+	if s.bucket.AccessKeyID != "" || s.bucket.SecretAccessKey != "" {
+		sign(req, s.bucket.AccessKeyID, s.bucket.SecretAccessKey, s.bucket.Region, time.Now())
+	}
+
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return resp, nil
+}
+
+func (s *Store) client() *http.Client {
+	if s.bucket.Client != nil {
+		return s.bucket.Client
+	}
+
+	return &http.Client{Timeout: 30 * time.Second}
+}
@@ -0,0 +1,206 @@
+package s3store
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+type fakeObject struct {
+	etag  string
+	text  string
+	title string
+}
+
+// fakeS3 serves a single bucket called "licenses", two keys per page so
+// pagination gets exercised.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	fetches int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/licenses/")
+	if !ok {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	if key != "" {
+		o, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+
+		f.fetches++
+
+		if o.title != "" {
+			w.Header().Set("X-Amz-Meta-Title", o.title)
+			w.Header().Set("X-Amz-Meta-Aliases", "expat, X11")
+			w.Header().Set("X-Amz-Meta-Family", "MIT")
+		}
+		w.Write([]byte(o.text))
+		return
+	}
+
+	keys := []string{}
+	for k := range f.objects {
+		if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	start := 0
+	if token := r.URL.Query().Get("continuation-token"); token != "" {
+		fmt.Sscanf(token, "%d", &start)
+	}
+
+	end := min(start+2, len(keys))
+
+	fmt.Fprint(w, `<ListBucketResult>`)
+	for _, k := range keys[start:end] {
+		fmt.Fprintf(w, `<Contents><Key>%s</Key><ETag>"%s"</ETag></Contents>`, k, f.objects[k].etag)
+	}
+	if end < len(keys) {
+		fmt.Fprintf(w, `<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>`, end)
+	}
+	fmt.Fprint(w, `</ListBucketResult>`)
+}
+
+func TestStore(t *testing.T) {
+	fake := &fakeS3{objects: map[string]fakeObject{
+		"ynal/MIT.txt":       {etag: "1", text: "mit text\n", title: "Expat-MIT"},
+		"ynal/BSD_3.txt":     {etag: "1", text: "bsd text\n"},
+		"ynal/GPL_3.txt":     {etag: "1", text: "gpl text\n"},
+		"ynal/README.md":     {etag: "1", text: "not a license"},
+		"ynal/old/Foo.txt":   {etag: "1", text: "not at the top level"},
+		"elsewhere/Bar.txt":  {etag: "1", text: "outside the prefix"},
+		"ynal/Unlicense.txt": {etag: "1", text: "unlicense text\n"},
+	}}
+
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	s, err := Open(context.Background(), Bucket{
+		Endpoint:        srv.URL,
+		Name:            "licenses",
+		Prefix:          "ynal/",
+		Region:          "us-east-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ids := []string{}
+	for _, l := range s.List() {
+		ids = append(ids, l.ID)
+	}
+
+	if !slices.Equal(ids, []string{"bsd_3", "expat-mit", "gpl_3", "unlicense"}) {
+		t.Fatalf("unexpected licenses: %v", ids)
+	}
+
+	l, _ := s.Get("expat-mit")
+	if l.Text != "mit text\n" || !slices.Equal(l.Aliases, []string{"expat", "x11"}) || l.Family != "MIT" {
+		t.Fatalf("expected metadata to be applied, got %+v", l)
+	}
+
+	watched := 0
+	s.Watch(func([]ynal.LicenseData) { watched++ })
+
+	// nothing changed, so nothing is refetched
+	fake.fetches = 0
+
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.fetches != 0 || watched != 0 {
+		t.Fatalf("expected no refetch and no change, got %d fetches and %d changes", fake.fetches, watched)
+	}
+
+	fake.objects["ynal/GPL_3.txt"] = fakeObject{etag: "2", text: "new gpl text\n"}
+	delete(fake.objects, "ynal/Unlicense.txt")
+
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if fake.fetches != 1 || watched != 1 {
+		t.Fatalf("expected one refetch and one change, got %d fetches and %d changes", fake.fetches, watched)
+	}
+
+	if l, _ := s.Get("gpl_3"); l.Text != "new gpl text\n" {
+		t.Fatalf("expected changed license to be refetched, got %q", l.Text)
+	}
+
+	if _, ok := s.Get("unlicense"); ok {
+		t.Fatalf("expected removed license to be gone")
+	}
+}
+
+func TestStoreKeepsLicensesOnFailure(t *testing.T) {
+	fake := &fakeS3{objects: map[string]fakeObject{
+		"MIT.txt": {etag: "1", text: "mit text\n"},
+	}}
+
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	bucket := Bucket{Endpoint: srv.URL, Name: "licenses", Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret"}
+
+	s, err := Open(context.Background(), bucket)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	fake.objects["Empty.txt"] = fakeObject{etag: "1", text: ""}
+
+	if err := s.Refresh(context.Background()); err == nil {
+		t.Fatalf("expected an error for an empty license")
+	}
+
+	if len(s.List()) != 1 {
+		t.Fatalf("expected the previous licenses to be kept, got %+v", s.List())
+	}
+
+	if err := s.Health(context.Background()); err == nil || !strings.Contains(err.Error(), "last refresh failed") {
+		t.Fatalf("expected the store to be unhealthy, got: %v", err)
+	}
+
+	delete(fake.objects, "Empty.txt")
+
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := s.Health(context.Background()); err != nil {
+		t.Fatalf("expected the store to recover, got: %s", err)
+	}
+
+	bucket.AccessKeyID = "wrong"
+
+	if _, err := Open(context.Background(), bucket); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected a 403, got: %v", err)
+	}
+}
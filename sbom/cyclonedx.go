@@ -0,0 +1,90 @@
+package sbom
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type cdxBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	Type     string       `json:"type"`
+	BOMRef   string       `json:"bom-ref,omitempty"`
+	Name     string       `json:"name"`
+	Version  string       `json:"version,omitempty"`
+	PURL     string       `json:"purl,omitempty"`
+	Licenses []cdxLicense `json:"licenses,omitempty"`
+}
+
+type cdxLicense struct {
+	Expression string `json:"expression"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// writeCycloneDX writes d as a CycloneDX 1.5 JSON BOM, with the project as
+// the component it describes.
+func (d Document) writeCycloneDX(w io.Writer) error {
+	root := "project"
+
+	bom := cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: d.created(),
+			Tools:     cdxTools{Components: []cdxComponent{{Type: "application", Name: "ynal"}}},
+			Component: cdxComponent{Type: "application", BOMRef: root, Name: d.Name, Version: d.Version},
+		},
+		Components: []cdxComponent{},
+	}
+
+	dependsOn := []string{}
+
+	for _, c := range d.components() {
+		ref := purl(c)
+		if ref == "" {
+			ref = c.Name + "@" + c.Version
+		}
+
+		cc := cdxComponent{Type: "library", BOMRef: ref, Name: c.Name, Version: c.Version, PURL: purl(c)}
+
+		// unlike SPDX, CycloneDX has no way to say a license is unknown other
+		// than leaving it out
+		if l := d.resolveLicense(c.License); l.expression != noAssertion {
+			cc.Licenses = []cdxLicense{{Expression: l.expression}}
+		}
+
+		bom.Components = append(bom.Components, cc)
+		dependsOn = append(dependsOn, ref)
+	}
+
+	bom.Dependencies = []cdxDependency{{Ref: root, DependsOn: dependsOn}}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(bom)
+}
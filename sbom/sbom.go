@@ -0,0 +1,187 @@
+// Package sbom writes software bills of materials: documents listing the
+// components a project is made of and the licenses they're under, in the SPDX
+// and CycloneDX JSON formats.
+//
+// Licenses are matched against a catalog, so components can name them by
+// ynal ID as well as SPDX identifier, and licenses that aren't on the SPDX
+// list can be written out in full.
+package sbom
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/manifest"
+	"github.com/packrat386/ynal/spdx"
+)
+
+// The formats a Document can be written in.
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// Formats are every format, the default first.
+var Formats = []string{FormatSPDX, FormatCycloneDX}
+
+// mediaTypes and extensions are what documents in each format are served and
+// saved as.
+var (
+	mediaTypes = map[string]string{
+		FormatSPDX:      "application/spdx+json",
+		FormatCycloneDX: "application/vnd.cyclonedx+json",
+	}
+
+	extensions = map[string]string{
+		FormatSPDX:      ".spdx.json",
+		FormatCycloneDX: ".cdx.json",
+	}
+)
+
+// MediaType returns the media type of documents in format.
+func MediaType(format string) string {
+	return mediaTypes[format]
+}
+
+// Filename returns the conventional name to save a document about the named
+// project in format as.
+func Filename(name string, format string) string {
+	return idFor(name) + extensions[format]
+}
+
+// noAssertion is what SPDX calls an unknown value.
+const noAssertion = "NOASSERTION"
+
+// Document is a project and the components it's made of.
+type Document struct {
+	Name    string
+	Version string
+
+	// Components are what the project depends on, like the dependencies the
+	// manifest package reads. Repeated components are only listed once.
+	Components []manifest.Dependency
+
+	// Licenses is the catalog component licenses are matched against.
+	Licenses []ynal.LicenseData
+
+	// Created is when the document was made. Defaults to now.
+	Created time.Time
+}
+
+// Write writes d in the given format.
+func (d Document) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatSPDX:
+		return d.writeSPDX(w)
+	case FormatCycloneDX:
+		return d.writeCycloneDX(w)
+	default:
+		return fmt.Errorf("unknown SBOM format: %s (try one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+func (d Document) created() string {
+	created := d.Created
+	if created.IsZero() {
+		created = time.Now()
+	}
+
+	return created.UTC().Format(time.RFC3339)
+}
+
+// components returns d's components without repeats.
+func (d Document) components() []manifest.Dependency {
+	components := []manifest.Dependency{}
+	for _, c := range d.Components {
+		if !slices.ContainsFunc(components, func(o manifest.Dependency) bool {
+			return o.Ecosystem == c.Ecosystem && o.Name == c.Name && o.Version == c.Version
+		}) {
+			components = append(components, c)
+		}
+	}
+
+	return components
+}
+
+// license is a component's license, normalized against the catalog, along
+// with the licenses it names that aren't on the SPDX list.
+type license struct {
+	expression string
+	refs       []ynal.LicenseData
+}
+
+// resolveLicense normalizes expr. Anything that doesn't parse, or names a
+// LicenseRef that isn't in the catalog and so can't be written out, is
+// NOASSERTION.
+func (d Document) resolveLicense(expr string) license {
+	if expr == "" {
+		return license{expression: noAssertion}
+	}
+
+	e, err := spdx.Parse(expr)
+	if err != nil {
+		return license{expression: noAssertion}
+	}
+
+	found, unknown := e.Resolve(d.Licenses)
+	for _, id := range unknown {
+		if strings.HasPrefix(id, "LicenseRef-") || strings.HasPrefix(id, "DocumentRef-") {
+			return license{expression: noAssertion}
+		}
+	}
+
+	l := license{expression: e.String()}
+	for _, f := range found {
+		if strings.HasPrefix(f.SPDXID(), "LicenseRef-") {
+			l.refs = append(l.refs, f)
+		}
+	}
+
+	return l
+}
+
+// purl returns the package URL of c, or nothing if its ecosystem isn't known.
+func purl(c manifest.Dependency) string {
+	types := map[string]string{
+		manifest.EcosystemGo:   "golang",
+		manifest.EcosystemNPM:  "npm",
+		manifest.EcosystemPyPI: "pypi",
+	}
+
+	t, ok := types[c.Ecosystem]
+	if !ok {
+		return ""
+	}
+
+	name := strings.ReplaceAll(url.PathEscape(c.Name), "%2F", "/")
+	if c.Version == "" {
+		return "pkg:" + t + "/" + name
+	}
+
+	return "pkg:" + t + "/" + name + "@" + url.PathEscape(c.Version)
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+var invalidIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// idFor makes s safe to use in an SPDX element ID.
+func idFor(s string) string {
+	return strings.Trim(invalidIDChars.ReplaceAllString(s, "-"), "-")
+}
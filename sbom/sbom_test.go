@@ -0,0 +1,116 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/manifest"
+)
+
+func testDocument(t *testing.T) Document {
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		t.Fatalf("could not load embedded licenses: %s", err)
+	}
+
+	return Document{
+		Name:    "widget",
+		Version: "1.0.0",
+		Components: []manifest.Dependency{
+			{Ecosystem: manifest.EcosystemGo, Name: "github.com/BurntSushi/toml", Version: "v1.6.0", License: "MIT"},
+			{Ecosystem: manifest.EcosystemNPM, Name: "@scope/thing", Version: "2.0.0", License: "mit or apache_2"},
+			{Ecosystem: manifest.EcosystemNPM, Name: "@scope/thing", Version: "2.0.0", License: "mit or apache_2"},
+			{Name: "vendored", Version: "0.1", License: "glwtspl"},
+			{Ecosystem: manifest.EcosystemPyPI, Name: "odd", Version: "1.0", License: "LicenseRef-Odd"},
+			{Ecosystem: manifest.EcosystemPyPI, Name: "unknown", Version: "1.0"},
+		},
+		Licenses: licenses,
+		Created:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := testDocument(t).Write(buf, FormatSPDX); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	doc := spdxDocument{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("could not decode document: %s", err)
+	}
+
+	if doc.SPDXVersion != "SPDX-2.3" || doc.CreationInfo.Created != "2024-01-02T03:04:05Z" || !strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/widget-") {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	// the project, then every component once
+	if len(doc.Packages) != 6 || len(doc.Relationships) != 6 {
+		t.Fatalf("expected six packages and relationships, got %+v", doc)
+	}
+
+	tt := []struct {
+		pkg     int
+		license string
+		purl    string
+	}{
+		{pkg: 1, license: "MIT", purl: "pkg:golang/github.com/BurntSushi/toml@v1.6.0"},
+		{pkg: 2, license: "MIT OR Apache-2.0", purl: "pkg:npm/@scope/thing@2.0.0"},
+		{pkg: 3, license: "LicenseRef-GLWTSPL"},
+		{pkg: 4, license: "NOASSERTION", purl: "pkg:pypi/odd@1.0"},
+		{pkg: 5, license: "NOASSERTION", purl: "pkg:pypi/unknown@1.0"},
+	}
+
+	for _, tc := range tt {
+		p := doc.Packages[tc.pkg]
+		if p.LicenseDeclared != tc.license {
+			t.Errorf("%s: expected license %q, got %q", p.Name, tc.license, p.LicenseDeclared)
+		}
+
+		if tc.purl != "" && (len(p.ExternalRefs) != 1 || p.ExternalRefs[0].Locator != tc.purl) {
+			t.Errorf("%s: expected purl %q, got %+v", p.Name, tc.purl, p.ExternalRefs)
+		}
+	}
+
+	if len(doc.ExtractedLicenses) != 1 || doc.ExtractedLicenses[0].LicenseID != "LicenseRef-GLWTSPL" || !strings.Contains(doc.ExtractedLicenses[0].ExtractedText, "GLWTS") {
+		t.Errorf("expected the text of the license that isn't on the SPDX list, got %+v", doc.ExtractedLicenses)
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := testDocument(t).Write(buf, FormatCycloneDX); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	bom := cdxBOM{}
+	if err := json.Unmarshal(buf.Bytes(), &bom); err != nil {
+		t.Fatalf("could not decode BOM: %s", err)
+	}
+
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" || !strings.HasPrefix(bom.SerialNumber, "urn:uuid:") || bom.Metadata.Component.Name != "widget" {
+		t.Fatalf("unexpected BOM: %+v", bom)
+	}
+
+	if len(bom.Components) != 5 || len(bom.Dependencies) != 1 || len(bom.Dependencies[0].DependsOn) != 5 {
+		t.Fatalf("expected five components the project depends on, got %+v", bom)
+	}
+
+	if l := bom.Components[1].Licenses; len(l) != 1 || l[0].Expression != "MIT OR Apache-2.0" {
+		t.Errorf("expected a normalized expression, got %+v", l)
+	}
+
+	if l := bom.Components[4].Licenses; len(l) != 0 {
+		t.Errorf("expected no license for an unknown one, got %+v", l)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if err := testDocument(t).Write(new(bytes.Buffer), "xml"); err == nil {
+		t.Fatalf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,123 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/packrat386/ynal"
+)
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+	ExtractedLicenses []spdxExtracted    `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+type spdxExtracted struct {
+	LicenseID     string `json:"licenseId"`
+	Name          string `json:"name"`
+	ExtractedText string `json:"extractedText"`
+}
+
+// writeSPDX writes d as an SPDX 2.3 JSON document, with the project as the
+// package it describes and every component as a package it depends on.
+func (d Document) writeSPDX(w io.Writer) error {
+	root := "SPDXRef-Package-" + idFor(d.Name)
+
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              d.Name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", idFor(d.Name), newUUID()),
+		CreationInfo: spdxCreationInfo{
+			Created:  d.created(),
+			Creators: []string{"Tool: ynal"},
+		},
+		Packages: []spdxPackage{{
+			Name:             d.Name,
+			SPDXID:           root,
+			VersionInfo:      d.Version,
+			DownloadLocation: noAssertion,
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  noAssertion,
+			CopyrightText:    noAssertion,
+		}},
+		Relationships: []spdxRelationship{{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: root}},
+	}
+
+	refs := []ynal.LicenseData{}
+
+	for i, c := range d.components() {
+		l := d.resolveLicense(c.License)
+
+		p := spdxPackage{
+			Name:             c.Name,
+			SPDXID:           fmt.Sprintf("SPDXRef-Package-%d-%s", i+1, idFor(c.Name)),
+			VersionInfo:      c.Version,
+			DownloadLocation: noAssertion,
+			LicenseConcluded: noAssertion,
+			LicenseDeclared:  l.expression,
+			CopyrightText:    noAssertion,
+		}
+
+		if purl := purl(c); purl != "" {
+			p.ExternalRefs = []spdxExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: purl}}
+		}
+
+		doc.Packages = append(doc.Packages, p)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{Element: root, Type: "DEPENDS_ON", Related: p.SPDXID})
+
+		for _, ref := range l.refs {
+			if !slices.ContainsFunc(refs, func(o ynal.LicenseData) bool { return o.ID == ref.ID }) {
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	for _, ref := range refs {
+		doc.ExtractedLicenses = append(doc.ExtractedLicenses, spdxExtracted{LicenseID: ref.SPDXID(), Name: ref.Title, ExtractedText: ref.Text})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(doc)
+}
@@ -0,0 +1,103 @@
+package ynal
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sectionHeading matches the first line of a numbered section, like
+// "  3. Grant of Patent License. Subject to..." in the Apache License.
+var sectionHeading = regexp.MustCompile(`^\s*(\d+)\.\s+([A-Z].*)$`)
+
+// maxSectionTitle is the longest a section's title can be. Numbered
+// paragraphs with longer first sentences, like the BSD license's conditions,
+// are clauses rather than headings.
+const maxSectionTitle = 80
+
+// Section is one of the numbered sections of a license's terms.
+type Section struct {
+	Number int
+	Title  string
+
+	// ID is what the section is anchored as in HTML, like "section-3".
+	ID string
+
+	// Text is the section's text, from its heading up to the next section.
+	Text string
+}
+
+// Sections splits text into its numbered sections, returning whatever comes
+// before the first one as the preamble, so the preamble and every section's
+// Text make up text exactly.
+//
+// A section starts with a line like "3. Title." after a blank line, and
+// sections must be numbered in order from 0 or 1, which keeps numbered lists
+// inside sections from being mistaken for them. Its title is the rest of the
+// heading's first sentence. Text with fewer than two
+// sections has none, and is all preamble.
+func Sections(text string) (string, []Section) {
+	lines := strings.SplitAfter(text, "\n")
+
+	type start struct {
+		offset int
+		number int
+		title  string
+	}
+
+	starts := []start{}
+	offset := 0
+	blank := true
+
+	for _, line := range lines {
+		m := sectionHeading.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+
+		if m != nil && blank {
+			n, _ := strconv.Atoi(m[1])
+
+			next := 0
+			if len(starts) > 0 {
+				next = starts[len(starts)-1].number + 1
+			}
+
+			title := sectionTitle(m[2])
+
+			if (n == next || (len(starts) == 0 && n == 1)) && len(title) <= maxSectionTitle {
+				starts = append(starts, start{offset: offset, number: n, title: title})
+			}
+		}
+
+		blank = strings.TrimSpace(line) == ""
+		offset += len(line)
+	}
+
+	if len(starts) < 2 {
+		return text, nil
+	}
+
+	sections := make([]Section, len(starts))
+	for i, s := range starts {
+		end := len(text)
+		if i+1 < len(starts) {
+			end = starts[i+1].offset
+		}
+
+		sections[i] = Section{
+			Number: s.number,
+			Title:  s.title,
+			ID:     "section-" + strconv.Itoa(s.number),
+			Text:   text[s.offset:end],
+		}
+	}
+
+	return text[:starts[0].offset], sections
+}
+
+// sectionTitle is the first sentence of a heading, without its period.
+func sectionTitle(heading string) string {
+	if i := strings.Index(heading, ". "); i != -1 {
+		heading = heading[:i]
+	}
+
+	return strings.TrimSuffix(strings.TrimSpace(heading), ".")
+}
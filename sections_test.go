@@ -0,0 +1,91 @@
+package ynal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSections(t *testing.T) {
+	text := `Preamble.
+
+  0. Definitions.
+
+  Words mean things.
+
+  1. Grant of License. You may do things, if:
+
+    2.  a numbered list item after a blank line
+
+  2. No Warranty.
+Still section two.
+`
+
+	preamble, sections := Sections(text)
+
+	if preamble != "Preamble.\n\n" {
+		t.Fatalf("unexpected preamble: %q", preamble)
+	}
+
+	if len(sections) != 3 {
+		t.Fatalf("expected 3 sections, got %+v", sections)
+	}
+
+	for i, want := range []struct {
+		id    string
+		title string
+	}{
+		{id: "section-0", title: "Definitions"},
+		{id: "section-1", title: "Grant of License"},
+		{id: "section-2", title: "No Warranty"},
+	} {
+		if sections[i].Number != i || sections[i].ID != want.id || sections[i].Title != want.title {
+			t.Errorf("unexpected section %d: %+v", i, sections[i])
+		}
+	}
+
+	if !strings.Contains(sections[1].Text, "a numbered list item") {
+		t.Errorf("expected the list item to stay in section 1, got %q", sections[1].Text)
+	}
+
+	joined := preamble
+	for _, s := range sections {
+		joined += s.Text
+	}
+
+	if joined != text {
+		t.Fatalf("expected the sections to make up the text, got %q", joined)
+	}
+}
+
+func TestSectionsNone(t *testing.T) {
+	for _, text := range []string{
+		"no sections at all\n",
+		"1. Only one section.\n\nThat's it.\n",
+		"3. Out of order.\n\n1. Numbering.\n",
+	} {
+		preamble, sections := Sections(text)
+		if preamble != text || sections != nil {
+			t.Errorf("expected no sections in %q, got %+v", text, sections)
+		}
+	}
+}
+
+func TestSectionsEmbedded(t *testing.T) {
+	licenses, err := Embedded()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	counts := map[string]int{"gpl_3": 18, "agpl_3": 18, "apache_2": 9, "bsd_3": 0, "mit": 0}
+
+	for id, want := range counts {
+		l, ok := FindLicense(licenses, id)
+		if !ok {
+			t.Fatalf("no such license: %s", id)
+		}
+
+		if _, sections := Sections(l.Text); len(sections) != want {
+			t.Errorf("expected %d sections in %s, got %d", want, id, len(sections))
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package ynal
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// shingleSize is how many words make up a shingle. Three is long enough that
+// shingles say something about word order, and short enough that a changed
+// word only disturbs a few of them.
+const shingleSize = 3
+
+// word matches the words text is compared by, ignoring punctuation, case, and
+// layout.
+var word = regexp.MustCompile(`[a-z0-9]+`)
+
+// Match is how similar some text is to a license, from 0 (nothing in common)
+// to 1 (the same, give or take punctuation, case, and copyright notices).
+type Match struct {
+	License LicenseData
+	Score   float64
+}
+
+// Matcher scores text against every license in a catalog.
+type Matcher struct {
+	licenses []LicenseData
+	shingles []map[string]bool
+}
+
+// NewMatcher shingles every license up front, so scoring only has to shingle
+// the text being matched.
+func NewMatcher(licenses []LicenseData) *Matcher {
+	m := &Matcher{licenses: licenses}
+	for _, l := range licenses {
+		m.shingles = append(m.shingles, shingle(l.Text))
+	}
+
+	return m
+}
+
+// Score returns how similar text is to every license, most similar first,
+// with ties in catalog order. Similarity is the Sørensen–Dice coefficient of
+// the two texts' sets of shingles: runs of words, once they're normalized.
+func (m *Matcher) Score(text string) []Match {
+	s := shingle(text)
+	matches := []Match{}
+
+	for i, l := range m.licenses {
+		matches = append(matches, Match{License: l, Score: dice(s, m.shingles[i])})
+	}
+
+	slices.SortStableFunc(matches, func(a, b Match) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	return matches
+}
+
+// Words returns the words of text as matching sees them: its Normalize form,
+// without punctuation.
+func Words(text string) []string {
+	return word.FindAllString(Normalize(text), -1)
+}
+
+// shingle returns the set of runs of shingleSize words in text. Text too short
+// for even one is a single shingle of all its words.
+func shingle(text string) map[string]bool {
+	words := Words(text)
+	set := map[string]bool{}
+
+	if len(words) > 0 && len(words) < shingleSize {
+		set[strings.Join(words, " ")] = true
+	}
+
+	for i := 0; i+shingleSize <= len(words); i++ {
+		set[strings.Join(words[i:i+shingleSize], " ")] = true
+	}
+
+	return set
+}
+
+func dice(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for s := range a {
+		if b[s] {
+			shared++
+		}
+	}
+
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
@@ -0,0 +1,92 @@
+package ynal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	licenses, err := Embedded()
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	mit, _ := FindLicense(licenses, "mit")
+	m := NewMatcher(licenses)
+
+	tt := []struct {
+		name     string
+		text     string
+		expected string
+		min      float64
+		max      float64
+	}{
+		{
+			name:     "exact",
+			text:     mit.Text,
+			expected: "mit",
+			min:      1,
+			max:      1,
+		},
+		{
+			name:     "reflowed onto one line",
+			text:     strings.ToUpper(strings.ReplaceAll(mit.Text, "\n", " ")),
+			expected: "mit",
+			min:      0.95,
+			max:      1,
+		},
+		{
+			name:     "with a different copyright",
+			text:     "// Copyright (c) 2024 Someone Else\n" + strings.SplitN(mit.Text, "\n", 2)[1],
+			expected: "mit",
+			min:      1,
+			max:      1,
+		},
+		{
+			name:     "modified",
+			text:     strings.Replace(mit.Text, "The above copyright notice and this permission notice shall be included in all copies or substantial portions of the Software.", "", 1),
+			expected: "mit",
+			min:      0.7,
+			max:      0.99,
+		},
+		{
+			name: "unrelated",
+			text: "Four score and seven years ago our fathers brought forth on this continent a new nation.",
+			max:  0.05,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			matches := m.Score(tc.text)
+
+			if len(matches) != len(licenses) {
+				t.Fatalf("expected a score for every license, got %d", len(matches))
+			}
+
+			for i := 1; i < len(matches); i++ {
+				if matches[i].Score > matches[i-1].Score {
+					t.Fatalf("expected matches ranked by score, got %+v", matches)
+				}
+			}
+
+			top := matches[0]
+			if tc.expected != "" && top.License.ID != tc.expected {
+				t.Errorf("expected %s to match best, got %s (%f)", tc.expected, top.License.ID, top.Score)
+			}
+
+			if top.Score < tc.min || top.Score > tc.max {
+				t.Errorf("expected a top score in [%f, %f], got %f", tc.min, tc.max, top.Score)
+			}
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := Words("Copyright (c) 2024 Someone\n\nPermission is hereby granted, free of charge...")
+	expected := []string{"permission", "is", "hereby", "granted", "free", "of", "charge"}
+
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,370 @@
+package spdx
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+// Expression is a parsed SPDX license expression, like
+// "MIT OR (Apache-2.0 WITH LLVM-exception)". It's either a single license,
+// possibly with an exception, or two expressions joined by AND or OR.
+type Expression struct {
+	// License is the license ID, for a single license.
+	License string
+
+	// OrLater is set for licenses written with a trailing +.
+	OrLater bool
+
+	// Exception is the exception after WITH, if any.
+	Exception string
+
+	// Op is "AND" or "OR" for a compound expression, which has Left and Right
+	// set instead of License.
+	Op    string
+	Left  *Expression
+	Right *Expression
+}
+
+// idPattern matches license and exception IDs, including LicenseRefs, which
+// may be qualified with a DocumentRef, and ynal IDs like apache_2.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]+$`)
+
+// Parse parses an SPDX license expression. Operators may be in any case, and
+// AND binds more tightly than OR.
+func Parse(s string) (*Expression, error) {
+	p := &parser{tokens: tokenize(s)}
+	if len(p.tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q", tok)
+	}
+
+	return e, nil
+}
+
+// tokenize splits s into parentheses and words.
+func tokenize(s string) []string {
+	tokens := []string{}
+
+	for _, field := range strings.Fields(s) {
+		for field != "" {
+			i := strings.IndexAny(field, "()")
+			switch {
+			case i == -1:
+				tokens = append(tokens, field)
+				field = ""
+			case i == 0:
+				tokens = append(tokens, field[:1])
+				field = field[1:]
+			default:
+				tokens = append(tokens, field[:i])
+				field = field[i:]
+			}
+		}
+	}
+
+	return tokens
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) next() (string, bool) {
+	tok, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+
+	return tok, ok
+}
+
+// operator reports whether the next token is the given operator.
+func (p *parser) operator(op string) bool {
+	tok, ok := p.peek()
+	return ok && strings.EqualFold(tok, op)
+}
+
+func (p *parser) parseOr() (*Expression, error) {
+	return p.parseBinary("OR", p.parseAnd)
+}
+
+func (p *parser) parseAnd() (*Expression, error) {
+	return p.parseBinary("AND", p.parseTerm)
+}
+
+func (p *parser) parseBinary(op string, operand func() (*Expression, error)) (*Expression, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.operator(op) {
+		p.next()
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &Expression{Op: op, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseTerm() (*Expression, error) {
+	tok, ok := p.next()
+	if !ok {
+		return nil, errors.New("expression ends too soon")
+	}
+
+	if tok == "(" {
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok, _ := p.next(); tok != ")" {
+			return nil, errors.New("missing )")
+		}
+
+		return e, nil
+	}
+
+	if tok == ")" || isOperator(tok) {
+		return nil, fmt.Errorf("expected a license, got %q", tok)
+	}
+
+	e := &Expression{License: tok}
+	if id, plus := strings.CutSuffix(tok, "+"); plus {
+		e.License = id
+		e.OrLater = true
+	}
+
+	if !idPattern.MatchString(e.License) {
+		return nil, fmt.Errorf("invalid license ID: %q", tok)
+	}
+
+	if p.operator("WITH") {
+		p.next()
+
+		exception, ok := p.next()
+		if !ok || exception == "(" || exception == ")" || isOperator(exception) || !idPattern.MatchString(exception) {
+			return nil, fmt.Errorf("expected an exception after %s WITH", tok)
+		}
+
+		e.Exception = exception
+	}
+
+	return e, nil
+}
+
+func isOperator(tok string) bool {
+	return strings.EqualFold(tok, "AND") || strings.EqualFold(tok, "OR") || strings.EqualFold(tok, "WITH")
+}
+
+// String returns the expression in normal form: operators in upper case, one
+// space between everything, and only the parentheses that are needed.
+func (e *Expression) String() string {
+	if e.Op == "" {
+		s := e.License
+		if e.OrLater {
+			s += "+"
+		}
+
+		if e.Exception != "" {
+			s += " WITH " + e.Exception
+		}
+
+		return s
+	}
+
+	return e.operand(e.Left) + " " + e.Op + " " + e.operand(e.Right)
+}
+
+// operand formats a side of a compound expression, in parentheses if it's an
+// OR inside an AND.
+func (e *Expression) operand(side *Expression) string {
+	if e.Op == "AND" && side.Op == "OR" {
+		return "(" + side.String() + ")"
+	}
+
+	return side.String()
+}
+
+// Licenses returns every single-license expression in e, left to right.
+func (e *Expression) Licenses() []*Expression {
+	if e.Op == "" {
+		return []*Expression{e}
+	}
+
+	return append(e.Left.Licenses(), e.Right.Licenses()...)
+}
+
+// Resolve matches every license in e against licenses, by SPDX identifier or
+// ynal ID and ignoring case, and rewrites it to the matching license's SPDX
+// identifier. It returns the licenses found, in order and without repeats,
+// and the IDs that matched nothing.
+func (e *Expression) Resolve(licenses []ynal.LicenseData) ([]ynal.LicenseData, []string) {
+	found := []ynal.LicenseData{}
+	unknown := []string{}
+
+	add := func(l ynal.LicenseData) {
+		if !slices.ContainsFunc(found, func(f ynal.LicenseData) bool { return f.ID == l.ID }) {
+			found = append(found, l)
+		}
+	}
+
+	for _, leaf := range e.Licenses() {
+		// deprecated IDs like GPL-2.0+ are licenses of their own
+		if leaf.OrLater {
+			if l, ok := Lookup(licenses, leaf.License+"+"); ok {
+				leaf.License = strings.TrimSuffix(l.SPDXID(), "+")
+				add(l)
+				continue
+			}
+		}
+
+		l, ok := Lookup(licenses, leaf.License)
+		if !ok {
+			unknown = append(unknown, leaf.License)
+			continue
+		}
+
+		leaf.License = l.SPDXID()
+		add(l)
+	}
+
+	return found, unknown
+}
+
+// ResolveExceptions does for the exceptions in e what Resolve does for its
+// licenses, matching them against exceptions.
+func (e *Expression) ResolveExceptions(exceptions []ynal.LicenseData) ([]ynal.LicenseData, []string) {
+	found := []ynal.LicenseData{}
+	unknown := []string{}
+
+	for _, leaf := range e.Licenses() {
+		if leaf.Exception == "" {
+			continue
+		}
+
+		x, ok := Lookup(exceptions, leaf.Exception)
+		if !ok {
+			unknown = append(unknown, leaf.Exception)
+			continue
+		}
+
+		leaf.Exception = x.SPDXID()
+		if !slices.ContainsFunc(found, func(f ynal.LicenseData) bool { return f.ID == x.ID }) {
+			found = append(found, x)
+		}
+	}
+
+	return found, unknown
+}
+
+// Lookup finds a license by SPDX identifier or ynal ID, ignoring case.
+func Lookup(licenses []ynal.LicenseData, id string) (ynal.LicenseData, bool) {
+	for _, l := range licenses {
+		if strings.EqualFold(l.SPDXID(), id) || strings.EqualFold(l.ID, id) {
+			return l, true
+		}
+	}
+
+	return ynal.LicenseData{}, false
+}
+
+// Compatibility decides whether the licenses e names can be used together.
+// Licenses joined by AND have to be compatible in one direction or the other,
+// and for OR, the best choice wins. It returns the verdict along with the
+// checks that decided it. Call Resolve first so the licenses are named by
+// their SPDX identifiers; exceptions only ever add permissions, so they're
+// ignored.
+func (e *Expression) Compatibility(c *ynal.Compatibility) (ynal.Verdict, []ynal.CompatibilityRule) {
+	best := ynal.Verdict("")
+	var checks []ynal.CompatibilityRule
+
+	for _, choice := range e.choices() {
+		v, rules := combined(c, choice)
+		if best == "" || v.Better(best) {
+			best, checks = v, rules
+		}
+	}
+
+	return best, checks
+}
+
+// choices returns every set of licenses that satisfies e, by picking one side
+// of each OR.
+func (e *Expression) choices() [][]string {
+	switch e.Op {
+	case "OR":
+		return append(e.Left.choices(), e.Right.choices()...)
+	case "AND":
+		choices := [][]string{}
+		for _, l := range e.Left.choices() {
+			for _, r := range e.Right.choices() {
+				choices = append(choices, append(slices.Clone(l), r...))
+			}
+		}
+
+		return choices
+	default:
+		id := e.License
+		if e.OrLater {
+			id += "+"
+		}
+
+		return [][]string{{id}}
+	}
+}
+
+// combined checks every pair of licenses, which have to be used together. The
+// worst pair decides.
+func combined(c *ynal.Compatibility, licenses []string) (ynal.Verdict, []ynal.CompatibilityRule) {
+	verdict := ynal.Compatible
+	rules := []ynal.CompatibilityRule{}
+
+	for i, a := range licenses {
+		for _, b := range licenses[i+1:] {
+			if a == b {
+				continue
+			}
+
+			r := c.Check(a, b)
+			if back := c.Check(b, a); back.Verdict.Better(r.Verdict) {
+				r = back
+			}
+
+			rules = append(rules, r)
+			if verdict.Better(r.Verdict) {
+				verdict = r.Verdict
+			}
+		}
+	}
+
+	return verdict, rules
+}
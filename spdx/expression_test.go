@@ -0,0 +1,148 @@
+package spdx
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestParse(t *testing.T) {
+	tt := []struct {
+		expr     string
+		expected string
+	}{
+		{"MIT", "MIT"},
+		{"mit or Apache-2.0", "mit OR Apache-2.0"},
+		{"MIT OR (Apache-2.0 WITH LLVM-exception)", "MIT OR Apache-2.0 WITH LLVM-exception"},
+		{"(MIT OR Apache-2.0) AND BSD-3-Clause", "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+		{"MIT OR Apache-2.0 AND BSD-3-Clause", "MIT OR Apache-2.0 AND BSD-3-Clause"},
+		{"((MIT))", "MIT"},
+		{"GPL-2.0+ WITH Classpath-exception-2.0", "GPL-2.0+ WITH Classpath-exception-2.0"},
+		{"LicenseRef-Custom AND DocumentRef-spdx:LicenseRef-Other", "LicenseRef-Custom AND DocumentRef-spdx:LicenseRef-Other"},
+	}
+
+	for _, tc := range tt {
+		e, err := Parse(tc.expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", tc.expr, err)
+			continue
+		}
+
+		if got := e.String(); got != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.expr, tc.expected, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"MIT OR",
+		"OR MIT",
+		"(MIT",
+		"MIT)",
+		"MIT Apache-2.0",
+		"MIT WITH",
+		"MIT WITH (LLVM-exception)",
+		"M!T",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	mit := ynal.NewLicense("MIT", "mit\n")
+	mit.SPDX = "MIT"
+
+	apache := ynal.NewLicense("Apache_2", "apache\n")
+	apache.SPDX = "Apache-2.0"
+
+	gpl := ynal.NewLicense("GPL-2.0+", "gpl\n")
+	gpl.SPDX = "GPL-2.0+"
+
+	licenses := []ynal.LicenseData{mit, apache, gpl, ynal.NewLicense("GLWTSPL", "glwtspl\n")}
+
+	e, err := Parse("mit AND (apache_2 OR apache-2.0) AND gpl-2.0+ AND licenseref-glwtspl AND Nope-1.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	found, unknown := e.Resolve(licenses)
+
+	ids := []string{}
+	for _, l := range found {
+		ids = append(ids, l.ID)
+	}
+
+	if !slices.Equal(ids, []string{"mit", "apache_2", "gpl-2.0+", "glwtspl"}) {
+		t.Fatalf("unexpected licenses: %v", ids)
+	}
+
+	if !slices.Equal(unknown, []string{"Nope-1.0"}) {
+		t.Fatalf("unexpected unknown IDs: %v", unknown)
+	}
+
+	expected := "MIT AND (Apache-2.0 OR Apache-2.0) AND GPL-2.0+ AND LicenseRef-GLWTSPL AND Nope-1.0"
+	if got := e.String(); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestResolveExceptions(t *testing.T) {
+	classpath := ynal.NewLicense("Classpath-exception-2.0", "classpath\n")
+	classpath.SPDX = classpath.Title
+
+	e, err := Parse("GPL-2.0 WITH classpath-exception-2.0 OR MIT WITH Nope-exception")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	found, unknown := e.ResolveExceptions([]ynal.LicenseData{classpath})
+
+	if len(found) != 1 || found[0].ID != "classpath-exception-2.0" || !slices.Equal(unknown, []string{"Nope-exception"}) {
+		t.Fatalf("unexpected exceptions: %+v, unknown: %v", found, unknown)
+	}
+
+	expected := "GPL-2.0 WITH Classpath-exception-2.0 OR MIT WITH Nope-exception"
+	if got := e.String(); got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestCompatibility(t *testing.T) {
+	c, err := ynal.EmbeddedCompatibility()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tt := []struct {
+		expr     string
+		expected ynal.Verdict
+		checks   int
+	}{
+		{"MIT", ynal.Compatible, 0},
+		{"MIT AND GPL-3.0-or-later", ynal.Compatible, 1},
+		{"GPL-3.0-or-later AND MIT", ynal.Compatible, 1},
+		{"GPL-2.0-only AND Apache-2.0", ynal.Incompatible, 1},
+		{"GPL-2.0-only AND (Apache-2.0 OR MIT)", ynal.Compatible, 1},
+		{"MPL-2.0 AND Apache-2.0 AND BSD-3-Clause", ynal.Compatible, 3},
+		{"Apache-2.0 AND GPL-2.0-or-later", ynal.Conditional, 1},
+		{"MIT AND Nope-1.0", ynal.Unknown, 1},
+		{"GPL-2.0-only WITH Classpath-exception-2.0 AND GPL-3.0-or-later", ynal.Incompatible, 1},
+	}
+
+	for _, tc := range tt {
+		e, err := Parse(tc.expr)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %s", tc.expr, err)
+		}
+
+		v, checks := e.Compatibility(c)
+		if v != tc.expected || len(checks) != tc.checks {
+			t.Errorf("%q: expected %s with %d checks, got %s with %+v", tc.expr, tc.expected, tc.checks, v, checks)
+		}
+	}
+}
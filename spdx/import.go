@@ -0,0 +1,226 @@
+package spdx
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+// Import is a license to copy from the SPDX list into a license directory.
+type Import struct {
+	// ID is the license's SPDX identifier, like "MPL-2.0".
+	ID string
+
+	// Title is the name of the file it's written to, and so the path it's
+	// served under. Defaults to ID.
+	Title string
+}
+
+func (i Import) title() string {
+	if i.Title != "" {
+		return i.Title
+	}
+
+	return i.ID
+}
+
+// titlePattern is what a title has to look like to make a sensible URL.
+var titlePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// placeholders are the ways SPDX texts ask for a year or a copyright holder,
+// and the placeholders ynal uses for them, which Substitute and `ynal init`
+// know how to fill in.
+var placeholders = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`(?i)<year>|\[yyyy\]|\[year\]`), "<YEAR>"},
+	{regexp.MustCompile(`(?i)<copyright holders?>|<owner>|<name of author>|\[name of copyright owner\]|\[fullname\]`), "<COPYRIGHT HOLDER>"},
+}
+
+// Normalize tidies a license text from the SPDX list the way the embedded
+// licenses are kept: Unix line endings, no trailing whitespace, a single
+// newline at the end, and ynal's placeholders.
+func Normalize(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	text = strings.Trim(strings.Join(lines, "\n"), "\n")
+	if text == "" {
+		return ""
+	}
+
+	for _, p := range placeholders {
+		text = p.pattern.ReplaceAllString(text, p.replacement)
+	}
+
+	return text + "\n"
+}
+
+// Import fetches the given licenses from the SPDX list, normalizes them, and
+// writes them into dir alongside whatever is there already. Once they're
+// written, dir is loaded back to check they came out right.
+func (s *Syncer) Import(ctx context.Context, dir string, imports []Import) error {
+	base, err := url.Parse(s.listURL())
+	if err != nil {
+		return fmt.Errorf("could not parse list URL: %w", err)
+	}
+
+	list := licenseList{}
+	if err := s.getJSON(ctx, base.String(), &list); err != nil {
+		return fmt.Errorf("could not fetch license list: %w", err)
+	}
+
+	existing, err := ynal.LoadLicenses(os.DirFS(dir))
+	if err != nil {
+		return fmt.Errorf("could not load %s: %w", dir, err)
+	}
+
+	entries := map[string]licenseEntry{}
+	ids := map[string]bool{}
+	for _, e := range list.Licenses {
+		entries[strings.ToLower(e.ID)] = e
+		ids[e.ID] = true
+	}
+
+	errs := []error{}
+
+	for _, imp := range imports {
+		if !titlePattern.MatchString(imp.title()) {
+			errs = append(errs, fmt.Errorf("%s: not a usable title: %q", imp.ID, imp.title()))
+			continue
+		}
+
+		e, ok := entries[strings.ToLower(imp.ID)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: not in the SPDX license list", imp.ID))
+			continue
+		}
+
+		if e.Deprecated {
+			e.successor = successorOf(e.ID, ids)
+		}
+
+		details, err := s.fetchDetails(ctx, base, e)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		m := metadataFor(e, details)
+		m.Header = Normalize(m.Header)
+		m.Successor = successorTitle(m.Successor, imports, existing)
+
+		if err := writeLicense(filepath.Join(dir, imp.title()+".txt"), Normalize(details.Text), m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	return validateImports(dir, imports)
+}
+
+// successorTitle finds the title of the license with the SPDX identifier id,
+// among those being imported or already there. Successors that are in neither
+// are left out, since there's nothing to point at.
+func successorTitle(id string, imports []Import, existing []ynal.LicenseData) string {
+	if id == "" {
+		return ""
+	}
+
+	for _, imp := range imports {
+		if strings.EqualFold(imp.ID, id) {
+			return imp.title()
+		}
+	}
+
+	if l, ok := Lookup(existing, id); ok {
+		return l.Title
+	}
+
+	return ""
+}
+
+// validateImports loads dir and checks every import is there, with a text, its
+// SPDX identifier, and a successor that exists if it has one.
+func validateImports(dir string, imports []Import) error {
+	licenses, err := ynal.LoadLicenses(os.DirFS(dir))
+	if err != nil {
+		return fmt.Errorf("could not load %s: %w", dir, err)
+	}
+
+	errs := []error{}
+
+	for _, imp := range imports {
+		l, ok := ynal.FindLicense(licenses, strings.ToLower(imp.title()))
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: missing from %s after import", imp.ID, dir))
+			continue
+		}
+
+		if strings.TrimSpace(l.Text) == "" {
+			errs = append(errs, fmt.Errorf("%s: license text is empty", imp.ID))
+		}
+
+		if !strings.EqualFold(l.SPDX, imp.ID) {
+			errs = append(errs, fmt.Errorf("%s: imported with SPDX identifier %q", imp.ID, l.SPDX))
+		}
+
+		if l.Successor != "" {
+			if _, ok := ynal.FindLicense(licenses, l.Successor); !ok {
+				errs = append(errs, fmt.Errorf("%s: successor %s isn't in %s", imp.ID, l.Successor, dir))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ReadManifest reads a list of licenses to import, one per line as an SPDX
+// identifier optionally followed by a title. Blank lines and lines starting
+// with # are ignored.
+func ReadManifest(r io.Reader) ([]Import, error) {
+	imports := []Import{}
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected an SPDX identifier and optionally a title, got %q", n, line)
+		}
+
+		imp := Import{ID: fields[0]}
+		if len(fields) == 2 {
+			imp.Title = fields[1]
+		}
+
+		imports = append(imports, imp)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("could not read manifest: %w", err)
+	}
+
+	return imports, nil
+}
@@ -0,0 +1,95 @@
+package spdx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestNormalize(t *testing.T) {
+	tt := map[string]string{
+		"Copyright (c) <year> <copyright holders>\r\n\r\nPermission...  \r\n\r\n": "Copyright (c) <YEAR> <COPYRIGHT HOLDER>\n\nPermission...\n",
+		"\n\nCopyright [yyyy] [name of copyright owner]\n":                        "Copyright <YEAR> <COPYRIGHT HOLDER>\n",
+		"Copyright (C) <year>  <name of author>\t\n":                              "Copyright (C) <YEAR>  <COPYRIGHT HOLDER>\n",
+		"\n \n": "",
+	}
+
+	for in, expected := range tt {
+		if got := Normalize(in); got != expected {
+			t.Errorf("Normalize(%q): expected %q, got %q", in, expected, got)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	srv := testServer(t, false)
+	dir := t.TempDir()
+
+	// an existing license, which the import must leave alone
+	if err := os.WriteFile(filepath.Join(dir, "Zlib.txt"), []byte("zlib\n"), 0644); err != nil {
+		t.Fatalf("could not write license: %s", err)
+	}
+
+	s := &Syncer{ListURL: srv.URL + "/licenses.json"}
+
+	imports := []Import{{ID: "mit", Title: "MIT"}, {ID: "GPL-2.0+", Title: "GPL_2_plus"}, {ID: "GPL-2.0-or-later"}}
+	if err := s.Import(context.Background(), dir, imports); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	licenses, err := ynal.LoadLicenses(os.DirFS(dir))
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	if len(licenses) != 4 {
+		t.Fatalf("expected 4 licenses, got %d", len(licenses))
+	}
+
+	if l, ok := ynal.FindLicense(licenses, "mit"); !ok || l.Text != "MIT text\n" || l.SPDX != "MIT" || l.Header != "MIT header\n" {
+		t.Fatalf("unexpected license: %+v", l)
+	}
+
+	if l, ok := ynal.FindLicense(licenses, "gpl_2_plus"); !ok || !l.Deprecated || l.Successor != "gpl-2.0-or-later" {
+		t.Fatalf("expected gpl_2_plus to be deprecated in favor of gpl-2.0-or-later, got: %+v", l)
+	}
+
+	if _, ok := ynal.FindLicense(licenses, "zlib"); !ok {
+		t.Fatalf("expected the existing license to be kept")
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	srv := testServer(t, false)
+	s := &Syncer{ListURL: srv.URL + "/licenses.json"}
+
+	err := s.Import(context.Background(), t.TempDir(), []Import{{ID: "Nope-1.0"}, {ID: "MIT", Title: "../MIT"}})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	for _, want := range []string{"Nope-1.0: not in the SPDX license list", `MIT: not a usable title: "../MIT"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	imports, err := ReadManifest(strings.NewReader("# licenses\n\nMPL-2.0 MPL_2\nISC\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(imports) != 2 || imports[0] != (Import{ID: "MPL-2.0", Title: "MPL_2"}) || imports[1] != (Import{ID: "ISC"}) {
+		t.Fatalf("unexpected imports: %+v", imports)
+	}
+
+	if _, err := ReadManifest(strings.NewReader("MPL-2.0 MPL 2\n")); err == nil {
+		t.Fatalf("expected an error for a title with spaces")
+	}
+}
@@ -0,0 +1,410 @@
+// Package spdx mirrors the official SPDX license list onto disk so ynal can
+// serve the full catalog rather than just the embedded licenses, and parses
+// SPDX license expressions.
+package spdx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+// DefaultListURL is the machine-readable SPDX license list.
+const DefaultListURL = "https://spdx.org/licenses/licenses.json"
+
+// defaultConcurrency bounds how many license texts are fetched at once, to be
+// polite to spdx.org.
+const defaultConcurrency = 8
+
+// families assigns licenses to a family by the start of their SPDX ID, since
+// the license list doesn't say.
+var families = []struct {
+	prefix string
+	family string
+}{
+	{"AGPL-", "AGPL"},
+	{"GPL-", "GPL"},
+	{"LGPL-", "LGPL"},
+	{"0BSD", "BSD"},
+	{"BSD-", "BSD"},
+	{"CC-", "CC"},
+	{"CC0-", "CC"},
+	{"Apache-", "Apache"},
+	{"MPL-", "MPL"},
+	{"EUPL-", "EUPL"},
+}
+
+// renamed are deprecated IDs whose replacement can't be worked out from the
+// ID alone.
+var renamed = map[string]string{
+	"BSD-2-Clause-FreeBSD": "BSD-2-Clause",
+	"BSD-2-Clause-NetBSD":  "BSD-2-Clause",
+	"StandardML-NJ":        "SMLNJ",
+	"bzip2-1.0.5":          "bzip2-1.0.6",
+}
+
+// successorOf returns the ID that replaced the deprecated license id, if it's
+// in ids. The GNU licenses were split into -only and -or-later variants,
+// where a trailing + meant "or later".
+func successorOf(id string, ids map[string]bool) string {
+	successor, ok := renamed[id]
+	if !ok {
+		if base, plus := strings.CutSuffix(id, "+"); plus {
+			successor = base + "-or-later"
+		} else {
+			successor = id + "-only"
+		}
+	}
+
+	if !ids[successor] {
+		return ""
+	}
+
+	return successor
+}
+
+// versionPattern finds the version in an ID like GPL-2.0-only or CC-BY-4.0.
+var versionPattern = regexp.MustCompile(`-(\d+(?:\.\d+)+)(?:[-+]|$)`)
+
+// versionOf returns the version in an SPDX ID, if it has one.
+func versionOf(id string) string {
+	if m := versionPattern.FindStringSubmatch(id); m != nil {
+		return m[1]
+	}
+
+	return ""
+}
+
+func familyOf(id string) string {
+	for _, f := range families {
+		if strings.HasPrefix(id, f.prefix) {
+			return f.family
+		}
+	}
+
+	return ""
+}
+
+type licenseList struct {
+	Version  string         `json:"licenseListVersion"`
+	Licenses []licenseEntry `json:"licenses"`
+}
+
+type licenseEntry struct {
+	ID         string `json:"licenseId"`
+	Name       string `json:"name"`
+	DetailsURL string `json:"detailsUrl"`
+	Deprecated bool   `json:"isDeprecatedLicenseId"`
+
+	// successor is worked out from the rest of the list, see successorOf
+	successor string
+}
+
+type licenseDetails struct {
+	ID     string `json:"licenseId"`
+	Text   string `json:"licenseText"`
+	Header string `json:"standardLicenseHeader"`
+}
+
+// Syncer downloads the SPDX license list into Dir as one <id>.txt file per
+// license, plus its metadata, which ynal.LoadLicenses can read directly.
+type Syncer struct {
+	// ListURL is where the license list is fetched from. Defaults to
+	// DefaultListURL.
+	ListURL string
+
+	// Dir is where license texts are written. It is replaced wholesale on
+	// every successful sync, so nothing else should live there.
+	Dir string
+
+	// Client is used for every request. Defaults to a client with a
+	// reasonable timeout.
+	Client *http.Client
+
+	mu      sync.Mutex
+	syncing bool
+	failed  error
+}
+
+func (s *Syncer) listURL() string {
+	if s.ListURL != "" {
+		return s.ListURL
+	}
+
+	return DefaultListURL
+}
+
+func (s *Syncer) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// Load reads the licenses from the last successful sync.
+func (s *Syncer) Load() ([]ynal.LicenseData, error) {
+	return ynal.LoadLicenses(os.DirFS(s.Dir))
+}
+
+// Sync fetches the whole license list and, only if every license was fetched,
+// swaps it into Dir. A failed sync leaves the previous one untouched.
+func (s *Syncer) Sync(ctx context.Context) error {
+	base, err := url.Parse(s.listURL())
+	if err != nil {
+		return fmt.Errorf("could not parse list URL: %w", err)
+	}
+
+	list := licenseList{}
+	if err := s.getJSON(ctx, base.String(), &list); err != nil {
+		return fmt.Errorf("could not fetch license list: %w", err)
+	}
+
+	if len(list.Licenses) == 0 {
+		return errors.New("license list is empty")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Dir), 0755); err != nil {
+		return fmt.Errorf("could not create parent directory: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(s.Dir), ".spdx-sync-*")
+	if err != nil {
+		return fmt.Errorf("could not create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	entries := make(chan licenseEntry)
+	errs := make(chan error, len(list.Licenses))
+
+	wg := sync.WaitGroup{}
+	for range defaultConcurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for e := range entries {
+				errs <- s.fetchLicense(ctx, base, e, tmp)
+			}
+		}()
+	}
+
+	ids := map[string]bool{}
+	for _, e := range list.Licenses {
+		ids[e.ID] = true
+	}
+
+	for _, e := range list.Licenses {
+		if e.Deprecated {
+			e.successor = successorOf(e.ID, ids)
+		}
+
+		entries <- e
+	}
+	close(entries)
+
+	wg.Wait()
+	close(errs)
+
+	all := []error{}
+	for err := range errs {
+		if err != nil {
+			all = append(all, err)
+		}
+	}
+
+	if err := errors.Join(all...); err != nil {
+		return err
+	}
+
+	return replaceDir(tmp, s.Dir)
+}
+
+func (s *Syncer) fetchLicense(ctx context.Context, base *url.URL, e licenseEntry, dir string) error {
+	details, err := s.fetchDetails(ctx, base, e)
+	if err != nil {
+		return err
+	}
+
+	return writeLicense(filepath.Join(dir, e.ID+".txt"), details.Text, metadataFor(e, details))
+}
+
+// fetchDetails fetches the text and header of the license e lists.
+func (s *Syncer) fetchDetails(ctx context.Context, base *url.URL, e licenseEntry) (licenseDetails, error) {
+	if e.ID == "" || strings.ContainsAny(e.ID, `/\`) || strings.HasPrefix(e.ID, ".") {
+		return licenseDetails{}, fmt.Errorf("refusing suspicious license ID: %q", e.ID)
+	}
+
+	ref, err := url.Parse(e.DetailsURL)
+	if err != nil {
+		return licenseDetails{}, fmt.Errorf("%s: could not parse details URL: %w", e.ID, err)
+	}
+
+	details := licenseDetails{}
+	if err := s.getJSON(ctx, base.ResolveReference(ref).String(), &details); err != nil {
+		return licenseDetails{}, fmt.Errorf("%s: %w", e.ID, err)
+	}
+
+	if details.Text == "" {
+		return licenseDetails{}, fmt.Errorf("%s: license text is empty", e.ID)
+	}
+
+	return details, nil
+}
+
+func metadataFor(e licenseEntry, details licenseDetails) ynal.Metadata {
+	m := ynal.Metadata{
+		Family:     familyOf(e.ID),
+		Deprecated: e.Deprecated,
+		Successor:  e.successor,
+		SPDX:       e.ID,
+		Header:     details.Header,
+	}
+	if m.Family != "" {
+		m.Version = versionOf(e.ID)
+	}
+
+	return m
+}
+
+// writeLicense writes a license and its metadata the way ynal.LoadLicenses
+// reads them.
+func writeLicense(lpath string, text string, m ynal.Metadata) error {
+	name := strings.TrimSuffix(filepath.Base(lpath), ".txt")
+
+	if err := os.WriteFile(lpath, []byte(text), 0644); err != nil {
+		return fmt.Errorf("%s: could not write license: %w", name, err)
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%s: could not marshal metadata: %w", name, err)
+	}
+
+	if err := os.WriteFile(ynal.MetadataPath(lpath), append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("%s: could not write metadata: %w", name, err)
+	}
+
+	return nil
+}
+
+func (s *Syncer) getJSON(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return fmt.Errorf("could not build request: %w", err)
+	}
+
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("could not fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not fetch %s: %s", u, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("could not decode %s: %w", u, err)
+	}
+
+	return nil
+}
+
+// replaceDir moves src into dst's place, keeping dst intact if that fails.
+func replaceDir(src string, dst string) error {
+	old := dst + ".old"
+
+	if err := os.RemoveAll(old); err != nil {
+		return fmt.Errorf("could not clear %s: %w", old, err)
+	}
+
+	if err := os.Rename(dst, old); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("could not move aside %s: %w", dst, err)
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		os.Rename(old, dst)
+		return fmt.Errorf("could not move sync into place: %w", err)
+	}
+
+	return os.RemoveAll(old)
+}
+
+// Run syncs immediately and then every interval until ctx is done, replacing
+// the licenses in catalog after each successful sync. Failures are logged and
+// the catalog keeps serving whatever it had.
+func (s *Syncer) Run(ctx context.Context, catalog *ynal.Catalog, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.setState(true, nil)
+		err := s.refresh(ctx, catalog)
+		s.setState(false, err)
+
+		if err != nil {
+			log.Printf("spdx sync failed: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Health says whether Run is in the middle of a sync, or its last one failed,
+// while the licenses in the catalog might be out of date.
+func (s *Syncer) Health(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.syncing {
+		return errors.New("syncing")
+	}
+
+	if s.failed != nil {
+		return fmt.Errorf("last sync failed: %w", s.failed)
+	}
+
+	return nil
+}
+
+func (s *Syncer) setState(syncing bool, failed error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.syncing = syncing
+	s.failed = failed
+}
+
+func (s *Syncer) refresh(ctx context.Context, catalog *ynal.Catalog) error {
+	if err := s.Sync(ctx); err != nil {
+		return err
+	}
+
+	licenses, err := s.Load()
+	if err != nil {
+		return fmt.Errorf("could not load synced licenses: %w", err)
+	}
+
+	catalog.Replace(licenses)
+	log.Printf("spdx sync loaded %d licenses", len(licenses))
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package spdx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+func testServer(t *testing.T, failMIT bool) *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/licenses.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"licenseListVersion": "3.99",
+			"licenses": [
+				{"licenseId": "MIT", "name": "MIT License", "detailsUrl": "./MIT.json"},
+				{"licenseId": "0BSD", "name": "BSD Zero Clause License", "detailsUrl": "./0BSD.json"},
+				{"licenseId": "GPL-2.0+", "name": "GNU General Public License v2.0 or later", "detailsUrl": "./0BSD.json", "isDeprecatedLicenseId": true},
+				{"licenseId": "GPL-2.0-or-later", "name": "GNU General Public License v2.0 or later", "detailsUrl": "./0BSD.json"}
+			]
+		}`))
+	})
+
+	mux.HandleFunc("/MIT.json", func(w http.ResponseWriter, r *http.Request) {
+		if failMIT {
+			http.Error(w, "nope", http.StatusInternalServerError)
+			return
+		}
+
+		w.Write([]byte(`{"licenseId": "MIT", "licenseText": "MIT text\n", "standardLicenseHeader": "MIT header\n"}`))
+	})
+
+	mux.HandleFunc("/0BSD.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"licenseId": "0BSD", "licenseText": "0BSD text\n"}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestSync(t *testing.T) {
+	srv := testServer(t, false)
+
+	s := &Syncer{
+		ListURL: srv.URL + "/licenses.json",
+		Dir:     filepath.Join(t.TempDir(), "spdx"),
+	}
+
+	catalog := ynal.NewCatalog(nil)
+
+	if err := s.refresh(context.Background(), catalog); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	l, ok := catalog.Get("mit")
+	if !ok || l.Text != "MIT text\n" || l.URL != "/mit" || l.SPDX != "MIT" || l.Header != "MIT header\n" {
+		t.Fatalf("unexpected license: %+v", l)
+	}
+
+	if l, ok := catalog.Get("0bsd"); !ok || l.Family != "BSD" {
+		t.Fatalf("expected 0bsd in the BSD family, got: %+v", l)
+	}
+
+	if l, ok := catalog.Get("gpl-2.0+"); !ok || !l.Deprecated || l.Successor != "gpl-2.0-or-later" || l.Version != "2.0" {
+		t.Fatalf("expected gpl-2.0+ to be deprecated in favor of gpl-2.0-or-later, got: %+v", l)
+	}
+}
+
+func TestSuccessorOf(t *testing.T) {
+	ids := map[string]bool{"GPL-2.0-only": true, "GPL-2.0-or-later": true, "BSD-2-Clause": true}
+
+	tt := map[string]string{
+		"GPL-2.0":              "GPL-2.0-only",
+		"GPL-2.0+":             "GPL-2.0-or-later",
+		"BSD-2-Clause-FreeBSD": "BSD-2-Clause",
+		"LGPL-2.1":             "",
+		"Nunit":                "",
+	}
+
+	for id, expected := range tt {
+		if got := successorOf(id, ids); got != expected {
+			t.Errorf("%s: expected %q, got %q", id, expected, got)
+		}
+	}
+}
+
+func TestVersionOf(t *testing.T) {
+	tt := map[string]string{
+		"GPL-2.0-only": "2.0",
+		"GPL-2.0+":     "2.0",
+		"Apache-2.0":   "2.0",
+		"CC-BY-4.0":    "4.0",
+		"BSD-3-Clause": "",
+		"0BSD":         "",
+	}
+
+	for id, expected := range tt {
+		if got := versionOf(id); got != expected {
+			t.Errorf("%s: expected %q, got %q", id, expected, got)
+		}
+	}
+}
+
+func TestSyncFailureKeepsPrevious(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "spdx")
+
+	good := &Syncer{ListURL: testServer(t, false).URL + "/licenses.json", Dir: dir}
+	if err := good.Sync(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	bad := &Syncer{ListURL: testServer(t, true).URL + "/licenses.json", Dir: dir}
+	if err := bad.Sync(context.Background()); err == nil {
+		t.Fatalf("expected sync to fail")
+	}
+
+	licenses, err := bad.Load()
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	if len(licenses) != 4 {
+		t.Fatalf("expected previous sync to survive, got %d licenses", len(licenses))
+	}
+}
+
+func TestSyncerHealth(t *testing.T) {
+	s := &Syncer{ListURL: testServer(t, true).URL + "/licenses.json", Dir: filepath.Join(t.TempDir(), "spdx")}
+
+	if err := s.Health(context.Background()); err != nil {
+		t.Fatalf("expected a syncer that hasn't run to be healthy, got: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// with ctx already done, Run syncs once and returns
+	s.Run(ctx, ynal.NewCatalog(nil), time.Hour)
+
+	if err := s.Health(context.Background()); err == nil || !strings.Contains(err.Error(), "last sync failed") {
+		t.Fatalf("expected the failed sync to make it unhealthy, got: %v", err)
+	}
+}
@@ -0,0 +1,366 @@
+// Package sqlitestore keeps licenses in a SQLite database, so licenses and
+// aliases added at runtime persist across restarts without a directory of
+// files to manage.
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/packrat386/ynal"
+	_ "modernc.org/sqlite"
+)
+
+// migrations are applied in order, each exactly once, when a database is
+// opened. The number applied so far is kept in PRAGMA user_version. Only ever
+// append to this list.
+var migrations = []string{
+	`CREATE TABLE licenses (
+		id         TEXT PRIMARY KEY,
+		title      TEXT NOT NULL,
+		text       TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	)`,
+	`CREATE TABLE aliases (
+		alias      TEXT PRIMARY KEY,
+		license_id TEXT NOT NULL REFERENCES licenses (id) ON DELETE CASCADE
+	)`,
+	// acceptances outlive the licenses they're for, since they're a record
+	// of what happened
+	`CREATE TABLE acceptances (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		license_id  TEXT NOT NULL,
+		digest      TEXT NOT NULL,
+		subject     TEXT NOT NULL,
+		accepted_at TEXT NOT NULL,
+		recorded_by TEXT NOT NULL
+	)`,
+	`CREATE INDEX acceptances_subject ON acceptances (subject)`,
+}
+
+// Store is a ynal.LicenseStore backed by SQLite. Every license is kept in
+// memory as well, so reads never touch the database.
+type Store struct {
+	db      *sql.DB
+	catalog *ynal.Catalog
+
+	mu sync.Mutex
+}
+
+// Open opens (creating if needed) the database at path and brings its schema
+// up to date. A newly created database is filled with seed.
+func Open(path string, seed []ynal.LicenseData) (*Store, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("could not open database: %w", err)
+	}
+
+	s := &Store{db: db, catalog: ynal.NewCatalog(nil)}
+
+	if err := s.migrate(seed); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := s.reload(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Health checks the database can still be read.
+func (s *Store) Health(ctx context.Context) error {
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM licenses`).Scan(&n); err != nil {
+		return fmt.Errorf("could not read database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate(seed []ynal.LicenseData) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not migrate database: %w", err)
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err := tx.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("could not read schema version: %w", err)
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this ynal supports (%d)", version, len(migrations))
+	}
+
+	for i, m := range migrations[version:] {
+		if _, err := tx.Exec(m); err != nil {
+			return fmt.Errorf("could not apply migration %d: %w", version+i+1, err)
+		}
+	}
+
+	if version == 0 {
+		for _, l := range seed {
+			if err := putLicense(tx, l.Title, l.Text); err != nil {
+				return fmt.Errorf("could not seed database: %w", err)
+			}
+		}
+	}
+
+	// PRAGMA doesn't take parameters
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", len(migrations))); err != nil {
+		return fmt.Errorf("could not set schema version: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) List() []ynal.LicenseData {
+	return s.catalog.List()
+}
+
+func (s *Store) Get(id string) (ynal.LicenseData, bool) {
+	return s.catalog.Get(id)
+}
+
+// Watch registers fn to be called after every change made through the Store.
+func (s *Store) Watch(fn func([]ynal.LicenseData)) {
+	s.catalog.Watch(fn)
+}
+
+// Put creates or replaces the license with the given title. Its ID is the
+// title lowercased, and it takes over any alias with the same name.
+func (s *Store) Put(title string, text string) (ynal.LicenseData, error) {
+	if err := ynal.ValidateLicense(title, text); err != nil {
+		return ynal.LicenseData{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.update(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM aliases WHERE alias = ?`, strings.ToLower(title)); err != nil {
+			return err
+		}
+
+		return putLicense(tx, title, text)
+	})
+	if err != nil {
+		return ynal.LicenseData{}, fmt.Errorf("could not put license: %w", err)
+	}
+
+	l, _ := s.catalog.Get(strings.ToLower(title))
+	return l, nil
+}
+
+// Delete removes the license with the given ID, along with its aliases,
+// returning ynal.ErrNotFound if there isn't one.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(func(tx *sql.Tx) error {
+		return mustAffect(tx.Exec(`DELETE FROM licenses WHERE id = ?`, id))
+	})
+}
+
+// PutAlias makes alias redirect to the license with the given ID, returning
+// ynal.ErrNotFound if there isn't one.
+func (s *Store) PutAlias(alias string, id string) error {
+	if err := ynal.ValidateLicense(alias, "-"); err != nil || alias != strings.ToLower(alias) {
+		return fmt.Errorf("invalid alias: %q", alias)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.catalog.Get(id); !ok {
+		return ynal.ErrNotFound
+	}
+
+	if _, ok := s.catalog.Get(alias); ok {
+		return fmt.Errorf("alias %q is already a license ID", alias)
+	}
+
+	return s.update(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO aliases (alias, license_id) VALUES (?, ?)
+			ON CONFLICT (alias) DO UPDATE SET license_id = excluded.license_id`,
+			alias, id,
+		)
+		return err
+	})
+}
+
+// DeleteAlias removes alias, returning ynal.ErrNotFound if there isn't one.
+func (s *Store) DeleteAlias(alias string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.update(func(tx *sql.Tx) error {
+		return mustAffect(tx.Exec(`DELETE FROM aliases WHERE alias = ?`, alias))
+	})
+}
+
+func putLicense(tx *sql.Tx, title string, text string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	_, err := tx.Exec(
+		`INSERT INTO licenses (id, title, text, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET title = excluded.title, text = excluded.text, updated_at = excluded.updated_at`,
+		strings.ToLower(title), title, text, now, now,
+	)
+
+	return err
+}
+
+func mustAffect(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return ynal.ErrNotFound
+	}
+
+	return nil
+}
+
+// update runs fn in a transaction and, if it commits, reloads every license.
+func (s *Store) update(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return s.reload()
+}
+
+func (s *Store) reload() error {
+	rows, err := s.db.Query(`SELECT title, text FROM licenses ORDER BY title`)
+	if err != nil {
+		return fmt.Errorf("could not load licenses: %w", err)
+	}
+	defer rows.Close()
+
+	licenses := []ynal.LicenseData{}
+	index := map[string]int{}
+
+	for rows.Next() {
+		var title, text string
+		if err := rows.Scan(&title, &text); err != nil {
+			return fmt.Errorf("could not load licenses: %w", err)
+		}
+
+		l := ynal.NewLicense(title, text)
+		index[l.ID] = len(licenses)
+		licenses = append(licenses, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not load licenses: %w", err)
+	}
+
+	aliases, err := s.db.Query(`SELECT alias, license_id FROM aliases ORDER BY alias`)
+	if err != nil {
+		return fmt.Errorf("could not load aliases: %w", err)
+	}
+	defer aliases.Close()
+
+	for aliases.Next() {
+		var alias, id string
+		if err := aliases.Scan(&alias, &id); err != nil {
+			return fmt.Errorf("could not load aliases: %w", err)
+		}
+
+		if i, ok := index[id]; ok {
+			licenses[i].Aliases = append(licenses[i].Aliases, alias)
+		}
+	}
+
+	if err := aliases.Err(); err != nil {
+		return fmt.Errorf("could not load aliases: %w", err)
+	}
+
+	s.catalog.Replace(licenses)
+
+	return nil
+}
+
+// RecordAcceptance stores a, returning it with its ID filled in.
+func (s *Store) RecordAcceptance(a ynal.Acceptance) (ynal.Acceptance, error) {
+	res, err := s.db.Exec(
+		`INSERT INTO acceptances (license_id, digest, subject, accepted_at, recorded_by) VALUES (?, ?, ?, ?, ?)`,
+		a.License, a.Digest, a.Subject, a.Time.UTC().Format(time.RFC3339Nano), a.RecordedBy,
+	)
+	if err != nil {
+		return a, fmt.Errorf("could not record acceptance: %w", err)
+	}
+
+	if a.ID, err = res.LastInsertId(); err != nil {
+		return a, fmt.Errorf("could not record acceptance: %w", err)
+	}
+
+	return a, nil
+}
+
+// Acceptances lists the recorded acceptances f matches, oldest first.
+func (s *Store) Acceptances(f ynal.AcceptanceFilter) ([]ynal.Acceptance, error) {
+	rows, err := s.db.Query(
+		`SELECT id, license_id, digest, subject, accepted_at, recorded_by FROM acceptances
+		WHERE (? = '' OR subject = ?) AND (? = '' OR license_id = ?)
+		ORDER BY id`,
+		f.Subject, f.Subject, f.License, f.License,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not list acceptances: %w", err)
+	}
+	defer rows.Close()
+
+	all := []ynal.Acceptance{}
+	for rows.Next() {
+		var a ynal.Acceptance
+		var at string
+		if err := rows.Scan(&a.ID, &a.License, &a.Digest, &a.Subject, &at, &a.RecordedBy); err != nil {
+			return nil, fmt.Errorf("could not list acceptances: %w", err)
+		}
+
+		if a.Time, err = time.Parse(time.RFC3339Nano, at); err != nil {
+			return nil, fmt.Errorf("could not list acceptances: %w", err)
+		}
+
+		all = append(all, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not list acceptances: %w", err)
+	}
+
+	return all, nil
+}
@@ -0,0 +1,205 @@
+package sqlitestore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+func mustOpen(t *testing.T, path string, seed []ynal.LicenseData) *Store {
+	s, err := Open(path, seed)
+	if err != nil {
+		t.Fatalf("could not open store: %s", err)
+	}
+
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ynal.db")
+
+	s := mustOpen(t, path, []ynal.LicenseData{ynal.NewLicense("MIT", "mit text\n")})
+
+	if _, ok := s.Get("mit"); !ok {
+		t.Fatalf("expected a new database to be seeded")
+	}
+
+	watched := 0
+	s.Watch(func([]ynal.LicenseData) { watched++ })
+
+	l, err := s.Put("Foo-1.0", "foo text\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if l.ID != "foo-1.0" || l.URL != "/foo-1.0" || l.Text != "foo text\n" {
+		t.Fatalf("unexpected license: %+v", l)
+	}
+
+	if err := s.PutAlias("expat", "mit"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := s.Delete("foo-1.0"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := s.Delete("foo-1.0"); !errors.Is(err, ynal.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+
+	if watched != 3 {
+		t.Fatalf("expected a change for every write, got %d", watched)
+	}
+
+	s.Close()
+
+	// everything survives a restart, and the seed isn't applied again
+	s = mustOpen(t, path, []ynal.LicenseData{ynal.NewLicense("Bar", "bar text\n")})
+
+	ids := []string{}
+	for _, l := range s.List() {
+		ids = append(ids, l.ID)
+	}
+
+	if !slices.Equal(ids, []string{"mit"}) {
+		t.Fatalf("expected only mit after reopening, got %v", ids)
+	}
+
+	if l, _ := s.Get("mit"); !slices.Equal(l.Aliases, []string{"expat"}) {
+		t.Fatalf("expected alias to persist, got %v", l.Aliases)
+	}
+}
+
+func TestStoreAliases(t *testing.T) {
+	s := mustOpen(t, filepath.Join(t.TempDir(), "ynal.db"), []ynal.LicenseData{
+		ynal.NewLicense("MIT", "mit text\n"),
+		ynal.NewLicense("BSD", "bsd text\n"),
+	})
+
+	if err := s.PutAlias("expat", "nope"); !errors.Is(err, ynal.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing license, got: %v", err)
+	}
+
+	for _, alias := range []string{"bsd", "Expat", "../x"} {
+		if err := s.PutAlias(alias, "mit"); err == nil {
+			t.Errorf("expected alias %q to be rejected", alias)
+		}
+	}
+
+	if err := s.PutAlias("expat", "mit"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// a license takes over an alias with the same ID
+	if _, err := s.Put("Expat", "expat text\n"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if l, _ := s.Get("mit"); len(l.Aliases) != 0 {
+		t.Fatalf("expected alias to be replaced by the license, got %v", l.Aliases)
+	}
+
+	if err := s.PutAlias("x11", "mit"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// deleting a license deletes its aliases
+	if err := s.Delete("mit"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := s.DeleteAlias("x11"); !errors.Is(err, ynal.ErrNotFound) {
+		t.Fatalf("expected alias to be deleted with its license, got: %v", err)
+	}
+}
+
+func TestStoreAcceptances(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ynal.db")
+
+	s := mustOpen(t, path, nil)
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, a := range []ynal.Acceptance{
+		{License: "eula", Digest: "abc", Subject: "alice", Time: at, RecordedBy: "portal"},
+		{License: "eula", Digest: "abc", Subject: "bob", Time: at.Add(time.Minute), RecordedBy: "portal"},
+		{License: "nda", Digest: "def", Subject: "alice", Time: at.Add(time.Hour), RecordedBy: "portal"},
+	} {
+		got, err := s.RecordAcceptance(a)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if got.ID == 0 {
+			t.Fatalf("expected an ID to be assigned, got %+v", got)
+		}
+	}
+
+	s.Close()
+
+	// acceptances must survive a restart
+	s = mustOpen(t, path, nil)
+
+	alice, err := s.Acceptances(ynal.AcceptanceFilter{Subject: "alice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(alice) != 2 || alice[0].License != "eula" || alice[1].License != "nda" || !alice[0].Time.Equal(at) {
+		t.Fatalf("unexpected acceptances: %+v", alice)
+	}
+
+	eula, err := s.Acceptances(ynal.AcceptanceFilter{Subject: "bob", License: "eula"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(eula) != 1 || eula[0].Subject != "bob" || eula[0].RecordedBy != "portal" {
+		t.Fatalf("unexpected acceptances: %+v", eula)
+	}
+
+	all, err := s.Acceptances(ynal.AcceptanceFilter{})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("expected every acceptance, got %+v, %v", all, err)
+	}
+}
+
+func TestStoreHealth(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "ynal.db"), nil)
+	if err != nil {
+		t.Fatalf("could not open store: %s", err)
+	}
+
+	if err := s.Health(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	s.Close()
+
+	if err := s.Health(context.Background()); err == nil {
+		t.Fatalf("expected a closed store to be unhealthy")
+	}
+}
+
+func TestMigrateRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ynal.db")
+
+	s := mustOpen(t, path, nil)
+
+	if _, err := s.db.Exec("PRAGMA user_version = 999"); err != nil {
+		t.Fatalf("could not set version: %s", err)
+	}
+
+	s.Close()
+
+	if _, err := Open(path, nil); err == nil {
+		t.Fatalf("expected an error opening a database from the future")
+	}
+}
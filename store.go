@@ -0,0 +1,67 @@
+package ynal
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+)
+
+// LicenseStore is where licenses are served from. Everything that serves
+// licenses works off a LicenseStore, so a new backend only needs to implement
+// it.
+type LicenseStore interface {
+	// List returns every license. Callers must not modify the slice.
+	List() []LicenseData
+
+	// Get returns the license with the given ID, if there is one.
+	Get(id string) (LicenseData, bool)
+
+	// Watch registers fn to be called with every license whenever they
+	// change. Stores whose licenses never change may never call it.
+	Watch(fn func([]LicenseData))
+}
+
+// HealthChecker is implemented by stores, and anything else licenses are
+// served from, that can stop working while ynal runs. Health returns why it
+// isn't working, or nil if it is.
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// FSStore serves the *.txt licenses in a filesystem, read once when it is
+// created.
+type FSStore struct {
+	licenses []LicenseData
+}
+
+// NewFSStore loads the licenses at the root of fsys. See LoadLicenses.
+func NewFSStore(fsys fs.FS) (*FSStore, error) {
+	licenses, err := LoadLicenses(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FSStore{licenses: licenses}, nil
+}
+
+// EmbeddedStore serves the licenses embedded in the binary. It is the default
+// everywhere a store isn't given.
+func EmbeddedStore() (*FSStore, error) {
+	sub, err := fs.Sub(Licenses, "licenses")
+	if err != nil {
+		return nil, fmt.Errorf("could not subsystem licenses: %w", err)
+	}
+
+	return NewFSStore(sub)
+}
+
+func (s *FSStore) List() []LicenseData {
+	return s.licenses
+}
+
+func (s *FSStore) Get(id string) (LicenseData, bool) {
+	return FindLicense(s.licenses, id)
+}
+
+// Watch does nothing, since an FSStore never changes.
+func (s *FSStore) Watch(fn func([]LicenseData)) {}
@@ -0,0 +1,42 @@
+package ynal
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSStore(t *testing.T) {
+	store, err := NewFSStore(fstest.MapFS{
+		"Foo.txt":   {Data: []byte("foo text\n")},
+		"Foo.json":  {Data: []byte(`{"family": "Foo"}`)},
+		"notes.md":  {Data: []byte("not a license")},
+		"sub/x.txt": {Data: []byte("not at the root")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(store.List()) != 1 {
+		t.Fatalf("expected one license, got %+v", store.List())
+	}
+
+	l, ok := store.Get("foo")
+	if !ok || l.Title != "Foo" || l.Text != "foo text\n" || l.Family != "Foo" {
+		t.Fatalf("unexpected license: %+v", l)
+	}
+
+	if _, ok := store.Get("x"); ok {
+		t.Fatalf("expected licenses outside the root to be ignored")
+	}
+}
+
+func TestEmbeddedStore(t *testing.T) {
+	store, err := EmbeddedStore()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, ok := store.Get("mit"); !ok {
+		t.Fatalf("expected MIT in the embedded store")
+	}
+}
@@ -0,0 +1,50 @@
+package ynal
+
+import "slices"
+
+// The tags a license can carry, for browsing the catalog by category. See
+// LicenseData.Tags.
+const (
+	TagCopyleft      = "copyleft"
+	TagPermissive    = "permissive"
+	TagPublicDomain  = "public-domain"
+	TagDocumentation = "documentation"
+	TagFonts         = "fonts"
+	TagHardware      = "hardware"
+)
+
+// Tags are every valid tag, in the order they're listed.
+var Tags = []string{TagCopyleft, TagPermissive, TagPublicDomain, TagDocumentation, TagFonts, TagHardware}
+
+// Tag is one of Tags and every license tagged with it.
+type Tag struct {
+	Name     string
+	URL      string
+	Licenses []LicenseData
+}
+
+// TagIndex groups licenses by tag, in the order of Tags. Every tag is there,
+// even ones no license carries yet, and licenses keep their order within a
+// tag.
+func TagIndex(licenses []LicenseData) []Tag {
+	tags := []Tag{}
+
+	for _, name := range Tags {
+		t := Tag{Name: name, URL: "/tags/" + name, Licenses: []LicenseData{}}
+
+		for _, l := range licenses {
+			if l.HasTag(name) {
+				t.Licenses = append(t.Licenses, l)
+			}
+		}
+
+		tags = append(tags, t)
+	}
+
+	return tags
+}
+
+// HasTag reports whether l is tagged with tag.
+func (l LicenseData) HasTag(tag string) bool {
+	return slices.Contains(l.Tags, tag)
+}
@@ -0,0 +1,34 @@
+package ynal
+
+import (
+	"testing"
+)
+
+func TestTagIndex(t *testing.T) {
+	mit := NewLicense("MIT", "mit\n")
+	mit.Tags = []string{TagPermissive}
+
+	gpl := NewLicense("GPL_3", "gpl\n")
+	gpl.Tags = []string{TagCopyleft}
+
+	bsd := NewLicense("BSD_3", "bsd\n")
+	bsd.Tags = []string{TagPermissive}
+
+	tags := TagIndex([]LicenseData{mit, gpl, NewLicense("Untagged", "untagged\n"), bsd})
+
+	if len(tags) != len(Tags) {
+		t.Fatalf("expected every tag, got %+v", tags)
+	}
+
+	if tags[0].Name != TagCopyleft || len(tags[0].Licenses) != 1 || tags[0].Licenses[0].ID != "gpl_3" {
+		t.Errorf("expected copyleft to hold GPL_3, got %+v", tags[0])
+	}
+
+	if tags[1].URL != "/tags/permissive" || len(tags[1].Licenses) != 2 || tags[1].Licenses[0].ID != "mit" || tags[1].Licenses[1].ID != "bsd_3" {
+		t.Errorf("expected permissive to hold MIT then BSD_3, got %+v", tags[1])
+	}
+
+	if tags[5].Name != TagHardware || len(tags[5].Licenses) != 0 {
+		t.Errorf("expected hardware to be empty, got %+v", tags[5])
+	}
+}
@@ -0,0 +1,66 @@
+package ynal
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validate checks licenses can be served together: every license has an ID
+// and some text, the text is UTF-8, its layout, tags, obligations, and logo
+// are known, and no two licenses would be served at the same URL (like
+// MIT.txt and mit.txt in the same directory). It reports every problem
+// rather than just the first.
+func Validate(licenses []LicenseData) error {
+	errs := []error{}
+	urls := map[string]string{}
+
+	for _, l := range licenses {
+		name := l.Title
+		if name == "" {
+			name = l.ID
+		}
+
+		if l.ID == "" {
+			errs = append(errs, fmt.Errorf("%s: no ID", name))
+		}
+
+		if strings.TrimSpace(l.Text) == "" {
+			errs = append(errs, fmt.Errorf("%s: empty text", name))
+		} else if !utf8.ValidString(l.Text) {
+			errs = append(errs, fmt.Errorf("%s: text isn't valid UTF-8", name))
+		}
+
+		if l.Layout != "" && !slices.Contains(Layouts, l.Layout) {
+			errs = append(errs, fmt.Errorf("%s: unknown layout %q", name, l.Layout))
+		}
+
+		for _, tag := range l.Tags {
+			if !slices.Contains(Tags, tag) {
+				errs = append(errs, fmt.Errorf("%s: unknown tag %q", name, tag))
+			}
+		}
+
+		for _, o := range l.Obligations {
+			if !slices.Contains(Obligations, o) {
+				errs = append(errs, fmt.Errorf("%s: unknown obligation %q", name, o))
+			}
+		}
+
+		if l.Logo != "" {
+			if _, err := Logo(l.Logo); err != nil {
+				errs = append(errs, fmt.Errorf("%s: unknown logo %q", name, l.Logo))
+			}
+		}
+
+		if other, ok := urls[l.URL]; ok {
+			errs = append(errs, fmt.Errorf("%s: served at %s, same as %s", name, l.URL, other))
+		} else {
+			urls[l.URL] = name
+		}
+	}
+
+	return errors.Join(errs...)
+}
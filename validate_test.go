@@ -0,0 +1,56 @@
+package ynal
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestValidate(t *testing.T) {
+	embedded, err := Embedded()
+	if err != nil {
+		t.Fatalf("could not load embedded licenses: %s", err)
+	}
+
+	if err := Validate(embedded); err != nil {
+		t.Fatalf("expected the embedded licenses to be valid, got: %s", err)
+	}
+
+	err = Validate([]LicenseData{
+		NewLicense("MIT", "mit text\n"),
+		NewLicense("mit", "other mit text\n"),
+		NewLicense("Empty", " \n"),
+		NewLicense("Latin1", "caf\xe9\n"),
+		{ID: "sideways", Title: "Sideways", Text: "text\n", URL: "/sideways", Layout: "sideways"},
+		{ID: "tagged", Title: "Tagged", Text: "text\n", URL: "/tagged", Tags: []string{"permissive", "lenient"}},
+		{ID: "obliging", Title: "Obliging", Text: "text\n", URL: "/obliging", Obligations: []string{"include-copyright", "buy-a-beer"}},
+		{ID: "branded", Title: "Branded", Text: "text\n", URL: "/branded", Logo: "../licenses/MIT"},
+	})
+	if err == nil {
+		t.Fatalf("expected validation errors")
+	}
+
+	for _, want := range []string{"mit: served at /mit, same as MIT", "Empty: empty text", "Latin1: text isn't valid UTF-8", `Sideways: unknown layout "sideways"`, `Tagged: unknown tag "lenient"`, `Obliging: unknown obligation "buy-a-beer"`, `Branded: unknown logo "../licenses/MIT"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestLoadLicensesReportsEveryProblem(t *testing.T) {
+	_, err := LoadLicenses(fstest.MapFS{
+		"Foo.txt":  {Data: []byte("foo\n")},
+		"Foo.json": {Data: []byte(`{"family": `)},
+		"Bar.txt":  {Data: []byte("bar\n")},
+		"Bar.json": {Data: []byte(`["bar"]`)},
+	})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	for _, want := range []string{"Foo.txt", "Bar.txt"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Package webhook tells other services when the license catalog changes, so
+// caches and mirrors downstream of ynal know when to invalidate.
+//
+// Every change is POSTed to each URL as a JSON Event. When there's a secret,
+// the body is signed with HMAC-SHA256 and the hex-encoded signature is sent as
+// "X-Ynal-Signature: sha256=<signature>", the way GitHub signs its webhooks.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+// SignatureHeader carries the signature of a signed event.
+const SignatureHeader = "X-Ynal-Signature"
+
+// EventType is the type of every event, so receivers can tell them apart from
+// events that may be added later.
+const EventType = "catalog.changed"
+
+// attempts is how many times an event is sent to a URL before giving up.
+const attempts = 3
+
+// Event describes a change to the catalog by license ID.
+type Event struct {
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Added   []string  `json:"added"`
+	Removed []string  `json:"removed"`
+	Changed []string  `json:"changed"`
+}
+
+// Notifier sends an Event to every URL whenever the licenses it's watching
+// change.
+type Notifier struct {
+	URLs []string
+
+	// Secret signs every event when set.
+	Secret string
+
+	// Client is used for every request. Defaults to a client with a
+	// reasonable timeout.
+	Client *http.Client
+
+	// Backoff is how long to wait before retrying a failed delivery, doubling
+	// each time. Defaults to a second.
+	Backoff time.Duration
+
+	mu       sync.Mutex
+	previous []ynal.LicenseData
+	wg       sync.WaitGroup
+}
+
+// Watch notifies n's URLs of every change to store from now on.
+func (n *Notifier) Watch(store ynal.LicenseStore) {
+	n.mu.Lock()
+	n.previous = store.List()
+	n.mu.Unlock()
+
+	store.Watch(n.changed)
+}
+
+// Wait blocks until every event sent so far has been delivered or given up on.
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+func (n *Notifier) changed(licenses []ynal.LicenseData) {
+	n.mu.Lock()
+	e := diff(n.previous, licenses)
+	n.previous = licenses
+	n.mu.Unlock()
+
+	if len(e.Added) == 0 && len(e.Removed) == 0 && len(e.Changed) == 0 {
+		return
+	}
+
+	e.Time = time.Now().UTC()
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("could not encode webhook event: %s", err)
+		return
+	}
+
+	for _, url := range n.URLs {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+
+			if err := n.deliver(url, body); err != nil {
+				log.Printf("could not deliver webhook to %s: %s", url, err)
+			}
+		}()
+	}
+}
+
+// diff describes the change from before to after as an event.
+func diff(before []ynal.LicenseData, after []ynal.LicenseData) Event {
+	c := ynal.Diff(before, after)
+
+	return Event{Type: EventType, Added: c.Added, Removed: c.Removed, Changed: c.Changed}
+}
+
+// deliver POSTs body to url, retrying with backoff on network errors and
+// server errors.
+func (n *Notifier) deliver(url string, body []byte) error {
+	backoff := n.Backoff
+	if backoff == 0 {
+		backoff = time.Second
+	}
+
+	var err error
+	for i := range attempts {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		var retry bool
+		if retry, err = n.post(url, body); !retry {
+			return err
+		}
+	}
+
+	return fmt.Errorf("gave up after %d attempts: %w", attempts, err)
+}
+
+// post makes a single delivery attempt, reporting whether it's worth trying
+// again.
+func (n *Notifier) post(url string, body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("could not build request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if n.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(n.Secret, body))
+	}
+
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("could not send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= 300 {
+		err := fmt.Errorf("unexpected status: %s", resp.Status)
+		return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+	}
+
+	return false, nil
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of body with secret, as sent in
+// SignatureHeader after "sha256=".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+// receiver records every event it's sent, after answering the first few
+// (as many as failures) with a 503.
+type receiver struct {
+	mu         sync.Mutex
+	failures   int
+	events     []Event
+	signatures []string
+	bodies     [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.failures > 0 {
+		rc.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	e := Event{}
+	if err := json.Unmarshal(body, &e); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	rc.events = append(rc.events, e)
+	rc.signatures = append(rc.signatures, r.Header.Get(SignatureHeader))
+	rc.bodies = append(rc.bodies, body)
+}
+
+func TestNotifier(t *testing.T) {
+	rc := &receiver{failures: 1}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	catalog := ynal.NewCatalog([]ynal.LicenseData{
+		ynal.NewLicense("MIT", "mit"),
+		ynal.NewLicense("BSD", "bsd"),
+		ynal.NewLicense("ISC", "isc"),
+	})
+
+	n := &Notifier{URLs: []string{srv.URL}, Secret: "hunter2", Backoff: time.Millisecond}
+	n.Watch(catalog)
+
+	catalog.Replace([]ynal.LicenseData{
+		ynal.NewLicense("MIT", "mit"),
+		ynal.NewLicense("BSD", "bsd, revised"),
+		ynal.NewLicense("Zlib", "zlib"),
+	})
+	n.Wait()
+
+	// nothing changed, so nothing is sent
+	catalog.Replace(catalog.List())
+	n.Wait()
+
+	if len(rc.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(rc.events))
+	}
+
+	e := rc.events[0]
+	if e.Type != EventType {
+		t.Fatalf("expected type %q, got %q", EventType, e.Type)
+	}
+
+	if !slices.Equal(e.Added, []string{"zlib"}) || !slices.Equal(e.Removed, []string{"isc"}) || !slices.Equal(e.Changed, []string{"bsd"}) {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+
+	if expected := "sha256=" + Sign("hunter2", rc.bodies[0]); rc.signatures[0] != expected {
+		t.Fatalf("expected signature %q, got %q", expected, rc.signatures[0])
+	}
+}
+
+func TestNotifierGivesUp(t *testing.T) {
+	tt := []struct {
+		name     string
+		code     int
+		expected int
+	}{
+		{name: "server error", code: http.StatusInternalServerError, expected: attempts},
+		{name: "client error", code: http.StatusNotFound, expected: 1},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if r.Header.Get(SignatureHeader) != "" {
+					t.Errorf("expected no signature without a secret")
+				}
+
+				w.WriteHeader(tc.code)
+			}))
+			defer srv.Close()
+
+			n := &Notifier{URLs: []string{srv.URL}, Backoff: time.Millisecond}
+			if err := n.deliver(srv.URL, []byte("{}")); err == nil {
+				t.Fatalf("expected an error")
+			}
+
+			if calls != tc.expected {
+				t.Fatalf("expected %d attempts, got %d", tc.expected, calls)
+			}
+		})
+	}
+}
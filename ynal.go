@@ -0,0 +1,276 @@
+// Package ynal holds the embedded license catalog and assets served by ynal.
+// See the ynalhttp package for the HTTP handler and cmd/ynal for the binary.
+package ynal
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Public holds the static assets served by ynal under public/.
+//
+//go:embed public/*
+var Public embed.FS
+
+// Licenses holds the embedded license texts under licenses/. Licenses listed
+// in spdx-imports.txt are fetched from the SPDX license list.
+//
+//go:generate go run ./cmd/spdx-import -dir licenses -manifest spdx-imports.txt
+//go:embed licenses/*
+var Licenses embed.FS
+
+// Exceptions holds the embedded license exception texts under exceptions/.
+//
+//go:embed exceptions/*
+var Exceptions embed.FS
+
+// CompatibilityData is the curated license compatibility matrix. See
+// Compatibility.
+//
+//go:embed compatibility.json
+var CompatibilityData []byte
+
+// Templates holds the HTML templates under templates/.
+//
+//go:embed templates/*
+var Templates embed.FS
+
+// Logos holds the marks under logos/ that licenses can be shown with, one
+// <name>.svg each. They're plain badges of ynal's own, not the licenses'
+// official artwork, which is often trademarked. See LicenseData.Logo.
+//
+//go:embed logos/*
+var Logos embed.FS
+
+// Messages holds the translations of the HTML pages' text under messages/,
+// one JSON file per language.
+//
+//go:embed messages/*
+var Messages embed.FS
+
+// ErrNotFound is returned when a license doesn't exist.
+var ErrNotFound = errors.New("license not found")
+
+// validTitle matches titles that are safe to use as filenames. The ID (and so
+// the URL) of a license is its title, lowercased.
+var validTitle = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+type LicenseData struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Text   string `json:"content"`
+	URL    string `json:"url"`
+	Digest Digest `json:"digest"`
+
+	// Aliases are other IDs the license is known by, which redirect to it.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Family is the name of the family the license belongs to, if any. See
+	// Families.
+	Family string `json:"family,omitempty"`
+
+	// Version is which version of its family the license is, if the family
+	// has versions. See CompareVersions.
+	Version string `json:"version,omitempty"`
+
+	// Deprecated licenses are still served, but with a warning pointing at
+	// their Successor, the ID of the license to use instead, if there is one.
+	Deprecated bool   `json:"deprecated,omitempty"`
+	Successor  string `json:"successor,omitempty"`
+
+	// SPDX is the license's SPDX identifier, if it has one. See SPDXID.
+	SPDX string `json:"spdx,omitempty"`
+
+	// Header is the short notice the license asks to be put at the top of
+	// each source file, if it has one. See FileHeader.
+	Header string `json:"header,omitempty"`
+
+	// Custom documents are an organization's own licenses and policies, not
+	// open source licenses. See LoadCustom.
+	Custom bool `json:"custom,omitempty"`
+
+	// Layout is how the text reads best in HTML, one of Layouts. Empty means
+	// LayoutPreserve.
+	Layout string `json:"layout,omitempty"`
+
+	// Deed is a short, plain-language summary of the license, like a
+	// Creative Commons deed. It's served on its own, next to the full legal
+	// code in Text.
+	Deed string `json:"deed,omitempty"`
+
+	// Summary is a one-line, plain-language tl;dr of what the license lets
+	// people do. It's a curated convenience, not legal advice.
+	Summary string `json:"summary,omitempty"`
+
+	// Tags are the categories the license is in, each one of Tags. See
+	// TagIndex.
+	Tags []string `json:"tags,omitempty"`
+
+	// Obligations are what the license asks of people who use it, each one
+	// of Obligations. See ObligationReport.
+	Obligations []string `json:"obligations,omitempty"`
+
+	// Template is the name of the HTML template the license's page is
+	// rendered with. Empty means license.html.tmpl.
+	Template string `json:"template,omitempty"`
+
+	// Logo is the name of the mark in Logos the license is shown with, if
+	// any. See Logo.
+	Logo string `json:"logo,omitempty"`
+}
+
+// NewLicense returns the license with the given title and text. Its ID is the
+// title lowercased.
+func NewLicense(title string, text string) LicenseData {
+	id := strings.ToLower(title)
+
+	return LicenseData{
+		ID:     id,
+		Title:  title,
+		Text:   text,
+		URL:    "/" + id,
+		Digest: digestOf(text),
+	}
+}
+
+// invalidRefChars matches what can't appear in an SPDX LicenseRef.
+var invalidRefChars = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// SPDXID returns the license's SPDX identifier, or a LicenseRef made from its
+// title for licenses that aren't on the SPDX list.
+func (l LicenseData) SPDXID() string {
+	if l.SPDX != "" {
+		return l.SPDX
+	}
+
+	return "LicenseRef-" + invalidRefChars.ReplaceAllString(l.Title, "-")
+}
+
+// FileHeader returns the text to put at the top of each source file under the
+// license: an SPDX-License-Identifier line, then the license's own header, or
+// a copyright line for licenses without one.
+func (l LicenseData) FileHeader() string {
+	header := l.Header
+	if header == "" {
+		header = "Copyright <YEAR> <COPYRIGHT HOLDER>\n"
+	}
+
+	return "SPDX-License-Identifier: " + l.SPDXID() + "\n\n" + strings.TrimRight(header, "\n") + "\n"
+}
+
+// ValidateLicense reports whether a license with the given title and text can
+// be stored. Titles must be safe to use as filenames.
+func ValidateLicense(title string, text string) error {
+	if !validTitle.MatchString(title) {
+		return fmt.Errorf("invalid license title: %q", title)
+	}
+
+	if text == "" {
+		return errors.New("license text is empty")
+	}
+
+	return nil
+}
+
+// Digest holds hex-encoded checksums of a license's plain text, so a fetched
+// LICENSE file can be checked byte for byte.
+type Digest struct {
+	SHA256 string `json:"sha256"`
+	SHA1   string `json:"sha1"`
+}
+
+func digestOf(text string) Digest {
+	s256 := sha256.Sum256([]byte(text))
+	s1 := sha1.Sum([]byte(text))
+
+	return Digest{
+		SHA256: hex.EncodeToString(s256[:]),
+		SHA1:   hex.EncodeToString(s1[:]),
+	}
+}
+
+// Embedded returns the license catalog embedded in the binary.
+func Embedded() ([]LicenseData, error) {
+	store, err := EmbeddedStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return store.List(), nil
+}
+
+// LoadLicenses reads every *.txt file at the root of licensesFS, along with
+// its metadata if there is any. The path each license is served under is its
+// filename, lowercased, without the extension. Licenses that can't be read are
+// all reported together.
+func LoadLicenses(licensesFS fs.FS) ([]LicenseData, error) {
+	lpaths, err := fs.Glob(licensesFS, "*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("could not glob licenses: %w", err)
+	}
+
+	licenses := []LicenseData{}
+	errs := []error{}
+
+	for _, lpath := range lpaths {
+		plainData, err := fs.ReadFile(licensesFS, lpath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not read license: %w", err))
+			continue
+		}
+
+		l := NewLicense(pathToTitle(lpath), string(plainData))
+
+		m, err := readMetadata(licensesFS, lpath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.Apply(&l)
+
+		licenses = append(licenses, l)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return licenses, nil
+}
+
+// FindLicense returns the license with the given ID, if there is one.
+func FindLicense(licenses []LicenseData, id string) (LicenseData, bool) {
+	for _, l := range licenses {
+		if l.ID == id {
+			return l, true
+		}
+	}
+
+	return LicenseData{}, false
+}
+
+func pathToTitle(lpath string) string {
+	return strings.TrimSuffix(path.Base(lpath), path.Ext(lpath))
+}
+
+// Substitute fills in placeholders like <YEAR> or <COPYRIGHT HOLDER> in the
+// license text. Keys are given without the surrounding angle brackets. It's a
+// single pass, so placeholders in the values are left as they are.
+func Substitute(text string, subs map[string]string) string {
+	pairs := []string{}
+	for _, k := range slices.Sorted(maps.Keys(subs)) {
+		pairs = append(pairs, "<"+k+">", subs[k])
+	}
+
+	return strings.NewReplacer(pairs...).Replace(text)
+}
@@ -0,0 +1,42 @@
+package ynal
+
+import (
+	"testing"
+)
+
+func TestSubstitute(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		text     string
+		subs     map[string]string
+		expected string
+	}{
+		{
+			name:     "fills in placeholders",
+			text:     "Copyright (c) <YEAR> <COPYRIGHT HOLDER>",
+			subs:     map[string]string{"YEAR": "2024", "COPYRIGHT HOLDER": "Corp"},
+			expected: "Copyright (c) 2024 Corp",
+		},
+		{
+			name:     "leaves unknown placeholders",
+			text:     "<YEAR> <OWNER>",
+			subs:     map[string]string{"YEAR": "2024"},
+			expected: "2024 <OWNER>",
+		},
+		{
+			name:     "doesn't substitute into values",
+			text:     "Copyright (c) <YEAR> <COPYRIGHT HOLDER>",
+			subs:     map[string]string{"YEAR": "2024", "COPYRIGHT HOLDER": "<YEAR> Corp"},
+			expected: "Copyright (c) 2024 <YEAR> Corp",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// map order differs from run to run, so try it a few times
+			for range 20 {
+				if got := Substitute(tc.text, tc.subs); got != tc.expected {
+					t.Fatalf("expected %q, got %q", tc.expected, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+// maxAcceptanceSize bounds acceptance records, which are a few short fields.
+const maxAcceptanceSize = 4 << 10
+
+// maxSubjectLength bounds the subject of an acceptance.
+const maxSubjectLength = 256
+
+// AcceptanceStore durably records acceptances.
+type AcceptanceStore interface {
+	RecordAcceptance(a ynal.Acceptance) (ynal.Acceptance, error)
+	Acceptances(f ynal.AcceptanceFilter) ([]ynal.Acceptance, error)
+}
+
+// WithAcceptances enables POST and GET on /api/v1/acceptances, for recording
+// that someone accepted a license or custom document and listing who has.
+// Like the admin API, every request must carry one of the tokens given to
+// WithTokens or a client certificate given to WithClientCertificates.
+func WithAcceptances(store AcceptanceStore) Option {
+	return func(c *config) {
+		c.acceptances = store
+	}
+}
+
+// acceptanceRequest is the body of a POST to /api/v1/acceptances. Time is
+// when the subject accepted, if that was before the request; it defaults to
+// now.
+type acceptanceRequest struct {
+	License string    `json:"license"`
+	Subject string    `json:"subject"`
+	Time    time.Time `json:"time"`
+}
+
+func acceptanceHandler(store AcceptanceStore, creds credentials, licenses ynal.LicenseStore, custom []ynal.LicenseData, tmpl *pageTemplates) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("POST /api/v1/acceptances", limitBody(tmpl, maxAcceptanceSize, requestTimeout, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req acceptanceRequest
+
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		if err := dec.Decode(&req); err != nil {
+			writeProblem(w, newProblem(r, bodyStatus(err), fmt.Sprintf("could not parse acceptance: %s", err)))
+			return
+		}
+
+		id := strings.ToLower(req.License)
+		noteAudit(r, AuditAcceptance, "", id)
+
+		if req.Subject == "" || len(req.Subject) > maxSubjectLength {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, fmt.Sprintf("subject must be between 1 and %d bytes", maxSubjectLength)))
+			return
+		}
+
+		l, ok := licenses.Get(id)
+		if !ok {
+			l, ok = ynal.FindLicense(custom, id)
+		}
+
+		if !ok {
+			writeProblem(w, newProblem(r, http.StatusUnprocessableEntity, fmt.Sprintf("no such license: %s", req.License)))
+			return
+		}
+
+		at := now(r)
+		if !req.Time.IsZero() {
+			if req.Time.After(at) {
+				writeProblem(w, newProblem(r, http.StatusBadRequest, "time can't be in the future"))
+				return
+			}
+
+			at = req.Time
+		}
+
+		a, err := store.RecordAcceptance(ynal.Acceptance{
+			License:    l.ID,
+			Digest:     l.Digest.SHA256,
+			Subject:    req.Subject,
+			Time:       at.UTC(),
+			RecordedBy: actor(r),
+		})
+		if err != nil {
+			logf(r, "could not record acceptance of %s for %s: %s", l.ID, actor(r), err)
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, "could not record acceptance"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(a)
+	})))
+
+	mux.HandleFunc("GET /api/v1/acceptances", func(w http.ResponseWriter, r *http.Request) {
+		f := ynal.AcceptanceFilter{
+			Subject: r.URL.Query().Get("subject"),
+			License: strings.ToLower(r.URL.Query().Get("license")),
+		}
+
+		all, err := store.Acceptances(f)
+		if err != nil {
+			logf(r, "could not list acceptances for %s: %s", actor(r), err)
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, "could not list acceptances"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string][]ynal.Acceptance{"acceptances": all})
+	})
+
+	authed := requireAuth(creds, mux)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		authed.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,155 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/sqlitestore"
+)
+
+func mustAcceptanceHandler(t *testing.T, audit *AuditLog) http.Handler {
+	store, err := sqlitestore.Open(filepath.Join(t.TempDir(), "ynal.db"), []ynal.LicenseData{ynal.NewLicense("MIT", "mit text\n")})
+	if err != nil {
+		t.Fatalf("could not open store: %s", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	h, err := New(
+		WithStore(store),
+		WithAcceptances(store),
+		WithCustom([]ynal.LicenseData{ynal.NewLicense("Acme_Terms", "be nice\n")}),
+		WithTokens(map[string]string{"alice": "s3cret"}),
+		WithClock(func() time.Time { return at }),
+		WithAuditLog(audit),
+	)
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	return h
+}
+
+func TestAcceptances(t *testing.T) {
+	h := mustAcceptanceHandler(t, NewAuditLog(&bytes.Buffer{}))
+
+	tt := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{
+			name:     "license",
+			body:     `{"license": "MIT", "subject": "alice@example.com"}`,
+			expected: http.StatusCreated,
+		},
+		{
+			name:     "custom document",
+			body:     `{"license": "acme_terms", "subject": "alice@example.com", "time": "2024-02-29T09:30:00Z"}`,
+			expected: http.StatusCreated,
+		},
+		{
+			name:     "unknown license",
+			body:     `{"license": "nope", "subject": "alice@example.com"}`,
+			expected: http.StatusUnprocessableEntity,
+		},
+		{
+			name:     "no subject",
+			body:     `{"license": "mit"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "time in the future",
+			body:     `{"license": "mit", "subject": "bob@example.com", "time": "2030-01-01T00:00:00Z"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "unknown field",
+			body:     `{"license": "mit", "subject": "bob@example.com", "by": "bob"}`,
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w := adminRequest(h, "POST", "/api/v1/acceptances", tc.body, "s3cret")
+			if w.Code != tc.expected {
+				t.Fatalf("expected %d, got %d: %s", tc.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w := adminRequest(h, "GET", "/api/v1/acceptances?subject=alice@example.com", "", "s3cret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got struct {
+		Acceptances []ynal.Acceptance `json:"acceptances"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("could not decode acceptances: %s", err)
+	}
+
+	expected := []ynal.Acceptance{
+		{ID: 1, License: "mit", Digest: ynal.NewLicense("MIT", "mit text\n").Digest.SHA256, Subject: "alice@example.com", Time: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), RecordedBy: "alice"},
+		{ID: 2, License: "acme_terms", Digest: ynal.NewLicense("Acme_Terms", "be nice\n").Digest.SHA256, Subject: "alice@example.com", Time: time.Date(2024, 2, 29, 9, 30, 0, 0, time.UTC), RecordedBy: "alice"},
+	}
+
+	if len(got.Acceptances) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got.Acceptances)
+	}
+
+	for i := range expected {
+		if !got.Acceptances[i].Time.Equal(expected[i].Time) {
+			t.Errorf("expected time %s, got %s", expected[i].Time, got.Acceptances[i].Time)
+		}
+
+		got.Acceptances[i].Time = expected[i].Time
+		if got.Acceptances[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], got.Acceptances[i])
+		}
+	}
+
+	w = adminRequest(h, "GET", "/api/v1/acceptances?license=MIT&subject=bob@example.com", "", "s3cret")
+	if body := strings.TrimSpace(w.Body.String()); body != `{"acceptances":[]}` {
+		t.Errorf("expected no acceptances, got %s", body)
+	}
+}
+
+func TestAcceptancesRequireAuth(t *testing.T) {
+	out := &bytes.Buffer{}
+	h := mustAcceptanceHandler(t, NewAuditLog(out))
+
+	for _, token := range []string{"", "wrong"} {
+		if w := adminRequest(h, "GET", "/api/v1/acceptances", "", token); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for token %q, got %d", token, w.Code)
+		}
+	}
+
+	w := adminRequest(h, "POST", "/api/v1/acceptances", `{"license": "mit", "subject": "carol"}`, "s3cret")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if !strings.Contains(out.String(), `"action":"acceptance.record","actor":"alice","licenses":["mit"]`) {
+		t.Errorf("expected the acceptance in the audit log, got %s", out.String())
+	}
+
+	store, err := sqlitestore.Open(filepath.Join(t.TempDir(), "ynal.db"), nil)
+	if err != nil {
+		t.Fatalf("could not open store: %s", err)
+	}
+	defer store.Close()
+
+	if _, err := New(WithStore(store), WithAcceptances(store)); err == nil {
+		t.Fatalf("expected an error recording acceptances without tokens")
+	}
+}
@@ -0,0 +1,181 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+// maxLicenseSize bounds uploads. The longest licenses in the catalog are
+// around 35KB, so this is plenty.
+const maxLicenseSize = 1 << 20
+
+// AdminStore persists changes made through the admin API. Changes must be
+// reflected in the LicenseStore being served for them to show up.
+type AdminStore interface {
+	Put(title string, text string) (ynal.LicenseData, error)
+	Delete(id string) error
+}
+
+// AliasStore is an AdminStore that can also manage aliases, which redirect to
+// the license they belong to.
+type AliasStore interface {
+	AdminStore
+
+	PutAlias(alias string, id string) error
+	DeleteAlias(alias string) error
+}
+
+// WithAdmin enables PUT and DELETE on /admin/licenses/{id}, and on
+// /admin/aliases/{alias} if store is an AliasStore. Every admin
+// request must carry one of the bearer tokens given to WithTokens.
+func WithAdmin(store AdminStore) Option {
+	return func(c *config) {
+		c.admin = store
+	}
+}
+
+// adminHandler checks every license put through it by building a handler with
+// it in the catalog, the same way every change is served, so a license that
+// can't be served is turned away before the store keeps it.
+func adminHandler(store AdminStore, creds credentials, licenses ynal.LicenseStore, tmpl *pageTemplates, build func([]ynal.LicenseData) (http.Handler, error)) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("PUT /admin/licenses/{id}", limitBody(tmpl, maxLicenseSize, requestTimeout, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		noteAudit(r, AuditLicensePut, "", id)
+
+		// the title sets the display name and filename, which can differ in
+		// case from the ID
+		title := r.URL.Query().Get("title")
+		if title == "" {
+			title = id
+		}
+
+		if strings.ToLower(title) != id {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, fmt.Sprintf("title %q doesn't match ID %q", title, id)))
+			return
+		}
+
+		text, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeProblem(w, newProblem(r, bodyStatus(err), fmt.Sprintf("could not read license: %s", err)))
+			return
+		}
+
+		if err := ynal.ValidateLicense(title, string(text)); err != nil {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		existing, existed := licenses.Get(id)
+
+		if _, err := build(withCandidate(licenses.List(), existing, existed, title, string(text))); err != nil {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, fmt.Sprintf("license %q can't be served: %s", id, err)))
+			return
+		}
+
+		l, err := store.Put(title, string(text))
+		if err != nil {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		base := basePath(r)
+		l.URL = base + l.URL
+
+		w.Header().Set("Content-Type", "application/json")
+		if existed {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.Header().Set("Location", l.URL)
+			w.WriteHeader(http.StatusCreated)
+		}
+
+		json.NewEncoder(w).Encode(toAPILicense(base, l))
+	})))
+
+	mux.HandleFunc("DELETE /admin/licenses/{id}", func(w http.ResponseWriter, r *http.Request) {
+		noteAudit(r, AuditLicenseDelete, "", r.PathValue("id"))
+
+		err := store.Delete(r.PathValue("id"))
+		if errors.Is(err, ynal.ErrNotFound) {
+			writeProblem(w, newProblem(r, http.StatusNotFound, fmt.Sprintf("no such license: %s", r.PathValue("id"))))
+			return
+		} else if err != nil {
+			logf(r, "could not delete license %s for %s: %s", r.PathValue("id"), actor(r), err)
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, "could not delete license"))
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if aliases, ok := store.(AliasStore); ok {
+		mux.HandleFunc("PUT /admin/aliases/{alias}", func(w http.ResponseWriter, r *http.Request) {
+			id := r.URL.Query().Get("id")
+			noteAudit(r, AuditAliasPut, r.PathValue("alias"), id)
+
+			err := aliases.PutAlias(r.PathValue("alias"), id)
+			if errors.Is(err, ynal.ErrNotFound) {
+				writeProblem(w, newProblem(r, http.StatusNotFound, fmt.Sprintf("no such license: %s", id)))
+				return
+			} else if err != nil {
+				writeProblem(w, newProblem(r, http.StatusBadRequest, err.Error()))
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		mux.HandleFunc("DELETE /admin/aliases/{alias}", func(w http.ResponseWriter, r *http.Request) {
+			noteAudit(r, AuditAliasDelete, r.PathValue("alias"))
+
+			err := aliases.DeleteAlias(r.PathValue("alias"))
+			if errors.Is(err, ynal.ErrNotFound) {
+				writeProblem(w, newProblem(r, http.StatusNotFound, fmt.Sprintf("no such alias: %s", r.PathValue("alias"))))
+				return
+			} else if err != nil {
+				logf(r, "could not delete alias %s for %s: %s", r.PathValue("alias"), actor(r), err)
+				writeProblem(w, newProblem(r, http.StatusInternalServerError, "could not delete alias"))
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+
+	mux.HandleFunc("/admin/", func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, newProblem(r, http.StatusNotFound, fmt.Sprintf("no such admin route: %s %s", r.Method, r.URL.Path)))
+	})
+
+	authed := requireAuth(creds, mux)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		authed.ServeHTTP(w, r)
+	})
+}
+
+// withCandidate is licenses with the one titled title put in, the way a store
+// would: if it existed, it keeps the metadata of what it replaces.
+func withCandidate(licenses []ynal.LicenseData, existing ynal.LicenseData, existed bool, title string, text string) []ynal.LicenseData {
+	candidate := ynal.NewLicense(title, text)
+	if existed {
+		existing.Title, existing.Text, existing.Digest = candidate.Title, candidate.Text, candidate.Digest
+		candidate = existing
+	}
+
+	candidates := slices.DeleteFunc(slices.Clone(licenses), func(l ynal.LicenseData) bool {
+		return l.ID == candidate.ID
+	})
+
+	return append(candidates, candidate)
+}
@@ -0,0 +1,176 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/sqlitestore"
+)
+
+func mustAdminHandler(t *testing.T) http.Handler {
+	store, err := ynal.NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not open store: %s", err)
+	}
+
+	h, err := New(WithStore(store), WithAdmin(store), WithTokens(map[string]string{"alice": "s3cret", "bob": "hunter2"}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	return h
+}
+
+func adminRequest(h http.Handler, method string, path string, body string, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestAdminRequiresToken(t *testing.T) {
+	h := mustAdminHandler(t)
+
+	for _, token := range []string{"", "wrong"} {
+		w := adminRequest(h, "PUT", "/admin/licenses/foo", "foo text", token)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 for token %q, got %d", token, w.Code)
+		}
+	}
+
+	if w := adminRequest(h, "GET", "/foo", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected unauthorized put to have no effect, got %d", w.Code)
+	}
+}
+
+func TestAdminPutAndDelete(t *testing.T) {
+	h := mustAdminHandler(t)
+
+	w := adminRequest(h, "PUT", "/admin/licenses/foo-1.0?title=Foo-1.0", "foo text\n", "s3cret")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := adminRequest(h, "GET", "/foo-1.0", "", ""); w.Body.String() != "foo text\n" {
+		t.Fatalf("expected new license to be served, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = adminRequest(h, "PUT", "/admin/licenses/foo-1.0?title=Foo-1.0", "new foo text\n", "s3cret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := adminRequest(h, "GET", "/foo-1.0", "", ""); w.Body.String() != "new foo text\n" {
+		t.Fatalf("expected updated license to be served, got %q", w.Body.String())
+	}
+
+	if w := adminRequest(h, "GET", "/search?q=foo", "", ""); !strings.Contains(w.Body.String(), "Foo-1.0") {
+		t.Fatalf("expected new license in search results, got %q", w.Body.String())
+	}
+
+	if w := adminRequest(h, "DELETE", "/admin/licenses/foo-1.0", "", "s3cret"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+
+	if w := adminRequest(h, "GET", "/foo-1.0", "", ""); w.Code != http.StatusNotFound {
+		t.Fatalf("expected deleted license to 404, got %d", w.Code)
+	}
+
+	if w := adminRequest(h, "DELETE", "/admin/licenses/foo-1.0", "", "s3cret"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestAdminPutRejectsMismatchedTitle(t *testing.T) {
+	h := mustAdminHandler(t)
+
+	w := adminRequest(h, "PUT", "/admin/licenses/foo?title=Bar", "text", "s3cret")
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestAdminPutRejectsUnservableLicenses(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := ynal.NewDirStore(dir)
+	if err != nil {
+		t.Fatalf("could not open store: %s", err)
+	}
+
+	h, err := New(WithStore(store), WithAdmin(store), WithTokens(map[string]string{"alice": "s3cret"}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	for _, id := range []string{"search", "stats", "version", "tags", "bundle"} {
+		w := adminRequest(h, "PUT", "/admin/licenses/"+id, "text\n", "s3cret")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", id, w.Code)
+		}
+	}
+
+	// nothing was written for the next start to trip over
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*")); len(matches) != 0 {
+		t.Fatalf("expected nothing stored, got %v", matches)
+	}
+
+	if _, err := New(WithStore(store)); err != nil {
+		t.Fatalf("expected the store to still load, got: %s", err)
+	}
+}
+
+func TestAdminRequiresTokens(t *testing.T) {
+	store, err := ynal.NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not open store: %s", err)
+	}
+
+	if _, err := New(WithAdmin(store)); err == nil {
+		t.Fatalf("expected an error enabling admin without tokens")
+	}
+}
+
+func TestAdminAliases(t *testing.T) {
+	store, err := sqlitestore.Open(filepath.Join(t.TempDir(), "ynal.db"), []ynal.LicenseData{ynal.NewLicense("MIT", "mit text\n")})
+	if err != nil {
+		t.Fatalf("could not open store: %s", err)
+	}
+	defer store.Close()
+
+	h, err := New(WithStore(store), WithAdmin(store), WithTokens(map[string]string{"alice": "s3cret"}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	if w := adminRequest(h, "PUT", "/admin/aliases/expat?id=nope", "", "s3cret"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+
+	if w := adminRequest(h, "PUT", "/admin/aliases/expat?id=mit", "", "s3cret"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := adminRequest(h, "GET", "/expat", "", ""); w.Header().Get("Location") != "/mit" {
+		t.Fatalf("expected alias to redirect, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	if w := adminRequest(h, "DELETE", "/admin/aliases/expat", "", "s3cret"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+
+	if w := adminRequest(h, "DELETE", "/admin/aliases/expat", "", "s3cret"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
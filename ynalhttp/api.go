@@ -0,0 +1,153 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+// The types below are the /api/v1 schema. They are deliberately separate from
+// ynal.LicenseData so the human-facing routes can change without breaking API
+// consumers. Only ever add fields to them.
+
+type apiLicenseSummary struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	Href       string `json:"href"`
+	Family     string `json:"family,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+}
+
+type apiLicenseList struct {
+	Licenses []apiLicenseSummary `json:"licenses"`
+	Families []apiFamily         `json:"families"`
+}
+
+type apiFamily struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	URL      string   `json:"url"`
+	Licenses []string `json:"licenses"`
+	Latest   string   `json:"latest,omitempty"`
+}
+
+type apiLicense struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title"`
+	URL        string    `json:"url"`
+	Href       string    `json:"href"`
+	Content    string    `json:"content,omitempty"`
+	Digest     apiDigest `json:"digest"`
+	Aliases    []string  `json:"aliases,omitempty"`
+	Family     string    `json:"family,omitempty"`
+	Version    string    `json:"version,omitempty"`
+	Deprecated bool      `json:"deprecated,omitempty"`
+	Successor  string    `json:"successor,omitempty"`
+	Summary    string    `json:"summary,omitempty"`
+}
+
+type apiDigest struct {
+	SHA256 string `json:"sha256"`
+	SHA1   string `json:"sha1"`
+}
+
+func apiHref(base string, l ynal.LicenseData) string {
+	return base + "/api/v1/licenses/" + l.ID
+}
+
+// toAPILicense converts l, whose URL must already have base in front of it.
+func toAPILicense(base string, l ynal.LicenseData) apiLicense {
+	return apiLicense{
+		ID:      l.ID,
+		Title:   l.Title,
+		URL:     l.URL,
+		Href:    apiHref(base, l),
+		Content: l.Text,
+		Digest: apiDigest{
+			SHA256: l.Digest.SHA256,
+			SHA1:   l.Digest.SHA1,
+		},
+		Aliases:    l.Aliases,
+		Family:     l.Family,
+		Version:    l.Version,
+		Deprecated: l.Deprecated,
+		Successor:  l.Successor,
+		Summary:    l.Summary,
+	}
+}
+
+// apiHandlers registers the /api/v1 routes on mux. Responses are always JSON,
+// regardless of the Accept header.
+func apiHandlers(mux *http.ServeMux, licenses []ynal.LicenseData, families []ynal.Family, tmpl *pageTemplates, base string) error {
+	list := apiLicenseList{Licenses: []apiLicenseSummary{}, Families: []apiFamily{}}
+	bodies := map[string][]byte{}
+
+	for _, l := range licenses {
+		list.Licenses = append(list.Licenses, apiLicenseSummary{
+			ID:         l.ID,
+			Title:      l.Title,
+			URL:        l.URL,
+			Href:       apiHref(base, l),
+			Family:     l.Family,
+			Deprecated: l.Deprecated,
+		})
+
+		b, err := json.Marshal(toAPILicense(base, l))
+		if err != nil {
+			return fmt.Errorf("could not marshal JSON: %w", err)
+		}
+
+		bodies[l.ID] = b
+	}
+
+	for _, f := range families {
+		af := apiFamily{ID: f.ID, Name: f.Name, URL: f.URL, Licenses: []string{}}
+		if latest, ok := f.Latest(); ok {
+			af.Latest = latest.ID
+		}
+
+		for _, l := range f.Licenses {
+			af.Licenses = append(af.Licenses, l.ID)
+		}
+
+		list.Families = append(list.Families, af)
+	}
+
+	listBody, err := json.Marshal(list)
+	if err != nil {
+		return fmt.Errorf("could not marshal JSON: %w", err)
+	}
+
+	mux.HandleFunc("GET /api/v1/licenses", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(listBody)
+	})
+
+	mux.HandleFunc("GET /api/v1/licenses/{id}", func(w http.ResponseWriter, r *http.Request) {
+		b, ok := bodies[r.PathValue("id")]
+		if !ok {
+			writeProblem(w, newProblem(r, http.StatusNotFound, fmt.Sprintf("no such license: %s", r.PathValue("id"))))
+			return
+		}
+
+		l, _ := ynal.FindLicense(licenses, r.PathValue("id"))
+		setDeprecationHeaders(w, l, base)
+		countHit(r, l.ID, "api")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	})
+
+	mux.Handle("POST /api/v1/licenses:batch", limitBody(tmpl, maxBatchSize, requestTimeout, batchHandler(licenses, base)))
+	mux.Handle("POST /api/v1/notice", limitBody(tmpl, maxNoticeSize, requestTimeout, noticeHandler(licenses)))
+	mux.Handle("POST /api/v1/sbom", limitBody(tmpl, maxSBOMSize, uploadTimeout, sbomHandler(licenses)))
+
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, newProblem(r, http.StatusNotFound, fmt.Sprintf("no such API route: %s", r.URL.Path)))
+	})
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIListLicenses(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/api/v1/licenses", nil)
+	r.Header.Set("Accept", "text/html")
+
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("expected JSON regardless of Accept, got %q", got)
+	}
+
+	list := apiLicenseList{}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	for _, l := range list.Licenses {
+		if l.ID == "mit" {
+			if l.Title != "MIT" || l.URL != "/mit" || l.Href != "/api/v1/licenses/mit" {
+				t.Fatalf("unexpected summary: %+v", l)
+			}
+
+			return
+		}
+	}
+
+	t.Fatalf("mit missing from %+v", list)
+}
+
+func TestAPIGetLicense(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/api/v1/licenses/mit", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	l := apiLicense{}
+	if err := json.NewDecoder(w.Body).Decode(&l); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	if l.ID != "mit" || l.Content == "" {
+		t.Fatalf("unexpected license: %+v", l)
+	}
+}
+
+func TestAPINotFound(t *testing.T) {
+	h := mustAppHandler(t)
+
+	for _, path := range []string{"/api/v1/licenses/nope", "/api/v2/licenses"} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept", "text/plain")
+
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d", path, w.Code)
+		}
+
+		if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+			t.Fatalf("%s: expected problem+json, got %q", path, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package ynalhttp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// immutableCacheControl is sent with fingerprinted assets. Their content can
+// never change without their path changing too, so caches can keep them
+// forever.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// assets maps the files in public/ to fingerprinted paths with a hash of their
+// content in the name, like styles.1a2b3c4d5e.css, so pages can link to them
+// and have them cached for good.
+type assets struct {
+	fingerprinted map[string]string
+	original      map[string]string
+}
+
+// fingerprintAssets hashes every file in public.
+func fingerprintAssets(public fs.FS) (*assets, error) {
+	a := &assets{fingerprinted: map[string]string{}, original: map[string]string{}}
+
+	err := fs.WalkDir(public, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		b, err := fs.ReadFile(public, p)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(b)
+		ext := path.Ext(p)
+		fp := strings.TrimSuffix(p, ext) + "." + hex.EncodeToString(sum[:5]) + ext
+
+		a.fingerprinted[p] = fp
+		a.original[fp] = p
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not fingerprint public assets: %w", err)
+	}
+
+	return a, nil
+}
+
+// path returns the fingerprinted path of name, relative to public/.
+func (a *assets) path(name string) (string, error) {
+	fp, ok := a.fingerprinted[name]
+	if !ok {
+		return "", fmt.Errorf("no such asset: %q", name)
+	}
+
+	return fp, nil
+}
+
+// linkFunc returns the template function that links to an asset by its
+// fingerprinted path, relative to base.
+func (a *assets) linkFunc(base string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		fp, err := a.path(name)
+		return base + "/" + fp, err
+	}
+}
@@ -0,0 +1,74 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFingerprintedAssets(t *testing.T) {
+	h, err := New(WithCacheControl("public, max-age=60"))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	r := httptest.NewRequest("GET", "/mit", nil)
+	r.Header.Set("Accept", "text/html")
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	m := regexp.MustCompile(`href="(/styles\.[0-9a-f]{10}\.css)"`).FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatalf("expected a fingerprinted stylesheet link, got:\n%s", w.Body.String())
+	}
+
+	tt := []struct {
+		name         string
+		path         string
+		code         int
+		cacheControl string
+	}{
+		{
+			name:         "fingerprinted",
+			path:         m[1],
+			code:         http.StatusOK,
+			cacheControl: immutableCacheControl,
+		},
+		{
+			name:         "original",
+			path:         "/styles.css",
+			code:         http.StatusOK,
+			cacheControl: "public, max-age=60",
+		},
+		{
+			name:         "stale fingerprint",
+			path:         "/styles.0000000000.css",
+			code:         http.StatusNotFound,
+			cacheControl: "public, max-age=60",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d", tc.code, w.Code)
+			}
+
+			if got := w.Header().Get("Cache-Control"); got != tc.cacheControl {
+				t.Fatalf("expected Cache-Control %q, got %q", tc.cacheControl, got)
+			}
+
+			if tc.code == http.StatusOK && !strings.HasPrefix(w.Header().Get("Content-Type"), "text/css") {
+				t.Fatalf("unexpected content type: %q", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package ynalhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/middleware"
+)
+
+// The actions recorded in the audit log.
+const (
+	AuditLicensePut    = "license.put"
+	AuditLicenseDelete = "license.delete"
+	AuditAliasPut      = "alias.put"
+	AuditAliasDelete   = "alias.delete"
+	AuditAcceptance    = "acceptance.record"
+	AuditRejected      = "auth.rejected"
+	AuditReload        = "catalog.reload"
+	AuditConfig        = "config.change"
+)
+
+// AuditEntry is one administrative action: a change made through the admin
+// API, a request turned away from it, the catalog being reloaded, or a
+// change to how ynal is running.
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+
+	// Actor is the name of the token or client certificate a request was
+	// authenticated with, or whatever else made the change, like "store" for
+	// a reload.
+	Actor string `json:"actor,omitempty"`
+
+	// Licenses are the IDs of the licenses affected.
+	Licenses []string `json:"licenses,omitempty"`
+
+	// Alias is the alias affected, for alias actions.
+	Alias string `json:"alias,omitempty"`
+
+	// Detail describes a config change.
+	Detail string `json:"detail,omitempty"`
+
+	// Method, Path, Status, Remote, and RequestID describe the request that
+	// made the change, if one did.
+	Method    string `json:"method,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Status    int    `json:"status,omitempty"`
+	Remote    string `json:"remote,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// AuditLog writes each AuditEntry to a stream as a line of JSON. Entries are
+// only ever appended, one Write each, so a file opened with O_APPEND can be
+// shared between processes.
+type AuditLog struct {
+	mu  sync.Mutex
+	out io.Writer
+	now func() time.Time
+}
+
+// NewAuditLog returns an AuditLog writing to out.
+func NewAuditLog(out io.Writer) *AuditLog {
+	return &AuditLog{out: out, now: time.Now}
+}
+
+// WithAuditLog records every administrative action in a, along with every
+// reload of the catalog.
+func WithAuditLog(a *AuditLog) Option {
+	return func(c *config) {
+		c.audit = a
+	}
+}
+
+// Record writes e to the log, timestamped now if it has no time of its own.
+func (a *AuditLog) Record(e AuditEntry) error {
+	if e.Time.IsZero() {
+		e.Time = a.now()
+	}
+
+	e.Time = e.Time.UTC()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("could not encode audit entry: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := a.out.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("could not write audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// watch records a reload every time the licenses in store change.
+func (a *AuditLog) watch(store ynal.LicenseStore, clock func() time.Time, logger *log.Logger) {
+	var mu sync.Mutex
+	previous := store.List()
+
+	store.Watch(func(licenses []ynal.LicenseData) {
+		mu.Lock()
+		changes := ynal.Diff(previous, licenses)
+		previous = licenses
+		mu.Unlock()
+
+		if changes.Empty() {
+			return
+		}
+
+		ids := slices.Concat(changes.Added, changes.Removed, changes.Changed)
+		slices.Sort(ids)
+
+		err := a.Record(AuditEntry{
+			Time:     clock(),
+			Action:   AuditReload,
+			Actor:    "store",
+			Licenses: ids,
+			Detail:   fmt.Sprintf("%d added, %d removed, %d changed", len(changes.Added), len(changes.Removed), len(changes.Changed)),
+		})
+		if err != nil {
+			logger.Printf("%s", err)
+		}
+	})
+}
+
+type auditKey struct{}
+
+// withAudit makes a available to the handlers below it, for audit.
+func withAudit(a *AuditLog, next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), auditKey{}, a)))
+	})
+}
+
+// auditNote is filled in by an admin handler with what its request is doing,
+// for requireAuth to record once it's done.
+type auditNote struct {
+	action   string
+	licenses []string
+	alias    string
+}
+
+type auditNoteKey struct{}
+
+// noteAudit says what the request is doing, for its audit entry.
+func noteAudit(r *http.Request, action string, alias string, licenses ...string) {
+	if n, ok := r.Context().Value(auditNoteKey{}).(*auditNote); ok {
+		*n = auditNote{action: action, licenses: licenses, alias: alias}
+	}
+}
+
+// audit records an entry for r in the audit log set with WithAuditLog, if
+// there is one, filling in the details of the request.
+func audit(r *http.Request, e AuditEntry) {
+	a, ok := r.Context().Value(auditKey{}).(*AuditLog)
+	if !ok {
+		return
+	}
+
+	e.Time = now(r)
+	e.Method = r.Method
+	e.Path = r.URL.RequestURI()
+	e.Remote = r.RemoteAddr
+	e.RequestID = middleware.RequestIDFrom(r.Context())
+
+	if err := a.Record(e); err != nil {
+		logf(r, "%s", err)
+	}
+}
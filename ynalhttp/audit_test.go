@@ -0,0 +1,98 @@
+package ynalhttp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestAuditLog(t *testing.T) {
+	store, err := ynal.NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not open store: %s", err)
+	}
+
+	var buf bytes.Buffer
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	h, err := New(
+		WithStore(store),
+		WithAdmin(store),
+		WithTokens(map[string]string{"bob": "hunter2"}),
+		WithAuditLog(NewAuditLog(&buf)),
+		WithClock(func() time.Time { return at }),
+	)
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	if w := adminRequest(h, "PUT", "/admin/licenses/foo?title=Foo", "foo text\n", "hunter2"); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := adminRequest(h, "DELETE", "/admin/licenses/foo", "", "nope"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+
+	if w := adminRequest(h, "DELETE", "/admin/licenses/foo", "", "hunter2"); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		e := AuditEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("could not decode %q: %s", scanner.Text(), err)
+		}
+
+		entries = append(entries, e)
+	}
+
+	expected := []AuditEntry{
+		{Action: AuditReload, Actor: "store", Licenses: []string{"foo"}},
+		{Action: AuditLicensePut, Actor: "bob", Licenses: []string{"foo"}, Method: "PUT", Status: http.StatusCreated},
+		{Action: AuditRejected, Method: "DELETE", Status: http.StatusUnauthorized},
+		{Action: AuditReload, Actor: "store", Licenses: []string{"foo"}},
+		{Action: AuditLicenseDelete, Actor: "bob", Licenses: []string{"foo"}, Method: "DELETE", Status: http.StatusNoContent},
+	}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d:\n%s", len(expected), len(entries), buf.String())
+	}
+
+	for i, want := range expected {
+		got := entries[i]
+		if got.Action != want.Action || got.Actor != want.Actor || !slices.Equal(got.Licenses, want.Licenses) || got.Method != want.Method || got.Status != want.Status {
+			t.Errorf("entry %d: expected %+v, got %+v", i, want, got)
+		}
+
+		if !got.Time.Equal(at) {
+			t.Errorf("entry %d: expected time %s, got %s", i, at, got.Time)
+		}
+	}
+}
+
+func TestAuditLogRecord(t *testing.T) {
+	var buf bytes.Buffer
+	a := NewAuditLog(&buf)
+
+	if err := a.Record(AuditEntry{Action: AuditConfig, Actor: "signal", Detail: "maintenance on"}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	e := AuditEntry{}
+	if err := json.Unmarshal(buf.Bytes(), &e); err != nil {
+		t.Fatalf("could not decode %q: %s", buf.String(), err)
+	}
+
+	if e.Time.IsZero() || e.Detail != "maintenance on" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
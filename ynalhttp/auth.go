@@ -0,0 +1,149 @@
+package ynalhttp
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+type actorKey struct{}
+
+// WithTokens sets the bearer tokens accepted on administrative routes. It maps
+// a name for whoever holds each token, which is what shows up in audit logs,
+// to the token itself.
+func WithTokens(tokens map[string]string) Option {
+	return func(c *config) {
+		c.tokens = tokens
+	}
+}
+
+// WithClientCertificates lets administrators authenticate with a TLS client
+// certificate the server has verified, as well as with a bearer token. It maps
+// a name for whoever holds each certificate, as in WithTokens, to the identity
+// the certificate is issued to: its subject's common name, or any DNS name,
+// email address, or URI in it.
+func WithClientCertificates(identities map[string]string) Option {
+	return func(c *config) {
+		c.certs = identities
+	}
+}
+
+// credentials are what administrators can authenticate with.
+type credentials struct {
+	tokens map[string]string
+	certs  map[string]string
+}
+
+// actor returns the name of the token holder that authenticated the request,
+// or the empty string if it wasn't authenticated.
+func actor(r *http.Request) string {
+	name, _ := r.Context().Value(actorKey{}).(string)
+	return name
+}
+
+// authenticate returns the name of the token the request carries, if it
+// carries a valid one, or otherwise of the verified client certificate it was
+// made with.
+func authenticate(r *http.Request, creds credentials) (string, bool) {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || got == "" {
+		return certificateHolder(r, creds.certs)
+	}
+
+	// check every token, so the time taken doesn't give away which one
+	// (if any) was close
+	matched := ""
+	for name, token := range creds.tokens {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			matched = name
+		}
+	}
+
+	return matched, matched != ""
+}
+
+// certificateHolder returns the name of the identity in certs that the
+// request's client certificate was issued to, if the server verified one.
+// Names are checked in order, so one certificate always maps to one name.
+func certificateHolder(r *http.Request, certs map[string]string) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	leaf := r.TLS.VerifiedChains[0][0]
+
+	names := []string{}
+	for name := range certs {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	for _, name := range names {
+		if hasIdentity(leaf, certs[name]) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// hasIdentity reports whether cert was issued to id.
+func hasIdentity(cert *x509.Certificate, id string) bool {
+	if id == "" {
+		return false
+	}
+
+	if cert.Subject.CommonName == id || slices.Contains(cert.DNSNames, id) || slices.Contains(cert.EmailAddresses, id) {
+		return true
+	}
+
+	for _, u := range cert.URIs {
+		if u.String() == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+type auditResponseWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (a *auditResponseWriter) WriteHeader(code int) {
+	a.code = code
+	a.ResponseWriter.WriteHeader(code)
+}
+
+// requireAuth rejects requests without a valid bearer token or client
+// certificate and writes an audit log entry for every request that has one.
+func requireAuth(creds credentials, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := authenticate(r, creds)
+		if !ok {
+			logf(r, "audit: rejected %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+			audit(r, AuditEntry{Action: AuditRejected, Status: http.StatusUnauthorized})
+
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ynal admin"`)
+			writeProblem(w, newProblem(r, http.StatusUnauthorized, "a valid bearer token or client certificate is required"))
+			return
+		}
+
+		aw := &auditResponseWriter{w, http.StatusOK}
+		note := &auditNote{}
+
+		ctx := context.WithValue(r.Context(), actorKey{}, name)
+		next.ServeHTTP(aw, r.WithContext(context.WithValue(ctx, auditNoteKey{}, note)))
+
+		logf(r, "audit: %s %s by %s from %s: %d", r.Method, r.URL.RequestURI(), name, r.RemoteAddr, aw.code)
+
+		if note.action != "" {
+			audit(r, AuditEntry{Action: note.action, Actor: name, Licenses: note.licenses, Alias: note.alias, Status: aw.code})
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestAuthAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	orig := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(orig) })
+
+	h := mustAdminHandler(t)
+
+	if w := adminRequest(h, "PUT", "/admin/licenses/foo?title=Foo", "foo text\n", "hunter2"); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := adminRequest(h, "DELETE", "/admin/licenses/foo", "", "nope"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+
+	logs := buf.String()
+
+	for _, want := range []string{
+		"audit: PUT /admin/licenses/foo?title=Foo by bob",
+		": 201",
+		"audit: rejected DELETE /admin/licenses/foo",
+	} {
+		if !strings.Contains(logs, want) {
+			t.Fatalf("expected %q in audit log, got:\n%s", want, logs)
+		}
+	}
+
+	if strings.Contains(logs, "hunter2") || strings.Contains(logs, "nope") {
+		t.Fatalf("expected tokens to stay out of the audit log, got:\n%s", logs)
+	}
+}
+
+func TestAuthClientCertificates(t *testing.T) {
+	store, err := ynal.NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("could not open store: %s", err)
+	}
+
+	h, err := New(WithStore(store), WithAdmin(store), WithClientCertificates(map[string]string{
+		"alice": "alice@example.com",
+		"bot":   "spiffe://example.com/deploy",
+		"carol": "carol",
+	}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	spiffe, _ := url.Parse("spiffe://example.com/deploy")
+
+	tt := []struct {
+		name       string
+		cert       *x509.Certificate
+		authorized bool
+	}{
+		{
+			name:       "no certificate",
+			authorized: false,
+		},
+		{
+			name:       "email address",
+			cert:       &x509.Certificate{EmailAddresses: []string{"alice@example.com"}},
+			authorized: true,
+		},
+		{
+			name:       "uri",
+			cert:       &x509.Certificate{URIs: []*url.URL{spiffe}},
+			authorized: true,
+		},
+		{
+			name:       "common name",
+			cert:       &x509.Certificate{Subject: pkix.Name{CommonName: "carol"}},
+			authorized: true,
+		},
+		{
+			name:       "unknown identity",
+			cert:       &x509.Certificate{Subject: pkix.Name{CommonName: "mallory"}},
+			authorized: false,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/admin/licenses/foo?title=Foo", strings.NewReader("foo text\n"))
+			if tc.cert != nil {
+				r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{tc.cert}}}
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if authorized := w.Code != http.StatusUnauthorized; authorized != tc.authorized {
+				t.Errorf("expected authorized to be %t, got %d: %s", tc.authorized, w.Code, w.Body.String())
+			}
+		})
+	}
+}
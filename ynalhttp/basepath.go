@@ -0,0 +1,104 @@
+package ynalhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+type basePathKey struct{}
+
+// WithBasePath serves everything under prefix (e.g. "/licenses") rather than
+// at the root, including every URL ynal generates. Requests are expected to
+// arrive with the prefix still on, so mount the handler at prefix + "/"
+// without stripping it.
+func WithBasePath(prefix string) Option {
+	return func(c *config) {
+		c.basePath = strings.TrimSuffix("/"+strings.Trim(prefix, "/"), "/")
+	}
+}
+
+// defaultSiteURL is where pages say they're served from unless WithSiteURL
+// says otherwise.
+const defaultSiteURL = "https://ynal.packrat386.com"
+
+// WithSiteURL sets the URL ynal is reached at, like
+// "https://licenses.example.com", for the absolute URLs in pages: canonical
+// links, social cards, and the curl commands to copy. The base path goes
+// after it. It defaults to https://ynal.packrat386.com.
+func WithSiteURL(site string) Option {
+	return func(c *config) {
+		c.siteURL = site
+	}
+}
+
+// validateSiteURL returns site without a trailing slash, or an error if it
+// isn't an absolute http or https URL without a query.
+func validateSiteURL(site string) (string, error) {
+	u, err := url.Parse(site)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("site URL must be an absolute http or https URL, like https://example.com, got %q", site)
+	}
+
+	return strings.TrimSuffix(site, "/"), nil
+}
+
+// basePath returns the prefix the request was served under, if any.
+func basePath(r *http.Request) string {
+	base, _ := r.Context().Value(basePathKey{}).(string)
+	return base
+}
+
+// withBasePath strips base from the path of every request before passing it
+// on, so every route can be written as if it were at the root. Anything
+// outside base is a 404.
+func withBasePath(base string, tmpl *pageTemplates, next http.Handler) http.Handler {
+	if base == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == base {
+			target := base + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+
+			http.Redirect(w, r, target, http.StatusPermanentRedirect)
+			return
+		}
+
+		rest, ok := strings.CutPrefix(r.URL.Path, base+"/")
+		if !ok {
+			writeError(w, r, tmpl, http.StatusNotFound, "nothing found at "+r.URL.Path)
+			return
+		}
+
+		r2 := r.WithContext(context.WithValue(r.Context(), basePathKey{}, base))
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = "/" + rest
+		r2.URL.RawPath = ""
+
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// withBase returns copies of licenses with base in front of every URL.
+func withBase(licenses []ynal.LicenseData, base string) []ynal.LicenseData {
+	if base == "" {
+		return licenses
+	}
+
+	linked := make([]ynal.LicenseData, len(licenses))
+	for i, l := range licenses {
+		l.URL = base + l.URL
+		linked[i] = l
+	}
+
+	return linked
+}
@@ -0,0 +1,169 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBasePath(t *testing.T) {
+	h, err := New(WithBasePath("/licenses/"))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		path     string
+		accept   string
+		code     int
+		location string
+		contains []string
+	}{
+		{
+			name:     "license",
+			path:     "/licenses/mit",
+			accept:   "application/json",
+			code:     http.StatusOK,
+			contains: []string{`"url":"/licenses/mit"`},
+		},
+		{
+			name:     "license page",
+			path:     "/licenses/mit",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			contains: []string{`href="/licenses/styles.`, `href="/licenses/download/mit"`, `href="https://ynal.packrat386.com/licenses/mit"`},
+		},
+		{
+			name:     "index",
+			path:     "/licenses/",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			contains: []string{`href="/licenses/mit"`, `action="/licenses/search"`},
+		},
+		{
+			name:     "api",
+			path:     "/licenses/api/v1/licenses",
+			code:     http.StatusOK,
+			contains: []string{`"url":"/licenses/mit","href":"/licenses/api/v1/licenses/mit"`},
+		},
+		{
+			name:     "search",
+			path:     "/licenses/search?q=mit",
+			accept:   "application/json",
+			code:     http.StatusOK,
+			contains: []string{`"url":"/licenses/mit"`},
+		},
+		{
+			name:   "assets",
+			path:   "/licenses/styles.css",
+			accept: "text/css",
+			code:   http.StatusOK,
+		},
+		{
+			name:     "bare prefix",
+			path:     "/licenses?x=1",
+			code:     http.StatusPermanentRedirect,
+			location: "/licenses/?x=1",
+		},
+		{
+			name:     "not found",
+			path:     "/licenses/nope",
+			accept:   "application/json",
+			code:     http.StatusNotFound,
+			contains: []string{`"instance":"/licenses/nope"`},
+		},
+		{
+			name: "outside the prefix",
+			path: "/mit",
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if got := w.Header().Get("Location"); got != tc.location {
+				t.Fatalf("expected Location %q, got %q", tc.location, got)
+			}
+
+			for _, want := range tc.contains {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Fatalf("expected %q in body, got:\n%s", want, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestSiteURL(t *testing.T) {
+	h, err := New(WithSiteURL("https://licenses.example.com/"), WithBasePath("/licenses"))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		path     string
+		expected []string
+	}{
+		{
+			path: "/licenses/mit",
+			expected: []string{
+				`<link rel="canonical" href="https://licenses.example.com/licenses/mit"/>`,
+				`<meta property="og:url" content="https://licenses.example.com/licenses/mit"/>`,
+				`"url": "https://licenses.example.com/licenses/mit"`,
+				`"url": "https://licenses.example.com/licenses/"`,
+				"curl -s --output LICENSE.txt https://licenses.example.com/licenses/mit",
+			},
+		},
+		{
+			path:     "/licenses/mit?print=1",
+			expected: []string{`<p class="source">https://licenses.example.com/licenses/mit</p>`},
+		},
+		{
+			path:     "/licenses/mit/summary",
+			expected: []string{`<link rel="canonical" href="https://licenses.example.com/licenses/mit/summary"/>`},
+		},
+		{
+			path:     "/licenses/",
+			expected: []string{"curl -s --output LICENSE.txt https://licenses.example.com/licenses/mit"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.path, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			for _, want := range tc.expected {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("expected the page to contain %q", want)
+				}
+			}
+
+			if strings.Contains(w.Body.String(), "ynal.packrat386.com") {
+				t.Errorf("expected no links to the default site")
+			}
+		})
+	}
+
+	for _, site := range []string{"licenses.example.com", "ftp://example.com", "https://example.com?a=b", "https://example.com/#top"} {
+		if _, err := New(WithSiteURL(site)); err == nil || !strings.Contains(err.Error(), "site URL must be") {
+			t.Errorf("%s: expected an invalid site URL error, got: %v", site, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+// maxBatchSize bounds batch requests, which are a list of IDs. It's room for
+// a few thousand.
+const maxBatchSize = 64 << 10
+
+// batchRequest is the body of a batch request.
+type batchRequest struct {
+	// IDs are the licenses to fetch, by ID or alias.
+	IDs []string `json:"ids"`
+
+	// Text includes each license's full text, which is left out by default
+	// since scanners mostly want the metadata.
+	Text bool `json:"text"`
+}
+
+// batchHandler serves the licenses listed in a JSON batchRequest, in the order
+// they're asked for, so tools checking a whole dependency tree can fetch
+// every license at once. If any of them don't exist, none are served.
+func batchHandler(licenses []ynal.LicenseData, base string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req batchRequest
+
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		if err := dec.Decode(&req); err != nil {
+			writeProblem(w, newProblem(r, bodyStatus(err), fmt.Sprintf("could not parse batch: %s", err)))
+			return
+		}
+
+		if len(req.IDs) == 0 {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, `no licenses requested, list them like {"ids": ["mit", "apache_2"]}`))
+			return
+		}
+
+		found := []ynal.LicenseData{}
+		missing := []string{}
+
+		for _, id := range req.IDs {
+			l, ok := findByIDOrAlias(licenses, strings.ToLower(id))
+			if !ok {
+				missing = append(missing, id)
+				continue
+			}
+
+			found = append(found, l)
+		}
+
+		if len(missing) > 0 {
+			writeProblem(w, newProblem(r, http.StatusUnprocessableEntity, fmt.Sprintf("no such licenses: %s", strings.Join(missing, ", "))))
+			return
+		}
+
+		batch := []apiLicense{}
+		for _, l := range found {
+			countHit(r, l.ID, "api")
+
+			al := toAPILicense(base, l)
+			if !req.Text {
+				al.Content = ""
+			}
+
+			batch = append(batch, al)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(batch)
+	})
+}
@@ -0,0 +1,96 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	tt := []struct {
+		name     string
+		body     string
+		code     int
+		expected []string
+		text     bool
+	}{
+		{
+			name:     "metadata",
+			body:     `{"ids": ["mit", "GPL_3"]}`,
+			code:     http.StatusOK,
+			expected: []string{"mit", "gpl_3"},
+		},
+		{
+			name:     "with text",
+			body:     `{"ids": ["apache_2"], "text": true}`,
+			code:     http.StatusOK,
+			expected: []string{"apache_2"},
+			text:     true,
+		},
+		{
+			name:     "in the order asked",
+			body:     `{"ids": ["unlicense", "bsd_3", "unlicense"]}`,
+			code:     http.StatusOK,
+			expected: []string{"unlicense", "bsd_3", "unlicense"},
+		},
+		{
+			name: "missing",
+			body: `{"ids": ["mit", "nope"]}`,
+			code: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "empty",
+			body: `{"ids": []}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "malformed",
+			body: `["mit"]`,
+			code: http.StatusBadRequest,
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/licenses:batch", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if tc.code != http.StatusOK {
+				if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+					t.Fatalf("expected a problem, got %q", got)
+				}
+
+				return
+			}
+
+			got := []apiLicense{}
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("could not decode response: %s", err)
+			}
+
+			if len(got) != len(tc.expected) {
+				t.Fatalf("expected %v, got %+v", tc.expected, got)
+			}
+
+			for i, l := range got {
+				if l.ID != tc.expected[i] {
+					t.Errorf("expected %s at %d, got %s", tc.expected[i], i, l.ID)
+				}
+
+				if (l.Content != "") != tc.text {
+					t.Errorf("expected text %t for %s, got %q", tc.text, l.ID, l.Content)
+				}
+			}
+		})
+	}
+}
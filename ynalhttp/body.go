@@ -0,0 +1,75 @@
+package ynalhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// How long a request with a body has to send it and be answered. Small JSON
+// requests should take no time at all; uploads get longer, since they can be
+// big and scans look up each dependency too.
+const (
+	requestTimeout = 10 * time.Second
+	uploadTimeout  = time.Minute
+)
+
+// limitBody bounds the body of every request to next to limit bytes, and the
+// whole request to timeout: reading the body after that fails, as does
+// anything still using the request's context. A body that says up front it's
+// too big is refused without reading it.
+func limitBody(tmpl *pageTemplates, limit int64, timeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			w.Header().Set("Connection", "close")
+			writeError(w, r, tmpl, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is larger than the limit of %d bytes", limit))
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		// not every ResponseWriter can, but the server's can, and the context
+		// covers the rest between reads
+		http.NewResponseController(w).SetReadDeadline(time.Now().Add(timeout))
+
+		r = r.WithContext(ctx)
+		r.Body = http.MaxBytesReader(w, contextReader{ctx: ctx, ReadCloser: r.Body}, limit)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// contextReader stops reading once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	io.ReadCloser
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return c.ReadCloser.Read(p)
+}
+
+// bodyStatus is the status to answer with when reading a request's body
+// failed with err: 413 if it was too big, 408 if it took too long, and 400
+// for anything else, like a malformed body.
+func bodyStatus(err error) int {
+	var tooBig *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &tooBig):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded):
+		return http.StatusRequestTimeout
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,129 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowReader sends one byte at a time, waiting delay before each.
+type slowReader struct {
+	delay time.Duration
+}
+
+func (s slowReader) Read(p []byte) (int, error) {
+	time.Sleep(s.delay)
+	p[0] = ' '
+
+	return 1, nil
+}
+
+func TestBodyLimits(t *testing.T) {
+	big := strings.Repeat("a", maxBatchSize+1)
+
+	tt := []struct {
+		name        string
+		path        string
+		body        func() io.Reader
+		length      int64
+		contentType string
+		accept      string
+		code        int
+		responseCT  string
+	}{
+		{
+			name:       "declared too large",
+			path:       "/api/v1/licenses:batch",
+			body:       func() io.Reader { return strings.NewReader(big) },
+			length:     int64(len(big)),
+			accept:     "text/html",
+			code:       http.StatusRequestEntityTooLarge,
+			responseCT: "text/html",
+		},
+		{
+			name:       "declared too large as JSON",
+			path:       "/api/v1/licenses:batch",
+			body:       func() io.Reader { return strings.NewReader(big) },
+			length:     int64(len(big)),
+			accept:     "application/json",
+			code:       http.StatusRequestEntityTooLarge,
+			responseCT: "application/problem+json",
+		},
+		{
+			name:       "streamed too large",
+			path:       "/api/v1/licenses:batch",
+			body:       func() io.Reader { return strings.NewReader(`{"ids": ["` + big + `"]}`) },
+			length:     -1,
+			code:       http.StatusRequestEntityTooLarge,
+			responseCT: "application/problem+json",
+		},
+		{
+			name:        "upload too large",
+			path:        "/api/v1/reuse",
+			body:        func() io.Reader { return bytes.NewReader(make([]byte, maxReuseSize+1)) },
+			length:      -1,
+			contentType: "application/octet-stream",
+			code:        http.StatusRequestEntityTooLarge,
+			responseCT:  "application/problem+json",
+		},
+		{
+			name:       "malformed",
+			path:       "/api/v1/licenses:batch",
+			body:       func() io.Reader { return strings.NewReader("{") },
+			length:     1,
+			code:       http.StatusBadRequest,
+			responseCT: "application/problem+json",
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tc.path+"?filename=x.txt", tc.body())
+			r.ContentLength = tc.length
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tc.responseCT {
+				t.Errorf("expected Content-Type %q, got %q", tc.responseCT, got)
+			}
+		})
+	}
+}
+
+func TestBodyTimeout(t *testing.T) {
+	h := limitBody(mustTemplates(t), 1<<20, 20*time.Millisecond, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			writeProblem(w, newProblem(r, bodyStatus(err), err.Error()))
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	r := httptest.NewRequest("POST", "/similarity", slowReader{delay: 5 * time.Millisecond})
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestTimeout {
+		t.Fatalf("expected 408, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,170 @@
+package ynalhttp
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+// bundleFormat is a kind of archive licenses can be bundled into.
+type bundleFormat struct {
+	contentType string
+	write       func(w io.Writer, licenses []ynal.LicenseData) error
+}
+
+var bundleFormats = map[string]bundleFormat{
+	"zip":    {contentType: "application/zip", write: writeZip},
+	"tar.gz": {contentType: "application/gzip", write: writeTarGz},
+}
+
+// bundleModTime is the modification time of every file in a bundle, so the
+// same licenses always make the same archive.
+var bundleModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// bundleName is the name of a license's file in a bundle.
+func bundleName(l ynal.LicenseData) string {
+	return l.Title + ".txt"
+}
+
+func writeZip(w io.Writer, licenses []ynal.LicenseData) error {
+	zw := zip.NewWriter(w)
+
+	for _, l := range licenses {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: bundleName(l), Method: zip.Deflate, Modified: bundleModTime})
+		if err != nil {
+			return fmt.Errorf("could not add %s: %w", l.ID, err)
+		}
+
+		if _, err := io.WriteString(f, l.Text); err != nil {
+			return fmt.Errorf("could not write %s: %w", l.ID, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeTarGz(w io.Writer, licenses []ynal.LicenseData) error {
+	gw := getGzipWriter(w)
+	defer putGzipWriter(gw)
+
+	tw := tar.NewWriter(gw)
+
+	for _, l := range licenses {
+		hdr := &tar.Header{
+			Name:    bundleName(l),
+			Mode:    0644,
+			Size:    int64(len(l.Text)),
+			ModTime: bundleModTime,
+			Format:  tar.FormatPAX,
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("could not add %s: %w", l.ID, err)
+		}
+
+		if _, err := io.WriteString(tw, l.Text); err != nil {
+			return fmt.Errorf("could not write %s: %w", l.ID, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// serveBundle streams licenses as an archive named filename. The archive is
+// written as it's built, so a failure partway can only be logged.
+func serveBundle(w http.ResponseWriter, r *http.Request, format string, filename string, licenses []ynal.LicenseData) {
+	bf := bundleFormats[format]
+
+	w.Header().Set("Content-Type", bf.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+
+	if err := bf.write(w, licenses); err != nil {
+		logf(r, "could not write bundle: %s", err)
+	}
+}
+
+// bundleHandler serves the licenses listed in the licenses query parameter,
+// comma separated, as a zip (or a tar.gz with format=tar.gz).
+func bundleHandler(licenses []ynal.LicenseData, tmpl *pageTemplates) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "zip"
+		}
+
+		if _, ok := bundleFormats[format]; !ok {
+			writeError(w, r, tmpl, http.StatusBadRequest, fmt.Sprintf("unknown bundle format: %s (try zip or tar.gz)", format))
+			return
+		}
+
+		found, missing := parseBundle(licenses, r.URL.Query().Get("licenses"))
+		if len(missing) > 0 {
+			writeError(w, r, tmpl, http.StatusNotFound, fmt.Sprintf("no such licenses: %s", strings.Join(missing, ", ")))
+			return
+		}
+
+		if len(found) == 0 {
+			writeError(w, r, tmpl, http.StatusBadRequest, "no licenses requested, list them like ?licenses=mit,apache_2")
+			return
+		}
+
+		serveBundle(w, r, format, "licenses", found)
+	})
+}
+
+// allHandler serves every license as a single archive.
+func allHandler(licenses []ynal.LicenseData, format string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveBundle(w, r, format, "licenses", licenses)
+	})
+}
+
+// parseBundle returns the licenses a bundle request asks for, by ID or alias,
+// in the order asked, along with any IDs that don't exist.
+func parseBundle(licenses []ynal.LicenseData, query string) ([]ynal.LicenseData, []string) {
+	found := []ynal.LicenseData{}
+	missing := []string{}
+
+	for _, id := range strings.Split(query, ",") {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" {
+			continue
+		}
+
+		l, ok := findByIDOrAlias(licenses, id)
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+
+		if !slices.ContainsFunc(found, func(f ynal.LicenseData) bool { return f.ID == l.ID }) {
+			found = append(found, l)
+		}
+	}
+
+	return found, missing
+}
+
+// findByIDOrAlias finds the license with the given ID, or with it as an
+// alias.
+func findByIDOrAlias(licenses []ynal.LicenseData, id string) (ynal.LicenseData, bool) {
+	i := slices.IndexFunc(licenses, func(l ynal.LicenseData) bool {
+		return l.ID == id || slices.Contains(l.Aliases, id)
+	})
+	if i == -1 {
+		return ynal.LicenseData{}, false
+	}
+
+	return licenses[i], true
+}
@@ -0,0 +1,156 @@
+package ynalhttp
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func zipNames(t *testing.T, body []byte) []string {
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("could not read zip: %s", err)
+	}
+
+	names := []string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+
+	return names
+}
+
+func tarNames(t *testing.T, body []byte) []string {
+	gr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("could not read gzip: %s", err)
+	}
+
+	tr := tar.NewReader(gr)
+	names := []string{}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not read tar: %s", err)
+		}
+
+		names = append(names, hdr.Name)
+	}
+
+	return names
+}
+
+func TestBundle(t *testing.T) {
+	mit := ynal.NewLicense("MIT", "mit text\n")
+	mit.Aliases = []string{"expat"}
+
+	h, err := New(WithLicenses([]ynal.LicenseData{mit, ynal.NewLicense("Apache_2", "apache text\n"), ynal.NewLicense("BSD_3", "bsd text\n")}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		name        string
+		path        string
+		code        int
+		contentType string
+		filename    string
+		files       []string
+	}{
+		{
+			name:        "zip",
+			path:        "/bundle?licenses=apache_2,expat,mit",
+			code:        http.StatusOK,
+			contentType: "application/zip",
+			filename:    "licenses.zip",
+			files:       []string{"Apache_2.txt", "MIT.txt"},
+		},
+		{
+			name:        "tar.gz",
+			path:        "/bundle?licenses=mit&format=tar.gz",
+			code:        http.StatusOK,
+			contentType: "application/gzip",
+			filename:    "licenses.tar.gz",
+			files:       []string{"MIT.txt"},
+		},
+		{
+			name:        "all",
+			path:        "/all.zip",
+			code:        http.StatusOK,
+			contentType: "application/zip",
+			filename:    "licenses.zip",
+			files:       []string{"MIT.txt", "Apache_2.txt", "BSD_3.txt"},
+		},
+		{
+			name:        "all tar.gz",
+			path:        "/all.tar.gz",
+			code:        http.StatusOK,
+			contentType: "application/gzip",
+			filename:    "licenses.tar.gz",
+			files:       []string{"MIT.txt", "Apache_2.txt", "BSD_3.txt"},
+		},
+		{
+			name: "missing license",
+			path: "/bundle?licenses=mit,nope",
+			code: http.StatusNotFound,
+		},
+		{
+			name: "nothing requested",
+			path: "/bundle",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "unknown format",
+			path: "/bundle?licenses=mit&format=rar",
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Fatalf("expected Content-Type %q, got %q", tc.contentType, got)
+			}
+
+			if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="`+tc.filename+`"` {
+				t.Fatalf("unexpected Content-Disposition: %q", got)
+			}
+
+			names := []string{}
+			if tc.contentType == "application/zip" {
+				names = zipNames(t, w.Body.Bytes())
+			} else {
+				names = tarNames(t, w.Body.Bytes())
+			}
+
+			if !slices.Equal(names, tc.files) {
+				t.Fatalf("expected %v in the bundle, got %v", tc.files, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package ynalhttp
+
+import (
+	"container/list"
+	"sync"
+)
+
+// WithRenderBudget keeps at most about budget bytes of rendered license pages
+// in memory: the HTML in every style, theme, and language, and the plain text
+// in every layout. As many as fit are rendered up front, and the rest are
+// rendered when they're asked for and kept until less recently used pages
+// push them out. Zero, the default, keeps every page, all rendered up front.
+//
+// The budget is per handler from New, and while the licenses are being
+// rebuilt the pages for the old ones are kept until the new ones are served.
+func WithRenderBudget(budget int64) Option {
+	return func(c *config) {
+		c.renderBudget = budget
+	}
+}
+
+// renderCache is an LRU cache of rendered pages, bounded by the size of their
+// bodies. A nil *renderCache keeps nothing, rendering every page every time.
+type renderCache struct {
+	budget int64
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key  string
+	page renderedPage
+}
+
+// newRenderCache returns a cache of up to budget bytes, or unbounded if
+// budget is zero.
+func newRenderCache(budget int64) *renderCache {
+	return &renderCache{budget: budget, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// get returns the page cached under key, rendering and caching it if it isn't
+// there.
+func (c *renderCache) get(key string, render func() (renderedPage, error)) (renderedPage, error) {
+	if c == nil {
+		return render()
+	}
+
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		c.mu.Unlock()
+
+		return e.Value.(*cacheEntry).page, nil
+	}
+	c.mu.Unlock()
+
+	// rendered outside the lock, so one slow page doesn't hold up the rest;
+	// two requests for the same page might both render it
+	p, err := render()
+	if err != nil {
+		return p, err
+	}
+
+	c.add(key, p, true)
+
+	return p, nil
+}
+
+// warm renders the page for key up front, if there's still room for it. It
+// renders it regardless while the cache isn't full, so a template that can't
+// be rendered is caught before anything is served.
+func (c *renderCache) warm(key string, render func() (renderedPage, error)) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	full := c.budget > 0 && c.size >= c.budget
+	c.mu.Unlock()
+
+	if full {
+		return nil
+	}
+
+	p, err := render()
+	if err != nil {
+		return err
+	}
+
+	c.add(key, p, false)
+
+	return nil
+}
+
+// add caches p under key, pushing out the least recently used pages to make
+// room if evict is set. Pages larger than the whole budget are never cached.
+func (c *renderCache) add(key string, p renderedPage, evict bool) {
+	size := int64(len(p.body))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+
+	if c.budget > 0 {
+		if size > c.budget || (!evict && c.size+size > c.budget) {
+			return
+		}
+
+		for c.size+size > c.budget {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+
+			e := oldest.Value.(*cacheEntry)
+			delete(c.entries, e.key)
+			c.size -= int64(len(e.page.body))
+		}
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, page: p})
+	c.size += size
+}
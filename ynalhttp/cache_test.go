@@ -0,0 +1,141 @@
+package ynalhttp
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestRenderCache(t *testing.T) {
+	renders := 0
+	page := func(body string) func() (renderedPage, error) {
+		return func() (renderedPage, error) {
+			renders++
+			return newRenderedPage([]byte(body)), nil
+		}
+	}
+
+	c := newRenderCache(10)
+
+	for _, key := range []string{"a", "b"} {
+		if err := c.warm(key, page("1234")); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	// warming stops once the budget is spent rather than push anything out
+	if err := c.warm("c", page("1234")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, ok := c.entries["c"]; ok {
+		t.Errorf("expected warming past the budget to keep nothing")
+	}
+
+	renders = 0
+
+	c.get("a", page("1234"))
+	if renders != 0 {
+		t.Errorf("expected a to be cached, rendered %d times", renders)
+	}
+
+	// b is the least recently used, so it makes room for c
+	c.get("c", page("1234"))
+	if _, ok := c.entries["b"]; ok {
+		t.Errorf("expected b to be evicted")
+	}
+
+	if _, ok := c.entries["a"]; !ok {
+		t.Errorf("expected a to be kept")
+	}
+
+	if c.size != 8 {
+		t.Errorf("expected 8 bytes cached, got %d", c.size)
+	}
+
+	// too big to ever cache, but still served
+	p, err := c.get("d", page("12345678901"))
+	if err != nil || string(p.body) != "12345678901" {
+		t.Errorf("expected the page to be rendered, got %q, %v", p.body, err)
+	}
+
+	if _, ok := c.entries["d"]; ok || len(c.entries) != 2 {
+		t.Errorf("expected an oversized page to leave the cache alone, got %d entries", len(c.entries))
+	}
+
+	if err := c.warm("e", func() (renderedPage, error) { return renderedPage{}, errors.New("bad template") }); err == nil {
+		t.Errorf("expected a failed render to be returned from warm")
+	}
+
+	var none *renderCache
+
+	renders = 0
+	for range 2 {
+		none.get("a", page("1234"))
+	}
+
+	if renders != 2 {
+		t.Errorf("expected a nil cache to render every time, rendered %d times", renders)
+	}
+}
+
+func TestRenderBudget(t *testing.T) {
+	licenses := []ynal.LicenseData{
+		ynal.NewLicense("MIT", strings.Repeat("mit text goes on and on ", 50)+"\n"),
+		ynal.NewLicense("Zlib", strings.Repeat("zlib text goes on and on ", 50)+"\n"),
+	}
+
+	requests := []struct {
+		path   string
+		accept string
+		lang   string
+	}{
+		{path: "/mit", accept: "text/html"},
+		{path: "/mit?layout=reflow", accept: "text/html", lang: "de"},
+		{path: "/zlib?print", accept: "text/html", lang: "fr"},
+		{path: "/mit", accept: "text/html"},
+		{path: "/mit?width=72", accept: "text/plain"},
+		{path: "/raw/zlib?width=40&eol=crlf", accept: "text/plain"},
+		{path: "/raw/zlib?width=40&eol=crlf", accept: "text/plain"},
+	}
+
+	serve := func(budget int64) []string {
+		h, err := New(WithLicenses(licenses), WithRenderBudget(budget))
+		if err != nil {
+			t.Fatalf("could not initialize app handler: %s", err)
+		}
+
+		bodies := []string{}
+		for _, req := range requests {
+			r := httptest.NewRequest("GET", req.path, nil)
+			r.Header.Set("Accept", req.accept)
+			r.Header.Set("Accept-Language", req.lang)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != 200 {
+				t.Fatalf("budget %d, %s: expected 200, got %d", budget, req.path, w.Code)
+			}
+
+			bodies = append(bodies, w.Body.String())
+		}
+
+		return bodies
+	}
+
+	expected := serve(0)
+
+	for _, budget := range []int64{1, 4 << 10, 64 << 10} {
+		got := serve(budget)
+
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Errorf("budget %d, %s: expected the same page as with no budget", budget, requests[i].path)
+			}
+		}
+	}
+}
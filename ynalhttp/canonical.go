@@ -0,0 +1,42 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// withCanonicalPath redirects requests for paths with a trailing slash,
+// repeated slashes, or dot segments to the one path that's actually served,
+// so /mit/ and //mit both end up at /mit. It sits below withBasePath, so
+// redirects keep the base path. Nothing is served at a path with a
+// backslash, so those are a 404 rather than a redirect, which browsers would
+// read as one to another site when it starts with /\.
+func withCanonicalPath(tmpl *pageTemplates, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, `\`) {
+			writeError(w, r, tmpl, http.StatusNotFound, "nothing found at "+r.URL.Path)
+			return
+		}
+
+		canonical := path.Clean(r.URL.Path)
+		if canonical == r.URL.Path {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		target := basePath(r) + canonical
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+
+		// 301 is what links and crawlers understand best, but only 308
+		// promises clients will resend a POST's body
+		code := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			code = http.StatusPermanentRedirect
+		}
+
+		http.Redirect(w, r, target, code)
+	})
+}
@@ -0,0 +1,59 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanonicalPath(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     []Option
+		method   string
+		path     string
+		code     int
+		location string
+	}{
+		{name: "trailing slash", path: "/mit/", code: http.StatusMovedPermanently, location: "/mit"},
+		{name: "duplicate slashes", path: "//family//gpl", code: http.StatusMovedPermanently, location: "/family/gpl"},
+		{name: "dot segments", path: "/raw/./mit", code: http.StatusMovedPermanently, location: "/raw/mit"},
+		{name: "query kept", path: "/header/mit/?lang=go", code: http.StatusMovedPermanently, location: "/header/mit?lang=go"},
+		{name: "post keeps its method", method: "POST", path: "/spdx/validate/", code: http.StatusPermanentRedirect, location: "/spdx/validate"},
+		{name: "base path", opts: []Option{WithBasePath("/licenses")}, path: "/licenses/mit/", code: http.StatusMovedPermanently, location: "/licenses/mit"},
+		{name: "index", path: "/", code: http.StatusOK},
+		{name: "canonical", path: "/mit", code: http.StatusOK},
+		{name: "public directory", path: "/themes/", code: http.StatusMovedPermanently, location: "/themes"},
+		{name: "backslash", path: "/%5Cevil.com/", code: http.StatusNotFound},
+		{name: "backslash after slashes", path: "//%5Cevil.com//", code: http.StatusNotFound},
+		{name: "protocol-relative", path: "//evil.com/", code: http.StatusMovedPermanently, location: "/evil.com"},
+		{name: "public directory without slash", path: "/themes", code: http.StatusNotFound},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("could not initialize app handler: %s", err)
+			}
+
+			method := tc.method
+			if method == "" {
+				method = "GET"
+			}
+
+			r := httptest.NewRequest(method, tc.path, nil)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d", tc.code, w.Code)
+			}
+
+			if got := w.Header().Get("Location"); got != tc.location {
+				t.Fatalf("expected Location %q, got %q", tc.location, got)
+			}
+		})
+	}
+}
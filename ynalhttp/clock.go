@@ -0,0 +1,34 @@
+package ynalhttp
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// WithClock sets what ynal asks for the time, for timestamps in what it
+// serves. Without it, that's time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(c *config) {
+		c.clock = now
+	}
+}
+
+type clockKey struct{}
+
+// withClock makes now available to every handler below it, for now.
+func withClock(now func() time.Time, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clockKey{}, now)))
+	})
+}
+
+// now is the time according to the clock set with WithClock, or time.Now for
+// a request that didn't come through New.
+func now(r *http.Request) time.Time {
+	if clock, ok := r.Context().Value(clockKey{}).(func() time.Time); ok {
+		return clock()
+	}
+
+	return time.Now()
+}
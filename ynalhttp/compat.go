@@ -0,0 +1,139 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/spdx"
+)
+
+type compatResult struct {
+	From       string                   `json:"from,omitempty"`
+	Into       string                   `json:"into,omitempty"`
+	Expression string                   `json:"expression,omitempty"`
+	Verdict    ynal.Verdict             `json:"verdict"`
+	Checks     []ynal.CompatibilityRule `json:"checks"`
+}
+
+// compatPage is what the compatibility template is rendered with.
+type compatPage struct {
+	Licenses []ynal.LicenseData
+
+	// From, Into, and Expression are what was asked, to fill the form back
+	// in.
+	From       string
+	Into       string
+	Expression string
+
+	Result *compatResult
+}
+
+// spdxID returns the SPDX identifier of the license id names, or id itself if
+// it's not in the catalog, since the rules know about more licenses than ynal
+// serves. It also returns the ynal ID, if there is one.
+func spdxID(licenses []ynal.LicenseData, id string) (string, string) {
+	if l, ok := spdx.Lookup(licenses, id); ok {
+		return l.SPDXID(), l.ID
+	}
+
+	return id, id
+}
+
+// checkCompatibility answers the question in the query string: either from
+// and into, or an SPDX expression. It returns nil if nothing was asked.
+func checkCompatibility(licenses []ynal.LicenseData, c *ynal.Compatibility, page *compatPage) (*compatResult, error) {
+	if page.Expression != "" {
+		e, err := spdx.Parse(page.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse expression: %w", err)
+		}
+
+		// licenses the catalog doesn't have may still be in the rules
+		e.Resolve(licenses)
+
+		v, checks := e.Compatibility(c)
+
+		return &compatResult{Expression: e.String(), Verdict: v, Checks: checks}, nil
+	}
+
+	if page.From == "" && page.Into == "" {
+		return nil, nil
+	}
+
+	if page.From == "" || page.Into == "" {
+		return nil, fmt.Errorf("pass both from and into, or an expression")
+	}
+
+	from, fromID := spdxID(licenses, page.From)
+	into, intoID := spdxID(licenses, page.Into)
+
+	page.From, page.Into = fromID, intoID
+
+	r := c.Check(from, into)
+
+	return &compatResult{From: from, Into: into, Verdict: r.Verdict, Checks: []ynal.CompatibilityRule{r}}, nil
+}
+
+// compatHandler says whether code under one license can go into a project
+// under another (?from=mit&into=gpl_3), or whether the licenses in an SPDX
+// expression can be used together (?expression=...). Browsers get a form to
+// ask with.
+func compatHandler(licenses []ynal.LicenseData, c *ynal.Compatibility, tmpl *pageTemplates) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		page := compatPage{
+			Licenses:   licenses,
+			From:       q.Get("from"),
+			Into:       q.Get("into"),
+			Expression: q.Get("expression"),
+		}
+
+		res, err := checkCompatibility(licenses, c, &page)
+		if err != nil {
+			writeError(w, r, tmpl, http.StatusBadRequest, err.Error())
+			return
+		}
+		page.Result = res
+
+		mediatype := mostAcceptable(r)
+		if mediatype == "" {
+			writeNotAcceptable(w, r, tmpl)
+			return
+		}
+
+		if res == nil && mediatype != "text/html" {
+			writeError(w, r, tmpl, http.StatusBadRequest, "nothing to check, pass from and into, or an expression")
+			return
+		}
+
+		switch mediatype {
+		case "text/html":
+			buf := getBuffer()
+			defer putBuffer(buf)
+			v := tmpl.variant(r)
+
+			if err := tmpl.ExecuteTemplate(buf, v, "compatibility.html.tmpl", page); err != nil {
+				logf(r, "could not render compatibility template: %s", err)
+				writeError(w, r, tmpl, http.StatusInternalServerError, "could not render compatibility page")
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html")
+			setVariantHeaders(w, v)
+			w.Write(buf.Bytes())
+		case "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(res)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+
+			fmt.Fprintf(w, "%s\n", res.Verdict)
+			for _, check := range res.Checks {
+				fmt.Fprintf(w, "\n%s code in a %s project: %s\n", check.From, check.Into, check.Reason)
+			}
+		}
+	})
+}
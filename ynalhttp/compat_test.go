@@ -0,0 +1,145 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestCompatibility(t *testing.T) {
+	tt := []struct {
+		name    string
+		path    string
+		verdict ynal.Verdict
+		from    string
+		into    string
+	}{
+		{
+			name:    "ynal IDs",
+			path:    "/compatibility?from=mit&into=gpl_3",
+			verdict: ynal.Compatible,
+			from:    "MIT",
+			into:    "GPL-3.0-or-later",
+		},
+		{
+			name:    "SPDX IDs",
+			path:    "/compatibility?from=GPL-3.0-or-later&into=Apache-2.0",
+			verdict: ynal.Incompatible,
+			from:    "GPL-3.0-or-later",
+			into:    "Apache-2.0",
+		},
+		{
+			name:    "not in the catalog",
+			path:    "/compatibility?from=mpl-2.0&into=mit",
+			verdict: ynal.Conditional,
+			from:    "mpl-2.0",
+			into:    "MIT",
+		},
+		{
+			name:    "expression",
+			path:    "/compatibility?expression=" + "gpl_3+AND+(apache_2+OR+GPL-2.0-only)",
+			verdict: ynal.Compatible,
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "application/json")
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			res := compatResult{}
+			if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+				t.Fatalf("could not decode response: %s", err)
+			}
+
+			if res.Verdict != tc.verdict || res.From != tc.from || res.Into != tc.into || len(res.Checks) == 0 {
+				t.Fatalf("unexpected result: %+v", res)
+			}
+		})
+	}
+}
+
+func TestCompatibilityPage(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name     string
+		path     string
+		accept   string
+		code     int
+		expected string
+	}{
+		{
+			name:     "form",
+			path:     "/compatibility",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: `<select name="from">`,
+		},
+		{
+			name:     "verdict",
+			path:     "/compatibility?from=gpl_3&into=mit",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: `<option value="gpl_3" selected>GPL_3</option>`,
+		},
+		{
+			name:     "plain",
+			path:     "/compatibility?from=mit&into=gpl_3",
+			accept:   "text/plain",
+			code:     http.StatusOK,
+			expected: "compatible\n\nMIT code in a GPL-3.0-or-later project: ",
+		},
+		{
+			name:   "nothing asked",
+			path:   "/compatibility",
+			accept: "application/json",
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "half a question",
+			path:   "/compatibility?from=mit",
+			accept: "text/html",
+			code:   http.StatusBadRequest,
+		},
+		{
+			name:   "bad expression",
+			path:   "/compatibility?expression=MIT+AND",
+			accept: "application/json",
+			code:   http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
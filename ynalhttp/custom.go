@@ -0,0 +1,14 @@
+package ynalhttp
+
+import (
+	"github.com/packrat386/ynal"
+)
+
+// WithCustom serves an organization's own licenses and policy documents, as
+// loaded by ynal.LoadCustom, under /custom/ with their own section of the
+// index.
+func WithCustom(docs []ynal.LicenseData) Option {
+	return func(c *config) {
+		c.custom = docs
+	}
+}
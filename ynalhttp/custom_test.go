@@ -0,0 +1,96 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestCustom(t *testing.T) {
+	mit := ynal.NewLicense("MIT", "mit text\n")
+
+	// named like a license on purpose, the namespace keeps them apart
+	eula := ynal.NewLicense("MIT", "corp eula text\n")
+	eula.URL = "/custom/mit"
+	eula.Custom = true
+
+	h, err := New(WithLicenses([]ynal.LicenseData{mit}), WithCustom([]ynal.LicenseData{eula}), WithBasePath("/licenses"))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		path     string
+		accept   string
+		code     int
+		expected string
+	}{
+		{name: "document", path: "/licenses/custom/mit", accept: "text/plain", code: http.StatusOK, expected: "corp eula text\n"},
+		{name: "license", path: "/licenses/mit", accept: "text/plain", code: http.StatusOK, expected: "mit text\n"},
+		{name: "document HTML", path: "/licenses/custom/mit", accept: "text/html", code: http.StatusOK, expected: "not an open source license"},
+		{name: "document download link", path: "/licenses/custom/mit", accept: "text/html", code: http.StatusOK, expected: `href="/licenses/download/custom/mit"`},
+		{name: "license HTML", path: "/licenses/mit", accept: "text/html", code: http.StatusOK, expected: `href="/licenses/download/mit"`},
+		{name: "document JSON", path: "/licenses/custom/mit", accept: "application/json", code: http.StatusOK, expected: `"custom":true`},
+		{name: "document raw", path: "/licenses/raw/custom/mit", code: http.StatusOK, expected: "corp eula text\n"},
+		{name: "document download", path: "/licenses/download/custom/mit", code: http.StatusOK, expected: "corp eula text\n"},
+		{name: "unknown document", path: "/licenses/custom/nope", accept: "text/plain", code: http.StatusNotFound},
+		{name: "index", path: "/licenses/", accept: "text/html", code: http.StatusOK, expected: `<a href="/licenses/custom/mit">MIT</a>`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCustomIndexJSON(t *testing.T) {
+	eula := ynal.NewLicense("Corp-EULA", "corp eula text\n")
+	eula.URL = "/custom/corp-eula"
+	eula.Custom = true
+
+	h, err := New(WithCustom([]ynal.LicenseData{eula}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	index := indexJSON{}
+	if err := json.NewDecoder(w.Body).Decode(&index); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	if len(index.Custom) != 1 || index.Custom[0].URL != "/custom/corp-eula" || !index.Custom[0].Custom {
+		t.Fatalf("unexpected custom documents: %+v", index.Custom)
+	}
+
+	for _, l := range index.Licenses {
+		if l.ID == "corp-eula" {
+			t.Fatalf("expected the document to be kept out of the licenses")
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+type deedJSON struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Deed       string `json:"content"`
+	URL        string `json:"url"`
+	LicenseURL string `json:"license_url"`
+}
+
+// deedURL is where l's deed is served, if it has one.
+func deedURL(l ynal.LicenseData) string {
+	return l.URL + "/deed"
+}
+
+// deedHandler serves the plain-language summary of l, negotiated like the
+// license itself, and linking back to its full legal code.
+func deedHandler(l ynal.LicenseData, tmpl *pageTemplates, base string) (http.Handler, error) {
+	htmlData := map[variant][]byte{}
+	for _, v := range tmpl.variants() {
+		buf := new(bytes.Buffer)
+		if err := tmpl.ExecuteTemplate(buf, v, "deed.html.tmpl", l); err != nil {
+			return nil, fmt.Errorf("could not render html template: %w", err)
+		}
+
+		htmlData[v] = buf.Bytes()
+	}
+
+	jsonData, err := json.Marshal(deedJSON{ID: l.ID, Title: l.Title, Deed: l.Deed, URL: deedURL(l), LicenseURL: l.URL})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal JSON: %w", err)
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediatype := mostAcceptable(r)
+		if mediatype == "" {
+			writeNotAcceptable(w, r, tmpl)
+			return
+		}
+
+		setDeprecationHeaders(w, l, base)
+
+		switch mediatype {
+		case "text/plain":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(l.Deed))
+		case "text/html":
+			v := tmpl.variant(r)
+
+			w.Header().Set("Content-Type", "text/html")
+			setVariantHeaders(w, v)
+			w.Write(htmlData[v])
+		case "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(jsonData)
+		default:
+			writeError(w, r, tmpl, http.StatusNotAcceptable, fmt.Sprintf("unrecognized media type: %s", mediatype))
+		}
+	})
+
+	return h, nil
+}
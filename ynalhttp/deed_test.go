@@ -0,0 +1,97 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestDeed(t *testing.T) {
+	cc := ynal.NewLicense("CC-BY-4.0", "Attribution 4.0 International\n\nThe full legal code.\n")
+	cc.Deed = "You are free to share and adapt\nthe material.\n"
+
+	h, err := New(WithLicenses([]ynal.LicenseData{cc, ynal.NewLicense("MIT", "mit\n")}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		path     string
+		accept   string
+		code     int
+		expected string
+	}{
+		{
+			name:     "html",
+			path:     "/cc-by-4.0/deed",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: "<p>You are free to share and adapt the material.</p>",
+		},
+		{
+			name:     "links to the legal code",
+			path:     "/cc-by-4.0/deed",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: `<a href="/cc-by-4.0">`,
+		},
+		{
+			name:     "plain",
+			path:     "/cc-by-4.0/deed",
+			accept:   "text/plain",
+			code:     http.StatusOK,
+			expected: "You are free to share and adapt\nthe material.\n",
+		},
+		{
+			name:     "json",
+			path:     "/cc-by-4.0/deed",
+			accept:   "application/json",
+			code:     http.StatusOK,
+			expected: `{"id":"cc-by-4.0","title":"CC-BY-4.0","content":"You are free to share and adapt\nthe material.\n","url":"/cc-by-4.0/deed","license_url":"/cc-by-4.0"}`,
+		},
+		{
+			name:     "legal code links to the deed",
+			path:     "/cc-by-4.0",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: `<a href="/cc-by-4.0/deed">`,
+		},
+		{
+			name:     "legal code is still the text",
+			path:     "/cc-by-4.0",
+			accept:   "text/plain",
+			code:     http.StatusOK,
+			expected: "Attribution 4.0 International\n\nThe full legal code.\n",
+		},
+		{
+			name:     "no deed",
+			path:     "/mit/deed",
+			accept:   "text/plain",
+			code:     http.StatusNotFound,
+			expected: "",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
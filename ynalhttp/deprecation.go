@@ -0,0 +1,26 @@
+package ynalhttp
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+// setDeprecationHeaders warns clients that l is deprecated, and points them at
+// its successor if it has one. Nothing is set for licenses that aren't.
+func setDeprecationHeaders(w http.ResponseWriter, l ynal.LicenseData, base string) {
+	if !l.Deprecated {
+		return
+	}
+
+	warning := fmt.Sprintf("%s is deprecated", l.Title)
+
+	if l.Successor != "" {
+		warning += fmt.Sprintf(", use %s instead", l.Successor)
+		w.Header().Add("Link", fmt.Sprintf(`<%s/%s>; rel="successor-version"`, base, l.Successor))
+	}
+
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Warning", fmt.Sprintf("299 - %q", warning))
+}
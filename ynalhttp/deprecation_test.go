@@ -0,0 +1,72 @@
+package ynalhttp
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestDeprecatedLicense(t *testing.T) {
+	old := ynal.NewLicense("GPL_1", "gpl 1\n")
+	old.Deprecated = true
+	old.Successor = "gpl_3"
+
+	gone := ynal.NewLicense("Gone", "gone\n")
+	gone.Deprecated = true
+
+	h, err := New(WithLicenses([]ynal.LicenseData{old, gone, ynal.NewLicense("GPL_3", "gpl 3\n")}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		path    string
+		warning string
+		link    string
+	}{
+		{path: "/gpl_1", warning: `299 - "GPL_1 is deprecated, use gpl_3 instead"`, link: `</gpl_3>; rel="successor-version"`},
+		{path: "/raw/gpl_1", warning: `299 - "GPL_1 is deprecated, use gpl_3 instead"`, link: `</gpl_3>; rel="successor-version"`},
+		{path: "/api/v1/licenses/gpl_1", warning: `299 - "GPL_1 is deprecated, use gpl_3 instead"`, link: `</gpl_3>; rel="successor-version"`},
+		{path: "/gone", warning: `299 - "Gone is deprecated"`},
+		{path: "/gpl_3"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.path, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			deprecation := ""
+			if tc.warning != "" {
+				deprecation = "true"
+			}
+
+			if got := w.Header().Get("Deprecation"); got != deprecation {
+				t.Fatalf("expected Deprecation %q, got %q", deprecation, got)
+			}
+
+			if got := w.Header().Get("Warning"); got != tc.warning {
+				t.Fatalf("expected Warning %q, got %q", tc.warning, got)
+			}
+
+			if got := w.Header().Get("Link"); got != tc.link {
+				t.Fatalf("expected Link %q, got %q", tc.link, got)
+			}
+		})
+	}
+
+	r := httptest.NewRequest("GET", "/gpl_1", nil)
+	r.Header.Set("Accept", "text/html")
+
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if !strings.Contains(w.Body.String(), `class="deprecated"`) || !strings.Contains(w.Body.String(), `<a href="/gpl_3">gpl_3</a>`) {
+		t.Fatalf("expected a deprecation banner pointing at the successor, got:\n%s", w.Body.String())
+	}
+}
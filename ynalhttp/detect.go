@@ -0,0 +1,85 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/github"
+)
+
+// WithDetector serves /detect, which looks up the license of a GitHub
+// repository with d and sends the client to it in the catalog.
+func WithDetector(d github.Detector) Option {
+	return func(c *config) {
+		c.detector = d
+	}
+}
+
+type detectResponse struct {
+	Repo    string        `json:"repo"`
+	License spdxReference `json:"license"`
+}
+
+// detectHandler looks up the license of the repository in the repo query
+// parameter and redirects to its page, or describes it for clients that ask
+// for JSON. It's a 302 since repositories change licenses.
+func detectHandler(store ynal.LicenseStore, detector github.Detector, tmpl *pageTemplates, base string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo, err := github.ParseRepo(r.URL.Query().Get("repo"))
+		if err != nil {
+			writeError(w, r, tmpl, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		id, err := detector.Detect(r.Context(), repo)
+		switch {
+		case errors.Is(err, github.ErrNotFound), errors.Is(err, github.ErrUnrecognized):
+			writeError(w, r, tmpl, http.StatusNotFound, err.Error())
+			return
+		case errors.Is(err, github.ErrRateLimited):
+			w.Header().Set("Retry-After", "60")
+			writeError(w, r, tmpl, http.StatusServiceUnavailable, err.Error())
+			return
+		case err != nil:
+			writeError(w, r, tmpl, http.StatusBadGateway, err.Error())
+			return
+		}
+
+		l, ok := findBySPDXID(withBase(store.List(), base), id)
+		if !ok {
+			writeError(w, r, tmpl, http.StatusNotFound, fmt.Sprintf("%s is licensed under %s, which isn't in the catalog", repo, id))
+			return
+		}
+
+		if mostAcceptable(r) != "application/json" {
+			http.Redirect(w, r, l.URL, http.StatusFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(detectResponse{
+			Repo: repo,
+			License: spdxReference{
+				SPDX:  l.SPDXID(),
+				ID:    l.ID,
+				Title: l.Title,
+				URL:   l.URL,
+				Href:  apiHref(base, l),
+			},
+		})
+	})
+}
+
+func findBySPDXID(licenses []ynal.LicenseData, id string) (ynal.LicenseData, bool) {
+	for _, l := range licenses {
+		if strings.EqualFold(l.SPDXID(), id) {
+			return l, true
+		}
+	}
+
+	return ynal.LicenseData{}, false
+}
@@ -0,0 +1,129 @@
+package ynalhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/packrat386/ynal/github"
+)
+
+type fakeDetector map[string]error
+
+func (f fakeDetector) Detect(ctx context.Context, repo string) (string, error) {
+	switch repo {
+	case "packrat386/ynal":
+		return "MIT", nil
+	case "someone/proprietary":
+		return "LicenseRef-Proprietary", nil
+	}
+
+	if err, ok := f[repo]; ok {
+		return "", err
+	}
+
+	return "", github.ErrNotFound
+}
+
+func TestDetect(t *testing.T) {
+	tt := []struct {
+		name     string
+		query    string
+		accept   string
+		code     int
+		location string
+	}{
+		{
+			name:     "redirect",
+			query:    "?repo=packrat386/ynal",
+			code:     http.StatusFound,
+			location: "/mit",
+		},
+		{
+			name:     "url",
+			query:    "?repo=https://github.com/packrat386/ynal",
+			code:     http.StatusFound,
+			location: "/mit",
+		},
+		{
+			name:   "json",
+			query:  "?repo=packrat386/ynal",
+			accept: "application/json",
+			code:   http.StatusOK,
+		},
+		{
+			name:  "not in the catalog",
+			query: "?repo=someone/proprietary",
+			code:  http.StatusNotFound,
+		},
+		{
+			name:  "no license",
+			query: "?repo=someone/nothing",
+			code:  http.StatusNotFound,
+		},
+		{
+			name:  "rate limited",
+			query: "?repo=someone/busy",
+			code:  http.StatusServiceUnavailable,
+		},
+		{
+			name:  "not a repo",
+			query: "?repo=ynal",
+			code:  http.StatusBadRequest,
+		},
+	}
+
+	h, err := New(WithDetector(fakeDetector{"someone/busy": github.ErrRateLimited}))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/detect"+tc.query, nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if got := w.Header().Get("Location"); got != tc.location {
+				t.Errorf("expected location %q, got %q", tc.location, got)
+			}
+
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			var got detectResponse
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("could not decode response: %s", err)
+			}
+
+			if got.Repo != "packrat386/ynal" || got.License.SPDX != "MIT" || got.License.URL != "/mit" {
+				t.Errorf("unexpected response: %+v", got)
+			}
+		})
+	}
+}
+
+func TestDetectWithoutDetector(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/detect?repo=packrat386/ynal", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package ynalhttp
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+// WithDevMode reads templates/, messages/, and public/ from root on every
+// request instead of using the copies embedded in the binary, so changes to
+// them show up on the next reload. It is slow, and only meant for working on
+// ynal itself.
+func WithDevMode(root fs.FS) Option {
+	return func(c *config) {
+		c.dev = root
+	}
+}
+
+// devHandler rebuilds everything from scratch for every request. Mistakes in
+// templates are shown in the response rather than failing startup.
+type devHandler struct {
+	store      ynal.LicenseStore
+	exceptions []ynal.LicenseData
+	custom     []ynal.LicenseData
+	root       fs.FS
+	overrides  fs.FS
+	base       string
+	site       string
+	theme      string
+}
+
+func newDevHandler(store ynal.LicenseStore, exceptions []ynal.LicenseData, custom []ynal.LicenseData, root fs.FS, overrides fs.FS, base string, site string, theme string) *devHandler {
+	return &devHandler{store: store, exceptions: exceptions, custom: custom, root: root, overrides: overrides, base: base, site: site, theme: theme}
+}
+
+func (d *devHandler) build() (http.Handler, error) {
+	public, err := fs.Sub(d.root, "public")
+	if err != nil {
+		return nil, fmt.Errorf("could not subsystem public assets: %w", err)
+	}
+
+	tmpl, err := parseTemplates(d.root, d.overrides, d.root, public, d.base, d.site, d.theme)
+	if err != nil {
+		return nil, err
+	}
+
+	return appHandler(d.store.List(), d.exceptions, d.custom, tmpl, public, d.base)
+}
+
+func (d *devHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	h, err := d.build()
+	if err != nil {
+		logf(r, "dev: could not build handler: %s", err)
+		http.Error(w, fmt.Sprintf("dev mode: could not build handler:\n\n%s", err), http.StatusInternalServerError)
+		return
+	}
+
+	logf(r, "dev: rebuilt handler for %s in %s", r.URL.Path, time.Since(start))
+
+	h.ServeHTTP(w, r)
+}
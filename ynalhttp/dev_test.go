@@ -0,0 +1,112 @@
+package ynalhttp
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/packrat386/ynal"
+)
+
+// devRoot copies the embedded templates, messages, and public assets into a
+// MapFS that the test can change underneath the handler.
+func devRoot(t *testing.T) fstest.MapFS {
+	root := fstest.MapFS{}
+
+	for _, embedded := range []fs.FS{ynal.Templates, ynal.Messages, ynal.Public} {
+		err := fs.WalkDir(embedded, ".", func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			b, err := fs.ReadFile(embedded, p)
+			if err != nil {
+				return err
+			}
+
+			root[p] = &fstest.MapFile{Data: b}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("could not copy embedded files: %s", err)
+		}
+	}
+
+	return root
+}
+
+func TestDevMode(t *testing.T) {
+	root := devRoot(t)
+
+	h, err := New(WithDevMode(root))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept", "text/html")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	if w := get("/mit"); !strings.Contains(w.Body.String(), "License: MIT") {
+		t.Fatalf("expected the license page, got %d: %s", w.Code, w.Body.String())
+	}
+
+	root["templates/license.html.tmpl"] = &fstest.MapFile{Data: []byte("edited {{ .Title }}")}
+	root["public/new.css"] = &fstest.MapFile{Data: []byte("body {}")}
+
+	if w := get("/mit"); w.Body.String() != "edited MIT" {
+		t.Fatalf("expected the edited template to be used, got %q", w.Body.String())
+	}
+
+	if w := get("/new.css"); w.Code != http.StatusOK {
+		t.Fatalf("expected the new asset to be served, got %d", w.Code)
+	}
+
+	root["templates/license.html.tmpl"] = &fstest.MapFile{Data: []byte("{{ .Title ")}
+
+	if w := get("/mit"); w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "license.html.tmpl") {
+		t.Fatalf("expected the template error to be shown, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDevModeOverrides(t *testing.T) {
+	root := devRoot(t)
+	overrides := fstest.MapFS{"license.html.tmpl": {Data: []byte("overridden {{ .Title }}")}}
+
+	h, err := New(WithDevMode(root), WithTemplates(overrides))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	get := func() string {
+		r := httptest.NewRequest("GET", "/mit", nil)
+		r.Header.Set("Accept", "text/html")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w.Body.String()
+	}
+
+	// the overrides win over templates/ in root, as they do in production
+	root["templates/license.html.tmpl"] = &fstest.MapFile{Data: []byte("edited {{ .Title }}")}
+
+	if body := get(); body != "overridden MIT" {
+		t.Fatalf("expected the override to be used, got %q", body)
+	}
+
+	overrides["license.html.tmpl"] = &fstest.MapFile{Data: []byte("reloaded {{ .Title }}")}
+
+	if body := get(); body != "reloaded MIT" {
+		t.Fatalf("expected the edited override to be used, got %q", body)
+	}
+}
@@ -0,0 +1,101 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// problem is an RFC 7807 problem details object.
+type problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Suggestions are URLs the client may have meant instead.
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+func newProblem(r *http.Request, code int, detail string) problem {
+	return problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(code),
+		Status:   code,
+		Detail:   detail,
+		Instance: basePath(r) + r.URL.Path,
+	}
+}
+
+// errorMediaType is the format an error is written in. It's negotiated like
+// license responses, except that asking for application/problem+json (or any
+// other JSON type) counts as asking for JSON.
+func errorMediaType(r *http.Request) string {
+	types := strings.Split(r.Header.Get("Accept"), ",")
+	for i, t := range types {
+		mediatype, params, _ := strings.Cut(strings.TrimSpace(t), ";")
+		if strings.HasSuffix(mediatype, "+json") {
+			types[i] = "application/json"
+			if params != "" {
+				types[i] += ";" + params
+			}
+		}
+	}
+
+	return negotiatorsFrom(r).general.negotiate(strings.Join(types, ","))
+}
+
+// writeError responds with an error body in whichever format the client
+// prefers, using the same negotiation as license responses: a styled page for
+// HTML, application/problem+json for JSON, and plain text otherwise.
+func writeError(w http.ResponseWriter, r *http.Request, tmpl *pageTemplates, code int, detail string) {
+	writeNegotiatedProblem(w, r, tmpl, newProblem(r, code, detail))
+}
+
+// writeNegotiatedProblem is writeError for a problem that's already been put
+// together, with suggestions say.
+func writeNegotiatedProblem(w http.ResponseWriter, r *http.Request, tmpl *pageTemplates, p problem) {
+	w.Header().Del("Content-Length")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	switch errorMediaType(r) {
+	case "text/html":
+		buf := getBuffer()
+		defer putBuffer(buf)
+		v := tmpl.variant(r)
+
+		if err := tmpl.ExecuteTemplate(buf, v, "error.html.tmpl", p); err != nil {
+			logf(r, "could not render error template: %s", err)
+			http.Error(w, p.text(), p.Status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		setVariantHeaders(w, v)
+		w.WriteHeader(p.Status)
+		w.Write(buf.Bytes())
+	case "application/json":
+		writeProblem(w, p)
+	default:
+		http.Error(w, p.text(), p.Status)
+	}
+}
+
+// text is the problem as plain text: the detail, and any suggestions one per
+// line after it.
+func (p problem) text() string {
+	if len(p.Suggestions) == 0 {
+		return p.Detail
+	}
+
+	return p.Detail + "\n\ndid you mean:\n  " + strings.Join(p.Suggestions, "\n  ")
+}
+
+func writeProblem(w http.ResponseWriter, p problem) {
+	w.Header().Del("Content-Length")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
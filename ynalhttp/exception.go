@@ -0,0 +1,144 @@
+package ynalhttp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/packrat386/ynal"
+)
+
+// WithExceptions serves the given license exceptions instead of the embedded
+// ones.
+func WithExceptions(exceptions []ynal.LicenseData) Option {
+	return func(c *config) {
+		c.exceptions = exceptions
+	}
+}
+
+// combination finds the license and exception named by an ID like
+// gpl_3+classpath-exception-2.0 and combines them. Exception IDs never contain
+// a +, so it splits at the last one.
+func combination(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, id string) (ynal.LicenseData, bool) {
+	i := strings.LastIndex(id, "+")
+	if i == -1 {
+		return ynal.LicenseData{}, false
+	}
+
+	l, ok := ynal.FindLicense(licenses, id[:i])
+	if !ok {
+		return ynal.LicenseData{}, false
+	}
+
+	e, ok := ynal.FindLicense(exceptions, id[i+1:])
+	if !ok {
+		return ynal.LicenseData{}, false
+	}
+
+	return l.WithException(e), true
+}
+
+// lookup finds a license, an exception, or a combination of the two by ID.
+func lookup(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, id string) (ynal.LicenseData, bool) {
+	if l, ok := ynal.FindLicense(licenses, id); ok {
+		return l, true
+	}
+
+	if e, ok := ynal.FindLicense(exceptions, id); ok {
+		return e, true
+	}
+
+	return combination(licenses, exceptions, id)
+}
+
+// documentRoutes serves each exception or custom document at its own URL, in
+// every format a license comes in.
+func documentRoutes(mux *http.ServeMux, docs []ynal.LicenseData, tmpl *pageTemplates, base string) error {
+	for _, e := range docs {
+		linked := e
+		linked.URL = base + e.URL
+
+		h, err := handlerFor(licensePage{LicenseData: linked}, tmpl, base)
+		if err != nil {
+			return fmt.Errorf("could not init handler for %s: %w", e.ID, err)
+		}
+
+		mux.Handle("GET "+e.URL, h)
+	}
+
+	return nil
+}
+
+// combinationHandler serves licenses combined with an exception, like
+// /gpl_3+classpath-exception-2.0, passing every other request on to next.
+// There are too many combinations to render up front, so each one is
+// rendered the first time it's asked for.
+type combinationHandler struct {
+	licenses   []ynal.LicenseData
+	exceptions []ynal.LicenseData
+	tmpl       *pageTemplates
+	base       string
+	next       http.Handler
+
+	mu       sync.Mutex
+	handlers map[string]http.Handler
+}
+
+func newCombinationHandler(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, tmpl *pageTemplates, base string, next http.Handler) *combinationHandler {
+	return &combinationHandler{
+		licenses:   licenses,
+		exceptions: exceptions,
+		tmpl:       tmpl,
+		base:       base,
+		next:       next,
+		handlers:   map[string]http.Handler{},
+	}
+}
+
+func (c *combinationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/")
+	if (r.Method != http.MethodGet && r.Method != http.MethodHead) || strings.Contains(id, "/") {
+		c.next.ServeHTTP(w, r)
+		return
+	}
+
+	h, err := c.handler(id)
+	if err != nil {
+		writeError(w, r, c.tmpl, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if h == nil {
+		c.next.ServeHTTP(w, r)
+		return
+	}
+
+	h.ServeHTTP(w, r)
+}
+
+// handler returns the handler for the combination id, or nil if it isn't one.
+func (c *combinationHandler) handler(id string) (http.Handler, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if h, ok := c.handlers[id]; ok {
+		return h, nil
+	}
+
+	l, ok := combination(c.licenses, c.exceptions, id)
+	if !ok {
+		return nil, nil
+	}
+
+	l.URL = c.base + l.URL
+
+	h, err := handlerFor(licensePage{LicenseData: l}, c.tmpl, c.base)
+	if err != nil {
+		return nil, fmt.Errorf("could not init handler for %s: %w", id, err)
+	}
+
+	c.handlers[id] = h
+
+	return h, nil
+}
@@ -0,0 +1,126 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestExceptions(t *testing.T) {
+	gpl := ynal.NewLicense("GPL_2", "gpl text\n")
+	gpl.SPDX = "GPL-2.0-only"
+
+	classpath := ynal.NewLicense("Classpath-exception-2.0", "classpath text\n")
+	classpath.URL = "/exceptions/classpath-exception-2.0"
+	classpath.SPDX = classpath.Title
+
+	h, err := New(WithLicenses([]ynal.LicenseData{gpl}), WithExceptions([]ynal.LicenseData{classpath}), WithBasePath("/licenses"))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		path     string
+		accept   string
+		code     int
+		expected string
+	}{
+		{
+			name:     "exception",
+			path:     "/licenses/exceptions/classpath-exception-2.0",
+			accept:   "text/plain",
+			code:     http.StatusOK,
+			expected: "classpath text\n",
+		},
+		{
+			name:     "combination",
+			path:     "/licenses/gpl_2+classpath-exception-2.0",
+			accept:   "text/plain",
+			code:     http.StatusOK,
+			expected: "gpl text\n\nclasspath text\n",
+		},
+		{
+			name:     "combination HTML",
+			path:     "/licenses/gpl_2+classpath-exception-2.0",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: "GPL_2 WITH Classpath-exception-2.0",
+		},
+		{
+			name:     "combination raw",
+			path:     "/licenses/raw/gpl_2+classpath-exception-2.0",
+			code:     http.StatusOK,
+			expected: "gpl text\n\nclasspath text\n",
+		},
+		{
+			name:     "exception raw",
+			path:     "/licenses/raw/classpath-exception-2.0",
+			code:     http.StatusOK,
+			expected: "classpath text\n",
+		},
+		{
+			name: "unknown exception",
+			path: "/licenses/gpl_2+nope",
+			code: http.StatusNotFound,
+		},
+		{
+			name: "unknown license",
+			path: "/licenses/nope+classpath-exception-2.0",
+			code: http.StatusNotFound,
+		},
+		{
+			name:     "public assets",
+			path:     "/licenses/styles.css",
+			code:     http.StatusOK,
+			expected: "body",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCombinationJSON(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/gpl_3+classpath-exception-2.0", nil)
+	r.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	l := ynal.LicenseData{}
+	if err := json.NewDecoder(w.Body).Decode(&l); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	if l.ID != "gpl_3+classpath-exception-2.0" || l.SPDX != "GPL-3.0-or-later WITH Classpath-exception-2.0" {
+		t.Fatalf("unexpected combination: %+v", l)
+	}
+
+	if !strings.HasSuffix(l.Text, "delete this exception statement from your version.\n") {
+		t.Fatalf("expected the exception at the end of the text, got %q", l.Text[len(l.Text)-100:])
+	}
+}
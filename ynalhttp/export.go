@@ -0,0 +1,266 @@
+package ynalhttp
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+// exportAccepts are the Accept headers negotiated pages are exported with,
+// one per representation.
+var exportAccepts = []string{"text/html", "application/json", "text/plain", "text/x-rst", "text/asciidoc"}
+
+// exportExtensions is the extension each representation is written with.
+var exportExtensions = map[string]string{
+	"text/html":        ".html",
+	"application/json": ".json",
+	"text/plain":       ".txt",
+	"text/x-rst":       ".rst",
+	"text/asciidoc":    ".adoc",
+}
+
+// Export renders everything New would serve into dir as static files, for
+// hosting somewhere that can't run ynal, like GitHub Pages or S3. Every file
+// comes from the same handler New returns, so it's exactly what ynal would
+// serve.
+//
+// Pages that come in more than one representation are written once for each,
+// with its extension: /mit becomes mit.html, mit.json, and mit.txt, and the
+// index index.html, index.json, and index.txt. Everything else, like /raw/mit
+// and the public assets, is written at its own path. A 404.html and a
+// sitemap.xml of every HTML page under siteURL are written too, and pages
+// link to siteURL as in WithSiteURL. It returns how many files it wrote.
+func Export(dir string, siteURL string, opts ...Option) (int, error) {
+	c, err := newConfig(append(slices.Clone(opts), WithSiteURL(siteURL)))
+	if err != nil {
+		return 0, err
+	}
+
+	h, err := c.handler()
+	if err != nil {
+		return 0, err
+	}
+
+	public, err := fs.Sub(ynal.Public, "public")
+	if err != nil {
+		return 0, fmt.Errorf("could not subsystem public assets: %w", err)
+	}
+
+	a, err := fingerprintAssets(public)
+	if err != nil {
+		return 0, err
+	}
+
+	e := &exporter{h: h, dir: dir, base: c.basePath}
+	licenses := c.store.List()
+
+	pages := []string{"/", "/compatibility"}
+	files := []string{}
+
+	for _, l := range licenses {
+		pages = append(pages, l.URL, "/header/"+l.ID)
+		files = append(files, "/raw/"+l.ID, "/download/"+l.ID, l.URL+"/sha256", l.URL+"/sha1", normalizedURL(l))
+
+		if l.Deed != "" {
+			pages = append(pages, deedURL(l))
+		}
+
+		if l.Summary != "" {
+			pages = append(pages, summaryURL(l))
+		}
+	}
+
+	for _, f := range ynal.Families(licenses) {
+		pages = append(pages, f.URL)
+	}
+
+	pages = append(pages, "/tags")
+	for _, t := range ynal.TagIndex(licenses) {
+		pages = append(pages, t.URL)
+	}
+
+	for _, x := range c.exceptions {
+		pages = append(pages, x.URL)
+		files = append(files, "/raw/"+x.ID, "/download/"+x.ID)
+	}
+
+	for _, d := range c.custom {
+		pages = append(pages, d.URL)
+		files = append(files, "/raw/custom/"+d.ID, "/download/custom/"+d.ID)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(a.fingerprinted)) {
+		// the file server redirects index.html to its directory, which for
+		// the root is the rendered index
+		if path.Base(name) == "index.html" {
+			continue
+		}
+
+		files = append(files, "/"+name, "/"+a.fingerprinted[name])
+	}
+
+	errs := []error{}
+
+	for _, route := range pages {
+		if err := e.page(route); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, route := range files {
+		if err := e.file(route); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := e.notFound(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := e.sitemap(siteURL); err != nil {
+		errs = append(errs, err)
+	}
+
+	return e.written, errors.Join(errs...)
+}
+
+// exporter writes what h serves for each route under dir.
+type exporter struct {
+	h    http.Handler
+	dir  string
+	base string
+
+	written int
+
+	// html is every route written as HTML, for the sitemap
+	html []string
+}
+
+func (e *exporter) get(route string, accept string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", e.base+route, nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+
+	w := httptest.NewRecorder()
+	e.h.ServeHTTP(w, r)
+
+	return w
+}
+
+// page writes route in every representation it comes in.
+func (e *exporter) page(route string) error {
+	name := strings.TrimPrefix(route, "/")
+	if name == "" {
+		name = "index"
+	}
+
+	written := map[string]bool{}
+
+	for _, accept := range exportAccepts {
+		w := e.get(route, accept)
+
+		// some pages only come in some representations, but every one comes
+		// in HTML
+		if w.Code != http.StatusOK {
+			if accept == "text/html" {
+				return fmt.Errorf("GET %s: %d", route, w.Code)
+			}
+
+			continue
+		}
+
+		mediatype, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		ext, ok := exportExtensions[mediatype]
+		if !ok || written[ext] {
+			continue
+		}
+
+		if err := e.write(name+ext, w.Body.Bytes()); err != nil {
+			return err
+		}
+
+		written[ext] = true
+
+		if ext == ".html" {
+			e.html = append(e.html, route)
+		}
+	}
+
+	return nil
+}
+
+// file writes route as is.
+func (e *exporter) file(route string) error {
+	w := e.get(route, "")
+	if w.Code != http.StatusOK {
+		return fmt.Errorf("GET %s: %d", route, w.Code)
+	}
+
+	return e.write(strings.TrimPrefix(route, "/"), w.Body.Bytes())
+}
+
+// notFound writes the error page for a route that doesn't exist, which static
+// hosts like GitHub Pages serve for every missing file.
+func (e *exporter) notFound() error {
+	w := e.get("/404", "text/html")
+	if w.Code != http.StatusNotFound {
+		return fmt.Errorf("GET /404: expected 404, got %d", w.Code)
+	}
+
+	return e.write("404.html", w.Body.Bytes())
+}
+
+type sitemapURL struct {
+	Loc string `xml:"loc"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemap writes a sitemap of every HTML page written so far, linked under
+// siteURL.
+func (e *exporter) sitemap(siteURL string) error {
+	set := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, route := range e.html {
+		set.URLs = append(set.URLs, sitemapURL{Loc: strings.TrimSuffix(siteURL, "/") + e.base + route})
+	}
+
+	b, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal sitemap: %w", err)
+	}
+
+	return e.write("sitemap.xml", append([]byte(xml.Header), append(b, '\n')...))
+}
+
+func (e *exporter) write(name string, b []byte) error {
+	p := filepath.Join(e.dir, filepath.FromSlash(name))
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("could not create directory: %w", err)
+	}
+
+	if err := os.WriteFile(p, b, 0644); err != nil {
+		return fmt.Errorf("could not write %s: %w", name, err)
+	}
+
+	e.written++
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package ynalhttp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+
+	mit := ynal.NewLicense("MIT", "mit text\n")
+	mit.Family = "Permissive"
+
+	n, err := Export(dir, "https://licenses.example/", WithLicenses([]ynal.LicenseData{mit}), WithBasePath("/licenses"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		expected string
+	}{
+		{name: "index.html", expected: `<a href="/licenses/mit">MIT</a>`},
+		{name: "index.json", expected: `"id":"mit"`},
+		{name: "index.txt", expected: "mit"},
+		{name: "mit.html", expected: "<h2>License: MIT</h2>"},
+		{name: "mit.json", expected: `"content":"mit text\n"`},
+		{name: "mit.txt", expected: "mit text\n"},
+		{name: "mit/sha256", expected: mit.Digest.SHA256},
+		{name: "raw/mit", expected: "mit text\n"},
+		{name: "download/mit", expected: "mit text\n"},
+		{name: "family/permissive.html", expected: "MIT"},
+		{name: "exceptions/classpath-exception-2.0.txt", expected: "As a special exception"},
+		{name: "compatibility.html", expected: "<html"},
+		{name: "styles.css", expected: "body"},
+		{name: "404.html", expected: "<html"},
+		{name: "sitemap.xml", expected: "<loc>https://licenses.example/licenses/mit</loc>"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join(dir, tc.name))
+			if err != nil {
+				t.Fatalf("could not read exported file: %s", err)
+			}
+
+			if !strings.Contains(string(b), tc.expected) {
+				t.Fatalf("expected %s to contain %q, got:\n%s", tc.name, tc.expected, b)
+			}
+		})
+	}
+
+	// the fingerprinted assets the pages link to are there too
+	matches, err := filepath.Glob(filepath.Join(dir, "styles.*.css"))
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("expected one fingerprinted stylesheet, got %v: %v", matches, err)
+	}
+
+	files := 0
+	filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files++
+		}
+
+		return err
+	})
+
+	if files != n {
+		t.Fatalf("expected %d files, found %d", n, files)
+	}
+}
@@ -0,0 +1,192 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+// indexPage is what the index template is rendered with.
+type indexPage struct {
+	Families []ynal.Family
+
+	// Other is every license that isn't in a family.
+	Other []ynal.LicenseData
+
+	Exceptions []ynal.LicenseData
+
+	// Custom are the organization's own documents, listed apart from the
+	// licenses.
+	Custom []ynal.LicenseData
+}
+
+// linkedFamilies groups licenses, whose URLs must already have base in front
+// of them, by family.
+func linkedFamilies(licenses []ynal.LicenseData, base string) []ynal.Family {
+	families := ynal.Families(licenses)
+	for i := range families {
+		families[i].URL = base + families[i].URL
+	}
+
+	return families
+}
+
+func newIndexPage(licenses []ynal.LicenseData, families []ynal.Family, exceptions []ynal.LicenseData, custom []ynal.LicenseData) indexPage {
+	page := indexPage{Families: families, Exceptions: exceptions, Custom: custom}
+
+	for _, l := range licenses {
+		if l.Family == "" {
+			page.Other = append(page.Other, l)
+		}
+	}
+
+	return page
+}
+
+// licensePage is what the license template is rendered with.
+type licensePage struct {
+	ynal.LicenseData
+
+	// OtherVersions are the rest of the license's family, if the family has
+	// versions.
+	OtherVersions []ynal.LicenseData
+
+	// LatestURL always redirects to the newest version of the family.
+	LatestURL string
+
+	// Preamble and Sections are the license's text split into its numbered
+	// sections, if it has any, so each can be anchored. See ynal.Sections.
+	Preamble string
+	Sections []ynal.Section
+
+	// Reflow shows the text as paragraphs rather than as is. See
+	// ynal.LayoutReflow.
+	Reflow bool
+}
+
+func newLicensePage(l ynal.LicenseData, families []ynal.Family, base string) licensePage {
+	page := licensePage{LicenseData: l}
+
+	for _, f := range families {
+		if f.Name != l.Family || !f.Versioned() {
+			continue
+		}
+
+		for _, other := range f.Licenses {
+			if other.ID != l.ID {
+				page.OtherVersions = append(page.OtherVersions, other)
+			}
+		}
+
+		if _, ok := f.Latest(); ok {
+			page.LatestURL = latestURL(f, base)
+		}
+	}
+
+	return page
+}
+
+func latestURL(f ynal.Family, base string) string {
+	return base + "/" + f.ID + "/latest"
+}
+
+// latestHandler redirects to the newest version of a family, keeping the
+// query string. It's a 302 since the target changes as versions are added.
+func latestHandler(latest ynal.LicenseData) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := latest.URL
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+
+		http.Redirect(w, r, target, http.StatusFound)
+	})
+}
+
+type familyMember struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Version string `json:"version,omitempty"`
+}
+
+type familyJSON struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	URL      string         `json:"url"`
+	Licenses []familyMember `json:"licenses"`
+}
+
+// familyHandler serves a landing page for every family listing the licenses
+// in it, negotiated like the license routes.
+func familyHandler(families []ynal.Family, tmpl *pageTemplates) (http.Handler, error) {
+	htmlData := map[string]map[variant][]byte{}
+	jsonData := map[string][]byte{}
+	plainData := map[string][]byte{}
+
+	for _, f := range families {
+		htmlData[f.ID] = map[variant][]byte{}
+
+		for _, v := range tmpl.variants() {
+			buf := new(bytes.Buffer)
+			if err := tmpl.ExecuteTemplate(buf, v, "family.html.tmpl", f); err != nil {
+				return nil, fmt.Errorf("could not render html template: %w", err)
+			}
+
+			htmlData[f.ID][v] = buf.Bytes()
+		}
+
+		fj := familyJSON{ID: f.ID, Name: f.Name, URL: f.URL, Licenses: []familyMember{}}
+		plain := new(bytes.Buffer)
+
+		for _, l := range f.Licenses {
+			fj.Licenses = append(fj.Licenses, familyMember{ID: l.ID, Title: l.Title, URL: l.URL, Version: l.Version})
+			fmt.Fprintf(plain, "%s (%s)\n", l.Title, l.URL)
+		}
+
+		b, err := json.Marshal(fj)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal JSON: %w", err)
+		}
+
+		jsonData[f.ID] = b
+		plainData[f.ID] = plain.Bytes()
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+
+		if _, ok := jsonData[id]; !ok {
+			writeError(w, r, tmpl, http.StatusNotFound, fmt.Sprintf("no such license family: %s", id))
+			return
+		}
+
+		mediatype := mostAcceptable(r)
+		if mediatype == "" {
+			writeNotAcceptable(w, r, tmpl)
+			return
+		}
+
+		switch mediatype {
+		case "text/plain":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write(plainData[id])
+		case "text/html":
+			v := tmpl.variant(r)
+
+			w.Header().Set("Content-Type", "text/html")
+			setVariantHeaders(w, v)
+			w.Write(htmlData[id][v])
+		case "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(jsonData[id])
+		default:
+			writeError(w, r, tmpl, http.StatusNotAcceptable, fmt.Sprintf("unrecognized media type: %s", mediatype))
+		}
+	})
+
+	return h, nil
+}
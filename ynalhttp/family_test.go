@@ -0,0 +1,171 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestFamilyPage(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name     string
+		path     string
+		accept   string
+		code     int
+		expected string
+	}{
+		{
+			name:     "html",
+			path:     "/family/gpl",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: `<a href="/gpl_3">GPL_3</a>`,
+		},
+		{
+			name:     "plain",
+			path:     "/family/gpl",
+			accept:   "text/plain",
+			code:     http.StatusOK,
+			expected: "GPL_3 (/gpl_3)\n",
+		},
+		{
+			name:     "json",
+			path:     "/family/bsd",
+			accept:   "application/json",
+			code:     http.StatusOK,
+			expected: `{"id":"bsd","name":"BSD","url":"/family/bsd","licenses":[{"id":"bsd_3","title":"BSD_3","url":"/bsd_3"}]}`,
+		},
+		{
+			name:     "missing",
+			path:     "/family/nope",
+			accept:   "text/plain",
+			code:     http.StatusNotFound,
+			expected: "no such license family: nope",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d", tc.code, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIndexGroupsFamilies(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	body := w.Body.String()
+
+	// GPL licenses are listed under their family, and MIT, in no family,
+	// comes after every family
+	gpl := strings.Index(body, `<a href="/family/gpl">GPL</a>`)
+	gpl3 := strings.Index(body, `<a href="/gpl_3">`)
+	mit := strings.Index(body, `<a href="/mit">`)
+
+	if gpl == -1 || gpl3 < gpl || mit < gpl3 {
+		t.Fatalf("expected licenses grouped by family, got:\n%s", body)
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	licenses := []ynal.LicenseData{}
+	for _, v := range []struct {
+		title      string
+		version    string
+		deprecated bool
+	}{
+		{"Foo_1", "1.0", false},
+		{"Foo_10", "10.0", true},
+		{"Foo_2", "2.0", false},
+		{"Foo_2-or-later", "2.0", false},
+	} {
+		l := ynal.NewLicense(v.title, v.title+" text\n")
+		l.Family = "Foo"
+		l.Version = v.version
+		l.Deprecated = v.deprecated
+
+		licenses = append(licenses, l)
+	}
+
+	h, err := New(WithLicenses(licenses))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	r := httptest.NewRequest("GET", "/foo/latest?x=1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	// deprecated versions are never the latest, and of equal versions the
+	// first wins
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/foo_2?x=1" {
+		t.Fatalf("expected a redirect to /foo_2?x=1, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	r = httptest.NewRequest("GET", "/foo_1", nil)
+	r.Header.Set("Accept", "text/html")
+
+	w = httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	for _, want := range []string{`<a href="/foo_10">Foo_10</a> (10.0)`, `<a href="/foo_2-or-later">Foo_2-or-later</a> (2.0)`, `<a href="/foo/latest">/foo/latest</a>`} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Fatalf("expected the page to contain %q, got:\n%s", want, w.Body.String())
+		}
+	}
+
+	if strings.Contains(w.Body.String(), `<a href="/foo_1">`) {
+		t.Fatalf("expected the page not to list itself as another version")
+	}
+}
+
+func TestAPIFamilies(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/api/v1/licenses", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	list := apiLicenseList{}
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	found := false
+	for _, f := range list.Families {
+		if f.ID == "gpl" {
+			found = strings.Join(f.Licenses, ",") == "gpl_3" && f.Latest == "gpl_3"
+		}
+	}
+
+	if !found {
+		t.Fatalf("expected the GPL family in the catalog, got %+v", list.Families)
+	}
+}
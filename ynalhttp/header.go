@@ -0,0 +1,210 @@
+package ynalhttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+// commentStyle is how a language writes comments. Languages with line
+// comments set prefix; ones better served by a block comment set start and
+// end too, and prefix is what goes in front of each line inside it.
+type commentStyle struct {
+	start  string
+	prefix string
+	end    string
+}
+
+var (
+	slashComment = commentStyle{prefix: "// "}
+	hashComment  = commentStyle{prefix: "# "}
+	dashComment  = commentStyle{prefix: "-- "}
+	semiComment  = commentStyle{prefix: ";; "}
+	pctComment   = commentStyle{prefix: "% "}
+	cssComment   = commentStyle{start: "/*", prefix: " * ", end: " */"}
+	htmlComment  = commentStyle{start: "<!--", prefix: "  ", end: "-->"}
+	mlComment    = commentStyle{start: "(*", prefix: "   ", end: "*)"}
+)
+
+// commentStyles maps each language, and common names and extensions for it,
+// to its comment syntax.
+var commentStyles = map[string]commentStyle{
+	"c":          slashComment,
+	"h":          slashComment,
+	"cpp":        slashComment,
+	"c++":        slashComment,
+	"cc":         slashComment,
+	"hpp":        slashComment,
+	"csharp":     slashComment,
+	"cs":         slashComment,
+	"dart":       slashComment,
+	"go":         slashComment,
+	"groovy":     slashComment,
+	"java":       slashComment,
+	"javascript": slashComment,
+	"js":         slashComment,
+	"jsx":        slashComment,
+	"kotlin":     slashComment,
+	"kt":         slashComment,
+	"objc":       slashComment,
+	"php":        slashComment,
+	"protobuf":   slashComment,
+	"proto":      slashComment,
+	"rust":       slashComment,
+	"rs":         slashComment,
+	"scala":      slashComment,
+	"solidity":   slashComment,
+	"sol":        slashComment,
+	"swift":      slashComment,
+	"typescript": slashComment,
+	"ts":         slashComment,
+	"tsx":        slashComment,
+	"zig":        slashComment,
+
+	"bash":       hashComment,
+	"sh":         hashComment,
+	"shell":      hashComment,
+	"zsh":        hashComment,
+	"fish":       hashComment,
+	"cmake":      hashComment,
+	"dockerfile": hashComment,
+	"elixir":     hashComment,
+	"ex":         hashComment,
+	"exs":        hashComment,
+	"julia":      hashComment,
+	"jl":         hashComment,
+	"make":       hashComment,
+	"makefile":   hashComment,
+	"nim":        hashComment,
+	"perl":       hashComment,
+	"pl":         hashComment,
+	"powershell": hashComment,
+	"ps1":        hashComment,
+	"python":     hashComment,
+	"py":         hashComment,
+	"r":          hashComment,
+	"ruby":       hashComment,
+	"rb":         hashComment,
+	"terraform":  hashComment,
+	"tf":         hashComment,
+	"toml":       hashComment,
+	"yaml":       hashComment,
+	"yml":        hashComment,
+
+	"haskell": dashComment,
+	"hs":      dashComment,
+	"lua":     dashComment,
+	"sql":     dashComment,
+
+	"clojure": semiComment,
+	"clj":     semiComment,
+	"lisp":    semiComment,
+	"elisp":   semiComment,
+	"el":      semiComment,
+	"scheme":  semiComment,
+
+	"erlang": pctComment,
+	"erl":    pctComment,
+	"latex":  pctComment,
+	"tex":    pctComment,
+	"matlab": pctComment,
+
+	"css":  cssComment,
+	"less": cssComment,
+	"scss": cssComment,
+
+	"html":     htmlComment,
+	"markdown": htmlComment,
+	"md":       htmlComment,
+	"svg":      htmlComment,
+	"vue":      htmlComment,
+	"xml":      htmlComment,
+
+	"ocaml":  mlComment,
+	"ml":     mlComment,
+	"fsharp": slashComment,
+	"fs":     slashComment,
+}
+
+// languages returns every language with a comment style, sorted.
+func languages() []string {
+	langs := []string{}
+	for lang := range commentStyles {
+		langs = append(langs, lang)
+	}
+
+	slices.Sort(langs)
+
+	return langs
+}
+
+// comment wraps text in a comment.
+func (c commentStyle) comment(text string) string {
+	buf := new(strings.Builder)
+	c.writeComment(buf, text)
+
+	return buf.String()
+}
+
+// writeComment writes text to w wrapped in a comment, a line at a time.
+func (c commentStyle) writeComment(w io.Writer, text string) {
+	if c.start != "" {
+		io.WriteString(w, c.start+"\n")
+	}
+
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		io.WriteString(w, strings.TrimRight(c.prefix+line, " ")+"\n")
+	}
+
+	if c.end != "" {
+		io.WriteString(w, c.end+"\n")
+	}
+}
+
+// headerHandler serves the header to put at the top of each source file under
+// a license, commented for the language in the lang query parameter. The
+// year and holder parameters fill in the copyright line.
+func headerHandler(licenses []ynal.LicenseData, tmpl *pageTemplates, base string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l, ok := ynal.FindLicense(licenses, r.PathValue("id"))
+		if !ok {
+			writeError(w, r, tmpl, http.StatusNotFound, fmt.Sprintf("no such license: %s", r.PathValue("id")))
+			return
+		}
+
+		q := r.URL.Query()
+
+		subs := map[string]string{}
+		if year := q.Get("year"); year != "" {
+			subs["YEAR"] = year
+		}
+		if holder := q.Get("holder"); holder != "" {
+			subs["COPYRIGHT HOLDER"] = holder
+		}
+
+		header := ynal.Substitute(l.FileHeader(), subs)
+
+		lang := strings.ToLower(q.Get("lang"))
+
+		style, ok := commentStyles[lang]
+		if lang != "" && !ok {
+			writeError(w, r, tmpl, http.StatusBadRequest, fmt.Sprintf("unknown language: %s (try one of %s)", lang, strings.Join(languages(), ", ")))
+			return
+		}
+
+		setDeprecationHeaders(w, l, base)
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		// written as it's commented rather than built up first
+		if lang == "" {
+			io.WriteString(w, header)
+		} else {
+			style.writeComment(w, header)
+		}
+	})
+}
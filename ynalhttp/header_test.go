@@ -0,0 +1,91 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHeader(t *testing.T) {
+	tt := []struct {
+		name     string
+		path     string
+		code     int
+		expected string
+	}{
+		{
+			name:     "plain",
+			path:     "/header/mit",
+			code:     http.StatusOK,
+			expected: "SPDX-License-Identifier: MIT\n\nCopyright <YEAR> <COPYRIGHT HOLDER>\n",
+		},
+		{
+			name:     "go",
+			path:     "/header/mit?lang=go&year=2024&holder=Jane+Doe",
+			code:     http.StatusOK,
+			expected: "// SPDX-License-Identifier: MIT\n//\n// Copyright 2024 Jane Doe\n",
+		},
+		{
+			name:     "python",
+			path:     "/header/bsd_3?lang=Python",
+			code:     http.StatusOK,
+			expected: "# SPDX-License-Identifier: BSD-3-Clause\n#\n# Copyright <YEAR> <COPYRIGHT HOLDER>\n",
+		},
+		{
+			name:     "block comment",
+			path:     "/header/mit?lang=css&year=2024",
+			code:     http.StatusOK,
+			expected: "/*\n * SPDX-License-Identifier: MIT\n *\n * Copyright 2024 <COPYRIGHT HOLDER>\n */\n",
+		},
+		{
+			name:     "not on the SPDX list",
+			path:     "/header/glwtspl?lang=sh",
+			code:     http.StatusOK,
+			expected: "# SPDX-License-Identifier: LicenseRef-GLWTSPL\n#\n# Copyright <YEAR> <COPYRIGHT HOLDER>\n",
+		},
+		{
+			name: "unknown language",
+			path: "/header/mit?lang=cobol",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "unknown license",
+			path: "/header/nope?lang=go",
+			code: http.StatusNotFound,
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if tc.expected != "" && w.Body.String() != tc.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHeaderBoilerplate(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/header/apache_2?lang=go&year=2024&holder=Jane+Doe", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	expected := "// SPDX-License-Identifier: Apache-2.0\n//\n// Copyright 2024 Jane Doe\n//\n// Licensed under the Apache License, Version 2.0"
+	if !strings.HasPrefix(w.Body.String(), expected) {
+		t.Fatalf("expected the Apache boilerplate, got:\n%s", w.Body.String())
+	}
+}
@@ -0,0 +1,137 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultLang is used when a client accepts none of the languages there are
+// messages for, and fills in any message another language is missing.
+const defaultLang = "en"
+
+// catalog maps a message key to its text in one language. Messages are
+// trusted HTML, like the templates themselves, and may take printf arguments,
+// which are escaped.
+type catalog map[string]string
+
+// loadMessages reads messages/*.json in fsys. Each file is named for the
+// language it holds, e.g. messages/de.json.
+func loadMessages(fsys fs.FS) (map[string]catalog, error) {
+	paths, err := fs.Glob(fsys, "messages/*.json")
+	if err != nil {
+		return nil, fmt.Errorf("could not glob messages: %w", err)
+	}
+
+	catalogs := map[string]catalog{}
+
+	for _, p := range paths {
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("could not read messages: %w", err)
+		}
+
+		c := catalog{}
+		if err := json.Unmarshal(b, &c); err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", p, err)
+		}
+
+		catalogs[strings.ToLower(strings.TrimSuffix(path.Base(p), ".json"))] = c
+	}
+
+	if _, ok := catalogs[defaultLang]; !ok {
+		return nil, fmt.Errorf("no messages for the default language %q", defaultLang)
+	}
+
+	return catalogs, nil
+}
+
+// msgFunc returns the template function that looks up messages in c, falling
+// back to the default language.
+func msgFunc(c catalog, fallback catalog) func(string, ...any) (template.HTML, error) {
+	return func(key string, args ...any) (template.HTML, error) {
+		text, ok := c[key]
+		if !ok {
+			text, ok = fallback[key]
+		}
+
+		if !ok {
+			return "", fmt.Errorf("no message for %q", key)
+		}
+
+		if len(args) == 0 {
+			return template.HTML(text), nil
+		}
+
+		escaped := make([]any, len(args))
+		for i, a := range args {
+			escaped[i] = template.HTMLEscapeString(fmt.Sprint(a))
+		}
+
+		return template.HTML(fmt.Sprintf(text, escaped...)), nil
+	}
+}
+
+// negotiateLanguage picks the language from available the Accept-Language
+// header likes best. A tag like "de-AT" matches "de" if there's nothing more
+// specific.
+func negotiateLanguage(accept string, available []string) string {
+	type preference struct {
+		tag    string
+		weight float64
+	}
+
+	prefs := []preference{}
+
+	for _, v := range strings.Split(accept, ",") {
+		tag, rawParams, _ := strings.Cut(v, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		// Accept-Language parameters look like media type parameters, so
+		// borrow the parser with a placeholder type
+		weight := float64(1.0)
+		if _, params, err := mime.ParseMediaType("x/x;" + rawParams); err == nil {
+			if val, err := strconv.ParseFloat(params["q"], 64); err == nil {
+				weight = val
+			}
+		}
+
+		if weight <= 0 {
+			continue
+		}
+
+		prefs = append(prefs, preference{tag: tag, weight: weight})
+	}
+
+	slices.SortStableFunc(prefs, func(a preference, b preference) int {
+		switch {
+		case a.weight > b.weight:
+			return -1
+		case a.weight < b.weight:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for _, p := range prefs {
+		if slices.Contains(available, p.tag) {
+			return p.tag
+		}
+
+		if primary, _, ok := strings.Cut(p.tag, "-"); ok && slices.Contains(available, primary) {
+			return primary
+		}
+	}
+
+	return defaultLang
+}
@@ -0,0 +1,103 @@
+package ynalhttp
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	available := []string{"de", "en", "es", "fr"}
+
+	tt := []struct {
+		accept   string
+		expected string
+	}{
+		{accept: "", expected: "en"},
+		{accept: "de", expected: "de"},
+		{accept: "de-AT", expected: "de"},
+		{accept: "FR-ca, en;q=0.5", expected: "fr"},
+		{accept: "ja, es;q=0.8, de;q=0.9", expected: "de"},
+		{accept: "es;q=0, fr;q=0.1", expected: "fr"},
+		{accept: "ja, *;q=0.5", expected: "en"},
+		{accept: "garbage;;q=x, es", expected: "es"},
+	}
+
+	for _, tc := range tt {
+		if got := negotiateLanguage(tc.accept, available); got != tc.expected {
+			t.Errorf("Accept-Language %q: expected %q, got %q", tc.accept, tc.expected, got)
+		}
+	}
+}
+
+func TestLocalizedPages(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		path     string
+		lang     string
+		expected []string
+	}{
+		{
+			path:     "/",
+			lang:     "es",
+			expected: []string{`<html lang="es">`, "Licencias disponibles:"},
+		},
+		{
+			path:     "/mit",
+			lang:     "de-DE",
+			expected: []string{"Lizenz: MIT", `<a href="/download/mit">lade sie</a>`},
+		},
+		{
+			path:     "/search?q=%3Cxyzzy%3E",
+			lang:     "fr",
+			expected: []string{"Aucune licence ne correspond à <code>&lt;xyzzy&gt;</code>."},
+		},
+		{
+			path:     "/nope",
+			lang:     "de",
+			expected: []string{">Startseite</a>"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.path, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/html")
+			r.Header.Set("Accept-Language", tc.lang)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			for _, want := range tc.expected {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Fatalf("expected the page to contain %q, got:\n%s", want, w.Body.String())
+				}
+			}
+
+			if !strings.HasPrefix(tc.lang, w.Header().Get("Content-Language")) {
+				t.Fatalf("expected Content-Language to match %q, got %q", tc.lang, w.Header().Get("Content-Language"))
+			}
+		})
+	}
+}
+
+// every message in every language should also exist in the default one, or it
+// could never be used
+func TestMessageKeys(t *testing.T) {
+	catalogs, err := loadMessages(ynal.Messages)
+	if err != nil {
+		t.Fatalf("could not load messages: %s", err)
+	}
+
+	for lang, c := range catalogs {
+		for key := range c {
+			if _, ok := catalogs[defaultLang][key]; !ok {
+				t.Errorf("%s has a message %q that %s doesn't", lang, key, defaultLang)
+			}
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+// renderedPage is a page rendered ahead of time, with an ETag for its
+// content.
+type renderedPage struct {
+	body []byte
+	etag string
+}
+
+func newRenderedPage(body []byte) renderedPage {
+	sum := sha256.Sum256(body)
+	return renderedPage{body: body, etag: `"` + hex.EncodeToString(sum[:8]) + `"`}
+}
+
+// serve writes the page, or a 304 if the client already has it.
+func (p renderedPage) serve(w http.ResponseWriter, r *http.Request, name string) {
+	w.Header().Set("ETag", p.etag)
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(p.body))
+}
+
+// indexJSON is the index as JSON.
+type indexJSON struct {
+	Licenses   []indexLicense `json:"licenses"`
+	Families   []indexFamily  `json:"families"`
+	Exceptions []indexLicense `json:"exceptions"`
+	Custom     []indexLicense `json:"custom"`
+}
+
+type indexLicense struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	Family     string `json:"family,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+	Custom     bool   `json:"custom,omitempty"`
+}
+
+type indexFamily struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	URL      string   `json:"url"`
+	Licenses []string `json:"licenses"`
+}
+
+func toIndexLicense(l ynal.LicenseData) indexLicense {
+	return indexLicense{ID: l.ID, Title: l.Title, URL: l.URL, Family: l.Family, Deprecated: l.Deprecated, Custom: l.Custom}
+}
+
+// popularityInterval is how often the index is re-sorted in OrderPopular.
+const popularityInterval = time.Minute
+
+// indexRenderer serves the index as HTML, JSON, or plain text. One is built
+// for every set of licenses, along with the rest of the handlers, so a change
+// to the licenses means a new index.
+//
+// The index is sorted by the order set with WithIndexOrder. JSON, plain text,
+// and the default HTML variant are rendered up front in alphabetical order,
+// so a broken template is reported when the handlers are built. Other orders
+// and HTML variants are rendered the first time they're asked for and kept.
+type indexRenderer struct {
+	tmpl *pageTemplates
+	page indexPage
+
+	mu     sync.Mutex
+	sorted map[string]*sortedIndex
+}
+
+// sortedIndex is the index in one order.
+type sortedIndex struct {
+	page indexPage
+
+	// at is when the index was sorted, for orders that change over time.
+	at time.Time
+
+	json renderedPage
+	text renderedPage
+	html map[variant]renderedPage
+}
+
+func newIndexRenderer(tmpl *pageTemplates, page indexPage) (*indexRenderer, error) {
+	ir := &indexRenderer{tmpl: tmpl, page: page, sorted: map[string]*sortedIndex{}}
+
+	order := indexOrder{kind: OrderAlphabetical}
+
+	si, err := newSortedIndex(order.sort(page, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := si.htmlPage(tmpl, variant{theme: tmpl.defaultTheme, lang: defaultLang}); err != nil {
+		return nil, err
+	}
+
+	ir.sorted[order.key()] = si
+
+	return ir, nil
+}
+
+func newSortedIndex(page indexPage) (*sortedIndex, error) {
+	si := &sortedIndex{page: page, html: map[variant]renderedPage{}}
+
+	all := indexJSON{Licenses: []indexLicense{}, Families: []indexFamily{}, Exceptions: []indexLicense{}, Custom: []indexLicense{}}
+	text := new(bytes.Buffer)
+	tw := tabwriter.NewWriter(text, 0, 0, 2, ' ', 0)
+
+	for _, f := range page.Families {
+		af := indexFamily{ID: f.ID, Name: f.Name, URL: f.URL, Licenses: []string{}}
+
+		for _, l := range f.Licenses {
+			all.Licenses = append(all.Licenses, toIndexLicense(l))
+			af.Licenses = append(af.Licenses, l.ID)
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", l.ID, l.Title, l.URL)
+		}
+
+		all.Families = append(all.Families, af)
+	}
+
+	for _, l := range page.Other {
+		all.Licenses = append(all.Licenses, toIndexLicense(l))
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", l.ID, l.Title, l.URL)
+	}
+
+	for _, e := range page.Exceptions {
+		all.Exceptions = append(all.Exceptions, toIndexLicense(e))
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", e.ID, e.Title, e.URL)
+	}
+
+	for _, d := range page.Custom {
+		all.Custom = append(all.Custom, toIndexLicense(d))
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", d.ID, d.Title, d.URL)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return nil, fmt.Errorf("could not render index: %w", err)
+	}
+
+	b, err := json.Marshal(all)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal JSON: %w", err)
+	}
+
+	si.json = newRenderedPage(b)
+	si.text = newRenderedPage(text.Bytes())
+
+	return si, nil
+}
+
+// htmlPage returns the index in v, rendering it if it hasn't been yet. The
+// caller must hold the indexRenderer's mu.
+func (si *sortedIndex) htmlPage(tmpl *pageTemplates, v variant) (renderedPage, error) {
+	if p, ok := si.html[v]; ok {
+		return p, nil
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(buf, v, "index.html.tmpl", si.page); err != nil {
+		return renderedPage{}, fmt.Errorf("could not render index: %w", err)
+	}
+
+	p := newRenderedPage(buf.Bytes())
+	si.html[v] = p
+
+	return p, nil
+}
+
+// index returns the index sorted the way r's handler was configured to,
+// sorting it if it hasn't been yet, or if it's popularity-sorted and due to
+// be again. The caller must hold ir.mu.
+func (ir *indexRenderer) index(r *http.Request) (*sortedIndex, error) {
+	o := indexOrderFrom(r)
+	key := o.key()
+
+	si, ok := ir.sorted[key]
+	if ok && (o.kind != OrderPopular || now(r).Sub(si.at) < popularityInterval) {
+		return si, nil
+	}
+
+	hits := map[string]int64{}
+	if o.kind == OrderPopular {
+		if s := statsFrom(r); s != nil {
+			hits = s.totals()
+		}
+	}
+
+	page := o.sort(ir.page, hits)
+
+	// a re-sort that didn't change anything keeps what's been rendered
+	if ok && slices.Equal(indexIDs(page), indexIDs(si.page)) {
+		si.at = now(r)
+		return si, nil
+	}
+
+	si, err := newSortedIndex(page)
+	if err != nil {
+		return nil, err
+	}
+
+	si.at = now(r)
+	ir.sorted[key] = si
+
+	return si, nil
+}
+
+// indexIDs lists the IDs on the index, in order.
+func indexIDs(page indexPage) []string {
+	ids := []string{}
+	for _, f := range page.Families {
+		for _, l := range f.Licenses {
+			ids = append(ids, l.ID)
+		}
+	}
+
+	for _, list := range [][]ynal.LicenseData{page.Other, page.Exceptions, page.Custom} {
+		for _, l := range list {
+			ids = append(ids, l.ID)
+		}
+	}
+
+	return ids
+}
+
+// render returns the index as mediatype, in v if that's HTML.
+func (ir *indexRenderer) render(r *http.Request, mediatype string, v variant) (renderedPage, error) {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+
+	si, err := ir.index(r)
+	if err != nil {
+		return renderedPage{}, err
+	}
+
+	switch mediatype {
+	case "text/html":
+		return si.htmlPage(ir.tmpl, v)
+	case "application/json":
+		return si.json, nil
+	default:
+		return si.text, nil
+	}
+}
+
+func (ir *indexRenderer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// the index has always been HTML, so it stays that way for clients that
+	// don't say what they want
+	mediatype := "text/html"
+	if r.Header.Get("Accept") != "" {
+		mediatype = mostAcceptable(r)
+	}
+
+	if mediatype == "" {
+		writeNotAcceptable(w, r, ir.tmpl)
+		return
+	}
+
+	v := ir.tmpl.variant(r)
+
+	p, err := ir.render(r, mediatype, v)
+	if err != nil {
+		logf(r, "%s", err)
+		writeError(w, r, ir.tmpl, http.StatusInternalServerError, "could not render index")
+		return
+	}
+
+	switch mediatype {
+	case "text/html":
+		w.Header().Set("Content-Type", "text/html")
+		setVariantHeaders(w, v)
+		w.Header().Add("Vary", "Accept")
+		p.serve(w, r, "index.html")
+	case "application/json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add("Vary", "Accept")
+		p.serve(w, r, "index.json")
+	default:
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Add("Vary", "Accept")
+		p.serve(w, r, "index.txt")
+	}
+}
@@ -0,0 +1,97 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestIndexFormats(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name        string
+		accept      string
+		contentType string
+		expected    string
+	}{
+		{name: "default", accept: "", contentType: "text/html", expected: `<a href="/mit">`},
+		{name: "html", accept: "text/html", contentType: "text/html", expected: `<a href="/mit">`},
+		{name: "json", accept: "application/json", contentType: "application/json", expected: `{"id":"mit","title":"MIT","url":"/mit"}`},
+		{name: "plain", accept: "text/plain", contentType: "text/plain", expected: "mit"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Accept", tc.accept)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Fatalf("expected %q, got %q", tc.contentType, got)
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+
+			if !strings.Contains(strings.Join(w.Header().Values("Vary"), ", "), "Accept") {
+				t.Errorf("expected Vary to include Accept, got %q", w.Header().Values("Vary"))
+			}
+		})
+	}
+}
+
+func TestIndexJSON(t *testing.T) {
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+
+	mustAppHandler(t).ServeHTTP(w, r)
+
+	index := indexJSON{}
+	if err := json.Unmarshal(w.Body.Bytes(), &index); err != nil {
+		t.Fatalf("could not decode index: %s", err)
+	}
+
+	if len(index.Licenses) != len(licenses) {
+		t.Errorf("expected %d licenses, got %d", len(licenses), len(index.Licenses))
+	}
+
+	if len(index.Families) == 0 || len(index.Exceptions) == 0 {
+		t.Errorf("expected families and exceptions, got %+v", index)
+	}
+}
+
+func TestIndexRendererError(t *testing.T) {
+	public, err := fs.Sub(ynal.Public, "public")
+	if err != nil {
+		t.Fatalf("could not open public assets: %s", err)
+	}
+
+	templates := fstest.MapFS{
+		"templates/index.html.tmpl": {Data: []byte("{{ .NoSuchField }}")},
+	}
+
+	tmpl, err := parseTemplates(templates, nil, ynal.Messages, public, "", defaultSiteURL, "")
+	if err != nil {
+		t.Fatalf("could not parse templates: %s", err)
+	}
+
+	_, err = newIndexRenderer(tmpl, indexPage{})
+	if err == nil || !strings.Contains(err.Error(), "could not render index") {
+		t.Fatalf("expected a render error, got: %v", err)
+	}
+}
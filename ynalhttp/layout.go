@@ -0,0 +1,26 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/packrat386/ynal"
+)
+
+// layoutParam picks the layout of a license page for one request, overriding
+// the license's own.
+const layoutParam = "layout"
+
+// requestedLayout returns the layout r asks for, or l's if it doesn't ask for
+// a known one.
+func requestedLayout(r *http.Request, l ynal.LicenseData) string {
+	if layout := r.URL.Query().Get(layoutParam); slices.Contains(ynal.Layouts, layout) {
+		return layout
+	}
+
+	if l.Layout != "" {
+		return l.Layout
+	}
+
+	return ynal.LayoutPreserve
+}
@@ -0,0 +1,50 @@
+package ynalhttp
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestLayout(t *testing.T) {
+	wrapped := ynal.NewLicense("Wrapped", "1. First.\n\nThe first\nclause.\n\n2. Second.\n\nThe second\nclause.\n")
+
+	reflowed := ynal.NewLicense("Reflowed", "A short\nlicense.\n")
+	reflowed.Layout = ynal.LayoutReflow
+
+	h, err := New(WithLicenses([]ynal.LicenseData{wrapped, reflowed}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "preserved by default", path: "/wrapped", expected: "The first\nclause."},
+		{name: "reflowed by request", path: "/wrapped?layout=reflow", expected: "<p>The first clause.</p>"},
+		{name: "sections keep their anchors", path: "/wrapped?layout=reflow", expected: `<div class="section" id="section-2">`},
+		{name: "toggle", path: "/wrapped", expected: `<a href="/wrapped?layout=reflow">`},
+		{name: "reflowed by metadata", path: "/reflowed", expected: "<p>A short license.</p>"},
+		{name: "preserved by request", path: "/reflowed?layout=preserve", expected: "A short\nlicense."},
+		{name: "unknown layout", path: "/reflowed?layout=sideways", expected: "<p>A short license.</p>"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/html")
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the page to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
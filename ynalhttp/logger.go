@@ -0,0 +1,35 @@
+package ynalhttp
+
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
+// WithLogger sets where ynal logs errors, panics, and audit entries. Without
+// it they go to the standard logger.
+func WithLogger(l *log.Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+type loggerKey struct{}
+
+// withLogger makes l available to every handler below it, for logf.
+func withLogger(l *log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loggerKey{}, l)))
+	})
+}
+
+// logf logs to the logger set with WithLogger, or the standard logger for a
+// request that didn't come through New.
+func logf(r *http.Request, format string, args ...any) {
+	l, ok := r.Context().Value(loggerKey{}).(*log.Logger)
+	if !ok {
+		l = log.Default()
+	}
+
+	l.Printf(format, args...)
+}
@@ -0,0 +1,30 @@
+package ynalhttp
+
+import (
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+// logoURL is where l's logo is served, if it has one.
+func logoURL(l ynal.LicenseData) string {
+	return "/img/license/" + l.ID + ".svg"
+}
+
+// logoHandler serves l's logo. SVG can carry scripts, so it's served with a
+// policy that keeps any from running when it's opened on its own.
+func logoHandler(l ynal.LicenseData) (http.Handler, error) {
+	svg, err := ynal.Logo(l.Logo)
+	if err != nil {
+		return nil, err
+	}
+
+	page := newRenderedPage(svg)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		page.serve(w, r, l.ID+".svg")
+	}), nil
+}
@@ -0,0 +1,109 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestLogo(t *testing.T) {
+	cc := ynal.NewLicense("CC-BY-4.0", "Attribution 4.0 International\n")
+	cc.Logo = "cc"
+
+	licenses := []ynal.LicenseData{cc, ynal.NewLicense("MIT", "mit\n")}
+
+	svg, err := ynal.Logo("cc")
+	if err != nil {
+		t.Fatalf("could not read logo: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		base     string
+		path     string
+		code     int
+		expected string
+	}{
+		{
+			name:     "logo",
+			path:     "/img/license/cc-by-4.0.svg",
+			code:     http.StatusOK,
+			expected: string(svg),
+		},
+		{
+			name: "no logo",
+			path: "/img/license/mit.svg",
+			code: http.StatusNotFound,
+		},
+		{
+			name:     "license page",
+			path:     "/cc-by-4.0",
+			code:     http.StatusOK,
+			expected: `<h2><img class="logo" src="/img/license/cc-by-4.0.svg" alt=""/> License: CC-BY-4.0</h2>`,
+		},
+		{
+			name:     "index",
+			path:     "/",
+			code:     http.StatusOK,
+			expected: `<li><img class="logo" src="/img/license/cc-by-4.0.svg" alt=""/> <a href="/cc-by-4.0">`,
+		},
+		{
+			name:     "base path",
+			base:     "/licenses",
+			path:     "/licenses/cc-by-4.0",
+			code:     http.StatusOK,
+			expected: `src="/licenses/img/license/cc-by-4.0.svg"`,
+		},
+		{
+			name:     "logo under base path",
+			base:     "/licenses",
+			path:     "/licenses/img/license/cc-by-4.0.svg",
+			code:     http.StatusOK,
+			expected: string(svg),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(WithLicenses(licenses), WithBasePath(tc.base))
+			if err != nil {
+				t.Fatalf("could not initialize app handler: %s", err)
+			}
+
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d", tc.code, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the response to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+
+	r := httptest.NewRequest("GET", "/img/license/cc-by-4.0.svg", nil)
+	w := httptest.NewRecorder()
+
+	h, err := New(WithLicenses(licenses))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	h.ServeHTTP(w, r)
+
+	if ct := w.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("expected an SVG, got %q", ct)
+	}
+
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'none'") {
+		t.Errorf("expected scripts to be blocked, got %q", csp)
+	}
+}
@@ -0,0 +1,83 @@
+package ynalhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// healthPath is the health check, which keeps answering in maintenance mode
+// so load balancers and orchestrators can tell the instance is still alive.
+const healthPath = "/healthz"
+
+// Maintenance switches every route but the health check to a 503 while it's
+// on. It can be switched at any time and is safe for concurrent use.
+type Maintenance struct {
+	on         atomic.Bool
+	retryAfter time.Duration
+}
+
+// NewMaintenance returns a Maintenance, off, that tells clients to come back
+// after retryAfter while it's on.
+func NewMaintenance(retryAfter time.Duration) *Maintenance {
+	return &Maintenance{retryAfter: retryAfter}
+}
+
+// Set turns maintenance mode on or off.
+func (m *Maintenance) Set(on bool) {
+	m.on.Store(on)
+}
+
+// Enabled reports whether maintenance mode is on.
+func (m *Maintenance) Enabled() bool {
+	return m.on.Load()
+}
+
+// health fails while maintenance mode is on, so load balancers stop sending
+// the instance traffic.
+func (m *Maintenance) health(ctx context.Context) error {
+	if m.Enabled() {
+		return errors.New("down for maintenance")
+	}
+
+	return nil
+}
+
+// WithMaintenance serves a 503 for every route but /healthz and /readyz
+// whenever m is on.
+func WithMaintenance(m *Maintenance) Option {
+	return func(c *config) {
+		c.maintenance = m
+	}
+}
+
+func withMaintenance(m *Maintenance, tmpl *pageTemplates, next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+
+	retryAfter := strconv.Itoa(int(m.retryAfter.Seconds()))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.Enabled() || r.URL.Path == healthPath || r.URL.Path == readyPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Retry-After", retryAfter)
+		w.Header().Set("Cache-Control", "no-store")
+		writeError(w, r, tmpl, http.StatusServiceUnavailable, "ynal is down for maintenance, please try again later")
+	})
+}
+
+// healthHandler says the instance is up.
+func healthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Write([]byte("ok\n"))
+	})
+}
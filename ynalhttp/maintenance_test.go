@@ -0,0 +1,79 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaintenance(t *testing.T) {
+	m := NewMaintenance(2 * time.Minute)
+
+	h, err := New(WithMaintenance(m), WithCacheControl("public, max-age=3600"))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	get := func(path string, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	if w := get("/mit", "text/plain"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 before maintenance, got %d", w.Code)
+	}
+
+	m.Set(true)
+
+	tt := []struct {
+		accept      string
+		contentType string
+	}{
+		{accept: "text/plain", contentType: "text/plain; charset=utf-8"},
+		{accept: "application/json", contentType: "application/problem+json"},
+		{accept: "text/html", contentType: "text/html"},
+	}
+
+	for _, tc := range tt {
+		for _, path := range []string{"/mit", "/", "/api/v1/licenses"} {
+			w := get(path, tc.accept)
+
+			if w.Code != http.StatusServiceUnavailable {
+				t.Fatalf("%s as %s: expected 503, got %d", path, tc.accept, w.Code)
+			}
+
+			if got := w.Header().Get("Retry-After"); got != "120" {
+				t.Errorf("%s as %s: expected Retry-After: 120, got %q", path, tc.accept, got)
+			}
+
+			if got := w.Header().Get("Cache-Control"); got != "no-store" {
+				t.Errorf("%s as %s: expected the 503 not to be cached, got %q", path, tc.accept, got)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Errorf("%s as %s: expected %q, got %q", path, tc.accept, tc.contentType, got)
+			}
+
+			if !strings.Contains(w.Body.String(), "maintenance") {
+				t.Errorf("%s as %s: expected a friendly message, got %q", path, tc.accept, w.Body.String())
+			}
+		}
+	}
+
+	if w := get("/healthz", ""); w.Code != http.StatusOK || w.Body.String() != "ok\n" {
+		t.Fatalf("expected the health check to keep working, got %d %q", w.Code, w.Body.String())
+	}
+
+	m.Set(false)
+
+	if w := get("/mit", "text/plain"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 after maintenance, got %d", w.Code)
+	}
+}
@@ -0,0 +1,51 @@
+package ynalhttp
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/packrat386/ynal"
+)
+
+// toRST renders l as a reStructuredText document, titled and labelled with
+// its ID, with the text in a literal block so it comes out as written.
+func toRST(l ynal.LicenseData) []byte {
+	buf := new(strings.Builder)
+
+	buf.WriteString(".. _license-" + l.ID + ":\n\n")
+	buf.WriteString(l.Title + "\n")
+	buf.WriteString(strings.Repeat("=", max(utf8.RuneCountInString(l.Title), 1)) + "\n\n")
+
+	buf.WriteString(":SPDX-License-Identifier: " + l.SPDXID() + "\n\n")
+
+	buf.WriteString("::\n\n")
+	for _, line := range strings.Split(strings.TrimRight(l.Text, "\n"), "\n") {
+		buf.WriteString(strings.TrimRight("   "+line, " ") + "\n")
+	}
+
+	return []byte(buf.String())
+}
+
+// toAsciiDoc renders l as an AsciiDoc document, titled and anchored with its
+// ID, with the text in a literal block so it comes out as written.
+func toAsciiDoc(l ynal.LicenseData) []byte {
+	buf := new(strings.Builder)
+
+	buf.WriteString("[[license-" + l.ID + "]]\n")
+	buf.WriteString("= " + l.Title + "\n")
+
+	buf.WriteString(":spdx-license-identifier: " + l.SPDXID() + "\n")
+
+	// the block runs until a line of just its delimiter, so make sure no line
+	// of the text is one
+	delim := "...."
+	for strings.Contains("\n"+l.Text+"\n", "\n"+delim+"\n") {
+		delim += "."
+	}
+
+	buf.WriteString("\n" + delim + "\n")
+	buf.WriteString(strings.TrimRight(l.Text, "\n") + "\n")
+	buf.WriteString(delim + "\n")
+
+	return []byte(buf.String())
+}
@@ -0,0 +1,96 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestMarkup(t *testing.T) {
+	tt := []struct {
+		name        string
+		path        string
+		accept      string
+		contentType string
+		start       string
+	}{
+		{
+			name:        "rst",
+			path:        "/mit",
+			accept:      "text/x-rst",
+			contentType: "text/x-rst; charset=utf-8",
+			start:       ".. _license-mit:\n\nMIT\n===\n\n:SPDX-License-Identifier: MIT\n\n::\n\n   Copyright <YEAR> <COPYRIGHT HOLDER>\n",
+		},
+		{
+			name:        "registered rst type",
+			path:        "/mit",
+			accept:      "text/prs.fallenstein.rst",
+			contentType: "text/x-rst; charset=utf-8",
+			start:       ".. _license-mit:",
+		},
+		{
+			name:        "asciidoc",
+			path:        "/mit",
+			accept:      "text/asciidoc",
+			contentType: "text/asciidoc; charset=utf-8",
+			start:       "[[license-mit]]\n= MIT\n:spdx-license-identifier: MIT\n\n....\nCopyright <YEAR> <COPYRIGHT HOLDER>\n",
+		},
+		{
+			name:        "preferred over html",
+			path:        "/mit",
+			accept:      "text/html;q=0.5, text/x-asciidoc",
+			contentType: "text/asciidoc; charset=utf-8",
+			start:       "[[license-mit]]",
+		},
+		{
+			name:        "combination",
+			path:        "/apache_2+llvm-exception",
+			accept:      "text/x-rst",
+			contentType: "text/x-rst; charset=utf-8",
+			start:       ".. _license-apache_2+llvm-exception:",
+		},
+		{
+			name:        "other pages fall back",
+			path:        "/tags",
+			accept:      "text/x-rst",
+			contentType: "text/plain",
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tc.contentType) {
+				t.Errorf("expected Content-Type %q, got %q", tc.contentType, got)
+			}
+
+			if !strings.HasPrefix(w.Body.String(), tc.start) {
+				t.Errorf("expected body to start with %q, got %q", tc.start, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAsciiDocDelimiter(t *testing.T) {
+	got := string(toAsciiDoc(ynal.LicenseData{ID: "x", Title: "X", Text: "a\n....\nb\n"}))
+	want := "[[license-x]]\n= X\n:spdx-license-identifier: LicenseRef-X\n\n.....\na\n....\nb\n.....\n"
+
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
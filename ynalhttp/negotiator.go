@@ -0,0 +1,261 @@
+package ynalhttp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/packrat386/ynal/negotiate"
+)
+
+// WithStrictAccept answers requests with a malformed Accept header, like one
+// with a range that can't be parsed or a weight outside 0 to 1, with a 400
+// saying what's wrong with it. Without it the bad parts are ignored, weights
+// clamped to 0 to 1, and the rest negotiated as usual.
+func WithStrictAccept() Option {
+	return func(c *config) {
+		c.strictAccept = true
+	}
+}
+
+// WithPreferredTypes breaks ties in negotiation, like the one */* makes of
+// everything, in favor of types, in that order. The rest still win ties after
+// them in the usual order, which starts with text/plain. Each must be one of
+// MediaTypes.
+func WithPreferredTypes(types ...string) Option {
+	return func(c *config) {
+		c.preferred = types
+	}
+}
+
+// WithBrowserPreferredTypes is WithPreferredTypes for requests from browsers,
+// told apart by a User-Agent starting with "Mozilla/", as every major one's
+// does. Other requests keep the order WithPreferredTypes sets, and every
+// response varies by User-Agent.
+func WithBrowserPreferredTypes(types ...string) Option {
+	return func(c *config) {
+		c.browserPreferred = types
+	}
+}
+
+// MediaTypes returns every media type a negotiated route can serve, which are
+// the ones WithPreferredTypes accepts.
+func MediaTypes() []string {
+	return slices.Sorted(maps.Keys(licenseMediaTypes))
+}
+
+// maxMemoizedAccepts is how many Accept headers a negotiator remembers. Most
+// clients send one of a handful, so this is plenty for them, and once it's
+// exceeded, by clients sending something new every time, it starts over.
+const maxMemoizedAccepts = 1024
+
+// maxMemoizedAccept is the longest Accept header a negotiator remembers.
+// Real ones are far shorter, and longer ones are negotiated every time so the
+// memo can't be made to hold more than about 256KB.
+const maxMemoizedAccept = 256
+
+// negotiator picks the media type to respond with from a fixed set of offers,
+// remembering what each Accept header came to so the same one isn't parsed
+// over and over.
+type negotiator struct {
+	offered []string
+	extra   map[string]string
+
+	mu   sync.Mutex
+	memo map[string]string
+}
+
+// newNegotiator offers the media types in offers, and those in extra, which
+// maps each name a client might ask for to the one the route knows it by.
+// Those in prefer win ties over the rest, in that order.
+func newNegotiator(extra map[string]string, prefer []string) *negotiator {
+	offered := slices.Clone(offers)
+	for _, t := range slices.Sorted(maps.Keys(extra)) {
+		if !slices.Contains(offered, t) {
+			offered = append(offered, t)
+		}
+	}
+
+	ordered := []string{}
+	for _, t := range slices.Concat(prefer, offered) {
+		if slices.Contains(offered, t) && !slices.Contains(ordered, t) {
+			ordered = append(ordered, t)
+		}
+	}
+
+	return &negotiator{offered: ordered, extra: extra, memo: map[string]string{}}
+}
+
+// negotiators negotiate every route in one order of preference: general for
+// routes serving just offers, and license for license pages, which serve
+// more.
+type negotiators struct {
+	general *negotiator
+	license *negotiator
+}
+
+func newNegotiators(prefer []string) *negotiators {
+	return &negotiators{general: newNegotiator(nil, prefer), license: newNegotiator(licenseMediaTypes, prefer)}
+}
+
+var defaultNegotiators = newNegotiators(nil)
+
+// preferences are the negotiators for browsers and everything else, set by
+// WithPreferredTypes and WithBrowserPreferredTypes. browser is nil when
+// browsers aren't treated any differently.
+type preferences struct {
+	standard *negotiators
+	browser  *negotiators
+}
+
+func newPreferences(preferred []string, browserPreferred []string) *preferences {
+	if len(preferred) == 0 && len(browserPreferred) == 0 {
+		return nil
+	}
+
+	p := &preferences{standard: newNegotiators(preferred)}
+	if len(browserPreferred) > 0 {
+		p.browser = newNegotiators(browserPreferred)
+	}
+
+	return p
+}
+
+func validatePreferred(types []string) error {
+	for _, t := range types {
+		if !slices.Contains(MediaTypes(), t) {
+			return fmt.Errorf("unknown media type %q, must be one of %s", t, strings.Join(MediaTypes(), ", "))
+		}
+	}
+
+	return nil
+}
+
+type preferencesKey struct{}
+
+// withPreferences makes p available to every handler below it, for
+// negotiatorsFrom.
+func withPreferences(p *preferences, next http.Handler) http.Handler {
+	if p == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p.browser != nil {
+			w.Header().Add("Vary", "User-Agent")
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), preferencesKey{}, p)))
+	})
+}
+
+// negotiatorsFrom returns the negotiators for r, which depend on whether it's
+// from a browser.
+func negotiatorsFrom(r *http.Request) *negotiators {
+	p, _ := r.Context().Value(preferencesKey{}).(*preferences)
+	if p == nil {
+		return defaultNegotiators
+	}
+
+	if p.browser != nil && strings.HasPrefix(r.UserAgent(), "Mozilla/") {
+		return p.browser
+	}
+
+	return p.standard
+}
+
+// negotiate returns the media type to respond to accept with.
+func (n *negotiator) negotiate(accept string) string {
+	n.mu.Lock()
+	mediatype, ok := n.memo[accept]
+	n.mu.Unlock()
+
+	if ok {
+		return mediatype
+	}
+
+	mediatype = n.choose(accept)
+
+	if len(accept) > maxMemoizedAccept {
+		return mediatype
+	}
+
+	n.mu.Lock()
+	if len(n.memo) >= maxMemoizedAccepts {
+		clear(n.memo)
+	}
+	n.memo[accept] = mediatype
+	n.mu.Unlock()
+
+	return mediatype
+}
+
+// choose negotiates accept from scratch. Only a type the header refuses with
+// q=0 is ever ruled out: if nothing offered matches, it falls back to the
+// first offer that isn't refused, and only when every one is does it return
+// "".
+func (n *negotiator) choose(accept string) string {
+	offered := n.offered
+
+	for {
+		mediatype, err := negotiate.Negotiate(accept, offered)
+		if err != nil {
+			break
+		}
+
+		// an alias is served as its canonical type, so it can't win when
+		// that's refused, like application/xhtml+xml for text/*;q=0
+		canonical := n.canonical(mediatype)
+		if canonical == mediatype || !negotiate.Refuses(accept, canonical) {
+			return canonical
+		}
+
+		offered = slices.DeleteFunc(slices.Clone(offered), func(o string) bool { return o == mediatype })
+	}
+
+	for _, o := range n.offered {
+		if !negotiate.Refuses(accept, o) && !negotiate.Refuses(accept, n.canonical(o)) {
+			return n.canonical(o)
+		}
+	}
+
+	return ""
+}
+
+// canonical returns the media type a route knows mediatype by.
+func (n *negotiator) canonical(mediatype string) string {
+	if known, ok := n.extra[mediatype]; ok {
+		return known
+	}
+
+	if mediatype == "application/xhtml+xml" {
+		return "text/html"
+	}
+
+	return mediatype
+}
+
+// writeNotAcceptable answers a request whose Accept header refuses every
+// media type the route can serve.
+func writeNotAcceptable(w http.ResponseWriter, r *http.Request, tmpl *pageTemplates) {
+	writeError(w, r, tmpl, http.StatusNotAcceptable, fmt.Sprintf("the Accept header refuses every media type this serves: %s", r.Header.Get("Accept")))
+}
+
+func withStrictAccept(strict bool, tmpl *pageTemplates, next http.Handler) http.Handler {
+	if !strict {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := negotiate.ParseStrict(r.Header.Get("Accept")); err != nil {
+			writeError(w, r, tmpl, http.StatusBadRequest, fmt.Sprintf("%s (see RFC 9110, section 12.5.1)", err))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
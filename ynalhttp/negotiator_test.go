@@ -0,0 +1,183 @@
+package ynalhttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestNegotiator(t *testing.T) {
+	tt := []struct {
+		accept   string
+		expected string
+	}{
+		{accept: "", expected: "text/plain"},
+		{accept: "*/*", expected: "text/plain"},
+		{accept: "application/xhtml+xml", expected: "text/html"},
+		{accept: "text/x-rst", expected: "text/x-rst"},
+		{accept: "text/prs.fallenstein.rst", expected: "text/x-rst"},
+		{accept: "image/png", expected: "text/plain"},
+		{accept: "text/plain;q=0, */*", expected: "text/html"},
+		{accept: "text/plain;q=0", expected: "text/html"},
+		{accept: "text/plain;q=0, image/png", expected: "text/html"},
+		{accept: "text/*;q=0, */*;q=0.1", expected: "application/json"},
+		{accept: "text/html;q=0, application/xhtml+xml", expected: "text/plain"},
+		{accept: "text/x-rst;q=0, text/prs.fallenstein.rst", expected: "text/plain"},
+		{accept: "*/*;q=0", expected: ""},
+	}
+
+	n := newNegotiator(licenseMediaTypes, nil)
+
+	// the second time round comes from the memo
+	for range 2 {
+		for _, tc := range tt {
+			if got := n.negotiate(tc.accept); got != tc.expected {
+				t.Errorf("Accept %q: expected %q, got %q", tc.accept, tc.expected, got)
+			}
+		}
+	}
+
+	if len(n.memo) != len(tt) {
+		t.Errorf("expected %d headers remembered, got %d", len(tt), len(n.memo))
+	}
+
+	for i := range maxMemoizedAccepts + 1 {
+		n.negotiate(fmt.Sprintf("application/json, text/x-%d", i))
+	}
+
+	if len(n.memo) > maxMemoizedAccepts {
+		t.Errorf("expected at most %d headers remembered, got %d", maxMemoizedAccepts, len(n.memo))
+	}
+
+	if got := n.negotiate("application/json, text/x-0"); got != "application/json" {
+		t.Errorf("expected a forgotten header to be negotiated again, got %q", got)
+	}
+
+	long := "application/json, text/x-" + strings.Repeat("a", maxMemoizedAccept)
+	if got := n.negotiate(long); got != "application/json" {
+		t.Errorf("expected a long header to be negotiated, got %q", got)
+	}
+
+	if _, ok := n.memo[long]; ok {
+		t.Errorf("expected a header over %d bytes not to be remembered", maxMemoizedAccept)
+	}
+}
+
+func TestNotAcceptable(t *testing.T) {
+	h, err := New(WithLicenses([]ynal.LicenseData{ynal.NewLicense("MIT", "mit text\n")}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	for _, path := range []string{"/", "/mit", "/search?q=mit", "/stats"} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept", "text/*;q=0, application/json;q=0")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotAcceptable {
+			t.Errorf("%s: expected 406, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestPreferredTypes(t *testing.T) {
+	licenses := []ynal.LicenseData{ynal.NewLicense("MIT", "mit text\n")}
+	browser := "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+
+	tt := []struct {
+		name      string
+		opts      []Option
+		accept    string
+		userAgent string
+		expected  string
+		varies    bool
+	}{
+		{name: "default", accept: "*/*", expected: "text/plain"},
+		{name: "preferred", opts: []Option{WithPreferredTypes("text/html")}, accept: "*/*", expected: "text/html"},
+		{name: "preferred in order", opts: []Option{WithPreferredTypes("text/x-rst", "application/json")}, accept: "*/*", expected: "text/x-rst"},
+		{name: "only breaks ties", opts: []Option{WithPreferredTypes("text/html")}, accept: "application/json, */*;q=0.5", expected: "application/json"},
+		{name: "browser", opts: []Option{WithBrowserPreferredTypes("text/html")}, accept: "*/*", userAgent: browser, expected: "text/html", varies: true},
+		{name: "not a browser", opts: []Option{WithBrowserPreferredTypes("text/html")}, accept: "*/*", userAgent: "curl/8.5.0", expected: "text/plain", varies: true},
+		{name: "browser and default", opts: []Option{WithPreferredTypes("application/json"), WithBrowserPreferredTypes("text/html")}, accept: "*/*", userAgent: "curl/8.5.0", expected: "application/json", varies: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(append(tc.opts, WithLicenses(licenses))...)
+			if err != nil {
+				t.Fatalf("could not initialize app handler: %s", err)
+			}
+
+			r := httptest.NewRequest("GET", "/mit", nil)
+			r.Header.Set("Accept", tc.accept)
+			r.Header.Set("User-Agent", tc.userAgent)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got, _, _ := strings.Cut(w.Header().Get("Content-Type"), ";"); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+
+			if varies := strings.Contains(strings.Join(w.Header().Values("Vary"), ","), "User-Agent"); varies != tc.varies {
+				t.Errorf("expected varying by User-Agent to be %t", tc.varies)
+			}
+		})
+	}
+
+	if _, err := New(WithLicenses(licenses), WithPreferredTypes("image/png")); err == nil {
+		t.Errorf("expected an error preferring a type that isn't served")
+	}
+}
+
+func TestStrictAccept(t *testing.T) {
+	licenses := []ynal.LicenseData{ynal.NewLicense("MIT", "mit text\n")}
+
+	tt := []struct {
+		name     string
+		strict   bool
+		accept   string
+		expected int
+		body     string
+	}{
+		{name: "lenient", accept: "text/plain;q=2, text/", expected: http.StatusOK, body: "mit text\n"},
+		{name: "lenient clamps", accept: "application/json;q=0.9, text/plain;q=7", expected: http.StatusOK, body: "mit text\n"},
+		{name: "strict", strict: true, accept: "text/plain, application/json;q=0.5", expected: http.StatusOK, body: "mit text\n"},
+		{name: "strict bad weight", strict: true, accept: "text/plain;q=2", expected: http.StatusBadRequest, body: `malformed Accept header: invalid weight "2"`},
+		{name: "strict bad range", strict: true, accept: "text/plain, text/", expected: http.StatusBadRequest, body: `malformed Accept header: invalid media type " text/"`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []Option{WithLicenses(licenses)}
+			if tc.strict {
+				opts = append(opts, WithStrictAccept())
+			}
+
+			h, err := New(opts...)
+			if err != nil {
+				t.Fatalf("could not initialize app handler: %s", err)
+			}
+
+			r := httptest.NewRequest("GET", "/mit", nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.expected {
+				t.Fatalf("expected %d, got %d: %s", tc.expected, w.Code, w.Body.String())
+			}
+
+			if !strings.Contains(w.Body.String(), tc.body) {
+				t.Errorf("expected the body to contain %q, got %q", tc.body, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package ynalhttp
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+type normalizedJSON struct {
+	ID         string `json:"id"`
+	SPDX       string `json:"spdx"`
+	Normalized string `json:"normalized"`
+	SHA256     string `json:"sha256"`
+	LicenseURL string `json:"license_url"`
+}
+
+// normalizedURL is where l's normalized text is served.
+func normalizedURL(l ynal.LicenseData) string {
+	return l.URL + "/normalized"
+}
+
+// normalizedHandler serves l's text in the canonical form of ynal.Normalize,
+// which tools compare and deduplicate licenses by, along with its SHA-256. It's
+// plain text, exactly the bytes hashed with no trailing newline and the hash in
+// Repr-Digest, unless JSON is asked for.
+func normalizedHandler(l ynal.LicenseData) (http.Handler, error) {
+	normalized := ynal.Normalize(l.Text)
+	sum := sha256.Sum256([]byte(normalized))
+
+	jsonData, err := json.Marshal(normalizedJSON{
+		ID:         l.ID,
+		SPDX:       l.SPDXID(),
+		Normalized: normalized,
+		SHA256:     hex.EncodeToString(sum[:]),
+		LicenseURL: l.URL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal JSON: %w", err)
+	}
+
+	plainData := []byte(normalized)
+	digest := fmt.Sprintf("sha-256=:%s:", base64.StdEncoding.EncodeToString(sum[:]))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mostAcceptable(r) == "application/json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(jsonData)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Repr-Digest", digest)
+		w.Write(plainData)
+	}), nil
+}
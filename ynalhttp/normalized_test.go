@@ -0,0 +1,50 @@
+package ynalhttp
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNormalized(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/mit/normalized", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	text := w.Body.String()
+	if !strings.HasPrefix(text, "permission is hereby granted, free of charge,") || strings.Contains(text, "\n") {
+		t.Errorf("expected normalized text without the copyright line, got %q", text)
+	}
+
+	sum := sha256.Sum256(w.Body.Bytes())
+	if got, expected := w.Header().Get("Repr-Digest"), "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":"; got != expected {
+		t.Errorf("expected Repr-Digest %q, got %q", expected, got)
+	}
+
+	r = httptest.NewRequest("GET", "/mit/normalized", nil)
+	r.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	var got normalizedJSON
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	if got.ID != "mit" || got.SPDX != "MIT" || got.Normalized != text || got.SHA256 != hex.EncodeToString(sum[:]) || got.LicenseURL != "/mit" {
+		t.Errorf("unexpected response: %+v", got)
+	}
+}
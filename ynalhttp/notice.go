@@ -0,0 +1,43 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+// maxNoticeSize bounds NOTICE requests, which are a handful of names and
+// copyright lines.
+const maxNoticeSize = 64 << 10
+
+// noticeHandler renders the NOTICE file described by a JSON ynal.Notice in the
+// request body.
+func noticeHandler(licenses []ynal.LicenseData) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n ynal.Notice
+
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		if err := dec.Decode(&n); err != nil {
+			writeProblem(w, newProblem(r, bodyStatus(err), fmt.Sprintf("could not parse notice: %s", err)))
+			return
+		}
+
+		text, err := n.Render(licenses)
+		if errors.Is(err, ynal.ErrNotFound) {
+			writeProblem(w, newProblem(r, http.StatusUnprocessableEntity, err.Error()))
+			return
+		} else if err != nil {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, err.Error()))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="NOTICE"`)
+		w.Write([]byte(text))
+	})
+}
@@ -0,0 +1,73 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotice(t *testing.T) {
+	tt := []struct {
+		name        string
+		body        string
+		code        int
+		contentType string
+		expected    string
+	}{
+		{
+			name:        "notice",
+			body:        `{"project": "widget", "copyright": ["2024 Jane Doe"], "components": [{"name": "gizmo", "license": "mit"}]}`,
+			code:        http.StatusOK,
+			contentType: "text/plain; charset=utf-8",
+			expected:    "widget\nCopyright 2024 Jane Doe\n\nThis product bundles the following components:\n\ngizmo\nLicensed under MIT\n",
+		},
+		{
+			name:        "malformed",
+			body:        `{"project": `,
+			code:        http.StatusBadRequest,
+			contentType: "application/problem+json",
+		},
+		{
+			name:        "unknown field",
+			body:        `{"project": "widget", "holder": "Jane Doe"}`,
+			code:        http.StatusBadRequest,
+			contentType: "application/problem+json",
+		},
+		{
+			name:        "missing project",
+			body:        `{"copyright": ["2024 Jane Doe"]}`,
+			code:        http.StatusBadRequest,
+			contentType: "application/problem+json",
+		},
+		{
+			name:        "unknown license",
+			body:        `{"project": "widget", "components": [{"name": "gizmo", "license": "nope"}]}`,
+			code:        http.StatusUnprocessableEntity,
+			contentType: "application/problem+json",
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/notice", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Fatalf("expected Content-Type %q, got %q", tc.contentType, got)
+			}
+
+			if tc.expected != "" && w.Body.String() != tc.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+type obligationJSON struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Licenses    []familyMember `json:"licenses"`
+}
+
+type obligationReportJSON struct {
+	Licenses    []familyMember   `json:"licenses"`
+	Obligations []obligationJSON `json:"obligations"`
+}
+
+type obligationsPage struct {
+	Query       string
+	Names       string
+	Licenses    []ynal.LicenseData
+	Obligations []ynal.Obligation
+}
+
+func toFamilyMembers(licenses []ynal.LicenseData) []familyMember {
+	members := []familyMember{}
+	for _, l := range licenses {
+		members = append(members, familyMember{ID: l.ID, Title: l.Title, URL: l.URL, Version: l.Version})
+	}
+
+	return members
+}
+
+func toObligationReportJSON(page obligationsPage) obligationReportJSON {
+	report := obligationReportJSON{Licenses: toFamilyMembers(page.Licenses), Obligations: []obligationJSON{}}
+	for _, o := range page.Obligations {
+		report.Obligations = append(report.Obligations, obligationJSON{Name: o.Name, Description: o.Description, Licenses: toFamilyMembers(o.Licenses)})
+	}
+
+	return report
+}
+
+// writeObligationsMarkdown writes page as a Markdown document, to drop into a
+// project's docs.
+func writeObligationsMarkdown(w io.Writer, page obligationsPage) {
+	fmt.Fprintf(w, "# License obligations\n\n")
+
+	links := []string{}
+	for _, l := range page.Licenses {
+		links = append(links, fmt.Sprintf("[%s](%s)", l.Title, l.URL))
+	}
+
+	fmt.Fprintf(w, "Using %s, you must:\n\n", strings.Join(links, ", "))
+
+	if len(page.Obligations) == 0 {
+		fmt.Fprintf(w, "Nothing. None of these licenses ask anything of you.\n\n")
+	}
+
+	for _, o := range page.Obligations {
+		titles := []string{}
+		for _, l := range o.Licenses {
+			titles = append(titles, l.Title)
+		}
+
+		fmt.Fprintf(w, "- %s (%s)\n", o.Description, strings.Join(titles, ", "))
+	}
+
+	if len(page.Obligations) > 0 {
+		fmt.Fprintf(w, "\n")
+	}
+
+	fmt.Fprintf(w, "_This is a summary, not legal advice. The full licenses are what count._\n")
+}
+
+// obligationsHandler reports everything using the licenses in the licenses
+// query parameter together asks of someone, negotiated like the license
+// routes except that plain text is Markdown. format=html, json, or markdown
+// picks one regardless, and markdown is served as a download.
+func obligationsHandler(licenses []ynal.LicenseData, tmpl *pageTemplates) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			switch mostAcceptable(r) {
+			case "text/html":
+				format = "html"
+			case "application/json":
+				format = "json"
+			default:
+				format = "markdown"
+			}
+		}
+
+		if format != "html" && format != "json" && format != "markdown" {
+			writeError(w, r, tmpl, http.StatusBadRequest, fmt.Sprintf("unknown format: %s (try html, json, or markdown)", format))
+			return
+		}
+
+		query := r.URL.Query().Get("licenses")
+
+		found, missing := parseBundle(licenses, query)
+		if len(missing) > 0 {
+			writeError(w, r, tmpl, http.StatusNotFound, fmt.Sprintf("no such licenses: %s", strings.Join(missing, ", ")))
+			return
+		}
+
+		// the HTML page is also the form to ask with
+		if len(found) == 0 && format != "html" {
+			writeError(w, r, tmpl, http.StatusBadRequest, "no licenses requested, list them like ?licenses=mit,apache_2")
+			return
+		}
+
+		titles := []string{}
+		for _, l := range found {
+			titles = append(titles, l.Title)
+		}
+
+		page := obligationsPage{
+			Query:       query,
+			Names:       strings.Join(titles, ", "),
+			Licenses:    found,
+			Obligations: ynal.ObligationReport(found),
+		}
+
+		switch format {
+		case "html":
+			buf := getBuffer()
+			defer putBuffer(buf)
+			v := tmpl.variant(r)
+
+			if err := tmpl.ExecuteTemplate(buf, v, "obligations.html.tmpl", page); err != nil {
+				logf(r, "could not render obligations template: %s", err)
+				writeError(w, r, tmpl, http.StatusInternalServerError, "could not render obligations")
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html")
+			setVariantHeaders(w, v)
+			w.Write(buf.Bytes())
+		case "json":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(toObligationReportJSON(page))
+		default:
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+			if r.URL.Query().Get("format") == "markdown" {
+				w.Header().Set("Content-Disposition", `attachment; filename="OBLIGATIONS.md"`)
+			}
+
+			writeObligationsMarkdown(w, page)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestObligations(t *testing.T) {
+	tt := []struct {
+		name        string
+		path        string
+		accept      string
+		code        int
+		contentType string
+		expected    []string
+	}{
+		{
+			name:        "html",
+			path:        "/obligations?licenses=mit,gpl_3",
+			accept:      "text/html",
+			code:        http.StatusOK,
+			contentType: "text/html",
+			expected:    []string{"Using MIT, GPL_3, you must:", "Make the source code available", `<a href="/gpl_3">GPL_3</a>`},
+		},
+		{
+			name:        "form",
+			path:        "/obligations",
+			accept:      "text/html",
+			code:        http.StatusOK,
+			contentType: "text/html",
+			expected:    []string{`name="licenses"`},
+		},
+		{
+			name:        "markdown",
+			path:        "/obligations?licenses=apache_2",
+			code:        http.StatusOK,
+			contentType: "text/markdown; charset=utf-8",
+			expected:    []string{"# License obligations", "Using [Apache_2](/apache_2), you must:", "- Pass on any NOTICE file that came with it. (Apache_2)"},
+		},
+		{
+			name:        "nothing asked",
+			path:        "/obligations?licenses=unlicense&format=markdown",
+			accept:      "text/html",
+			code:        http.StatusOK,
+			contentType: "text/markdown; charset=utf-8",
+			expected:    []string{"None of these licenses ask anything of you."},
+		},
+		{
+			name:   "missing",
+			path:   "/obligations?licenses=mit,nope",
+			accept: "application/json",
+			code:   http.StatusNotFound,
+		},
+		{
+			name:   "none",
+			path:   "/obligations",
+			accept: "application/json",
+			code:   http.StatusBadRequest,
+		},
+		{
+			name: "unknown format",
+			path: "/obligations?licenses=mit&format=pdf",
+			code: http.StatusBadRequest,
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Errorf("expected content type %q, got %q", tc.contentType, got)
+			}
+
+			for _, want := range tc.expected {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("expected body to contain %q, got %s", want, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestObligationsJSON(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/obligations?licenses=agpl_3,mit", nil)
+	r.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	report := obligationReportJSON{}
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	if len(report.Licenses) != 2 || report.Licenses[0].ID != "agpl_3" {
+		t.Fatalf("expected the licenses asked for, got %+v", report.Licenses)
+	}
+
+	if len(report.Obligations) != 5 {
+		t.Fatalf("expected every obligation of the AGPL, got %+v", report.Obligations)
+	}
+
+	if o := report.Obligations[0]; o.Name != "include-copyright" || len(o.Licenses) != 2 || o.Description == "" {
+		t.Errorf("expected both licenses to ask for the copyright notice, got %+v", o)
+	}
+
+	if o := report.Obligations[4]; o.Name != "network-use-disclose" || len(o.Licenses) != 1 || o.Licenses[0].URL != "/agpl_3" {
+		t.Errorf("expected only the AGPL to cover network use, got %+v", o)
+	}
+}
@@ -0,0 +1,158 @@
+package ynalhttp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+// IndexOrder is how the index lists licenses, in every format.
+type IndexOrder string
+
+const (
+	// OrderAlphabetical lists licenses by title. It's the default.
+	OrderAlphabetical IndexOrder = "alphabetical"
+
+	// OrderPriority lists the licenses given to WithIndexOrder first, in
+	// that order, then the rest by title.
+	OrderPriority IndexOrder = "priority"
+
+	// OrderPopular lists the most fetched licenses first, by the counts in
+	// Stats, then the rest by title. It's re-sorted every
+	// popularityInterval.
+	OrderPopular IndexOrder = "popular"
+)
+
+var indexOrders = []IndexOrder{OrderAlphabetical, OrderPriority, OrderPopular}
+
+// IndexOrders returns the orders WithIndexOrder accepts.
+func IndexOrders() []IndexOrder {
+	return slices.Clone(indexOrders)
+}
+
+// WithIndexOrder sets how the index lists licenses. priority is the IDs to
+// list first with OrderPriority, and can't be given with any other order.
+// Families are listed in the order of their first license.
+func WithIndexOrder(order IndexOrder, priority ...string) Option {
+	return func(c *config) {
+		c.order = indexOrder{kind: order, priority: priority}
+	}
+}
+
+// indexOrder is an IndexOrder along with its priorities.
+type indexOrder struct {
+	kind     IndexOrder
+	priority []string
+}
+
+func (o indexOrder) validate() error {
+	if !slices.Contains(indexOrders, o.kind) {
+		return fmt.Errorf("unknown index order %q", o.kind)
+	}
+
+	if len(o.priority) > 0 && o.kind != OrderPriority {
+		return fmt.Errorf("priorities only apply to the %s index order, not %s", OrderPriority, o.kind)
+	}
+
+	return nil
+}
+
+// key identifies the order, for caching the index sorted by it.
+func (o indexOrder) key() string {
+	return string(o.kind) + ":" + strings.Join(o.priority, ",")
+}
+
+// compare returns the function to sort licenses with. hits are the fetch
+// counts by ID, used by OrderPopular.
+func (o indexOrder) compare(hits map[string]int64) func(a ynal.LicenseData, b ynal.LicenseData) int {
+	rank := map[string]int{}
+	for i, id := range o.priority {
+		if _, ok := rank[id]; !ok {
+			rank[id] = i
+		}
+	}
+
+	return func(a ynal.LicenseData, b ynal.LicenseData) int {
+		switch o.kind {
+		case OrderPriority:
+			ra, oka := rank[a.ID]
+			rb, okb := rank[b.ID]
+
+			switch {
+			case oka && okb && ra != rb:
+				return ra - rb
+			case oka != okb:
+				if oka {
+					return -1
+				}
+
+				return 1
+			}
+		case OrderPopular:
+			if ha, hb := hits[a.ID], hits[b.ID]; ha != hb {
+				if ha > hb {
+					return -1
+				}
+
+				return 1
+			}
+		}
+
+		if c := strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.ID, b.ID)
+	}
+}
+
+// sort returns a copy of page in the order.
+func (o indexOrder) sort(page indexPage, hits map[string]int64) indexPage {
+	cmp := o.compare(hits)
+
+	sorted := indexPage{
+		Other:      slices.SortedStableFunc(slices.Values(page.Other), cmp),
+		Exceptions: slices.SortedStableFunc(slices.Values(page.Exceptions), cmp),
+		Custom:     slices.SortedStableFunc(slices.Values(page.Custom), cmp),
+	}
+
+	for _, f := range page.Families {
+		f.Licenses = slices.SortedStableFunc(slices.Values(f.Licenses), cmp)
+		sorted.Families = append(sorted.Families, f)
+	}
+
+	slices.SortStableFunc(sorted.Families, func(a ynal.Family, b ynal.Family) int {
+		if len(a.Licenses) == 0 || len(b.Licenses) == 0 {
+			return len(b.Licenses) - len(a.Licenses)
+		}
+
+		return cmp(a.Licenses[0], b.Licenses[0])
+	})
+
+	return sorted
+}
+
+type indexOrderKey struct{}
+
+// withIndexOrder makes o available to the index, which survives the handlers
+// being rebuilt when the licenses change.
+func withIndexOrder(o indexOrder, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), indexOrderKey{}, o)))
+	})
+}
+
+// indexOrderFrom returns the order set with WithIndexOrder, or alphabetical
+// for a request that didn't come through New.
+func indexOrderFrom(r *http.Request) indexOrder {
+	o, ok := r.Context().Value(indexOrderKey{}).(indexOrder)
+	if !ok {
+		return indexOrder{kind: OrderAlphabetical}
+	}
+
+	return o
+}
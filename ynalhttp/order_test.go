@@ -0,0 +1,130 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+var orderLicenses = []ynal.LicenseData{
+	ynal.NewLicense("Zlib", "zlib\n"),
+	ynal.NewLicense("apache_2", "apache\n"),
+	ynal.NewLicense("MIT", "mit\n"),
+	ynal.NewLicense("BSD_3", "bsd\n"),
+}
+
+// indexOrderOf fetches the index as JSON and plain text and returns the IDs
+// on it, checking that both list them in the same order.
+func indexOrderOf(t *testing.T, h http.Handler) []string {
+	t.Helper()
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	index := indexJSON{}
+	if err := json.Unmarshal(w.Body.Bytes(), &index); err != nil {
+		t.Fatalf("could not decode index: %s", err)
+	}
+
+	ids := []string{}
+	for _, l := range index.Licenses {
+		ids = append(ids, l.ID)
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/plain")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	text := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(w.Body.String()), "\n") {
+		text = append(text, strings.Fields(line)[0])
+	}
+
+	if !slices.Equal(ids, text) {
+		t.Fatalf("expected JSON and plain text in the same order, got %v and %v", ids, text)
+	}
+
+	return ids
+}
+
+func TestIndexOrder(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{
+			name:     "alphabetical by default",
+			expected: []string{"apache_2", "bsd_3", "mit", "zlib"},
+		},
+		{
+			name:     "priority",
+			opts:     []Option{WithIndexOrder(OrderPriority, "mit", "zlib", "no-such-license")},
+			expected: []string{"mit", "zlib", "apache_2", "bsd_3"},
+		},
+		{
+			name:     "popular with no hits",
+			opts:     []Option{WithIndexOrder(OrderPopular)},
+			expected: []string{"apache_2", "bsd_3", "mit", "zlib"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(append([]Option{WithLicenses(orderLicenses), WithExceptions([]ynal.LicenseData{})}, tc.opts...)...)
+			if err != nil {
+				t.Fatalf("could not initialize app handler: %s", err)
+			}
+
+			if got := indexOrderOf(t, h); !slices.Equal(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestIndexOrderPopular(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	h, err := New(WithLicenses(orderLicenses), WithExceptions([]ynal.LicenseData{}), WithIndexOrder(OrderPopular), WithClock(func() time.Time { return at }))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	alphabetical := []string{"apache_2", "bsd_3", "mit", "zlib"}
+	if got := indexOrderOf(t, h); !slices.Equal(got, alphabetical) {
+		t.Fatalf("expected %v, got %v", alphabetical, got)
+	}
+
+	for _, path := range []string{"/zlib", "/zlib", "/mit"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	if got := indexOrderOf(t, h); !slices.Equal(got, alphabetical) {
+		t.Fatalf("expected the order to hold until it's re-sorted, got %v", got)
+	}
+
+	at = at.Add(popularityInterval)
+
+	expected := []string{"zlib", "mit", "apache_2", "bsd_3"}
+	if got := indexOrderOf(t, h); !slices.Equal(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestIndexOrderInvalid(t *testing.T) {
+	for _, opt := range []Option{WithIndexOrder("random"), WithIndexOrder(OrderPopular, "mit")} {
+		if _, err := New(opt); err == nil {
+			t.Errorf("expected an error")
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+)
+
+// maxPooledBuffer is the most a buffer can have grown to and still be reused,
+// so one huge page doesn't pin its memory for as long as the pool lives.
+const maxPooledBuffer = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer for rendering a page that's only needed
+// for one response. Give it back with putBuffer once it's been written.
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// gzipPool holds gzip writers, which each carry hundreds of kilobytes of
+// state, far more than they compress for a bundle of licenses. archive/zip
+// already reuses its own.
+var gzipPool sync.Pool
+
+// getGzipWriter returns a gzip writer to w. Close it, then give it back with
+// putGzipWriter.
+func getGzipWriter(w io.Writer) *gzip.Writer {
+	if gw, ok := gzipPool.Get().(*gzip.Writer); ok {
+		gw.Reset(w)
+		return gw
+	}
+
+	return gzip.NewWriter(w)
+}
+
+func putGzipWriter(gw *gzip.Writer) {
+	gzipPool.Put(gw)
+}
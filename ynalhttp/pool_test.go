@@ -0,0 +1,83 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestPutBuffer(t *testing.T) {
+	buf := getBuffer()
+	buf.WriteString("leftovers")
+	putBuffer(buf)
+
+	if buf.Len() != 0 {
+		t.Errorf("expected a returned buffer to be emptied, got %q", buf.String())
+	}
+
+	big := getBuffer()
+	big.Grow(maxPooledBuffer + 1)
+	big.WriteString("kept")
+	putBuffer(big)
+
+	if big.String() != "kept" {
+		t.Errorf("expected an oversized buffer to be dropped, not reset")
+	}
+}
+
+func TestPooledRendersConcurrently(t *testing.T) {
+	licenses := []ynal.LicenseData{
+		ynal.NewLicense("MIT", strings.Repeat("mit text\n\n", 100)),
+		ynal.NewLicense("Apache_2", strings.Repeat("apache text\n\n", 100)),
+	}
+
+	h, err := New(WithLicenses(licenses))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	paths := []string{
+		"/bundle?licenses=mit&format=tar.gz",
+		"/bundle?licenses=apache_2,mit&format=tar.gz",
+		"/search?q=mit",
+		"/nope",
+		"/header/mit?lang=go&holder=Alice",
+	}
+
+	get := func(path string) []byte {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept", "text/html")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w.Body.Bytes()
+	}
+
+	expected := map[string][]byte{}
+	for _, path := range paths {
+		expected[path] = get(path)
+	}
+
+	// every response comes out the same however the buffers and writers it
+	// was built with were used before
+	wg := sync.WaitGroup{}
+	for i := range 50 {
+		path := paths[i%len(paths)]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if got := get(path); !bytes.Equal(got, expected[path]) {
+				t.Errorf("%s: expected the same response every time", path)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,48 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/packrat386/ynal"
+)
+
+// printParam asks for a license page laid out for printing or saving as a
+// PDF, with just the text and none of the navigation around it.
+const printParam = "print"
+
+// htmlStyle is how a license page is laid out, beyond its variant.
+type htmlStyle struct {
+	layout string
+	print  bool
+}
+
+// htmlStyles are every style a license page is rendered in.
+func htmlStyles() []htmlStyle {
+	styles := []htmlStyle{}
+	for _, layout := range ynal.Layouts {
+		styles = append(styles, htmlStyle{layout: layout}, htmlStyle{layout: layout, print: true})
+	}
+
+	return styles
+}
+
+// requestedStyle returns the style r asks for. See requestedLayout.
+func requestedStyle(r *http.Request, l ynal.LicenseData) htmlStyle {
+	print, _ := strconv.ParseBool(r.URL.Query().Get(printParam))
+	return htmlStyle{layout: requestedLayout(r, l), print: print}
+}
+
+// template is the name of the template l's page is rendered with in s. The
+// print layout is the same for every license.
+func (s htmlStyle) template(l ynal.LicenseData) string {
+	if s.print {
+		return "print.html.tmpl"
+	}
+
+	if l.Template != "" {
+		return l.Template
+	}
+
+	return "license.html.tmpl"
+}
@@ -0,0 +1,59 @@
+package ynalhttp
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrint(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name     string
+		path     string
+		expected []string
+		missing  []string
+	}{
+		{
+			name:     "print",
+			path:     "/mit?print=1",
+			expected: []string{"<h1>MIT</h1>", "/print.", "<pre>Copyright &lt;YEAR&gt;"},
+			missing:  []string{`class="theme"`, "/styles.", "curl -s"},
+		},
+		{
+			name:     "print reflowed",
+			path:     "/apache_2?print=true&layout=reflow",
+			expected: []string{`<div class="reflowed">`, `<div class="section" id="section-3">`},
+			missing:  []string{`class="toc"`},
+		},
+		{
+			name:     "not print",
+			path:     "/mit?print=0",
+			expected: []string{`class="theme"`, `<a href="/mit?print=1">`},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/html")
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			for _, want := range tc.expected {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("expected the page to contain %q", want)
+				}
+			}
+
+			for _, unwanted := range tc.missing {
+				if strings.Contains(w.Body.String(), unwanted) {
+					t.Errorf("expected the page not to contain %q", unwanted)
+				}
+			}
+		})
+	}
+}
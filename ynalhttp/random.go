@@ -0,0 +1,53 @@
+package ynalhttp
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+// randomHandler redirects to a license picked at random, or to one with the
+// tag in the tag query parameter. Deprecated licenses are left out. Any other
+// query parameters are kept, so /random?width=72 works like the license page
+// does. It's a 302 that mustn't be cached, so every visit picks again.
+func randomHandler(licenses []ynal.LicenseData, tmpl *pageTemplates) http.Handler {
+	candidates := []ynal.LicenseData{}
+	for _, l := range licenses {
+		if !l.Deprecated {
+			candidates = append(candidates, l)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		pool := candidates
+		if tag := q.Get("tag"); tag != "" {
+			if !slices.Contains(ynal.Tags, tag) {
+				writeError(w, r, tmpl, http.StatusNotFound, fmt.Sprintf("no such tag: %s (try one of %s)", tag, strings.Join(ynal.Tags, ", ")))
+				return
+			}
+
+			pool = slices.DeleteFunc(slices.Clone(candidates), func(l ynal.LicenseData) bool { return !l.HasTag(tag) })
+		}
+
+		q.Del("tag")
+
+		if len(pool) == 0 {
+			writeError(w, r, tmpl, http.StatusNotFound, "no licenses to pick from")
+			return
+		}
+
+		target := pool[rand.IntN(len(pool))].URL
+		if len(q) > 0 {
+			target += "?" + q.Encode()
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, target, http.StatusFound)
+	})
+}
@@ -0,0 +1,74 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestRandom(t *testing.T) {
+	mit := ynal.NewLicense("MIT", "mit\n")
+	mit.Tags = []string{ynal.TagPermissive}
+
+	gpl := ynal.NewLicense("GPL_3", "gpl\n")
+	gpl.Tags = []string{ynal.TagCopyleft}
+
+	old := ynal.NewLicense("GPL_2", "gpl 2\n")
+	old.Tags = []string{ynal.TagCopyleft}
+	old.Deprecated = true
+
+	h, err := New(WithLicenses([]ynal.LicenseData{mit, gpl, old}), WithCacheControl("public, max-age=3600"))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		path     string
+		code     int
+		expected []string
+	}{
+		{name: "any", path: "/random", code: http.StatusFound, expected: []string{"/mit", "/gpl_3"}},
+		{name: "tag", path: "/random?tag=copyleft", code: http.StatusFound, expected: []string{"/gpl_3"}},
+		{name: "other parameters are kept", path: "/random?tag=permissive&width=72", code: http.StatusFound, expected: []string{"/mit?width=72"}},
+		{name: "no licenses with tag", path: "/random?tag=fonts", code: http.StatusNotFound},
+		{name: "unknown tag", path: "/random?tag=nope", code: http.StatusNotFound},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			seen := map[string]bool{}
+
+			for range 50 {
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+
+				if w.Code != tc.code {
+					t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+				}
+
+				if tc.code != http.StatusFound {
+					return
+				}
+
+				if got := w.Header().Get("Cache-Control"); got != "no-store" {
+					t.Errorf("expected Cache-Control no-store, got %q", got)
+				}
+
+				seen[w.Header().Get("Location")] = true
+			}
+
+			for _, want := range tc.expected {
+				if !seen[want] {
+					t.Errorf("expected to be sent to %s at least once, got %v", want, seen)
+				}
+			}
+
+			if len(seen) != len(tc.expected) {
+				t.Errorf("expected only %v, got %v", tc.expected, seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestRangeRequests(t *testing.T) {
+	mit, ok := ynal.FindLicense(mustEmbedded(t), "mit")
+	if !ok {
+		t.Fatalf("mit is missing from the catalog")
+	}
+
+	etag := `"` + mit.Digest.SHA256 + `"`
+
+	tt := []struct {
+		name     string
+		path     string
+		accept   string
+		headers  map[string]string
+		code     int
+		expected string
+	}{
+		{name: "whole", path: "/raw/mit", code: http.StatusOK, expected: mit.Text},
+		{name: "range", path: "/raw/mit", headers: map[string]string{"Range": "bytes=0-14"}, code: http.StatusPartialContent, expected: mit.Text[:15]},
+		{name: "suffix", path: "/raw/mit", headers: map[string]string{"Range": "bytes=-10"}, code: http.StatusPartialContent, expected: mit.Text[len(mit.Text)-10:]},
+		{name: "if-range matches", path: "/raw/mit", headers: map[string]string{"Range": "bytes=0-14", "If-Range": etag}, code: http.StatusPartialContent, expected: mit.Text[:15]},
+		{name: "if-range stale", path: "/raw/mit", headers: map[string]string{"Range": "bytes=0-14", "If-Range": `"stale"`}, code: http.StatusOK, expected: mit.Text},
+		{name: "unsatisfiable", path: "/raw/mit", headers: map[string]string{"Range": "bytes=100000-"}, code: http.StatusRequestedRangeNotSatisfiable},
+		{name: "not modified", path: "/raw/mit", headers: map[string]string{"If-None-Match": etag}, code: http.StatusNotModified},
+		{name: "download", path: "/download/mit", headers: map[string]string{"Range": "bytes=0-14"}, code: http.StatusPartialContent, expected: mit.Text[:15]},
+		{name: "negotiated", path: "/mit", accept: "text/plain", headers: map[string]string{"Range": "bytes=0-14"}, code: http.StatusPartialContent, expected: mit.Text[:15]},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d", tc.code, w.Code)
+			}
+
+			if tc.expected != "" && w.Body.String() != tc.expected {
+				t.Fatalf("expected body %q, got %q", tc.expected, w.Body.String())
+			}
+
+			if tc.code == http.StatusOK && w.Header().Get("Accept-Ranges") != "bytes" {
+				t.Errorf("expected Accept-Ranges: bytes, got %q", w.Header().Get("Accept-Ranges"))
+			}
+
+			if tc.code != http.StatusRequestedRangeNotSatisfiable && w.Header().Get("ETag") != etag {
+				t.Errorf("expected ETag %s, got %q", etag, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func mustEmbedded(t *testing.T) []ynal.LicenseData {
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	return licenses
+}
@@ -0,0 +1,80 @@
+package ynalhttp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// readyPath is the readiness check. Unlike the health check, which only says
+// the instance is alive, it says whether it should be sent traffic right now.
+const readyPath = "/readyz"
+
+// readyTimeout bounds how long the readiness checks get, all together.
+const readyTimeout = 5 * time.Second
+
+// WithReadinessCheck adds check to /readyz under name. The instance is only
+// ready while every check returns nil. The store, if it's a
+// ynal.HealthChecker, the rebuilding of pages when the licenses change, and
+// maintenance mode are always checked.
+func WithReadinessCheck(name string, check func(ctx context.Context) error) Option {
+	return func(c *config) {
+		c.checks = append(c.checks, readinessCheck{name: name, check: check})
+	}
+}
+
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// readinessResult is the outcome of one check, with why it failed in Detail.
+type readinessResult struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type readinessJSON struct {
+	Ready  bool              `json:"ready"`
+	Checks []readinessResult `json:"checks"`
+}
+
+// readyHandler runs every check at once and answers 200 if they all pass, or
+// 503 if any don't, with the result of each.
+func readyHandler(checks []readinessCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		defer cancel()
+
+		results := make([]readinessResult, len(checks))
+		wg := sync.WaitGroup{}
+
+		for i, c := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				results[i] = readinessResult{Name: c.name, OK: true}
+				if err := c.check(ctx); err != nil {
+					results[i] = readinessResult{Name: c.name, Detail: err.Error()}
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		ready := !slices.ContainsFunc(results, func(res readinessResult) bool { return !res.OK })
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		json.NewEncoder(w).Encode(readinessJSON{Ready: ready, Checks: results})
+	})
+}
@@ -0,0 +1,150 @@
+package ynalhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+// unhealthyStore is a store that's down.
+type unhealthyStore struct {
+	*ynal.Catalog
+}
+
+func (unhealthyStore) Health(ctx context.Context) error {
+	return errors.New("bucket unreachable")
+}
+
+func TestReady(t *testing.T) {
+	down := NewMaintenance(time.Minute)
+	down.Set(true)
+
+	licenses := []ynal.LicenseData{ynal.NewLicense("MIT", "mit text\n")}
+
+	tt := []struct {
+		name     string
+		opts     []Option
+		expected int
+		checks   []readinessResult
+	}{
+		{
+			name:     "ready",
+			opts:     []Option{WithLicenses(licenses)},
+			expected: http.StatusOK,
+			checks:   []readinessResult{{Name: "renderer", OK: true}},
+		},
+		{
+			name:     "store down",
+			opts:     []Option{WithStore(unhealthyStore{ynal.NewCatalog(licenses)})},
+			expected: http.StatusServiceUnavailable,
+			checks:   []readinessResult{{Name: "store", Detail: "bucket unreachable"}, {Name: "renderer", OK: true}},
+		},
+		{
+			name:     "maintenance",
+			opts:     []Option{WithLicenses(licenses), WithMaintenance(down)},
+			expected: http.StatusServiceUnavailable,
+			checks:   []readinessResult{{Name: "renderer", OK: true}, {Name: "maintenance", Detail: "down for maintenance"}},
+		},
+		{
+			name: "extra checks",
+			opts: []Option{
+				WithLicenses(licenses),
+				WithReadinessCheck("upstream", func(ctx context.Context) error { return nil }),
+				WithReadinessCheck("queue", func(ctx context.Context) error { return errors.New("backed up") }),
+			},
+			expected: http.StatusServiceUnavailable,
+			checks:   []readinessResult{{Name: "renderer", OK: true}, {Name: "upstream", OK: true}, {Name: "queue", Detail: "backed up"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("could not initialize app handler: %s", err)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+			if w.Code != tc.expected {
+				t.Fatalf("expected %d, got %d: %s", tc.expected, w.Code, w.Body.String())
+			}
+
+			var got readinessJSON
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("could not decode readiness: %s", err)
+			}
+
+			if got.Ready != (tc.expected == http.StatusOK) {
+				t.Errorf("expected ready to match the status, got %t", got.Ready)
+			}
+
+			if len(got.Checks) != len(tc.checks) {
+				t.Fatalf("expected %+v, got %+v", tc.checks, got.Checks)
+			}
+
+			for i := range tc.checks {
+				if got.Checks[i] != tc.checks[i] {
+					t.Errorf("expected %+v, got %+v", tc.checks[i], got.Checks[i])
+				}
+			}
+
+			// liveness doesn't depend on any of it
+			w = httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected /healthz to stay up, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestReadyWhileRebuilding(t *testing.T) {
+	catalog := ynal.NewCatalog([]ynal.LicenseData{ynal.NewLicense("MIT", "mit text\n")})
+
+	release := make(chan struct{})
+	building := make(chan struct{})
+
+	rh, err := newReloadingHandler(catalog, nil, func(licenses []ynal.LicenseData) (http.Handler, error) {
+		if len(licenses) > 1 {
+			close(building)
+			<-release
+		}
+
+		return http.NotFoundHandler(), nil
+	})
+	if err != nil {
+		t.Fatalf("could not build handler: %s", err)
+	}
+
+	if err := rh.health(context.Background()); err != nil {
+		t.Fatalf("expected a built handler to be healthy, got: %s", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		catalog.Replace(append(catalog.List(), ynal.NewLicense("Zlib", "zlib text\n")))
+		close(done)
+	}()
+
+	<-building
+
+	if err := rh.health(context.Background()); err == nil {
+		t.Errorf("expected a rebuilding handler to be unhealthy")
+	}
+
+	close(release)
+	<-done
+
+	if err := rh.health(context.Background()); err != nil {
+		t.Errorf("expected a rebuilt handler to be healthy, got: %s", err)
+	}
+}
@@ -0,0 +1,16 @@
+package ynalhttp
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/packrat386/ynal/middleware"
+)
+
+// withRecovery turns a panicking handler into a logged stack trace and a 500
+// in whatever format the client asked for.
+func withRecovery(logger *log.Logger, tmpl *pageTemplates, next http.Handler) http.Handler {
+	return middleware.Recovery(next, middleware.WithLogger(logger), middleware.WithPanicHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, tmpl, http.StatusInternalServerError, "internal server error")
+	})))
+}
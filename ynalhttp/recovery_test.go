@@ -0,0 +1,66 @@
+package ynalhttp
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecovery(t *testing.T) {
+	tmpl := mustTemplates(t)
+
+	h := withRecovery(log.Default(), tmpl, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("oh no")
+	}))
+
+	tt := []struct {
+		name        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "plain text",
+			accept:      "text/plain",
+			contentType: "text/plain; charset=utf-8",
+			body:        "internal server error\n",
+		},
+		{
+			name:        "json",
+			accept:      "application/json",
+			contentType: "application/problem+json",
+			body:        `"status":500,"detail":"internal server error"`,
+		},
+		{
+			name:        "html",
+			accept:      "text/html",
+			contentType: "text/html",
+			body:        "<h2>500 Internal Server Error</h2>",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/mit", nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("expected 500, got %d", w.Code)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Fatalf("expected content type %q, got %q", tc.contentType, got)
+			}
+
+			if !strings.Contains(w.Body.String(), tc.body) {
+				t.Fatalf("expected body to contain %q, got %q", tc.body, w.Body.String())
+			}
+		})
+	}
+}
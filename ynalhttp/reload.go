@@ -0,0 +1,58 @@
+package ynalhttp
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/packrat386/ynal"
+)
+
+// reloadingHandler serves whatever handler was last built from the store.
+// Everything is prerendered, so a change means building a fresh handler and
+// swapping it in; requests already in flight finish on the old one.
+type reloadingHandler struct {
+	current    atomic.Pointer[http.Handler]
+	rebuilding atomic.Bool
+}
+
+func newReloadingHandler(store ynal.LicenseStore, logger *log.Logger, build func([]ynal.LicenseData) (http.Handler, error)) (*reloadingHandler, error) {
+	rh := &reloadingHandler{}
+
+	h, err := build(store.List())
+	if err != nil {
+		return nil, err
+	}
+	rh.current.Store(&h)
+
+	store.Watch(func(licenses []ynal.LicenseData) {
+		rh.rebuilding.Store(true)
+		defer rh.rebuilding.Store(false)
+
+		h, err := build(licenses)
+		if err != nil {
+			logger.Printf("could not rebuild handler, still serving the previous licenses: %s", err)
+			return
+		}
+
+		rh.current.Store(&h)
+	})
+
+	return rh, nil
+}
+
+func (rh *reloadingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*rh.current.Load()).ServeHTTP(w, r)
+}
+
+// health says whether the handler is being rebuilt, while it's still serving
+// the previous licenses.
+func (rh *reloadingHandler) health(ctx context.Context) error {
+	if rh.rebuilding.Load() {
+		return errors.New("rebuilding for changed licenses")
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+// renderer is one media type a license page can be negotiated as. Each
+// license is rendered up front by prepare, and the handler it returns serves
+// that license in the renderer's media type from then on.
+type renderer interface {
+	// mediaTypes are the media types the renderer is negotiated by, first
+	// the one it's known by and then any others clients ask for it with.
+	mediaTypes() []string
+
+	prepare(page licensePage, tmpl *pageTemplates) (http.Handler, error)
+}
+
+// licenseRenderers are every way a license page can be negotiated. Adding a
+// format is adding a renderer here.
+var licenseRenderers = []renderer{
+	textRenderer{},
+	htmlRenderer{},
+	jsonRenderer{},
+	markupRenderer{
+		types:          []string{"text/x-rst", "text/prs.fallenstein.rst"},
+		representation: "rst",
+		render:         toRST,
+	},
+	markupRenderer{
+		types:          []string{"text/asciidoc", "text/x-asciidoc"},
+		representation: "asciidoc",
+		render:         toAsciiDoc,
+	},
+}
+
+// licenseMediaTypes maps every media type in licenseRenderers to the one its
+// renderer is known by, for the license negotiator.
+var licenseMediaTypes = mediaTypesOf(licenseRenderers)
+
+func mediaTypesOf(renderers []renderer) map[string]string {
+	types := map[string]string{}
+	for _, rr := range renderers {
+		for _, t := range rr.mediaTypes() {
+			types[t] = rr.mediaTypes()[0]
+		}
+	}
+
+	return types
+}
+
+// prepareRenderers renders page with every renderer, keyed by the media type
+// each is known by.
+func prepareRenderers(renderers []renderer, page licensePage, tmpl *pageTemplates) (map[string]http.Handler, error) {
+	handlers := map[string]http.Handler{}
+	for _, rr := range renderers {
+		h, err := rr.prepare(page, tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("could not render %s: %w", rr.mediaTypes()[0], err)
+		}
+
+		handlers[rr.mediaTypes()[0]] = h
+	}
+
+	return handlers, nil
+}
+
+// textRenderer serves the license as written, or laid out as asked for with
+// the parameters parseTextFormat reads.
+type textRenderer struct{}
+
+func (textRenderer) mediaTypes() []string {
+	return []string{"text/plain"}
+}
+
+func (textRenderer) prepare(page licensePage, tmpl *pageTemplates) (http.Handler, error) {
+	l := page.LicenseData
+	plain := newPlainText(l, tmpl.cache)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, err := parseTextFormat(r.URL.Query())
+		if err != nil {
+			writeError(w, r, tmpl, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		countHit(r, l.ID, "text")
+
+		p := plain.page(f)
+		signBody(w, r, p.body)
+
+		w.Header().Set("Content-Type", "text/plain")
+		p.serve(w, r, "LICENSE")
+	}), nil
+}
+
+// htmlRenderer serves the license page in every style, theme, and language,
+// rendered up front as far as the render budget goes and on demand past it.
+type htmlRenderer struct{}
+
+func (htmlRenderer) mediaTypes() []string {
+	return []string{"text/html", "application/xhtml+xml"}
+}
+
+func (htmlRenderer) prepare(page licensePage, tmpl *pageTemplates) (http.Handler, error) {
+	l := page.LicenseData
+	page.Preamble, page.Sections = ynal.Sections(l.Text)
+
+	if l.Template != "" && !tmpl.has(l.Template) {
+		return nil, fmt.Errorf("%s: unknown template %q", l.ID, l.Template)
+	}
+
+	render := func(style htmlStyle, v variant) func() (renderedPage, error) {
+		return func() (renderedPage, error) {
+			page := page
+			page.Reflow = style.layout == ynal.LayoutReflow
+
+			buf := new(bytes.Buffer)
+			if err := tmpl.ExecuteTemplate(buf, v, style.template(l), page); err != nil {
+				return renderedPage{}, fmt.Errorf("could not render html template: %w", err)
+			}
+
+			return newRenderedPage(buf.Bytes()), nil
+		}
+	}
+
+	key := func(style htmlStyle, v variant) string {
+		return fmt.Sprintf("%s|html|%s|%t|%s|%s", l.URL, style.layout, style.print, v.theme, v.lang)
+	}
+
+	for _, v := range tmpl.variants() {
+		for _, style := range htmlStyles() {
+			if err := tmpl.cache.warm(key(style, v), render(style, v)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countHit(r, l.ID, "html")
+		v := tmpl.variant(r)
+		style := requestedStyle(r, l)
+
+		p, err := tmpl.cache.get(key(style, v), render(style, v))
+		if err != nil {
+			writeError(w, r, tmpl, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		setVariantHeaders(w, v)
+		w.Write(p.body)
+	}), nil
+}
+
+// jsonRenderer serves the license's data as JSON.
+type jsonRenderer struct{}
+
+func (jsonRenderer) mediaTypes() []string {
+	return []string{"application/json"}
+}
+
+func (jsonRenderer) prepare(page licensePage, tmpl *pageTemplates) (http.Handler, error) {
+	l := page.LicenseData
+
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal JSON: %w", err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countHit(r, l.ID, "json")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	}), nil
+}
+
+// markupRenderer serves the license as a document in some markup, rendered
+// by render.
+type markupRenderer struct {
+	types          []string
+	representation string
+	render         func(l ynal.LicenseData) []byte
+}
+
+func (m markupRenderer) mediaTypes() []string {
+	return m.types
+}
+
+func (m markupRenderer) prepare(page licensePage, tmpl *pageTemplates) (http.Handler, error) {
+	l := page.LicenseData
+	b := m.render(l)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countHit(r, l.ID, m.representation)
+		w.Header().Set("Content-Type", m.types[0]+"; charset=utf-8")
+		w.Write(b)
+	}), nil
+}
@@ -0,0 +1,51 @@
+package ynalhttp
+
+import (
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLicenseRenderers(t *testing.T) {
+	h := mustAppHandler(t)
+
+	for _, rr := range licenseRenderers {
+		for _, accept := range rr.mediaTypes() {
+			t.Run(accept, func(t *testing.T) {
+				r := httptest.NewRequest("GET", "/mit", nil)
+				r.Header.Set("Accept", accept)
+
+				w := httptest.NewRecorder()
+
+				h.ServeHTTP(w, r)
+
+				if w.Code != http.StatusOK {
+					t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+				}
+
+				got, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+				if err != nil {
+					t.Fatalf("could not parse Content-Type: %s", err)
+				}
+
+				if want := rr.mediaTypes()[0]; got != want {
+					t.Errorf("expected %s, got %s", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestMediaTypesUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, rr := range licenseRenderers {
+		for _, mediatype := range rr.mediaTypes() {
+			if seen[mediatype] {
+				t.Errorf("%s is negotiated by more than one renderer", mediatype)
+			}
+
+			seen[mediatype] = true
+		}
+	}
+}
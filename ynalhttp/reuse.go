@@ -0,0 +1,105 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/reuse"
+)
+
+// maxReuseSize bounds files and archives uploaded to be checked.
+const maxReuseSize = 64 << 20
+
+type reuseReport struct {
+	File      string       `json:"file"`
+	Compliant bool         `json:"compliant"`
+	Summary   reuseSummary `json:"summary"`
+	Files     []reuseFile  `json:"files"`
+}
+
+type reuseSummary struct {
+	Files     int `json:"files"`
+	Compliant int `json:"compliant"`
+
+	// Missing is how many files have no header at all, and Invalid how many
+	// have one that doesn't parse or names something not in the catalog.
+	Missing int `json:"missing"`
+	Invalid int `json:"invalid"`
+}
+
+type reuseFile struct {
+	Path        string           `json:"path"`
+	Compliant   bool             `json:"compliant"`
+	Identifiers []spdxValidation `json:"identifiers"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// reuseHandler checks that an uploaded source file, or every file in an
+// uploaded archive, has an SPDX-License-Identifier header naming licenses and
+// exceptions in the catalog. A file that doesn't is still a 200, with
+// compliant set to false.
+func reuseHandler(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, base string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, b, err := readUpload(r, "file")
+		if err != nil {
+			writeProblem(w, newProblem(r, bodyStatus(err), err.Error()))
+			return
+		}
+
+		files, err := reuse.Read(name, b)
+		if err != nil {
+			writeProblem(w, newProblem(r, http.StatusUnprocessableEntity, err.Error()))
+			return
+		}
+
+		report := reuseReport{File: name, Files: []reuseFile{}}
+
+		for _, f := range files {
+			rf := checkHeaders(licenses, exceptions, base, f)
+
+			report.Summary.Files++
+			switch {
+			case rf.Compliant:
+				report.Summary.Compliant++
+			case len(rf.Identifiers) == 0:
+				report.Summary.Missing++
+			default:
+				report.Summary.Invalid++
+			}
+
+			report.Files = append(report.Files, rf)
+		}
+
+		report.Compliant = report.Summary.Compliant == report.Summary.Files
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// checkHeaders validates every header in f against the catalog.
+func checkHeaders(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, base string, f reuse.File) reuseFile {
+	rf := reuseFile{Path: f.Path, Identifiers: []spdxValidation{}}
+
+	if len(f.Identifiers) == 0 {
+		rf.Error = "no SPDX-License-Identifier header"
+		return rf
+	}
+
+	errs := []string{}
+	for _, id := range f.Identifiers {
+		v := validateExpression(licenses, exceptions, base, id)
+		if !v.Valid {
+			errs = append(errs, id+": "+v.Error)
+		}
+
+		rf.Identifiers = append(rf.Identifiers, v)
+	}
+
+	rf.Compliant = len(errs) == 0
+	rf.Error = strings.Join(errs, "; ")
+
+	return rf
+}
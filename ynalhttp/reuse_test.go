@@ -0,0 +1,130 @@
+package ynalhttp
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReuse(t *testing.T) {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	for name, content := range map[string]string{
+		"main.go":          "// SPDX-License-Identifier: MIT\npackage main\n",
+		"lib.go":           "// SPDX-License-Identifier: GPL-3.0-or-later WITH Classpath-exception-2.0\npackage main\n",
+		"vendor/odd.go":    "// SPDX-License-Identifier: LicenseRef-Odd\npackage odd\n",
+		"README.md":        "# widget\n",
+		"LICENSES/MIT.txt": "MIT License\n",
+	} {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+
+	zw.Close()
+
+	h := mustAppHandler(t)
+
+	contentType, body := multipartUpload(t, "file", "widget.zip", buf.String())
+
+	r := httptest.NewRequest("POST", "/api/v1/reuse", body)
+	r.Header.Set("Content-Type", contentType)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	report := reuseReport{}
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	expected := reuseSummary{Files: 4, Compliant: 2, Missing: 1, Invalid: 1}
+	if report.File != "widget.zip" || report.Compliant || report.Summary != expected {
+		t.Fatalf("expected summary %+v, got %+v", expected, report)
+	}
+
+	byPath := map[string]reuseFile{}
+	for _, f := range report.Files {
+		byPath[f.Path] = f
+	}
+
+	if f := byPath["main.go"]; !f.Compliant || f.Identifiers[0].Licenses[0].URL != "/mit" {
+		t.Errorf("expected main.go to link to MIT, got %+v", f)
+	}
+
+	if f := byPath["lib.go"]; !f.Compliant || len(f.Identifiers[0].Exceptions) != 1 {
+		t.Errorf("expected lib.go to link its exception, got %+v", f)
+	}
+
+	if f := byPath["vendor/odd.go"]; f.Compliant || !strings.Contains(f.Error, "LicenseRef-Odd") {
+		t.Errorf("expected vendor/odd.go to name what's not in the catalog, got %+v", f)
+	}
+
+	if f := byPath["README.md"]; f.Compliant || f.Error != "no SPDX-License-Identifier header" {
+		t.Errorf("expected README.md to be missing a header, got %+v", f)
+	}
+}
+
+func TestReuseRequests(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name     string
+		path     string
+		body     string
+		code     int
+		expected string
+	}{
+		{
+			name:     "single file",
+			path:     "/api/v1/reuse?filename=main.go",
+			body:     "// SPDX-License-Identifier: Apache-2.0\npackage main\n",
+			code:     http.StatusOK,
+			expected: `"compliant":true`,
+		},
+		{
+			name:     "missing",
+			path:     "/api/v1/reuse?filename=main.go",
+			body:     "package main\n",
+			code:     http.StatusOK,
+			expected: `"compliant":false`,
+		},
+		{
+			name: "no name",
+			path: "/api/v1/reuse",
+			body: "package main\n",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "corrupt archive",
+			path: "/api/v1/reuse?filename=widget.tar.gz",
+			body: "not gzip",
+			code: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Errorf("expected body to contain %s, got %s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/manifest"
+	"github.com/packrat386/ynal/sbom"
+)
+
+// maxSBOMSize bounds SBOM requests, which list every component of a project.
+const maxSBOMSize = 16 << 20
+
+type sbomRequest struct {
+	Name       string                `json:"name"`
+	Version    string                `json:"version"`
+	Components []manifest.Dependency `json:"components"`
+
+	// Dependencies is what /api/v1/scan returns, so a scan can be posted as
+	// is, with the project's name in the query string.
+	Dependencies []manifest.Dependency `json:"dependencies"`
+}
+
+// sbomHandler generates an SBOM for the project described by a JSON
+// sbomRequest, as an SPDX document or, with format=cyclonedx, a CycloneDX
+// one. Component licenses are matched against licenses.
+func sbomHandler(licenses []ynal.LicenseData) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = sbom.FormatSPDX
+		}
+
+		if !slices.Contains(sbom.Formats, format) {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, fmt.Sprintf("unknown SBOM format: %s (try one of %s)", format, strings.Join(sbom.Formats, ", "))))
+			return
+		}
+
+		var req sbomRequest
+
+		// unknown fields are allowed so scans can be posted with everything
+		// else they return
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeProblem(w, newProblem(r, bodyStatus(err), fmt.Sprintf("could not parse request: %s", err)))
+			return
+		}
+
+		if req.Name == "" {
+			req.Name = r.URL.Query().Get("name")
+		}
+
+		if req.Name == "" {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, "no project name, set name in the body or ?name="))
+			return
+		}
+
+		doc := sbom.Document{
+			Name:       req.Name,
+			Version:    req.Version,
+			Components: slices.Concat(req.Components, req.Dependencies),
+			Licenses:   licenses,
+			Created:    now(r),
+		}
+
+		buf := getBuffer()
+		defer putBuffer(buf)
+
+		if err := doc.Write(buf, format); err != nil {
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, fmt.Sprintf("could not write SBOM: %s", err)))
+			return
+		}
+
+		w.Header().Set("Content-Type", sbom.MediaType(format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, sbom.Filename(req.Name, format)))
+		w.Write(buf.Bytes())
+	})
+}
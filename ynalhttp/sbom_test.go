@@ -0,0 +1,99 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSBOM(t *testing.T) {
+	tt := []struct {
+		name        string
+		query       string
+		body        string
+		code        int
+		contentType string
+		filename    string
+	}{
+		{
+			name:        "spdx",
+			body:        `{"name": "widget", "version": "1.0.0", "components": [{"ecosystem": "go", "name": "example.com/dep", "version": "v1.2.3", "license": "MIT"}]}`,
+			code:        http.StatusOK,
+			contentType: "application/spdx+json",
+			filename:    "widget.spdx.json",
+		},
+		{
+			name:        "cyclonedx",
+			query:       "?format=cyclonedx",
+			body:        `{"name": "widget", "components": [{"ecosystem": "npm", "name": "left-pad", "version": "1.3.0", "license": "WTFPL"}]}`,
+			code:        http.StatusOK,
+			contentType: "application/vnd.cyclonedx+json",
+			filename:    "widget.cdx.json",
+		},
+		{
+			name:        "scan output",
+			query:       "?name=widget",
+			body:        `{"manifest": "go.mod", "dependencies": [{"ecosystem": "go", "name": "example.com/dep", "version": "v1.2.3", "license": "MIT", "licenses": [], "unknown": []}]}`,
+			code:        http.StatusOK,
+			contentType: "application/spdx+json",
+			filename:    "widget.spdx.json",
+		},
+		{
+			name: "no name",
+			body: `{"components": []}`,
+			code: http.StatusBadRequest,
+		},
+		{
+			name:  "unknown format",
+			query: "?format=swid",
+			body:  `{"name": "widget"}`,
+			code:  http.StatusBadRequest,
+		},
+		{
+			name: "malformed",
+			body: `["widget"]`,
+			code: http.StatusBadRequest,
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/sbom"+tc.query, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if tc.code != http.StatusOK {
+				if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+					t.Fatalf("expected a problem, got %q", got)
+				}
+
+				return
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Errorf("expected content type %q, got %q", tc.contentType, got)
+			}
+
+			if got := w.Header().Get("Content-Disposition"); !strings.Contains(got, tc.filename) {
+				t.Errorf("expected to be saved as %s, got %q", tc.filename, got)
+			}
+
+			if !json.Valid(w.Body.Bytes()) {
+				t.Errorf("expected a JSON document, got %s", w.Body.String())
+			}
+
+			if !strings.Contains(w.Body.String(), "MIT") && !strings.Contains(w.Body.String(), "WTFPL") {
+				t.Errorf("expected the component's license in the document, got %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/manifest"
+	"github.com/packrat386/ynal/spdx"
+)
+
+// maxManifestSize bounds scanned manifests. Lockfiles for big projects run to
+// a few megabytes.
+const maxManifestSize = 16 << 20
+
+// WithResolver looks up the licenses of scanned dependencies whose manifests
+// don't declare them, like everything in a go.mod, with r. Without one, only
+// declared licenses are reported.
+func WithResolver(r manifest.Resolver) Option {
+	return func(c *config) {
+		c.resolver = r
+	}
+}
+
+type scanResponse struct {
+	Manifest     string           `json:"manifest"`
+	Dependencies []scanDependency `json:"dependencies"`
+}
+
+type scanDependency struct {
+	manifest.Result
+
+	// Licenses are the licenses in the catalog the dependency's license
+	// names, and Unknown the ones that aren't in it.
+	Licenses []spdxReference `json:"licenses"`
+	Unknown  []string        `json:"unknown,omitempty"`
+}
+
+// readUpload returns the name and contents of the file uploaded in r, either
+// as field of a multipart form or as the whole body with its name in the
+// filename query parameter.
+func readUpload(r *http.Request, field string) (string, []byte, error) {
+	mediatype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediatype != "multipart/form-data" {
+		name := r.URL.Query().Get("filename")
+		if name == "" {
+			return "", nil, fmt.Errorf("no %s name, upload it as a form field named %s or set ?filename=", field, field)
+		}
+
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return "", nil, fmt.Errorf("could not read %s: %w", field, err)
+		}
+
+		return name, b, nil
+	}
+
+	f, header, err := r.FormFile(field)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not read %s: %w", field, err)
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not read %s: %w", field, err)
+	}
+
+	return header.Filename, b, nil
+}
+
+// scanHandler lists the dependencies in an uploaded manifest, along with the
+// license each is declared under and links to the ones in the catalog.
+// Dependencies whose licenses can't be worked out are still listed, with an
+// error saying why.
+func scanHandler(store ynal.LicenseStore, resolver manifest.Resolver, base string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, b, err := readUpload(r, "manifest")
+		if err != nil {
+			writeProblem(w, newProblem(r, bodyStatus(err), err.Error()))
+			return
+		}
+
+		deps, err := manifest.Parse(name, b)
+		if errors.Is(err, manifest.ErrUnsupported) {
+			writeProblem(w, newProblem(r, http.StatusUnsupportedMediaType, err.Error()))
+			return
+		} else if err != nil {
+			writeProblem(w, newProblem(r, http.StatusUnprocessableEntity, err.Error()))
+			return
+		}
+
+		licenses := withBase(store.List(), base)
+		resp := scanResponse{Manifest: name, Dependencies: []scanDependency{}}
+
+		for _, res := range manifest.Resolve(r.Context(), deps, resolver) {
+			resp.Dependencies = append(resp.Dependencies, linkDependency(licenses, base, res))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// linkDependency links the licenses res's license names to the catalog.
+func linkDependency(licenses []ynal.LicenseData, base string, res manifest.Result) scanDependency {
+	d := scanDependency{Result: res, Licenses: []spdxReference{}}
+	if res.License == "" {
+		return d
+	}
+
+	e, err := spdx.Parse(res.License)
+	if err != nil {
+		d.Error = fmt.Sprintf("could not parse license: %s", err)
+		return d
+	}
+
+	found, unknown := e.Resolve(licenses)
+	for _, l := range found {
+		d.Licenses = append(d.Licenses, spdxReference{
+			SPDX:  l.SPDXID(),
+			ID:    l.ID,
+			Title: l.Title,
+			URL:   l.URL,
+			Href:  apiHref(base, l),
+		})
+	}
+
+	d.Unknown = unknown
+
+	return d
+}
@@ -0,0 +1,154 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal/manifest"
+)
+
+type fakeResolver map[string]string
+
+func (f fakeResolver) Resolve(ctx context.Context, d manifest.Dependency) (string, error) {
+	license, ok := f[d.Name]
+	if !ok {
+		return "", errors.New("not found")
+	}
+
+	return license, nil
+}
+
+func multipartUpload(t *testing.T, field string, name string, data string) (string, *bytes.Buffer) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+
+	fw, err := mw.CreateFormFile(field, name)
+	if err != nil {
+		t.Fatalf("could not create form: %s", err)
+	}
+
+	fw.Write([]byte(data))
+	mw.Close()
+
+	return mw.FormDataContentType(), body
+}
+
+func TestScan(t *testing.T) {
+	h, err := New(WithResolver(fakeResolver{
+		"github.com/BurntSushi/toml": "MIT",
+		"example.com/dual":           "MIT OR Apache-2.0",
+		"example.com/odd":            "LicenseRef-Odd",
+	}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	gomod := "module example.com/widget\n\nrequire (\n\tgithub.com/BurntSushi/toml v1.6.0\n\texample.com/dual v1.0.0\n\texample.com/odd v1.0.0\n\texample.com/gone v1.0.0\n)\n"
+
+	contentType, body := multipartUpload(t, "manifest", "go.mod", gomod)
+
+	r := httptest.NewRequest("POST", "/api/v1/scan", body)
+	r.Header.Set("Content-Type", contentType)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	resp := scanResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	if resp.Manifest != "go.mod" || len(resp.Dependencies) != 4 {
+		t.Fatalf("expected four dependencies from go.mod, got %+v", resp)
+	}
+
+	toml := resp.Dependencies[0]
+	if toml.License != "MIT" || len(toml.Licenses) != 1 || toml.Licenses[0].URL != "/mit" || toml.Licenses[0].Href != "/api/v1/licenses/mit" {
+		t.Errorf("expected toml to link to MIT, got %+v", toml)
+	}
+
+	if dual := resp.Dependencies[1]; len(dual.Licenses) != 2 {
+		t.Errorf("expected both licenses of a dual license to be linked, got %+v", dual)
+	}
+
+	if odd := resp.Dependencies[2]; len(odd.Licenses) != 0 || len(odd.Unknown) != 1 {
+		t.Errorf("expected a license outside the catalog to be unknown, got %+v", odd)
+	}
+
+	if gone := resp.Dependencies[3]; gone.License != "" || gone.Error != "not found" {
+		t.Errorf("expected an unresolved dependency to say why, got %+v", gone)
+	}
+}
+
+func TestScanRequests(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name     string
+		path     string
+		body     string
+		code     int
+		expected string
+	}{
+		{
+			name:     "declared licenses need no registry",
+			path:     "/api/v1/scan?filename=package-lock.json",
+			body:     `{"packages": {"node_modules/left-pad": {"version": "1.3.0", "license": "MIT"}}}`,
+			code:     http.StatusOK,
+			expected: `"url":"/mit"`,
+		},
+		{
+			name:     "no registry",
+			path:     "/api/v1/scan?filename=requirements.txt",
+			body:     "django==5.0.1\n",
+			code:     http.StatusOK,
+			expected: `"error":"no registry to look up licenses in"`,
+		},
+		{
+			name: "no name",
+			path: "/api/v1/scan",
+			body: "django==5.0.1\n",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "unsupported",
+			path: "/api/v1/scan?filename=Gemfile.lock",
+			body: "GEM\n",
+			code: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "malformed",
+			path: "/api/v1/scan?filename=package-lock.json",
+			body: "{",
+			code: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tc.path, strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
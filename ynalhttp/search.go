@@ -0,0 +1,358 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/packrat386/ynal"
+)
+
+// snippetContext is roughly how much text to show on either side of the first
+// match in a search result.
+const snippetContext = 80
+
+// metadataBoost is how much a match in a license's title or ID is worth
+// compared to a single match in its text.
+const metadataBoost = 10
+
+// searchIndex is an inverted index over the license catalog, built once at
+// startup. Query terms match any indexed token they are a prefix of, so
+// "patent" finds "patents" too.
+type searchIndex struct {
+	licenses []ynal.LicenseData
+
+	// text maps a token to the byte offsets it occurs at in each license's
+	// text, keyed by position in licenses
+	text map[string]map[int][]int
+
+	// metadata maps a token to the licenses whose title or ID contain it
+	metadata map[string]map[int]bool
+
+	// tokens is every indexed token, sorted, for prefix lookups
+	tokens []string
+}
+
+type snippetSegment struct {
+	Text  string
+	Match bool
+}
+
+type searchResult struct {
+	ID         string           `json:"id"`
+	Title      string           `json:"title"`
+	URL        string           `json:"url"`
+	Score      int              `json:"score"`
+	Snippet    string           `json:"snippet"`
+	Highlights [][2]int         `json:"highlights"`
+	Segments   []snippetSegment `json:"-"`
+}
+
+type searchResults struct {
+	Query   string         `json:"query"`
+	Results []searchResult `json:"results"`
+}
+
+// token is a word lowercased, and where it is in the original text. Its end is
+// kept too, since lowercasing can change how many bytes it takes.
+type token struct {
+	text   string
+	offset int
+	end    int
+}
+
+// tokenize splits s into lowercased runs of letters and digits.
+func tokenize(s string) []token {
+	tokens := []token{}
+	start := -1
+
+	for i, r := range s {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+
+		if word && start < 0 {
+			start = i
+		} else if !word && start >= 0 {
+			tokens = append(tokens, token{strings.ToLower(s[start:i]), start, i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, token{strings.ToLower(s[start:]), start, len(s)})
+	}
+
+	return tokens
+}
+
+func newSearchIndex(licenses []ynal.LicenseData) *searchIndex {
+	idx := &searchIndex{
+		licenses: licenses,
+		text:     map[string]map[int][]int{},
+		metadata: map[string]map[int]bool{},
+	}
+
+	seen := map[string]bool{}
+
+	for doc, l := range licenses {
+		for _, t := range tokenize(l.Text) {
+			if idx.text[t.text] == nil {
+				idx.text[t.text] = map[int][]int{}
+			}
+			idx.text[t.text][doc] = append(idx.text[t.text][doc], t.offset)
+			seen[t.text] = true
+		}
+
+		for _, t := range tokenize(l.Title + " " + l.ID) {
+			if idx.metadata[t.text] == nil {
+				idx.metadata[t.text] = map[int]bool{}
+			}
+			idx.metadata[t.text][doc] = true
+			seen[t.text] = true
+		}
+	}
+
+	for t := range seen {
+		idx.tokens = append(idx.tokens, t)
+	}
+	slices.Sort(idx.tokens)
+
+	return idx
+}
+
+// expand returns every indexed token that starts with term.
+func (idx *searchIndex) expand(term string) []string {
+	i, _ := slices.BinarySearch(idx.tokens, term)
+
+	matches := []string{}
+	for ; i < len(idx.tokens) && strings.HasPrefix(idx.tokens[i], term); i++ {
+		matches = append(matches, idx.tokens[i])
+	}
+
+	return matches
+}
+
+// search returns the licenses matching every term in q, best first.
+func (idx *searchIndex) search(q string) []searchResult {
+	terms := []string{}
+	for _, t := range tokenize(q) {
+		terms = append(terms, t.text)
+	}
+
+	if len(terms) == 0 {
+		return []searchResult{}
+	}
+
+	scores := map[int]int{}
+	first := map[int]int{}
+
+	for i, term := range terms {
+		termScores := map[int]int{}
+
+		for _, tok := range idx.expand(term) {
+			for doc, offsets := range idx.text[tok] {
+				termScores[doc] += len(offsets)
+
+				if off, ok := first[doc]; !ok || offsets[0] < off {
+					first[doc] = offsets[0]
+				}
+			}
+
+			for doc := range idx.metadata[tok] {
+				termScores[doc] += metadataBoost
+			}
+		}
+
+		// every term has to match
+		if i == 0 {
+			scores = termScores
+			continue
+		}
+
+		for doc := range scores {
+			if _, ok := termScores[doc]; !ok {
+				delete(scores, doc)
+			} else {
+				scores[doc] += termScores[doc]
+			}
+		}
+	}
+
+	results := []searchResult{}
+
+	for doc, score := range scores {
+		l := idx.licenses[doc]
+
+		off, ok := first[doc]
+		if !ok {
+			off = 0
+		}
+
+		segments := snippet(l.Text, off, terms)
+		text, highlights := flattenSnippet(segments)
+
+		results = append(results, searchResult{
+			ID:         l.ID,
+			Title:      l.Title,
+			URL:        l.URL,
+			Score:      score,
+			Snippet:    text,
+			Highlights: highlights,
+			Segments:   segments,
+		})
+	}
+
+	slices.SortFunc(results, func(a searchResult, b searchResult) int {
+		if a.Score != b.Score {
+			return b.Score - a.Score
+		}
+
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return results
+}
+
+// snippet cuts the text around offset down to a few lines, collapsing
+// whitespace and marking every word that matches one of the terms.
+func snippet(text string, offset int, terms []string) []snippetSegment {
+	start := max(0, offset-snippetContext)
+	end := min(len(text), offset+2*snippetContext)
+
+	// don't cut words in half
+	for start > 0 && !isSpaceAt(text, start-1) {
+		start--
+	}
+	for end < len(text) && !isSpaceAt(text, end) {
+		end++
+	}
+
+	window := text[start:end]
+	segments := []snippetSegment{}
+
+	add := func(s string, match bool) {
+		segments = append(segments, snippetSegment{Text: s, Match: match})
+	}
+
+	if start > 0 {
+		add("… ", false)
+	}
+
+	pos := 0
+	for _, t := range tokenize(window) {
+		matched := slices.ContainsFunc(terms, func(term string) bool {
+			return strings.HasPrefix(t.text, term)
+		})
+
+		if !matched {
+			continue
+		}
+
+		if t.offset > pos {
+			add(padded(window[pos:t.offset]), false)
+		}
+
+		add(window[t.offset:t.end], true)
+		pos = t.end
+	}
+
+	if pos < len(window) {
+		add(padded(window[pos:]), false)
+	}
+
+	if end < len(text) {
+		add(" …", false)
+	}
+
+	return segments
+}
+
+// padded collapses the whitespace in s but keeps a single space at either end
+// if there was any, so words on either side of it don't run together.
+func padded(s string) string {
+	collapsed := strings.Join(strings.Fields(s), " ")
+
+	if collapsed == "" {
+		return " "
+	}
+
+	if r, _ := utf8.DecodeRuneInString(s); unicode.IsSpace(r) {
+		collapsed = " " + collapsed
+	}
+
+	if r, _ := utf8.DecodeLastRuneInString(s); unicode.IsSpace(r) {
+		collapsed = collapsed + " "
+	}
+
+	return collapsed
+}
+
+func isSpaceAt(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return unicode.IsSpace(r)
+}
+
+// flattenSnippet joins the segments into a single string, returning the byte
+// ranges of the matches within it.
+func flattenSnippet(segments []snippetSegment) (string, [][2]int) {
+	buf := new(strings.Builder)
+	highlights := [][2]int{}
+
+	for _, s := range segments {
+		text := s.Text
+
+		if s.Match {
+			highlights = append(highlights, [2]int{buf.Len(), buf.Len() + len(text)})
+		}
+
+		buf.WriteString(text)
+	}
+
+	return buf.String(), highlights
+}
+
+func searchHandler(idx *searchIndex, tmpl *pageTemplates) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+
+		res := searchResults{
+			Query:   q,
+			Results: idx.search(q),
+		}
+
+		mediatype := mostAcceptable(r)
+		if mediatype == "" {
+			writeNotAcceptable(w, r, tmpl)
+			return
+		}
+
+		switch mediatype {
+		case "text/html":
+			buf := getBuffer()
+			defer putBuffer(buf)
+			v := tmpl.variant(r)
+
+			if err := tmpl.ExecuteTemplate(buf, v, "search.html.tmpl", res); err != nil {
+				logf(r, "could not render search template: %s", err)
+				writeError(w, r, tmpl, http.StatusInternalServerError, "could not render search results")
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html")
+			setVariantHeaders(w, v)
+			w.Write(buf.Bytes())
+		case "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(res)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+
+			for _, result := range res.Results {
+				fmt.Fprintf(w, "%s (%s)\n    %s\n\n", result.Title, result.URL, result.Snippet)
+			}
+		}
+	})
+}
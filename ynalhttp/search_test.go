@@ -0,0 +1,132 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func testSearchIndex() *searchIndex {
+	return newSearchIndex([]ynal.LicenseData{
+		{ID: "one", Title: "One", URL: "/one", Text: "Grants a patent license.\n\nNo patents were harmed."},
+		{ID: "two", Title: "Two", URL: "/two", Text: "Provided as is, without warranty."},
+		{ID: "patent", Title: "Patent", URL: "/patent", Text: "Nothing to see here."},
+	})
+}
+
+func TestSearch(t *testing.T) {
+	idx := testSearchIndex()
+
+	tt := []struct {
+		name     string
+		q        string
+		expected []string
+	}{
+		{
+			name:     "metadata outranks text",
+			q:        "patent",
+			expected: []string{"patent", "one"},
+		},
+		{
+			name:     "prefix",
+			q:        "warr",
+			expected: []string{"two"},
+		},
+		{
+			name:     "all terms must match",
+			q:        "patent warranty",
+			expected: []string{},
+		},
+		{
+			name:     "case insensitive",
+			q:        "PROVIDED",
+			expected: []string{"two"},
+		},
+		{
+			name:     "empty",
+			q:        "  ",
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := []string{}
+			for _, r := range idx.search(tc.q) {
+				got = append(got, r.ID)
+			}
+
+			if !slices.Equal(got, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	results := testSearchIndex().search("patent")
+
+	r := results[1]
+	if r.Snippet != "Grants a patent license. No patents were harmed." {
+		t.Fatalf("unexpected snippet: %q", r.Snippet)
+	}
+
+	got := []string{}
+	for _, h := range r.Highlights {
+		got = append(got, r.Snippet[h[0]:h[1]])
+	}
+
+	if !slices.Equal(got, []string{"patent", "patents"}) {
+		t.Fatalf("unexpected highlights: %v", got)
+	}
+}
+
+func TestSnippetCaseFolding(t *testing.T) {
+	// Ⱥ takes a byte less than its lowercase
+	for _, tc := range []struct {
+		text     string
+		expected []string
+	}{
+		{"Copyright Ⱥ", []string{"Copyright ", "Ⱥ"}},
+		{"Ⱥ Ⱥ and more", []string{"Ⱥ", " ", "Ⱥ", " and more"}},
+	} {
+		t.Run(tc.text, func(t *testing.T) {
+			got := []string{}
+			for _, s := range snippet(tc.text, 0, []string{"ⱥ"}) {
+				got = append(got, s.Text)
+			}
+
+			if !slices.Equal(got, tc.expected) {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSearchHandler(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/search?q=sublicense", nil)
+	r.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	res := searchResults{}
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	ids := []string{}
+	for _, r := range res.Results {
+		ids = append(ids, r.ID)
+	}
+
+	if !slices.Contains(ids, "mit") || slices.Contains(ids, "unlicense") {
+		t.Fatalf("unexpected results: %v", ids)
+	}
+}
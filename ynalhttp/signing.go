@@ -0,0 +1,125 @@
+package ynalhttp
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+// SignatureHeader carries the detached JWS of a license's plain text.
+const SignatureHeader = "X-Ynal-JWS"
+
+// WithSigningKey signs the plain text of every license with key, so copies
+// of it can be checked against the public key wherever they end up. Each
+// plain text response carries the signature in SignatureHeader, /{id}/sig
+// serves the signature of the license as written, and /keys serves the public
+// key as a JSON Web Key Set.
+//
+// Signatures are JWS (RFC 7515) with a detached payload: the compact form with
+// the middle part left empty, where the payload is the response body.
+func WithSigningKey(key ed25519.PrivateKey) Option {
+	return func(c *config) {
+		c.signer = newSigner(key)
+	}
+}
+
+// signer makes detached JWS signatures with an Ed25519 key.
+type signer struct {
+	key    ed25519.PrivateKey
+	header string
+	jwk    jwk
+}
+
+// jwk is an Ed25519 public key as a JSON Web Key (RFC 8037).
+type jwk struct {
+	KTY string `json:"kty"`
+	CRV string `json:"crv"`
+	X   string `json:"x"`
+	KID string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+}
+
+func newSigner(key ed25519.PrivateKey) *signer {
+	x := base64.RawURLEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+
+	// the key ID is the key's RFC 7638 thumbprint, which anyone can work out
+	// from the key itself
+	thumbprint := sha256.Sum256([]byte(`{"crv":"Ed25519","kty":"OKP","x":"` + x + `"}`))
+	kid := base64.RawURLEncoding.EncodeToString(thumbprint[:])
+
+	header, _ := json.Marshal(map[string]string{"alg": "EdDSA", "kid": kid})
+
+	return &signer{
+		key:    key,
+		header: base64.RawURLEncoding.EncodeToString(header),
+		jwk:    jwk{KTY: "OKP", CRV: "Ed25519", X: x, KID: kid, Alg: "EdDSA", Use: "sig"},
+	}
+}
+
+// sign returns the detached JWS of body.
+func (s *signer) sign(body []byte) string {
+	input := s.header + "." + base64.RawURLEncoding.EncodeToString(body)
+	sig := ed25519.Sign(s.key, []byte(input))
+
+	return s.header + ".." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+type signerKey struct{}
+
+// withSigner makes s available to every handler below it, for signBody.
+func withSigner(s *signer, next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), signerKey{}, s)))
+	})
+}
+
+func signerFrom(r *http.Request) *signer {
+	s, _ := r.Context().Value(signerKey{}).(*signer)
+	return s
+}
+
+// signBody sets SignatureHeader to the signature of body, if there's a
+// signing key.
+func signBody(w http.ResponseWriter, r *http.Request, body []byte) {
+	if s := signerFrom(r); s != nil {
+		w.Header().Set(SignatureHeader, s.sign(body))
+	}
+}
+
+// signatureHandler serves the detached JWS of l's text as written.
+func signatureHandler(l ynal.LicenseData, tmpl *pageTemplates) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := signerFrom(r)
+		if s == nil {
+			writeError(w, r, tmpl, http.StatusNotFound, "licenses aren't signed here")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/jose")
+		w.Write([]byte(s.sign([]byte(l.Text))))
+	})
+}
+
+// keysHandler serves the public signing key as a JSON Web Key Set.
+func keysHandler(tmpl *pageTemplates) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := signerFrom(r)
+		if s == nil {
+			writeError(w, r, tmpl, http.StatusNotFound, "licenses aren't signed here")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/jwk-set+json")
+		json.NewEncoder(w).Encode(map[string][]jwk{"keys": {s.jwk}})
+	})
+}
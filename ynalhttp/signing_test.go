@@ -0,0 +1,142 @@
+package ynalhttp
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+// verifyJWS checks a detached JWS of body against the key in the JWK set.
+func verifyJWS(t *testing.T, keys []jwk, jws string, body []byte) {
+	t.Helper()
+
+	header, sig, ok := strings.Cut(jws, "..")
+	if !ok {
+		t.Fatalf("expected a detached JWS, got %q", jws)
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		t.Fatalf("could not decode header: %s", err)
+	}
+
+	h := map[string]string{}
+	if err := json.Unmarshal(raw, &h); err != nil {
+		t.Fatalf("could not parse header: %s", err)
+	}
+
+	if h["alg"] != "EdDSA" || len(keys) != 1 || h["kid"] != keys[0].KID {
+		t.Fatalf("unexpected header %v for keys %+v", h, keys)
+	}
+
+	pub, err := base64.RawURLEncoding.DecodeString(keys[0].X)
+	if err != nil {
+		t.Fatalf("could not decode key: %s", err)
+	}
+
+	s, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		t.Fatalf("could not decode signature: %s", err)
+	}
+
+	input := header + "." + base64.RawURLEncoding.EncodeToString(body)
+	if !ed25519.Verify(ed25519.PublicKey(pub), []byte(input), s) {
+		t.Fatalf("signature doesn't verify")
+	}
+}
+
+func TestSigning(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %s", err)
+	}
+
+	mit := ynal.NewLicense("MIT", "mit text\n")
+
+	h, err := New(WithLicenses([]ynal.LicenseData{mit}), WithSigningKey(key))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/keys", nil))
+
+	set := struct{ Keys []jwk }{}
+	if err := json.Unmarshal(w.Body.Bytes(), &set); err != nil {
+		t.Fatalf("could not decode keys: %s", err)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/mit/sig", nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/jose" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	verifyJWS(t, set.Keys, w.Body.String(), []byte(mit.Text))
+
+	for _, path := range []string{"/mit", "/mit?width=72&eol=crlf", "/raw/mit", "/download/mit"} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept", "text/plain")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		jws := w.Header().Get(SignatureHeader)
+		if jws == "" {
+			t.Fatalf("expected %s to be signed", path)
+		}
+
+		verifyJWS(t, set.Keys, jws, w.Body.Bytes())
+	}
+}
+
+func TestSignatureBesideLicenseWithSuffix(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("could not generate key: %s", err)
+	}
+
+	mit := ynal.NewLicense("MIT", "mit text\n")
+	sig := ynal.NewLicense("MIT.sig", "not a signature\n")
+
+	h, err := New(WithLicenses([]ynal.LicenseData{mit, sig}), WithSigningKey(key))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	r := httptest.NewRequest("GET", "/mit.sig", nil)
+	r.Header.Set("Accept", "text/plain")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK || w.Body.String() != sig.Text {
+		t.Errorf("expected /mit.sig to serve the license, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestSigningDisabled(t *testing.T) {
+	h := mustAppHandler(t)
+
+	for _, path := range []string{"/keys", "/mit/sig"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 for %s, got %d", path, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/raw/mit", nil))
+
+	if got := w.Header().Get(SignatureHeader); got != "" {
+		t.Errorf("expected no signature, got %q", got)
+	}
+}
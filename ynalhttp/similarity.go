@@ -0,0 +1,102 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+// maxSimilaritySize bounds the text compared against the catalog. The longest
+// licenses are a few hundred kilobytes.
+const maxSimilaritySize = 1 << 20
+
+type similarityRequest struct {
+	Text string `json:"text"`
+}
+
+type similarityMatch struct {
+	ID    string  `json:"id"`
+	Title string  `json:"title"`
+	SPDX  string  `json:"spdx"`
+	URL   string  `json:"url"`
+	Href  string  `json:"href"`
+	Score float64 `json:"score"`
+}
+
+type similarityResponse struct {
+	Matches []similarityMatch `json:"matches"`
+}
+
+// readSimilarityText returns the text to compare in r: the whole body, or the
+// text field of a JSON body.
+func readSimilarityText(r *http.Request) (string, error) {
+	mediatype, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediatype == "application/json" {
+		var req similarityRequest
+
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		if err := dec.Decode(&req); err != nil {
+			return "", fmt.Errorf("could not parse request: %w", err)
+		}
+
+		return req.Text, nil
+	}
+
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", fmt.Errorf("could not read text: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// similarityHandler scores the text in the request body against every license
+// in the catalog, most similar first, so someone with a modified license can
+// see which standard one it's closest to and how close. Scores run from 0 to
+// 1; see ynal.Matcher.
+func similarityHandler(m *ynal.Matcher, base string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		text, err := readSimilarityText(r)
+		if err != nil {
+			writeProblem(w, newProblem(r, bodyStatus(err), err.Error()))
+			return
+		}
+
+		if len(ynal.Words(text)) == 0 {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, "no text to compare, send it as the body"))
+			return
+		}
+
+		resp := similarityResponse{Matches: []similarityMatch{}}
+		for _, match := range m.Score(text) {
+			resp.Matches = append(resp.Matches, similarityMatch{
+				ID:    match.License.ID,
+				Title: match.License.Title,
+				SPDX:  match.License.SPDXID(),
+				URL:   match.License.URL,
+				Href:  apiHref(base, match.License),
+				Score: math.Round(match.Score*1000) / 1000,
+			})
+		}
+
+		if mostAcceptable(r) == "text/plain" {
+			w.Header().Set("Content-Type", "text/plain")
+
+			for _, match := range resp.Matches {
+				fmt.Fprintf(w, "%.3f %s (%s)\n", match.Score, match.Title, match.URL)
+			}
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
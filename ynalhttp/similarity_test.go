@@ -0,0 +1,122 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestSimilarity(t *testing.T) {
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	mit, _ := ynal.FindLicense(licenses, "mit")
+	modified, _ := json.Marshal(similarityRequest{Text: strings.Replace(mit.Text, "free of charge", "for a small fee", 1)})
+
+	tt := []struct {
+		name        string
+		body        string
+		contentType string
+		accept      string
+		code        int
+		expected    string
+		min         float64
+		max         float64
+	}{
+		{
+			name:     "exact",
+			body:     mit.Text,
+			accept:   "application/json",
+			code:     http.StatusOK,
+			expected: "mit",
+			min:      1,
+			max:      1,
+		},
+		{
+			name:        "modified",
+			body:        string(modified),
+			contentType: "application/json",
+			accept:      "application/json",
+			code:        http.StatusOK,
+			expected:    "mit",
+			min:         0.9,
+			max:         0.99,
+		},
+		{
+			name:     "plain",
+			body:     mit.Text,
+			code:     http.StatusOK,
+			expected: "1.000 MIT (/mit)\n",
+		},
+		{
+			name: "empty",
+			body: "  \n",
+			code: http.StatusBadRequest,
+		},
+		{
+			name:        "malformed",
+			body:        `{"txt": "mit"}`,
+			contentType: "application/json",
+			code:        http.StatusBadRequest,
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/similarity", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			if tc.accept == "" {
+				if !strings.HasPrefix(w.Body.String(), tc.expected) {
+					t.Errorf("expected body to start with %q, got %s", tc.expected, w.Body.String())
+				}
+
+				return
+			}
+
+			resp := similarityResponse{}
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("could not decode response: %s", err)
+			}
+
+			if len(resp.Matches) != len(licenses) {
+				t.Fatalf("expected a score for every license, got %+v", resp.Matches)
+			}
+
+			top := resp.Matches[0]
+			if top.ID != tc.expected || top.URL != "/mit" || top.SPDX != "MIT" {
+				t.Errorf("expected %s to match best, got %+v", tc.expected, top)
+			}
+
+			if top.Score < tc.min || top.Score > tc.max {
+				t.Errorf("expected a top score in [%f, %f], got %f", tc.min, tc.max, top.Score)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// constStyle declares a constant holding text in some language, as a snippet
+// to paste into source.
+type constStyle func(text string) string
+
+// constStyles maps each language, and common names and extensions for it,
+// to how it declares a string constant. commentStyles covers more languages,
+// as comments.
+var constStyles = map[string]constStyle{
+	"go":         goConst,
+	"rust":       rustConst,
+	"rs":         rustConst,
+	"python":     pythonConst,
+	"py":         pythonConst,
+	"javascript": jsConst,
+	"js":         jsConst,
+	"typescript": jsConst,
+	"ts":         jsConst,
+	"java":       javaConst,
+	"csharp":     csharpConst,
+	"cs":         csharpConst,
+}
+
+// constLanguages returns every language with a const style, sorted.
+func constLanguages() []string {
+	langs := []string{}
+	for lang := range constStyles {
+		langs = append(langs, lang)
+	}
+
+	slices.Sort(langs)
+
+	return langs
+}
+
+// quote returns s as a double-quoted string literal with JSON's escapes,
+// which C-like languages all understand.
+func quote(s string) string {
+	buf := new(bytes.Buffer)
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// quoteLines quotes text a line at a time, joined with sep, so long texts stay
+// readable in languages without multi-line strings.
+func quoteLines(text string, sep string) string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	quoted := []string{}
+	for _, line := range lines {
+		quoted = append(quoted, quote(line))
+	}
+
+	if len(quoted) == 0 {
+		return `""`
+	}
+
+	return strings.Join(quoted, sep)
+}
+
+// goConst uses a raw string unless the text has something a raw string can't
+// hold.
+func goConst(text string) string {
+	if strings.ContainsAny(text, "`\r") {
+		return "const License = " + strconv.Quote(text) + "\n"
+	}
+
+	return "const License = `" + text + "`\n"
+}
+
+// rustConst uses a raw string with enough #s that nothing in the text ends it.
+func rustConst(text string) string {
+	hashes := "#"
+	for strings.Contains(text, `"`+hashes) {
+		hashes += "#"
+	}
+
+	return `pub const LICENSE: &str = r` + hashes + `"` + text + `"` + hashes + ";\n"
+}
+
+// pythonConst uses a triple-quoted string unless the text could end it or has
+// escapes in it.
+func pythonConst(text string) string {
+	if strings.Contains(text, `"""`) || strings.Contains(text, `\`) || strings.HasSuffix(text, `"`) {
+		return "LICENSE = " + quote(text) + "\n"
+	}
+
+	return `LICENSE = """` + text + `"""` + "\n"
+}
+
+// jsConst uses a template literal unless the text has something that would
+// be interpreted in one.
+func jsConst(text string) string {
+	if strings.ContainsAny(text, "`\\") || strings.Contains(text, "${") {
+		return "export const LICENSE = " + quote(text) + ";\n"
+	}
+
+	return "export const LICENSE = `" + text + "`;\n"
+}
+
+func javaConst(text string) string {
+	return "public static final String LICENSE =\n    " + quoteLines(text, " +\n    ") + ";\n"
+}
+
+func csharpConst(text string) string {
+	return "public const string License =\n    " + quoteLines(text, " +\n    ") + ";\n"
+}
@@ -0,0 +1,148 @@
+package ynalhttp
+
+import (
+	"go/ast"
+	"go/parser"
+	gotoken "go/token"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestSourceFormat(t *testing.T) {
+	tt := []struct {
+		name   string
+		path   string
+		code   int
+		prefix string
+	}{
+		{
+			name:   "go constant",
+			path:   "/raw/mit?format=go",
+			code:   http.StatusOK,
+			prefix: "const License = `",
+		},
+		{
+			name:   "go comment",
+			path:   "/raw/mit?format=go&style=comment",
+			code:   http.StatusOK,
+			prefix: "// Copyright <YEAR>",
+		},
+		{
+			name:   "rust constant",
+			path:   "/download/mit?format=rs",
+			code:   http.StatusOK,
+			prefix: `pub const LICENSE: &str = r#"`,
+		},
+		{
+			name:   "java constant",
+			path:   "/raw/mit?format=java",
+			code:   http.StatusOK,
+			prefix: "public static final String LICENSE =\n    \"Copyright <YEAR> <COPYRIGHT HOLDER>\\n\" +",
+		},
+		{
+			name:   "comment only",
+			path:   "/mit?format=ruby",
+			code:   http.StatusOK,
+			prefix: "# Copyright <YEAR>",
+		},
+		{
+			name:   "block comment",
+			path:   "/raw/mit?format=css",
+			code:   http.StatusOK,
+			prefix: "/*\n * Copyright <YEAR>",
+		},
+		{
+			name:   "wrapped with crlf",
+			path:   "/raw/mit?format=python&width=40&eol=crlf",
+			code:   http.StatusOK,
+			prefix: "LICENSE = \"\"\"Copyright <YEAR> <COPYRIGHT HOLDER>\r\n",
+		},
+		{
+			name: "unknown format",
+			path: "/raw/mit?format=cobol",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "no constants",
+			path: "/raw/mit?format=ruby&style=const",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "unknown style",
+			path: "/raw/mit?format=go&style=heredoc",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "style without format",
+			path: "/raw/mit?style=comment",
+			code: http.StatusBadRequest,
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/plain")
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if !strings.HasPrefix(w.Body.String(), tc.prefix) {
+				t.Errorf("expected body to start with %q, got %q", tc.prefix, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGoConst(t *testing.T) {
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	texts := []string{"has a ` backtick\n", "has a \r carriage return\n", ""}
+	for _, l := range licenses {
+		texts = append(texts, l.Text)
+	}
+
+	for _, text := range texts {
+		src := "package license\n\n" + goConst(text)
+
+		f, err := parser.ParseFile(gotoken.NewFileSet(), "license.go", src, 0)
+		if err != nil {
+			t.Fatalf("could not parse %q: %s", src, err)
+		}
+
+		lit := f.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0].(*ast.BasicLit)
+
+		got, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			t.Fatalf("could not unquote %s: %s", lit.Value, err)
+		}
+
+		if got != text {
+			t.Errorf("expected %q, got %q", text, got)
+		}
+	}
+}
+
+func TestRustConst(t *testing.T) {
+	got := rustConst(`a "# quote`)
+	want := `pub const LICENSE: &str = r##"a "# quote"##;` + "\n"
+
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,112 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/spdx"
+)
+
+// maxExpressionSize bounds SPDX expressions, which are rarely more than a
+// line.
+const maxExpressionSize = 16 << 10
+
+type spdxRequest struct {
+	Expression string `json:"expression"`
+}
+
+type spdxValidation struct {
+	Valid bool `json:"valid"`
+
+	// Expression is the expression in normal form, with every known license
+	// written as its SPDX identifier. It's left out if it didn't parse.
+	Expression string `json:"expression,omitempty"`
+
+	Error      string          `json:"error,omitempty"`
+	Licenses   []spdxReference `json:"licenses"`
+	Exceptions []spdxReference `json:"exceptions"`
+	Unknown    []string        `json:"unknown,omitempty"`
+}
+
+type spdxReference struct {
+	SPDX  string `json:"spdx"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Href  string `json:"href,omitempty"`
+}
+
+// validateExpression parses and resolves an SPDX expression against licenses
+// and exceptions, whose URLs must already have base in front of them.
+func validateExpression(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, base string, expr string) spdxValidation {
+	v := spdxValidation{Licenses: []spdxReference{}, Exceptions: []spdxReference{}}
+
+	e, err := spdx.Parse(expr)
+	if err != nil {
+		v.Error = err.Error()
+		return v
+	}
+
+	found, unknown := e.Resolve(licenses)
+
+	for _, l := range found {
+		v.Licenses = append(v.Licenses, spdxReference{
+			SPDX:  l.SPDXID(),
+			ID:    l.ID,
+			Title: l.Title,
+			URL:   l.URL,
+			Href:  apiHref(base, l),
+		})
+	}
+
+	foundExceptions, unknownExceptions := e.ResolveExceptions(exceptions)
+	unknown = append(unknown, unknownExceptions...)
+
+	for _, x := range foundExceptions {
+		v.Exceptions = append(v.Exceptions, spdxReference{
+			SPDX:  x.SPDXID(),
+			ID:    x.ID,
+			Title: x.Title,
+			URL:   x.URL,
+		})
+	}
+
+	v.Expression = e.String()
+	v.Unknown = unknown
+	v.Valid = len(unknown) == 0
+
+	if !v.Valid {
+		v.Error = fmt.Sprintf("not in the catalog: %s", strings.Join(unknown, ", "))
+	}
+
+	return v
+}
+
+// spdxValidateHandler checks the SPDX expression in a JSON request body. An
+// expression that doesn't parse, or names licenses or exceptions that aren't
+// in the catalog, is still a 200 but with valid set to false.
+func spdxValidateHandler(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, base string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req spdxRequest
+
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+
+		if err := dec.Decode(&req); err != nil {
+			writeProblem(w, newProblem(r, bodyStatus(err), fmt.Sprintf("could not parse request: %s", err)))
+			return
+		}
+
+		b, err := json.Marshal(validateExpression(licenses, exceptions, base, req.Expression))
+		if err != nil {
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, fmt.Sprintf("could not marshal JSON: %s", err)))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(b)
+	})
+}
@@ -0,0 +1,116 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSPDXValidate(t *testing.T) {
+	tt := []struct {
+		name       string
+		body       string
+		valid      bool
+		expression string
+		licenses   []string
+		exceptions []string
+		unknown    []string
+	}{
+		{
+			name:       "valid",
+			body:       `{"expression": "mit or (apache-2.0 WITH LLVM-exception)"}`,
+			valid:      true,
+			expression: "MIT OR Apache-2.0 WITH LLVM-exception",
+			licenses:   []string{"/mit", "/apache_2"},
+			exceptions: []string{"/exceptions/llvm-exception"},
+		},
+		{
+			name:       "unknown exception",
+			body:       `{"expression": "MIT WITH Nope-exception"}`,
+			expression: "MIT WITH Nope-exception",
+			licenses:   []string{"/mit"},
+			unknown:    []string{"Nope-exception"},
+		},
+		{
+			name:       "ynal IDs",
+			body:       `{"expression": "gpl_3 AND bsd_3"}`,
+			valid:      true,
+			expression: "GPL-3.0-or-later AND BSD-3-Clause",
+			licenses:   []string{"/gpl_3", "/bsd_3"},
+		},
+		{
+			name:       "unknown license",
+			body:       `{"expression": "MIT AND Nope-1.0"}`,
+			expression: "MIT AND Nope-1.0",
+			licenses:   []string{"/mit"},
+			unknown:    []string{"Nope-1.0"},
+		},
+		{
+			name:     "syntax error",
+			body:     `{"expression": "MIT OR"}`,
+			licenses: []string{},
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/spdx/validate", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			v := spdxValidation{}
+			if err := json.NewDecoder(w.Body).Decode(&v); err != nil {
+				t.Fatalf("could not decode response: %s", err)
+			}
+
+			if v.Valid != tc.valid || v.Expression != tc.expression {
+				t.Fatalf("unexpected validation: %+v", v)
+			}
+
+			if !tc.valid && v.Error == "" {
+				t.Fatalf("expected an error for an invalid expression")
+			}
+
+			urls := []string{}
+			for _, l := range v.Licenses {
+				urls = append(urls, l.URL)
+			}
+
+			exceptions := []string{}
+			for _, x := range v.Exceptions {
+				exceptions = append(exceptions, x.URL)
+			}
+
+			if !slices.Equal(urls, tc.licenses) || !slices.Equal(v.Unknown, tc.unknown) {
+				t.Fatalf("unexpected licenses: %+v", v)
+			}
+
+			if len(tc.exceptions) > 0 && !slices.Equal(exceptions, tc.exceptions) {
+				t.Fatalf("unexpected exceptions: %+v", v)
+			}
+		})
+	}
+}
+
+func TestSPDXValidateBadRequest(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("POST", "/spdx/validate", strings.NewReader(`MIT OR Apache-2.0`))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
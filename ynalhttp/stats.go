@@ -0,0 +1,241 @@
+package ynalhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// representations are the ways a license can be fetched, in the order they're
+// shown on /stats.
+var representations = []string{"html", "text", "json", "rst", "asciidoc", "raw", "download", "api"}
+
+// Stats counts how many times each license has been fetched, by
+// representation. It is safe for concurrent use.
+type Stats struct {
+	mu   sync.Mutex
+	hits map[string]map[string]int64
+}
+
+func NewStats() *Stats {
+	return &Stats{hits: map[string]map[string]int64{}}
+}
+
+// LoadStats reads counts saved by Save. A missing file is the same as an
+// empty one, so the first start needs nothing special.
+func LoadStats(path string) (*Stats, error) {
+	s := NewStats()
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read stats: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &s.hits); err != nil {
+		return nil, fmt.Errorf("could not parse stats: %w", err)
+	}
+
+	return s, nil
+}
+
+// Save writes the counts to path, replacing it atomically so a crash partway
+// never loses the previous save.
+func (s *Stats) Save(path string) error {
+	s.mu.Lock()
+	b, err := json.Marshal(s.hits)
+	s.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("could not marshal stats: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".stats-*")
+	if err != nil {
+		return fmt.Errorf("could not create stats file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write stats: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write stats: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("could not replace stats: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Stats) add(id string, representation string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.hits[id] == nil {
+		s.hits[id] = map[string]int64{}
+	}
+
+	s.hits[id][representation]++
+}
+
+// totals returns how many times each license has been fetched, in any
+// representation.
+func (s *Stats) totals() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totals := map[string]int64{}
+	for id, hits := range s.hits {
+		for _, n := range hits {
+			totals[id] += n
+		}
+	}
+
+	return totals
+}
+
+// LicenseStats is how many times one license has been fetched.
+type LicenseStats struct {
+	ID    string           `json:"id"`
+	Total int64            `json:"total"`
+	Hits  map[string]int64 `json:"hits"`
+}
+
+// Snapshot returns the counts so far, most fetched first.
+func (s *Stats) Snapshot() []LicenseStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := []LicenseStats{}
+	for id, hits := range s.hits {
+		ls := LicenseStats{ID: id, Hits: map[string]int64{}}
+		for rep, n := range hits {
+			ls.Hits[rep] = n
+			ls.Total += n
+		}
+
+		all = append(all, ls)
+	}
+
+	slices.SortFunc(all, func(a LicenseStats, b LicenseStats) int {
+		switch {
+		case a.Total > b.Total:
+			return -1
+		case a.Total < b.Total:
+			return 1
+		default:
+			return strings.Compare(a.ID, b.ID)
+		}
+	})
+
+	return all
+}
+
+// WithStats counts license fetches in s, which is served at /stats. Pass a
+// Stats from LoadStats to keep counts across restarts. Without it, counts
+// start from zero every time.
+func WithStats(s *Stats) Option {
+	return func(c *config) {
+		c.stats = s
+	}
+}
+
+type statsKey struct{}
+
+// withStats makes s available to every handler below it, which survives the
+// handlers being rebuilt when the licenses change.
+func withStats(s *Stats, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), statsKey{}, s)))
+	})
+}
+
+func statsFrom(r *http.Request) *Stats {
+	s, _ := r.Context().Value(statsKey{}).(*Stats)
+	return s
+}
+
+// countHit records that the license id was fetched as representation.
+func countHit(r *http.Request, id string, representation string) {
+	traceLicense(r, id, representation)
+
+	if s := statsFrom(r); s != nil {
+		s.add(id, representation)
+	}
+}
+
+// statsPage is what the stats template is rendered with.
+type statsPage struct {
+	Representations []string
+	Licenses        []LicenseStats
+}
+
+// statsHandler serves the counts as an HTML table, JSON, or plain text.
+func statsHandler(tmpl *pageTemplates) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		all := []LicenseStats{}
+		if s := statsFrom(r); s != nil {
+			all = s.Snapshot()
+		}
+
+		mediatype := mostAcceptable(r)
+		if mediatype == "" {
+			writeNotAcceptable(w, r, tmpl)
+			return
+		}
+
+		switch mediatype {
+		case "text/html":
+			buf := getBuffer()
+			defer putBuffer(buf)
+			v := tmpl.variant(r)
+
+			if err := tmpl.ExecuteTemplate(buf, v, "stats.html.tmpl", statsPage{Representations: representations, Licenses: all}); err != nil {
+				logf(r, "could not render stats template: %s", err)
+				writeError(w, r, tmpl, http.StatusInternalServerError, "could not render stats page")
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html")
+			setVariantHeaders(w, v)
+			w.Write(buf.Bytes())
+		case "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string][]LicenseStats{"licenses": all})
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+
+			tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+			fmt.Fprint(tw, "license\ttotal")
+			for _, rep := range representations {
+				fmt.Fprintf(tw, "\t%s", rep)
+			}
+			fmt.Fprintln(tw)
+
+			for _, ls := range all {
+				fmt.Fprintf(tw, "%s\t%d", ls.ID, ls.Total)
+				for _, rep := range representations {
+					fmt.Fprintf(tw, "\t%d", ls.Hits[rep])
+				}
+				fmt.Fprintln(tw)
+			}
+
+			tw.Flush()
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	stats := NewStats()
+
+	h, err := New(WithStats(stats))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	fetches := []struct {
+		path   string
+		accept string
+	}{
+		{path: "/mit", accept: "text/html"},
+		{path: "/mit", accept: "text/html"},
+		{path: "/mit", accept: "application/json"},
+		{path: "/raw/mit"},
+		{path: "/download/apache_2"},
+		{path: "/api/v1/licenses/mit"},
+		{path: "/raw/nope"},
+	}
+
+	for _, f := range fetches {
+		r := httptest.NewRequest("GET", f.path, nil)
+		r.Header.Set("Accept", f.accept)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	tt := []struct {
+		name     string
+		accept   string
+		expected []string
+	}{
+		{
+			name:     "html",
+			accept:   "text/html",
+			expected: []string{"<table>", `<a href="/mit">mit</a>`, "<td>5</td>"},
+		},
+		{
+			name:     "plain",
+			accept:   "text/plain",
+			expected: []string{"license   total  html  text  json  rst  asciidoc  raw  download  api\nmit       5      2     0     1     0    0         1    0         1\napache_2  1"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/stats", nil)
+			r.Header.Set("Accept", tc.accept)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			for _, want := range tc.expected {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Fatalf("expected %q in:\n%s", want, w.Body.String())
+				}
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/stats", nil)
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		got := struct {
+			Licenses []LicenseStats `json:"licenses"`
+		}{}
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatalf("could not parse stats: %s", err)
+		}
+
+		if len(got.Licenses) != 2 || got.Licenses[0].ID != "mit" || got.Licenses[0].Hits["html"] != 2 || got.Licenses[1].Hits["download"] != 1 {
+			t.Fatalf("unexpected stats: %+v", got.Licenses)
+		}
+	})
+}
+
+func TestStatsPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+
+	stats, err := LoadStats(path)
+	if err != nil {
+		t.Fatalf("expected a missing file to load as empty, got: %s", err)
+	}
+
+	stats.add("mit", "raw")
+	stats.add("mit", "raw")
+
+	if err := stats.Save(path); err != nil {
+		t.Fatalf("could not save stats: %s", err)
+	}
+
+	loaded, err := LoadStats(path)
+	if err != nil {
+		t.Fatalf("could not load stats: %s", err)
+	}
+
+	if got := loaded.Snapshot(); len(got) != 1 || got[0].Hits["raw"] != 2 {
+		t.Fatalf("unexpected stats after reload: %+v", got)
+	}
+}
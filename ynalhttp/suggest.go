@@ -0,0 +1,111 @@
+package ynalhttp
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/packrat386/ynal"
+)
+
+// maxSuggestions is how many close matches a 404 offers at most.
+const maxSuggestions = 3
+
+// suggester finds licenses whose names are close to a path that wasn't found,
+// for the 404 page to offer instead.
+type suggester struct {
+	// names maps every name a license or exception goes by (its ID, SPDX
+	// identifier, and aliases), normalized, to its URL.
+	names map[string]string
+}
+
+// newSuggester indexes licenses and exceptions, whose URLs must already have
+// the base path in front of them.
+func newSuggester(licenses []ynal.LicenseData, exceptions []ynal.LicenseData) *suggester {
+	s := &suggester{names: map[string]string{}}
+
+	for _, l := range slices.Concat(licenses, exceptions) {
+		for _, name := range append([]string{l.ID, l.SPDX}, l.Aliases...) {
+			if n := normalizeName(name); n != "" {
+				s.names[n] = l.URL
+			}
+		}
+	}
+
+	return s
+}
+
+// suggest returns the URLs of the licenses closest to name, nearest first.
+// Only names within a few edits count, so nonsense gets no suggestions.
+func (s *suggester) suggest(name string) []string {
+	n := normalizeName(name)
+	if n == "" {
+		return nil
+	}
+
+	limit := max(2, len(n)/3)
+	best := map[string]int{}
+
+	for candidate, url := range s.names {
+		d := editDistance(n, candidate)
+		if d > limit {
+			continue
+		}
+
+		if prev, ok := best[url]; !ok || d < prev {
+			best[url] = d
+		}
+	}
+
+	urls := []string{}
+	for url := range best {
+		urls = append(urls, url)
+	}
+
+	slices.SortFunc(urls, func(a string, b string) int {
+		return cmp.Or(cmp.Compare(best[a], best[b]), cmp.Compare(a, b))
+	})
+
+	return urls[:min(len(urls), maxSuggestions)]
+}
+
+// normalizeName lowercases name and drops everything but letters and digits,
+// so gpl-3.0, GPL_3 and gpl3 all look alike.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return -1
+	}, name)
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
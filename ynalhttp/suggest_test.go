@@ -0,0 +1,119 @@
+package ynalhttp
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestSuggest(t *testing.T) {
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	exceptions, err := ynal.EmbeddedExceptions()
+	if err != nil {
+		t.Fatalf("could not load exceptions: %s", err)
+	}
+
+	s := newSuggester(licenses, exceptions)
+
+	tt := []struct {
+		name     string
+		expected string
+	}{
+		{name: "mti", expected: "/mit"},
+		{name: "gpl-3.0", expected: "/gpl_3"},
+		{name: "apache2", expected: "/apache_2"},
+		{name: "Apache-2.0", expected: "/apache_2"},
+		{name: "classpath-exception", expected: "/exceptions/classpath-exception-2.0"},
+		{name: "zzzzzzzz", expected: ""},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			got := s.suggest(tc.name)
+
+			if tc.expected == "" {
+				if len(got) != 0 {
+					t.Fatalf("expected no suggestions, got %v", got)
+				}
+
+				return
+			}
+
+			if len(got) == 0 || got[0] != tc.expected {
+				t.Fatalf("expected %s first, got %v", tc.expected, got)
+			}
+
+			if len(got) > maxSuggestions {
+				t.Fatalf("expected at most %d suggestions, got %v", maxSuggestions, got)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tt := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"mit", "mit", 0},
+		{"mit", "", 3},
+		{"mti", "mit", 2},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tc := range tt {
+		if got := editDistance(tc.a, tc.b); got != tc.expected {
+			t.Errorf("editDistance(%q, %q): expected %d, got %d", tc.a, tc.b, tc.expected, got)
+		}
+	}
+}
+
+func TestNotFoundSuggestions(t *testing.T) {
+	h, err := New(WithBasePath("/licenses"))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		accept   string
+		expected string
+	}{
+		{accept: "text/plain", expected: "nothing found at /gpl-3.0\n\ndid you mean:\n  /licenses/gpl_3\n"},
+		{accept: "application/json", expected: `"suggestions":["/licenses/gpl_3"`},
+		{accept: "text/html", expected: `<li><a href="/licenses/gpl_3">/licenses/gpl_3</a></li>`},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.accept, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/licenses/gpl-3.0", nil)
+			r.Header.Set("Accept", tc.accept)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != 404 {
+				t.Fatalf("expected 404, got %d", w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+
+	r := httptest.NewRequest("GET", "/licenses/zzzzzzzz", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if strings.Contains(w.Body.String(), "suggestions") {
+		t.Fatalf("expected no suggestions for nonsense, got: %s", w.Body.String())
+	}
+}
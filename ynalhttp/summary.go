@@ -0,0 +1,77 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+// summaryDisclaimer labels every summary served outside the HTML pages, which
+// carry a translated one.
+const summaryDisclaimer = "This is a plain-language summary, not legal advice. The full license is what counts."
+
+type summaryJSON struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Summary    string `json:"summary"`
+	Disclaimer string `json:"disclaimer"`
+	LicenseURL string `json:"license_url"`
+}
+
+// summaryURL is where l's summary is served, if it has one.
+func summaryURL(l ynal.LicenseData) string {
+	return l.URL + "/summary"
+}
+
+// summaryHandler serves the one-line summary of l, negotiated like the license
+// itself and always labeled as not legal advice.
+func summaryHandler(l ynal.LicenseData, tmpl *pageTemplates, base string) (http.Handler, error) {
+	htmlData := map[variant][]byte{}
+	for _, v := range tmpl.variants() {
+		buf := new(bytes.Buffer)
+		if err := tmpl.ExecuteTemplate(buf, v, "summary.html.tmpl", l); err != nil {
+			return nil, fmt.Errorf("could not render html template: %w", err)
+		}
+
+		htmlData[v] = buf.Bytes()
+	}
+
+	jsonData, err := json.Marshal(summaryJSON{ID: l.ID, Title: l.Title, Summary: l.Summary, Disclaimer: summaryDisclaimer, LicenseURL: l.URL})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal JSON: %w", err)
+	}
+
+	plainData := []byte(fmt.Sprintf("%s: %s\n\n%s\n", l.Title, l.Summary, summaryDisclaimer))
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediatype := mostAcceptable(r)
+		if mediatype == "" {
+			writeNotAcceptable(w, r, tmpl)
+			return
+		}
+
+		setDeprecationHeaders(w, l, base)
+
+		switch mediatype {
+		case "text/plain":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write(plainData)
+		case "text/html":
+			v := tmpl.variant(r)
+
+			w.Header().Set("Content-Type", "text/html")
+			setVariantHeaders(w, v)
+			w.Write(htmlData[v])
+		case "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(jsonData)
+		default:
+			writeError(w, r, tmpl, http.StatusNotAcceptable, fmt.Sprintf("unrecognized media type: %s", mediatype))
+		}
+	})
+
+	return h, nil
+}
@@ -0,0 +1,82 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name     string
+		path     string
+		accept   string
+		code     int
+		expected string
+	}{
+		{
+			name:     "html",
+			path:     "/mit/summary",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: "<strong>tl;dr:</strong> Do what you want, just keep the copyright notice. No warranty. <small>This is a plain-language summary, not legal advice.",
+		},
+		{
+			name:     "plain",
+			path:     "/mit/summary",
+			accept:   "text/plain",
+			code:     http.StatusOK,
+			expected: "MIT: Do what you want, just keep the copyright notice. No warranty.\n\nThis is a plain-language summary, not legal advice. The full license is what counts.\n",
+		},
+		{
+			name:     "json",
+			path:     "/mit/summary",
+			accept:   "application/json",
+			code:     http.StatusOK,
+			expected: `{"id":"mit","title":"MIT","summary":"Do what you want, just keep the copyright notice. No warranty.","disclaimer":"This is a plain-language summary, not legal advice. The full license is what counts.","license_url":"/mit"}`,
+		},
+		{
+			name:     "license page",
+			path:     "/mit",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: `<p class="summary">`,
+		},
+		{
+			name:     "license json",
+			path:     "/mit",
+			accept:   "application/json",
+			code:     http.StatusOK,
+			expected: `"summary":"Do what you want, just keep the copyright notice. No warranty."`,
+		},
+		{
+			name:     "api",
+			path:     "/api/v1/licenses/mit",
+			accept:   "application/json",
+			code:     http.StatusOK,
+			expected: `"summary":"Do what you want, just keep the copyright notice. No warranty."`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
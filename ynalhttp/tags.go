@@ -0,0 +1,147 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/packrat386/ynal"
+)
+
+type tagJSON struct {
+	Name     string         `json:"name"`
+	URL      string         `json:"url"`
+	Licenses []familyMember `json:"licenses"`
+}
+
+type tagIndexJSON struct {
+	Tags []tagJSON `json:"tags"`
+}
+
+func linkedTags(licenses []ynal.LicenseData, base string) []ynal.Tag {
+	tags := ynal.TagIndex(licenses)
+	for i := range tags {
+		tags[i].URL = base + tags[i].URL
+	}
+
+	return tags
+}
+
+func toTagJSON(t ynal.Tag) tagJSON {
+	tj := tagJSON{Name: t.Name, URL: t.URL, Licenses: []familyMember{}}
+	for _, l := range t.Licenses {
+		tj.Licenses = append(tj.Licenses, familyMember{ID: l.ID, Title: l.Title, URL: l.URL, Version: l.Version})
+	}
+
+	return tj
+}
+
+// negotiatedPage is a page rendered up front in every representation.
+type negotiatedPage struct {
+	html  map[variant][]byte
+	json  []byte
+	plain []byte
+}
+
+func (p negotiatedPage) serve(w http.ResponseWriter, r *http.Request, tmpl *pageTemplates) {
+	mediatype := mostAcceptable(r)
+	if mediatype == "" {
+		writeNotAcceptable(w, r, tmpl)
+		return
+	}
+
+	switch mediatype {
+	case "text/plain":
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(p.plain)
+	case "text/html":
+		v := tmpl.variant(r)
+
+		w.Header().Set("Content-Type", "text/html")
+		setVariantHeaders(w, v)
+		w.Write(p.html[v])
+	case "application/json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(p.json)
+	default:
+		writeError(w, r, tmpl, http.StatusNotAcceptable, fmt.Sprintf("unrecognized media type: %s", mediatype))
+	}
+}
+
+func renderNegotiated(tmpl *pageTemplates, name string, data any, j any, plain []byte) (negotiatedPage, error) {
+	p := negotiatedPage{html: map[variant][]byte{}, plain: plain}
+
+	for _, v := range tmpl.variants() {
+		buf := new(bytes.Buffer)
+		if err := tmpl.ExecuteTemplate(buf, v, name, data); err != nil {
+			return p, fmt.Errorf("could not render html template: %w", err)
+		}
+
+		p.html[v] = buf.Bytes()
+	}
+
+	b, err := json.Marshal(j)
+	if err != nil {
+		return p, fmt.Errorf("could not marshal JSON: %w", err)
+	}
+
+	p.json = b
+
+	return p, nil
+}
+
+// tagIndexHandler serves every tag and the licenses in each, for browsing the
+// catalog by category.
+func tagIndexHandler(tags []ynal.Tag, tmpl *pageTemplates) (http.Handler, error) {
+	index := tagIndexJSON{Tags: []tagJSON{}}
+	plain := new(bytes.Buffer)
+
+	for _, t := range tags {
+		index.Tags = append(index.Tags, toTagJSON(t))
+
+		fmt.Fprintf(plain, "%s (%s)\n", t.Name, t.URL)
+		for _, l := range t.Licenses {
+			fmt.Fprintf(plain, "  %s (%s)\n", l.Title, l.URL)
+		}
+	}
+
+	page, err := renderNegotiated(tmpl, "tags.html.tmpl", tags, index, plain.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page.serve(w, r, tmpl)
+	}), nil
+}
+
+// tagHandler serves a page for every tag listing the licenses tagged with it,
+// negotiated like the license routes.
+func tagHandler(tags []ynal.Tag, tmpl *pageTemplates) (http.Handler, error) {
+	pages := map[string]negotiatedPage{}
+
+	for _, t := range tags {
+		plain := new(bytes.Buffer)
+		for _, l := range t.Licenses {
+			fmt.Fprintf(plain, "%s (%s)\n", l.Title, l.URL)
+		}
+
+		page, err := renderNegotiated(tmpl, "tag.html.tmpl", t, toTagJSON(t), plain.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		pages[t.Name] = page
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.PathValue("tag")]
+		if !ok {
+			writeError(w, r, tmpl, http.StatusNotFound, fmt.Sprintf("no such tag: %s", r.PathValue("tag")))
+			return
+		}
+
+		page.serve(w, r, tmpl)
+	}), nil
+}
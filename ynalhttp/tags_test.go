@@ -0,0 +1,96 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTagPages(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name     string
+		path     string
+		accept   string
+		code     int
+		expected string
+	}{
+		{
+			name:     "html",
+			path:     "/tags/copyleft",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: `<a href="/gpl_3">GPL_3</a>`,
+		},
+		{
+			name:     "plain",
+			path:     "/tags/copyleft",
+			accept:   "text/plain",
+			code:     http.StatusOK,
+			expected: "AGPL_3 (/agpl_3)\nGPL_3 (/gpl_3)\n",
+		},
+		{
+			name:     "json",
+			path:     "/tags/public-domain",
+			accept:   "application/json",
+			code:     http.StatusOK,
+			expected: `{"name":"public-domain","url":"/tags/public-domain","licenses":[{"id":"unlicense","title":"Unlicense","url":"/unlicense"}]}`,
+		},
+		{
+			name:     "empty",
+			path:     "/tags/hardware",
+			accept:   "application/json",
+			code:     http.StatusOK,
+			expected: `{"name":"hardware","url":"/tags/hardware","licenses":[]}`,
+		},
+		{
+			name:     "missing",
+			path:     "/tags/nope",
+			accept:   "text/plain",
+			code:     http.StatusNotFound,
+			expected: "no such tag: nope",
+		},
+		{
+			name:     "index",
+			path:     "/tags",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: `<h3><a href="/tags/permissive">permissive</a></h3>`,
+		},
+		{
+			name:     "index json",
+			path:     "/tags",
+			accept:   "application/json",
+			code:     http.StatusOK,
+			expected: `{"tags":[{"name":"copyleft","url":"/tags/copyleft","licenses":[{"id":"agpl_3","title":"AGPL_3","url":"/agpl_3","version":"3.0"}`,
+		},
+		{
+			name:     "license page",
+			path:     "/unlicense",
+			accept:   "text/html",
+			code:     http.StatusOK,
+			expected: `<a href="/tags/public-domain">public domain</a> <a href="/tags/permissive">permissive</a>`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the body to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package ynalhttp
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+
+	"github.com/packrat386/ynal"
+)
+
+// variant is one of the ways a page can be rendered: in a theme, in a
+// language.
+type variant struct {
+	theme string
+	lang  string
+}
+
+// pageTemplates holds a copy of the templates for every variant, so a page can
+// be rendered in whichever one the request asks for.
+type pageTemplates struct {
+	byVariant    map[variant]map[string]*template.Template
+	assets       *assets
+	defaultTheme string
+	langs        []string
+
+	// cache holds the pages rendered with these templates, up to cacheBudget
+	// bytes. It's nil until withRenderCache.
+	cache       *renderCache
+	cacheBudget int64
+}
+
+// parseTemplates parses templates/*.tmpl in templates once per theme and
+// language in messages. Any *.tmpl in overrides, which may be nil, replaces
+// the template of the same name. Links in them are relative to base, and links
+// to assets in public are fingerprinted. Absolute links start with site. See
+// parsePages.
+func parseTemplates(templates fs.FS, overrides fs.FS, messages fs.FS, public fs.FS, base string, site string, defaultTheme string) (*pageTemplates, error) {
+	if defaultTheme == "" {
+		defaultTheme = builtinThemes[0].Name
+	}
+
+	if !slices.Contains(ThemeNames(), defaultTheme) {
+		return nil, fmt.Errorf("unknown theme: %q", defaultTheme)
+	}
+
+	catalogs, err := loadMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := fingerprintAssets(public)
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := template.FuncMap{
+		"base":   func() string { return base },
+		"site":   func() string { return site },
+		"asset":  a.linkFunc(base),
+		"theme":  func() Theme { return Theme{} },
+		"themes": func() []Theme { return builtinThemes },
+		"lang":   func() string { return defaultLang },
+		"msg":    msgFunc(catalogs[defaultLang], catalogs[defaultLang]),
+
+		"paragraphs": ynal.Paragraphs,
+	}
+
+	pages, err := parsePages(templates, overrides, funcs)
+	if err != nil {
+		return nil, err
+	}
+
+	pt := &pageTemplates{byVariant: map[variant]map[string]*template.Template{}, assets: a, defaultTheme: defaultTheme}
+
+	for lang := range catalogs {
+		pt.langs = append(pt.langs, lang)
+	}
+	sort.Strings(pt.langs)
+
+	for _, theme := range builtinThemes {
+		for _, lang := range pt.langs {
+			byName := map[string]*template.Template{}
+
+			for name, page := range pages {
+				t, err := page.Clone()
+				if err != nil {
+					return nil, fmt.Errorf("could not clone templates: %w", err)
+				}
+
+				t.Funcs(template.FuncMap{
+					"theme": func() Theme { return theme },
+					"lang":  func() string { return lang },
+					"msg":   msgFunc(catalogs[lang], catalogs[defaultLang]),
+				})
+
+				byName[name] = t
+			}
+
+			pt.byVariant[variant{theme: theme.Name, lang: lang}] = byName
+		}
+	}
+
+	return pt, nil
+}
+
+// variants returns every variant, for prerendering a page in each. The
+// default theme in the default language comes first, since it's the one most
+// requests get.
+func (pt *pageTemplates) variants() []variant {
+	vs := []variant{}
+	for v := range pt.byVariant {
+		vs = append(vs, v)
+	}
+
+	def := variant{theme: pt.defaultTheme, lang: defaultLang}
+	sort.Slice(vs, func(i, j int) bool {
+		if (vs[i] == def) != (vs[j] == def) {
+			return vs[i] == def
+		}
+
+		if vs[i].theme != vs[j].theme {
+			return vs[i].theme < vs[j].theme
+		}
+
+		return vs[i].lang < vs[j].lang
+	})
+
+	return vs
+}
+
+// withRenderCache returns a copy of pt with a cache of its own, so pages
+// rendered for one set of licenses are never served for another.
+func (pt *pageTemplates) withRenderCache() *pageTemplates {
+	c := *pt
+	c.cache = newRenderCache(pt.cacheBudget)
+
+	return &c
+}
+
+// variant returns the variant r asks for: the theme from its cookie and the
+// language from its Accept-Language header, or the defaults.
+func (pt *pageTemplates) variant(r *http.Request) variant {
+	v := variant{
+		theme: pt.defaultTheme,
+		lang:  negotiateLanguage(r.Header.Get("Accept-Language"), pt.langs),
+	}
+
+	if c, err := r.Cookie(themeCookie); err == nil && slices.Contains(ThemeNames(), c.Value) {
+		v.theme = c.Value
+	}
+
+	return v
+}
+
+// ExecuteTemplate renders the named page in the given variant.
+func (pt *pageTemplates) ExecuteTemplate(w io.Writer, v variant, name string, data any) error {
+	t, ok := pt.byVariant[v][name]
+	if !ok {
+		return fmt.Errorf("no such page template: %q", name)
+	}
+
+	return t.ExecuteTemplate(w, name, data)
+}
+
+// has reports whether there's a page template called name, embedded or
+// overridden.
+func (pt *pageTemplates) has(name string) bool {
+	for _, byName := range pt.byVariant {
+		_, ok := byName[name]
+		return ok
+	}
+
+	return false
+}
+
+// partials are the templates pages are built from rather than pages of their
+// own: the layout every page fills in the blocks of, the header, nav, and
+// footer in it, and the license text the license and print pages share.
+var partials = []string{"layout.html.tmpl", "header.html.tmpl", "nav.html.tmpl", "footer.html.tmpl", "license_text.html.tmpl"}
+
+// templateSource is the text of a template, and whether it's an override.
+type templateSource struct {
+	text     string
+	override bool
+}
+
+// parsePages parses every template but the partials as a page, each with its
+// own copy of the partials, so the blocks one page defines, like "content",
+// don't replace another's. An override adds a page if it isn't replacing one.
+func parsePages(templates fs.FS, overrides fs.FS, funcs template.FuncMap) (map[string]*template.Template, error) {
+	sources, err := readTemplates(templates, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	parse := func(t *template.Template, name string) error {
+		src := sources[name]
+		if _, err := t.New(name).Parse(src.text); err != nil {
+			if src.override {
+				return fmt.Errorf("could not parse template overrides: %w", err)
+			}
+
+			return fmt.Errorf("could not parse templates: %w", err)
+		}
+
+		return nil
+	}
+
+	shared := template.New("").Funcs(funcs)
+	for _, name := range partials {
+		if _, ok := sources[name]; !ok {
+			continue
+		}
+
+		if err := parse(shared, name); err != nil {
+			return nil, err
+		}
+	}
+
+	pages := map[string]*template.Template{}
+	for name := range sources {
+		if slices.Contains(partials, name) {
+			continue
+		}
+
+		t, err := shared.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("could not clone templates: %w", err)
+		}
+
+		if err := parse(t, name); err != nil {
+			return nil, err
+		}
+
+		pages[name] = t
+	}
+
+	return pages, nil
+}
+
+// readTemplates reads templates/*.tmpl in templates, then *.tmpl in
+// overrides, which may be nil, in their place, by name.
+func readTemplates(templates fs.FS, overrides fs.FS) (map[string]templateSource, error) {
+	sources := map[string]templateSource{}
+
+	read := func(fsys fs.FS, pattern string, override bool) error {
+		names, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			b, err := fs.ReadFile(fsys, name)
+			if err != nil {
+				return err
+			}
+
+			sources[path.Base(name)] = templateSource{text: string(b), override: override}
+		}
+
+		return nil
+	}
+
+	if err := read(templates, "templates/*.tmpl", false); err != nil {
+		return nil, fmt.Errorf("could not read templates: %w", err)
+	}
+
+	if overrides != nil {
+		if err := read(overrides, "*.tmpl", true); err != nil {
+			return nil, fmt.Errorf("could not read template overrides: %w", err)
+		}
+	}
+
+	return sources, nil
+}
+
+// setVariantHeaders describes which variant of a page is being served, and
+// tells caches what it depends on.
+func setVariantHeaders(w http.ResponseWriter, v variant) {
+	w.Header().Set("Content-Language", v.lang)
+	w.Header().Add("Vary", "Cookie, Accept-Language")
+}
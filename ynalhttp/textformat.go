@@ -0,0 +1,150 @@
+package ynalhttp
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/packrat386/ynal"
+)
+
+// The widths plain text can be rewrapped to.
+const (
+	minTextWidth = 20
+	maxTextWidth = 200
+)
+
+// textFormat is how a license's plain text is laid out: rewrapped to width
+// columns, or as written when width is 0, and with CRLF line endings if crlf
+// is set. If lang is set the text is wrapped up as source in that language, a
+// string constant where there's a constStyle for it unless comment is set, and
+// a comment otherwise.
+type textFormat struct {
+	width   int
+	crlf    bool
+	lang    string
+	comment bool
+}
+
+// commonTextFormats are rendered up front for every license, since LICENSE
+// files tend to be wrapped at 72 or 80 columns. Anything else is rendered when
+// it's asked for.
+var commonTextFormats = []textFormat{
+	{width: 0, crlf: true},
+	{width: 72, crlf: false},
+	{width: 72, crlf: true},
+	{width: 80, crlf: false},
+	{width: 80, crlf: true},
+}
+
+// parseTextFormat reads the width, eol, format, and style query parameters,
+// which are width=<columns>, eol=lf or eol=crlf, format=<language>, and
+// style=const or style=comment.
+func parseTextFormat(q url.Values) (textFormat, error) {
+	f := textFormat{}
+
+	if raw := q.Get("width"); raw != "" {
+		width, err := strconv.Atoi(raw)
+		if err != nil || width < minTextWidth || width > maxTextWidth {
+			return f, fmt.Errorf("width must be a number of columns from %d to %d, not %q", minTextWidth, maxTextWidth, raw)
+		}
+
+		f.width = width
+	}
+
+	switch eol := q.Get("eol"); eol {
+	case "", "lf":
+	case "crlf":
+		f.crlf = true
+	default:
+		return f, fmt.Errorf("eol must be lf or crlf, not %q", eol)
+	}
+
+	if lang := strings.ToLower(q.Get("format")); lang != "" {
+		if _, ok := commentStyles[lang]; !ok {
+			return f, fmt.Errorf("unknown format: %s (try one of %s)", lang, strings.Join(languages(), ", "))
+		}
+
+		f.lang = lang
+		_, hasConst := constStyles[lang]
+
+		switch style := q.Get("style"); style {
+		case "":
+			f.comment = !hasConst
+		case "const":
+			if !hasConst {
+				return f, fmt.Errorf("no string constants for %s (try one of %s)", lang, strings.Join(constLanguages(), ", "))
+			}
+		case "comment":
+			f.comment = true
+		default:
+			return f, fmt.Errorf("style must be const or comment, not %q", style)
+		}
+	} else if q.Has("style") {
+		return f, fmt.Errorf("style only applies with a format")
+	}
+
+	return f, nil
+}
+
+func (f textFormat) apply(text string) string {
+	if f.width > 0 {
+		text = ynal.Wrap(text, f.width)
+	}
+
+	switch {
+	case f.lang == "":
+	case f.comment:
+		text = commentStyles[f.lang].comment(text)
+	default:
+		text = constStyles[f.lang](text)
+	}
+
+	if f.crlf {
+		text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+	}
+
+	return text
+}
+
+// plainText is a license's text in every textFormat, the common ones rendered
+// up front. Every format but the text as written is kept in cache, which may
+// be nil to render them every time.
+type plainText struct {
+	text      string
+	key       string
+	asWritten renderedPage
+	cache     *renderCache
+}
+
+func newPlainText(l ynal.LicenseData, cache *renderCache) plainText {
+	p := plainText{text: l.Text, key: l.URL, asWritten: textPage(l), cache: cache}
+
+	for _, f := range commonTextFormats {
+		// rendering text can't fail
+		_ = cache.warm(p.cacheKey(f), p.render(f))
+	}
+
+	return p
+}
+
+func (p plainText) page(f textFormat) renderedPage {
+	if f == (textFormat{}) {
+		return p.asWritten
+	}
+
+	page, _ := p.cache.get(p.cacheKey(f), p.render(f))
+
+	return page
+}
+
+func (p plainText) render(f textFormat) func() (renderedPage, error) {
+	return func() (renderedPage, error) {
+		return newRenderedPage([]byte(f.apply(p.text))), nil
+	}
+}
+
+func (p plainText) cacheKey(f textFormat) string {
+	return fmt.Sprintf("%s|text|%d|%t|%s|%t", p.key, f.width, f.crlf, f.lang, f.comment)
+}
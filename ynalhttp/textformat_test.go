@@ -0,0 +1,109 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTextFormat(t *testing.T) {
+	tt := []struct {
+		name  string
+		path  string
+		code  int
+		width int
+		crlf  bool
+	}{
+		{
+			name: "as written",
+			path: "/raw/mit",
+			code: http.StatusOK,
+		},
+		{
+			name:  "rewrapped",
+			path:  "/raw/mit?width=72",
+			code:  http.StatusOK,
+			width: 72,
+		},
+		{
+			name: "crlf",
+			path: "/download/mit?eol=crlf",
+			code: http.StatusOK,
+			crlf: true,
+		},
+		{
+			name:  "uncommon width with crlf",
+			path:  "/mit?width=40&eol=crlf",
+			code:  http.StatusOK,
+			width: 40,
+			crlf:  true,
+		},
+		{
+			name:  "combination",
+			path:  "/raw/apache_2+llvm-exception?width=80",
+			code:  http.StatusOK,
+			width: 80,
+		},
+		{
+			name: "too narrow",
+			path: "/raw/mit?width=5",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "not a number",
+			path: "/mit?width=wide",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "unknown eol",
+			path: "/raw/mit?eol=cr",
+			code: http.StatusBadRequest,
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/plain")
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d: %s", tc.code, w.Code, w.Body.String())
+			}
+
+			if tc.code != http.StatusOK {
+				return
+			}
+
+			body := w.Body.String()
+
+			if got := strings.Count(body, "\r\n") > 0; got != tc.crlf {
+				t.Errorf("expected CRLF %t, got %q", tc.crlf, body)
+			}
+
+			if tc.crlf && strings.Count(body, "\n") != strings.Count(body, "\r\n") {
+				t.Errorf("expected every line to end in CRLF, got %q", body)
+			}
+
+			longest := 0
+			for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+				longest = max(longest, utf8.RuneCountInString(line))
+			}
+
+			if tc.width > 0 && longest > tc.width {
+				t.Errorf("expected lines of at most %d, got one of %d", tc.width, longest)
+			}
+
+			if tc.width == 0 && longest <= 80 {
+				t.Errorf("expected the text as written, with its long lines, got %q", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package ynalhttp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// themeCookie remembers which theme a visitor picked.
+const themeCookie = "ynal_theme"
+
+// maxThemeSize bounds the form picking a theme, which is one short field.
+const maxThemeSize = 4 << 10
+
+// Theme is a look for the HTML pages: a stylesheet loaded after styles.css,
+// plus whatever the templates need to know about it.
+type Theme struct {
+	// Name identifies the theme in config and in the theme cookie.
+	Name string
+
+	// Stylesheet is the theme's CSS, relative to public/.
+	Stylesheet string
+
+	// ColorScheme is "light" or "dark", and tells browsers how to draw form
+	// controls and scrollbars.
+	ColorScheme string
+}
+
+// builtinThemes are the themes every page can be shown in. The first one is
+// the default.
+var builtinThemes = []Theme{
+	{Name: "light", Stylesheet: "themes/light.css", ColorScheme: "light"},
+	{Name: "dark", Stylesheet: "themes/dark.css", ColorScheme: "dark"},
+}
+
+// ThemeNames returns the names of the built-in themes.
+func ThemeNames() []string {
+	names := []string{}
+	for _, t := range builtinThemes {
+		names = append(names, t.Name)
+	}
+
+	return names
+}
+
+// WithTheme sets the theme pages are shown in until a visitor picks another.
+// It defaults to "light".
+func WithTheme(name string) Option {
+	return func(c *config) {
+		c.theme = name
+	}
+}
+
+// themeHandler sets the theme cookie from a form post and sends the visitor
+// back to the page they were on.
+func themeHandler(tmpl *pageTemplates, base string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.PostFormValue("theme")
+		if !slices.Contains(ThemeNames(), name) {
+			writeError(w, r, tmpl, http.StatusBadRequest, fmt.Sprintf("unknown theme: %q", name))
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     themeCookie,
+			Value:    name,
+			Path:     base + "/",
+			MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		http.Redirect(w, r, themeReturn(r, base), http.StatusSeeOther)
+	})
+}
+
+// themeReturn is the page to go back to after picking a theme: the referring
+// page if it was one of ours, the index otherwise.
+func themeReturn(r *http.Request, base string) string {
+	ref, err := url.Parse(r.Referer())
+	if err != nil || ref.Host != r.Host || !strings.HasPrefix(ref.Path, base+"/") {
+		return base + "/"
+	}
+
+	return ref.RequestURI()
+}
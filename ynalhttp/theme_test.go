@@ -0,0 +1,151 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestThemes(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     []Option
+		path     string
+		cookie   string
+		expected string
+	}{
+		{
+			name:     "default",
+			path:     "/mit",
+			expected: "themes/light.",
+		},
+		{
+			name:     "configured default",
+			opts:     []Option{WithTheme("dark")},
+			path:     "/mit",
+			expected: "themes/dark.",
+		},
+		{
+			name:     "cookie",
+			path:     "/",
+			cookie:   "dark",
+			expected: "themes/dark.",
+		},
+		{
+			name:     "unknown cookie",
+			path:     "/mit",
+			cookie:   "plaid",
+			expected: "themes/light.",
+		},
+		{
+			name:     "error page",
+			path:     "/nope",
+			cookie:   "dark",
+			expected: "themes/dark.",
+		},
+		{
+			name:     "search",
+			path:     "/search?q=mit",
+			cookie:   "dark",
+			expected: "themes/dark.",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := New(tc.opts...)
+			if err != nil {
+				t.Fatalf("could not initialize app handler: %s", err)
+			}
+
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/html")
+			if tc.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: themeCookie, Value: tc.cookie})
+			}
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the page to use %s, got:\n%s", tc.expected, w.Body.String())
+			}
+
+			if !strings.Contains(w.Header().Get("Vary"), "Cookie") {
+				t.Fatalf("expected Vary to include Cookie, got %q", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestPickTheme(t *testing.T) {
+	tt := []struct {
+		name     string
+		theme    string
+		referer  string
+		code     int
+		location string
+	}{
+		{
+			name:     "back to referer",
+			theme:    "dark",
+			referer:  "http://example.com/mit?x=1",
+			code:     http.StatusSeeOther,
+			location: "/mit?x=1",
+		},
+		{
+			name:     "offsite referer",
+			theme:    "dark",
+			referer:  "http://evil.example.net/mit",
+			code:     http.StatusSeeOther,
+			location: "/",
+		},
+		{
+			name:  "unknown theme",
+			theme: "plaid",
+			code:  http.StatusBadRequest,
+		},
+	}
+
+	h := mustAppHandler(t)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			form := url.Values{"theme": {tc.theme}}
+
+			r := httptest.NewRequest("POST", "/theme", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.Header.Set("Referer", tc.referer)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d", tc.code, w.Code)
+			}
+
+			if tc.code != http.StatusSeeOther {
+				return
+			}
+
+			if got := w.Header().Get("Location"); got != tc.location {
+				t.Fatalf("expected a redirect to %q, got %q", tc.location, got)
+			}
+
+			cookies := w.Result().Cookies()
+			if len(cookies) != 1 || cookies[0].Name != themeCookie || cookies[0].Value != tc.theme {
+				t.Fatalf("expected the theme cookie to be set to %q, got %v", tc.theme, cookies)
+			}
+		})
+	}
+}
+
+func TestUnknownDefaultTheme(t *testing.T) {
+	if _, err := New(WithTheme("plaid")); err == nil {
+		t.Fatalf("expected an error for an unknown theme")
+	}
+}
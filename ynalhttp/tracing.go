@@ -0,0 +1,116 @@
+package ynalhttp
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/packrat386/ynal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans ynal makes.
+const tracerName = "github.com/packrat386/ynal/ynalhttp"
+
+// WithTracerProvider records a span for every request with tp, continuing
+// the caller's trace if the request carries one (in whatever format the
+// global propagator understands). Without it, nothing is traced.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracer = tp.Tracer(tracerName, trace.WithInstrumentationVersion(ReadBuildInfo().Version))
+	}
+}
+
+// tracingResponseWriter remembers the status, for the span.
+type tracingResponseWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (t *tracingResponseWriter) WriteHeader(code int) {
+	t.code = code
+	t.ResponseWriter.WriteHeader(code)
+}
+
+func (t *tracingResponseWriter) Flush() {
+	if f, ok := t.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (t *tracingResponseWriter) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
+
+// withTracing starts a server span for every request. The span is named for
+// the method until the route is known; see withRouteAttributes.
+func withTracing(tracer trace.Tracer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("server.address", r.Host),
+				attribute.String("user_agent.original", r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		tw := &tracingResponseWriter{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(tw, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", tw.code))
+		if ct := w.Header().Get("Content-Type"); ct != "" {
+			span.SetAttributes(attribute.String("ynal.media_type", ct))
+		}
+
+		if tw.code >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(tw.code))
+		}
+	})
+}
+
+// withRouteAttributes names the request's span for the route that served it
+// and notes which catalog it was served from. It wraps the mux, which is
+// where the route is known.
+func withRouteAttributes(licenses []ynal.LicenseData, mux *http.ServeMux) http.Handler {
+	catalog := []attribute.KeyValue{
+		attribute.String("ynal.catalog.revision", ynal.Revision(licenses)),
+		attribute.Int("ynal.catalog.licenses", len(licenses)),
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+
+		span := trace.SpanFromContext(r.Context())
+		if !span.IsRecording() {
+			return
+		}
+
+		span.SetAttributes(catalog...)
+
+		if r.Pattern != "" {
+			// patterns start with the method when they have one
+			_, route, ok := strings.Cut(r.Pattern, " ")
+			if !ok {
+				route = r.Pattern
+			}
+
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(attribute.String("http.route", route))
+		}
+	})
+}
+
+// traceLicense notes which license a request fetched, and how.
+func traceLicense(r *http.Request, id string, representation string) {
+	trace.SpanFromContext(r.Context()).SetAttributes(
+		attribute.String("ynal.license.id", id),
+		attribute.String("ynal.representation", representation),
+	)
+}
@@ -0,0 +1,127 @@
+package ynalhttp
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/packrat386/ynal"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		path     string
+		accept   string
+		span     string
+		expected map[attribute.Key]attribute.Value
+	}{
+		{
+			name:   "license",
+			path:   "/licenses/mit",
+			accept: "application/json",
+			span:   "GET /mit",
+			expected: map[attribute.Key]attribute.Value{
+				"http.route":                attribute.StringValue("/mit"),
+				"http.response.status_code": attribute.IntValue(200),
+				"ynal.media_type":           attribute.StringValue("application/json"),
+				"ynal.license.id":           attribute.StringValue("mit"),
+				"ynal.representation":       attribute.StringValue("json"),
+				"ynal.catalog.revision":     attribute.StringValue(ynal.Revision(licenses)),
+			},
+		},
+		{
+			name: "api",
+			path: "/licenses/api/v1/licenses/mit",
+			span: "GET /api/v1/licenses/{id}",
+			expected: map[attribute.Key]attribute.Value{
+				"http.route":          attribute.StringValue("/api/v1/licenses/{id}"),
+				"ynal.license.id":     attribute.StringValue("mit"),
+				"ynal.representation": attribute.StringValue("api"),
+			},
+		},
+		{
+			name: "not found",
+			path: "/licenses/nope",
+			span: "GET /",
+			expected: map[attribute.Key]attribute.Value{
+				"http.response.status_code": attribute.IntValue(404),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+			h, err := New(WithTracerProvider(tp), WithBasePath("/licenses"))
+			if err != nil {
+				t.Fatalf("could not initialize app handler: %s", err)
+			}
+
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			spans := rec.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected one span, got %d", len(spans))
+			}
+
+			if spans[0].Name() != tc.span {
+				t.Errorf("expected span %q, got %q", tc.span, spans[0].Name())
+			}
+
+			if spans[0].SpanKind() != trace.SpanKindServer {
+				t.Errorf("expected a server span, got %s", spans[0].SpanKind())
+			}
+
+			got := map[attribute.Key]attribute.Value{}
+			for _, kv := range spans[0].Attributes() {
+				got[kv.Key] = kv.Value
+			}
+
+			for k, v := range tc.expected {
+				if got[k] != v {
+					t.Errorf("expected %s to be %s, got %s", k, v.Emit(), got[k].Emit())
+				}
+			}
+		})
+	}
+}
+
+func TestTracingContinuesTrace(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	rec := tracetest.NewSpanRecorder()
+	h, err := New(WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	r := httptest.NewRequest("GET", "/mit", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	span := rec.Ended()[0]
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected the caller's trace, got %s", got)
+	}
+
+	if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("expected the caller's span as parent, got %s", got)
+	}
+}
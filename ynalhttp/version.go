@@ -0,0 +1,86 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/packrat386/ynal"
+)
+
+// BuildInfo describes the running binary, as recorded by the Go toolchain.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// ReadBuildInfo reports which build of ynal is running. Fields the toolchain
+// didn't record (in tests, or builds outside a git checkout) are left empty.
+func ReadBuildInfo() BuildInfo {
+	b := BuildInfo{Version: "(devel)", GoVersion: runtime.Version()}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+
+	if bi.Main.Version != "" {
+		b.Version = bi.Main.Version
+	}
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.Commit = s.Value
+		case "vcs.time":
+			b.Date = s.Value
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+
+	return b
+}
+
+func (b BuildInfo) String() string {
+	s := "ynal " + b.Version
+	if b.Commit != "" {
+		commit := b.Commit[:min(len(b.Commit), 12)]
+		if b.Modified {
+			commit += "+dirty"
+		}
+
+		s += fmt.Sprintf(" (commit %s, built %s)", commit, b.Date)
+	}
+
+	return s + " " + b.GoVersion
+}
+
+type versionInfo struct {
+	BuildInfo
+	Revision string `json:"catalog_revision"`
+	Licenses int    `json:"licenses"`
+}
+
+// versionHandler serves the build info along with the revision of the
+// licenses being served.
+func versionHandler(licenses []ynal.LicenseData) (http.Handler, error) {
+	body, err := json.Marshal(versionInfo{
+		BuildInfo: ReadBuildInfo(),
+		Revision:  ynal.Revision(licenses),
+		Licenses:  len(licenses),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal JSON: %w", err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}), nil
+}
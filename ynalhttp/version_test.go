@@ -0,0 +1,87 @@
+package ynalhttp
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/packrat386/ynal"
+)
+
+func TestVersion(t *testing.T) {
+	h, err := New()
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	r := httptest.NewRequest("GET", "/version", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected JSON, got %q", ct)
+	}
+
+	got := map[string]any{}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("could not decode response: %s", err)
+	}
+
+	licenses, err := ynal.Embedded()
+	if err != nil {
+		t.Fatalf("could not load licenses: %s", err)
+	}
+
+	expected := map[string]any{
+		"go_version":       runtime.Version(),
+		"catalog_revision": ynal.Revision(licenses),
+		"licenses":         float64(len(licenses)),
+	}
+
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("expected %s to be %v, got %v", k, v, got[k])
+		}
+	}
+
+	if got["version"] == "" {
+		t.Errorf("expected a version")
+	}
+}
+
+func TestBuildInfoString(t *testing.T) {
+	tt := []struct {
+		name     string
+		info     BuildInfo
+		expected string
+	}{
+		{
+			name:     "devel",
+			info:     BuildInfo{Version: "(devel)", GoVersion: "go1.25.0"},
+			expected: "ynal (devel) go1.25.0",
+		},
+		{
+			name:     "vcs",
+			info:     BuildInfo{Version: "v1.2.0", Commit: "0123456789abcdef", Date: "2026-01-02T03:04:05Z", GoVersion: "go1.25.0"},
+			expected: "ynal v1.2.0 (commit 0123456789ab, built 2026-01-02T03:04:05Z) go1.25.0",
+		},
+		{
+			name:     "modified",
+			info:     BuildInfo{Version: "(devel)", Commit: "0123456789abcdef", Date: "2026-01-02T03:04:05Z", Modified: true, GoVersion: "go1.25.0"},
+			expected: "ynal (devel) (commit 0123456789ab+dirty, built 2026-01-02T03:04:05Z) go1.25.0",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.info.String(); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,658 @@
+// Package ynalhttp serves the ynal license catalog over HTTP. It can be mounted
+// under any mux; cmd/ynal is a thin wrapper around it.
+package ynalhttp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/packrat386/ynal"
+	"github.com/packrat386/ynal/github"
+	"github.com/packrat386/ynal/manifest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type config struct {
+	store        ynal.LicenseStore
+	cacheControl string
+	admin        AdminStore
+	tokens       map[string]string
+	certs        map[string]string
+	basePath     string
+	siteURL      string
+	dev          fs.FS
+	theme        string
+	exceptions   []ynal.LicenseData
+	custom       []ynal.LicenseData
+	stats        *Stats
+	tracer       trace.Tracer
+	maintenance  *Maintenance
+	templates    fs.FS
+	resolver     manifest.Resolver
+	detector     github.Detector
+	licenseFS    fs.FS
+	logger       *log.Logger
+	clock        func() time.Time
+	audit        *AuditLog
+	order        indexOrder
+	signer       *signer
+	acceptances  AcceptanceStore
+	checks       []readinessCheck
+	renderBudget int64
+	strictAccept bool
+
+	preferred        []string
+	browserPreferred []string
+}
+
+// Option configures the handler returned by New.
+type Option func(*config)
+
+// WithLicenses serves the given licenses instead of the embedded catalog.
+func WithLicenses(licenses []ynal.LicenseData) Option {
+	return func(c *config) {
+		c.store = ynal.NewCatalog(licenses)
+	}
+}
+
+// WithStore serves the licenses in store, rebuilding every route whenever
+// they change.
+func WithStore(store ynal.LicenseStore) Option {
+	return func(c *config) {
+		c.store = store
+	}
+}
+
+// WithLicenseFS serves the *.txt licenses at the root of fsys, with their
+// metadata, instead of the embedded catalog. See ynal.LoadLicenses.
+func WithLicenseFS(fsys fs.FS) Option {
+	return func(c *config) {
+		c.licenseFS = fsys
+	}
+}
+
+// WithTemplates replaces the embedded templates with any *.tmpl files in
+// fsys of the same name, so a branded instance can change just the pages it
+// needs to. The rest keep using the embedded templates. Others are added
+// alongside them, for licenses whose metadata picks a template of their own.
+func WithTemplates(fsys fs.FS) Option {
+	return func(c *config) {
+		c.templates = fsys
+	}
+}
+
+// WithCacheControl sets the Cache-Control header on every response.
+func WithCacheControl(value string) Option {
+	return func(c *config) {
+		c.cacheControl = value
+	}
+}
+
+// New returns a handler serving the license catalog, the index page, and the
+// public assets.
+func New(opts ...Option) (http.Handler, error) {
+	c, err := newConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.handler()
+}
+
+// newConfig applies opts, filling in the defaults for anything they leave
+// out.
+func newConfig(opts []Option) (*config, error) {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.admin != nil && len(c.tokens) == 0 && len(c.certs) == 0 {
+		return nil, errors.New("the admin API needs at least one token or client certificate, see WithTokens and WithClientCertificates")
+	}
+
+	if c.acceptances != nil && len(c.tokens) == 0 && len(c.certs) == 0 {
+		return nil, errors.New("recording acceptances needs at least one token or client certificate, see WithTokens and WithClientCertificates")
+	}
+
+	if c.licenseFS != nil {
+		if c.store != nil {
+			return nil, errors.New("WithLicenseFS can't be used with WithLicenses or WithStore")
+		}
+
+		store, err := ynal.NewFSStore(c.licenseFS)
+		if err != nil {
+			return nil, fmt.Errorf("could not load licenses: %w", err)
+		}
+
+		c.store = store
+	}
+
+	if c.store == nil {
+		store, err := ynal.EmbeddedStore()
+		if err != nil {
+			return nil, fmt.Errorf("could not load licenses: %w", err)
+		}
+
+		c.store = store
+	}
+
+	if c.exceptions == nil {
+		exceptions, err := ynal.EmbeddedExceptions()
+		if err != nil {
+			return nil, err
+		}
+
+		c.exceptions = exceptions
+	}
+
+	if c.stats == nil {
+		c.stats = NewStats()
+	}
+
+	if c.logger == nil {
+		c.logger = log.Default()
+	}
+
+	if c.clock == nil {
+		c.clock = time.Now
+	}
+
+	if c.order.kind == "" {
+		c.order.kind = OrderAlphabetical
+	}
+
+	if err := c.order.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := validatePreferred(slices.Concat(c.preferred, c.browserPreferred)); err != nil {
+		return nil, err
+	}
+
+	if c.siteURL == "" {
+		c.siteURL = defaultSiteURL
+	}
+
+	site, err := validateSiteURL(c.siteURL)
+	if err != nil {
+		return nil, err
+	}
+	c.siteURL = site
+
+	return c, nil
+}
+
+func (c *config) handler() (http.Handler, error) {
+	public, err := fs.Sub(ynal.Public, "public")
+	if err != nil {
+		return nil, fmt.Errorf("could not subsystem public assets: %w", err)
+	}
+
+	tmpl, err := parseTemplates(ynal.Templates, c.templates, ynal.Messages, public, c.basePath, c.siteURL, c.theme)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.cacheBudget = c.renderBudget
+
+	var h http.Handler
+
+	checks := []readinessCheck{}
+	if hc, ok := c.store.(ynal.HealthChecker); ok {
+		checks = append(checks, readinessCheck{name: "store", check: hc.Health})
+	}
+
+	build := func(licenses []ynal.LicenseData) (http.Handler, error) {
+		return appHandler(licenses, c.exceptions, c.custom, tmpl, public, c.basePath)
+	}
+
+	if c.dev != nil {
+		h = newDevHandler(c.store, c.exceptions, c.custom, c.dev, c.templates, c.basePath, c.siteURL, c.theme)
+	} else {
+		rh, err := newReloadingHandler(c.store, c.logger, build)
+		if err != nil {
+			return nil, err
+		}
+
+		h = rh
+		checks = append(checks, readinessCheck{name: "renderer", check: rh.health})
+	}
+
+	if c.maintenance != nil {
+		checks = append(checks, readinessCheck{name: "maintenance", check: c.maintenance.health})
+	}
+
+	if c.audit != nil {
+		c.audit.watch(c.store, c.clock, c.logger)
+	}
+
+	// scans and detection check against whatever's in the store at the time,
+	// so they sit outside the handler that's rebuilt whenever it changes
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/v1/scan", limitBody(tmpl, maxManifestSize, uploadTimeout, scanHandler(c.store, c.resolver, c.basePath)))
+
+	// readiness covers the rebuilding of that handler, so it can't be in it
+	mux.Handle("GET "+readyPath, readyHandler(append(checks, c.checks...)))
+	if c.detector != nil {
+		mux.Handle("GET /detect", detectHandler(c.store, c.detector, tmpl, c.basePath))
+	}
+	mux.Handle("/", h)
+	h = mux
+
+	if c.admin != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/admin/", adminHandler(c.admin, credentials{tokens: c.tokens, certs: c.certs}, c.store, tmpl, build))
+		mux.Handle("/", h)
+
+		h = mux
+	}
+
+	if c.acceptances != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/api/v1/acceptances", acceptanceHandler(c.acceptances, credentials{tokens: c.tokens, certs: c.certs}, c.store, c.custom, tmpl))
+		mux.Handle("/", h)
+
+		h = mux
+	}
+
+	if c.cacheControl != "" {
+		h = withCacheControl(c.cacheControl, h)
+	}
+
+	h = withMaintenance(c.maintenance, tmpl, h)
+	h = withStrictAccept(c.strictAccept, tmpl, h)
+	h = withRecovery(c.logger, tmpl, withBasePath(c.basePath, tmpl, withCanonicalPath(tmpl, withStats(c.stats, withIndexOrder(c.order, withSigner(c.signer, h))))))
+	h = withLogger(c.logger, withClock(c.clock, withAudit(c.audit, withPreferences(newPreferences(c.preferred, c.browserPreferred), h))))
+
+	if c.tracer != nil {
+		h = withTracing(c.tracer, h)
+	}
+
+	return h, nil
+}
+
+// appHandler serves licenses and exceptions at the root, and custom documents
+// under /custom/. Every URL it generates has base in front of it.
+func appHandler(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, custom []ynal.LicenseData, tmpl *pageTemplates, public fs.FS, base string) (_ http.Handler, err error) {
+	// ServeMux panics when two routes conflict, like a license whose URL is
+	// one of ynal's own routes, and that's an error in the licenses
+	defer func() {
+		if r := recover(); r != nil {
+			conflict, ok := r.(error)
+			if !ok || errors.As(conflict, new(runtime.Error)) {
+				panic(r)
+			}
+
+			err = fmt.Errorf("could not route licenses: %w", conflict)
+		}
+	}()
+
+	if err := ynal.Validate(licenses); err != nil {
+		return nil, fmt.Errorf("invalid licenses:\n%w", err)
+	}
+
+	if err := checkReserved(licenses); err != nil {
+		return nil, fmt.Errorf("invalid licenses:\n%w", err)
+	}
+
+	if err := ynal.Validate(exceptions); err != nil {
+		return nil, fmt.Errorf("invalid exceptions:\n%w", err)
+	}
+
+	if err := ynal.Validate(custom); err != nil {
+		return nil, fmt.Errorf("invalid custom documents:\n%w", err)
+	}
+
+	tmpl = tmpl.withRenderCache()
+
+	mux := http.NewServeMux()
+	linked := withBase(licenses, base)
+	families := linkedFamilies(linked, base)
+
+	for i, l := range licenses {
+		h, err := handlerFor(newLicensePage(linked[i], families, base), tmpl, base)
+		if err != nil {
+			return nil, fmt.Errorf("could not init handler: %w", err)
+		}
+
+		mux.Handle("GET "+l.URL, h)
+		mux.Handle("GET "+l.URL+"/sha256", digestHandler(l.Digest.SHA256))
+		mux.Handle("GET "+l.URL+"/sha1", digestHandler(l.Digest.SHA1))
+		mux.Handle("GET "+l.URL+"/sig", signatureHandler(l, tmpl))
+
+		nh, err := normalizedHandler(linked[i])
+		if err != nil {
+			return nil, fmt.Errorf("could not init normalized handler: %w", err)
+		}
+
+		mux.Handle("GET "+normalizedURL(l), nh)
+
+		if l.Deed != "" {
+			dh, err := deedHandler(linked[i], tmpl, base)
+			if err != nil {
+				return nil, fmt.Errorf("could not init deed handler: %w", err)
+			}
+
+			mux.Handle("GET "+deedURL(l), dh)
+		}
+
+		if l.Summary != "" {
+			sh, err := summaryHandler(linked[i], tmpl, base)
+			if err != nil {
+				return nil, fmt.Errorf("could not init summary handler: %w", err)
+			}
+
+			mux.Handle("GET "+summaryURL(l), sh)
+		}
+
+		if l.Logo != "" {
+			lh, err := logoHandler(l)
+			if err != nil {
+				return nil, fmt.Errorf("could not init logo handler: %w", err)
+			}
+
+			mux.Handle("GET "+logoURL(l), lh)
+		}
+	}
+
+	mux.Handle("GET /raw/{id}", rawHandler(licenses, exceptions, tmpl, base))
+	mux.Handle("GET /download/{id}", downloadHandler(licenses, exceptions, tmpl, base))
+	mux.Handle("GET /raw/custom/{id}", rawHandler(custom, nil, tmpl, base))
+	mux.Handle("GET /download/custom/{id}", downloadHandler(custom, nil, tmpl, base))
+	mux.Handle("GET /header/{id}", headerHandler(licenses, tmpl, base))
+	mux.Handle("GET /search", searchHandler(newSearchIndex(linked), tmpl))
+	mux.Handle("POST /spdx/validate", limitBody(tmpl, maxExpressionSize, requestTimeout, spdxValidateHandler(linked, withBase(exceptions, base), base)))
+	mux.Handle("POST /api/v1/reuse", limitBody(tmpl, maxReuseSize, uploadTimeout, reuseHandler(linked, withBase(exceptions, base), base)))
+	mux.Handle("POST /similarity", limitBody(tmpl, maxSimilaritySize, requestTimeout, similarityHandler(ynal.NewMatcher(linked), base)))
+
+	compat, err := ynal.EmbeddedCompatibility()
+	if err != nil {
+		return nil, err
+	}
+	mux.Handle("GET /compatibility", compatHandler(linked, compat, tmpl))
+	mux.Handle("GET /bundle", bundleHandler(licenses, tmpl))
+	mux.Handle("GET /obligations", obligationsHandler(linked, tmpl))
+	mux.Handle("GET /all.zip", allHandler(licenses, "zip"))
+	mux.Handle("GET /all.tar.gz", allHandler(licenses, "tar.gz"))
+	mux.Handle("POST /theme", limitBody(tmpl, maxThemeSize, requestTimeout, themeHandler(tmpl, base)))
+	mux.Handle("GET /stats", statsHandler(tmpl))
+	mux.Handle("GET /keys", keysHandler(tmpl))
+	mux.Handle("GET "+healthPath, healthHandler())
+
+	vh, err := versionHandler(licenses)
+	if err != nil {
+		return nil, err
+	}
+	mux.Handle("GET /version", vh)
+
+	if err := documentRoutes(mux, exceptions, tmpl, base); err != nil {
+		return nil, fmt.Errorf("could not init exceptions: %w", err)
+	}
+
+	if err := documentRoutes(mux, custom, tmpl, base); err != nil {
+		return nil, fmt.Errorf("could not init custom documents: %w", err)
+	}
+
+	fh, err := familyHandler(families, tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("could not init family pages: %w", err)
+	}
+	mux.Handle("GET /family/{id}", fh)
+
+	tags := linkedTags(linked, base)
+
+	th, err := tagHandler(tags, tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("could not init tag pages: %w", err)
+	}
+	mux.Handle("GET /tags/{tag}", th)
+
+	tih, err := tagIndexHandler(tags, tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("could not init tag index: %w", err)
+	}
+	mux.Handle("GET /tags", tih)
+
+	for _, f := range families {
+		if latest, ok := f.Latest(); ok {
+			mux.Handle("GET /"+f.ID+"/latest", latestHandler(latest))
+		}
+	}
+
+	if err := apiHandlers(mux, linked, families, tmpl, base); err != nil {
+		return nil, fmt.Errorf("could not init API: %w", err)
+	}
+
+	// a license called random would win over picking one at random
+	if _, pattern := mux.Handler(&http.Request{Method: "GET", URL: &url.URL{Path: "/random"}}); pattern == "" {
+		mux.Handle("GET /random", randomHandler(linked, tmpl))
+	}
+
+	// aliases go last so they can never shadow a real route
+	for _, l := range linked {
+		for _, alias := range l.Aliases {
+			probe := &http.Request{Method: "GET", URL: &url.URL{Path: "/" + alias}}
+			if _, pattern := mux.Handler(probe); pattern != "" {
+				continue
+			}
+
+			mux.Handle("GET /"+alias, aliasHandler(l))
+		}
+	}
+
+	index, err := newIndexRenderer(tmpl, newIndexPage(linked, families, withBase(exceptions, base), withBase(custom, base)))
+	if err != nil {
+		return nil, err
+	}
+
+	mux.Handle("/", newCombinationHandler(licenses, exceptions, tmpl, base, newPublicHandler(public, tmpl, index, newSuggester(linked, slices.Concat(withBase(exceptions, base), withBase(custom, base))))))
+
+	return withRouteAttributes(licenses, mux), nil
+}
+
+// reservedIDs are the paths of ynal's own pages at the root, which no license
+// can be served at.
+var reservedIDs = []string{
+	"all.tar.gz", "all.zip", "bundle", "compatibility", "detect", strings.TrimPrefix(healthPath, "/"), "keys",
+	"obligations", strings.TrimPrefix(readyPath, "/"), "search", "stats", "tags", "version",
+}
+
+// checkReserved reports every license whose ID is one of reservedIDs, which
+// would otherwise be registered twice.
+func checkReserved(licenses []ynal.LicenseData) error {
+	errs := []error{}
+	for _, l := range licenses {
+		if slices.Contains(reservedIDs, l.ID) {
+			errs = append(errs, fmt.Errorf("%s: served at %s, same as one of ynal's own pages", l.Title, l.URL))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func handlerFor(page licensePage, tmpl *pageTemplates, base string) (http.Handler, error) {
+	l := page.LicenseData
+
+	handlers, err := prepareRenderers(licenseRenderers, page, tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediatype := negotiatorsFrom(r).license.negotiate(r.Header.Get("Accept"))
+
+		setDeprecationHeaders(w, l, base)
+
+		if mediatype == "" {
+			writeNotAcceptable(w, r, tmpl)
+			return
+		}
+
+		rh, ok := handlers[mediatype]
+		if !ok {
+			writeError(w, r, tmpl, http.StatusNotAcceptable, fmt.Sprintf("unrecognized media type: %s", mediatype))
+			return
+		}
+
+		rh.ServeHTTP(w, r)
+	})
+
+	return h, nil
+}
+
+// aliasHandler redirects to l, keeping the query string.
+func aliasHandler(l ynal.LicenseData) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := l.URL
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
+
+// rawHandler always serves plain text, whatever the Accept header says, so it
+// is safe to pipe straight into a file. It serves exceptions and licenses
+// combined with an exception too.
+func rawHandler(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, tmpl *pageTemplates, base string) http.Handler {
+	return textHandler(licenses, exceptions, tmpl, base, "raw")
+}
+
+// downloadHandler serves the same thing as rawHandler, but tells browsers to
+// save it as a file named LICENSE rather than display it.
+func downloadHandler(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, tmpl *pageTemplates, base string) http.Handler {
+	raw := textHandler(licenses, exceptions, tmpl, base, "download")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := lookup(licenses, exceptions, r.PathValue("id")); ok {
+			w.Header().Set("Content-Disposition", `attachment; filename="LICENSE"`)
+		}
+
+		raw.ServeHTTP(w, r)
+	})
+}
+
+// textHandler serves the plain text of the license named in the path, laid out
+// as the width and eol query parameters ask, counting it in the stats as
+// representation. Errors are still negotiated, so scripts asking for JSON get
+// a problem they can parse.
+func textHandler(licenses []ynal.LicenseData, exceptions []ynal.LicenseData, tmpl *pageTemplates, base string, representation string) http.Handler {
+	// licenses go last so they win over an exception with the same ID, like
+	// in lookup
+	texts := map[string]plainText{}
+	for _, l := range slices.Concat(exceptions, licenses) {
+		texts[l.ID] = newPlainText(l, tmpl.cache)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l, ok := lookup(licenses, exceptions, r.PathValue("id"))
+		if !ok {
+			writeError(w, r, tmpl, http.StatusNotFound, fmt.Sprintf("no such license: %s", r.PathValue("id")))
+			return
+		}
+
+		f, err := parseTextFormat(r.URL.Query())
+		if err != nil {
+			writeError(w, r, tmpl, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		// combinations aren't rendered up front
+		plain, ok := texts[l.ID]
+		if !ok {
+			plain = newPlainText(l, nil)
+		}
+
+		countHit(r, l.ID, representation)
+		setDeprecationHeaders(w, l, base)
+
+		p := plain.page(f)
+		signBody(w, r, p.body)
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		p.serve(w, r, "LICENSE")
+	})
+}
+
+// textPage is the plain text of l, with its SHA-256 as the ETag. Served with
+// renderedPage.serve, it can be fetched in ranges and revalidated.
+func textPage(l ynal.LicenseData) renderedPage {
+	if l.Digest.SHA256 == "" {
+		return newRenderedPage([]byte(l.Text))
+	}
+
+	return renderedPage{body: []byte(l.Text), etag: `"` + l.Digest.SHA256 + `"`}
+}
+
+func digestHandler(digest string) http.Handler {
+	body := []byte(digest + "\n")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(body)
+	})
+}
+
+// offers are the media types every negotiated route can serve, in the order
+// they win ties, so */* gets plain text.
+var offers = []string{"text/plain", "text/html", "application/xhtml+xml", "application/json"}
+
+// mostAcceptable returns which of offers r's Accept header prefers, or "" if
+// it refuses every one with q=0.
+func mostAcceptable(r *http.Request) string {
+	return negotiatorsFrom(r).general.negotiate(r.Header.Get("Accept"))
+}
+
+// newPublicHandler serves the index and everything in public. Anything else
+// is a 404, with suggestions from suggest.
+func newPublicHandler(public fs.FS, tmpl *pageTemplates, index *indexRenderer, suggest *suggester) http.Handler {
+	fileserver := http.FileServer(http.FS(public))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			index.ServeHTTP(w, r)
+			return
+		}
+
+		// fingerprinted paths can be cached forever, since a change to the
+		// file changes its path
+		if name, ok := tmpl.assets.original[strings.TrimPrefix(r.URL.Path, "/")]; ok {
+			w.Header().Set("Cache-Control", immutableCacheControl)
+
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = "/" + name
+			fileserver.ServeHTTP(w, r2)
+			return
+		}
+
+		// check first so missing files get our error page rather than the
+		// file server's bare 404, and directories aren't listed
+		if info, err := fs.Stat(public, strings.TrimPrefix(path.Clean(r.URL.Path), "/")); err != nil || info.IsDir() {
+			p := newProblem(r, http.StatusNotFound, fmt.Sprintf("nothing found at %s", r.URL.Path))
+			p.Suggestions = suggest.suggest(path.Base(r.URL.Path))
+
+			writeNegotiatedProblem(w, r, tmpl, p)
+			return
+		}
+
+		fileserver.ServeHTTP(w, r)
+	})
+}
+
+func withCacheControl(value string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", value)
+
+		next.ServeHTTP(w, r)
+	})
+}
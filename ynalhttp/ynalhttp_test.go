@@ -0,0 +1,714 @@
+package ynalhttp
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/packrat386/ynal"
+)
+
+func assertEqualToFile(t *testing.T, r io.Reader, f string) {
+	fdata, err := os.ReadFile(filepath.Join("testdata", f))
+	if err != nil {
+		t.Fatalf("error reading testdata: %s", err.Error())
+	}
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("error reading response data: %s", err.Error())
+	}
+
+	if string(fdata) != string(got) {
+		t.Fatalf("expected:\n---\n%s\n---\ngot:\n---\n%s\n---\n", string(fdata), string(got))
+	}
+}
+
+func mustAppHandler(t *testing.T) http.Handler {
+	h, err := New()
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	return h
+}
+
+// mustTemplates parses the embedded templates, for testing handlers that
+// need them on their own.
+func mustTemplates(t *testing.T) *pageTemplates {
+	public, err := fs.Sub(ynal.Public, "public")
+	if err != nil {
+		t.Fatalf("could not subsystem public assets: %s", err)
+	}
+
+	tmpl, err := parseTemplates(ynal.Templates, nil, ynal.Messages, public, "", defaultSiteURL, "")
+	if err != nil {
+		t.Fatalf("could not parse templates: %s", err)
+	}
+
+	return tmpl
+}
+
+func TestGetLicense(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name     string
+		accept   string
+		expected string
+	}{
+		{
+			name:     "explicit plain text",
+			accept:   "text/plain",
+			expected: "EXPECTED_TXT",
+		},
+		{
+			name:     "explicit html",
+			accept:   "text/html",
+			expected: "EXPECTED_HTML",
+		},
+		{
+			name:     "explicit json",
+			accept:   "application/json",
+			expected: "EXPECTED_JSON",
+		},
+		{
+			name:     "accept any",
+			accept:   "*/*",
+			expected: "EXPECTED_TXT",
+		},
+		{
+			name:     "unrecognized",
+			accept:   "lol/wut",
+			expected: "EXPECTED_TXT",
+		},
+		{
+			name:     "accept heirarchy",
+			accept:   "text/css, text/plain; q=0.8, text/html; q=0.9",
+			expected: "EXPECTED_HTML",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/mit", nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			assertEqualToFile(t, w.Result().Body, tc.expected)
+		})
+	}
+}
+
+func TestSectionAnchors(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name     string
+		path     string
+		expected []string
+		missing  string
+	}{
+		{
+			name: "apache",
+			path: "/apache_2",
+			expected: []string{
+				`<li value="3"><a href="#section-3">Grant of Patent License</a></li>`,
+				`<span class="section" id="section-3">   3. Grant of Patent License.`,
+			},
+		},
+		{
+			name: "gpl numbered from zero",
+			path: "/gpl_3",
+			expected: []string{
+				`<li value="0"><a href="#section-0">Definitions</a></li>`,
+				`<span class="section" id="section-17">`,
+			},
+		},
+		{
+			name:    "no sections",
+			path:    "/mit",
+			missing: `class="toc"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/html")
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			for _, want := range tc.expected {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("expected the page to contain %q", want)
+				}
+			}
+
+			if tc.missing != "" && strings.Contains(w.Body.String(), tc.missing) {
+				t.Errorf("expected the page not to contain %q", tc.missing)
+			}
+		})
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	h, err := New(WithCacheControl("public, max-age=3600"))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	r := httptest.NewRequest("GET", "/mit", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if got := w.Result().Header.Get("Cache-Control"); got != "public, max-age=3600" {
+		t.Fatalf("unexpected Cache-Control: %q", got)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	h := mustAppHandler(t)
+
+	tt := []struct {
+		name        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "plain text",
+			accept:      "text/plain",
+			contentType: "text/plain; charset=utf-8",
+			body:        "nothing found at /nope\n",
+		},
+		{
+			name:        "json",
+			accept:      "application/json",
+			contentType: "application/problem+json",
+			body:        `{"type":"about:blank","title":"Not Found","status":404,"detail":"nothing found at /nope","instance":"/nope"}` + "\n",
+		},
+		{
+			name:        "problem json",
+			accept:      "application/problem+json",
+			contentType: "application/problem+json",
+			body:        `"status":404`,
+		},
+		{
+			name:        "problem json preferred",
+			accept:      "text/plain;q=0.5, application/problem+json",
+			contentType: "application/problem+json",
+			body:        `"status":404`,
+		},
+		{
+			name:        "html",
+			accept:      "text/html",
+			contentType: "text/html",
+			body:        "<h2>404 Not Found</h2>",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/nope", nil)
+			r.Header.Set("Accept", tc.accept)
+
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("expected 404, got %d", w.Code)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tc.contentType {
+				t.Fatalf("expected content type %q, got %q", tc.contentType, got)
+			}
+
+			if !strings.Contains(w.Body.String(), tc.body) {
+				t.Fatalf("expected body to contain %q, got %q", tc.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPublicAssets(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/styles.css", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/css") {
+		t.Fatalf("unexpected content type: %q", got)
+	}
+}
+
+func TestRaw(t *testing.T) {
+	h := mustAppHandler(t)
+
+	for _, accept := range []string{"text/html", "application/json", ""} {
+		r := httptest.NewRequest("GET", "/raw/mit", nil)
+		r.Header.Set("Accept", accept)
+
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if got := w.Header().Get("Content-Type"); got != "text/plain; charset=utf-8" {
+			t.Fatalf("unexpected content type for %q: %q", accept, got)
+		}
+
+		assertEqualToFile(t, w.Result().Body, "EXPECTED_TXT")
+	}
+
+	// errors are negotiated, though
+	for accept, contentType := range map[string]string{
+		"":                 "text/plain; charset=utf-8",
+		"text/plain":       "text/plain; charset=utf-8",
+		"application/json": "application/problem+json",
+	} {
+		r := httptest.NewRequest("GET", "/raw/nope", nil)
+		r.Header.Set("Accept", accept)
+
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != contentType {
+			t.Fatalf("expected %s 404 for %q, got %d %q", contentType, accept, w.Code, w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestDownload(t *testing.T) {
+	h := mustAppHandler(t)
+
+	r := httptest.NewRequest("GET", "/download/mit", nil)
+	r.Header.Set("Accept", "text/html")
+
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="LICENSE"` {
+		t.Fatalf("unexpected Content-Disposition: %q", got)
+	}
+
+	assertEqualToFile(t, w.Result().Body, "EXPECTED_TXT")
+
+	r = httptest.NewRequest("GET", "/download/nope", nil)
+	w = httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Disposition") != "" {
+		t.Fatalf("expected a plain 404, got %d %q", w.Code, w.Header().Get("Content-Disposition"))
+	}
+}
+
+func TestAliases(t *testing.T) {
+	mit := ynal.NewLicense("MIT", "mit text\n")
+	mit.Aliases = []string{"expat", "search"}
+
+	h, err := New(WithLicenses([]ynal.LicenseData{mit}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	r := httptest.NewRequest("GET", "/expat?x=1", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "/mit?x=1" {
+		t.Fatalf("expected a redirect to /mit?x=1, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	// an alias can't shadow a real route
+	r = httptest.NewRequest("GET", "/search?q=mit", nil)
+	w = httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected search to still work, got %d", w.Code)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	h := mustAppHandler(t)
+
+	text, err := os.ReadFile(filepath.Join("testdata", "EXPECTED_TXT"))
+	if err != nil {
+		t.Fatalf("error reading testdata: %s", err)
+	}
+
+	for path, sum := range map[string]string{
+		"/mit/sha256": fmt.Sprintf("%x\n", sha256.Sum256(text)),
+		"/mit/sha1":   fmt.Sprintf("%x\n", sha1.Sum(text)),
+	} {
+		r := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if w.Body.String() != sum {
+			t.Fatalf("%s: expected %q, got %q", path, sum, w.Body.String())
+		}
+	}
+}
+
+func TestCatalogReplace(t *testing.T) {
+	catalog := ynal.NewCatalog([]ynal.LicenseData{
+		{ID: "one", Title: "One", URL: "/one", Text: "first"},
+	})
+
+	h, err := New(WithStore(catalog))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	if w := get("/one"); w.Body.String() != "first" {
+		t.Fatalf("unexpected body: %q", w.Body.String())
+	}
+
+	catalog.Replace([]ynal.LicenseData{
+		{ID: "two", Title: "Two", URL: "/two", Text: "second"},
+	})
+
+	if w := get("/one"); w.Code != http.StatusNotFound {
+		t.Fatalf("expected removed license to 404, got %d", w.Code)
+	}
+
+	if w := get("/two"); w.Body.String() != "second" {
+		t.Fatalf("unexpected body: %q", w.Body.String())
+	}
+}
+
+func TestIndexConditional(t *testing.T) {
+	catalog := ynal.NewCatalog([]ynal.LicenseData{ynal.NewLicense("One", "first\n")})
+
+	h, err := New(WithStore(catalog))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	get := func(etag string, lang string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("If-None-Match", etag)
+		r.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := get("", "en")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with an ETag, got %d %q", w.Code, etag)
+	}
+
+	if w := get(etag, "en"); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("expected an empty 304, got %d %q", w.Code, w.Body.String())
+	}
+
+	if w := get(`"stale", `+etag, "en"); w.Code != http.StatusNotModified {
+		t.Fatalf("expected a 304 when any ETag matches, got %d", w.Code)
+	}
+
+	if w := get(etag, "de"); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("expected another language to be a different page, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	catalog.Replace([]ynal.LicenseData{ynal.NewLicense("Two", "second\n")})
+
+	w = get(etag, "en")
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Fatalf("expected a new index after a reload, got %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	if !strings.Contains(w.Body.String(), "Two") {
+		t.Fatalf("expected the new license on the index, got: %s", w.Body.String())
+	}
+}
+
+func TestInvalidLicenses(t *testing.T) {
+	_, err := New(WithLicenses([]ynal.LicenseData{
+		ynal.NewLicense("MIT", "mit\n"),
+		ynal.NewLicense("mit", "mit again\n"),
+		ynal.NewLicense("Blank", ""),
+	}))
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	for _, want := range []string{"mit: served at /mit, same as MIT", "Blank: empty text"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in error, got: %s", want, err)
+		}
+	}
+}
+
+func TestReservedIDs(t *testing.T) {
+	for _, id := range reservedIDs {
+		t.Run(id, func(t *testing.T) {
+			_, err := New(WithLicenses([]ynal.LicenseData{ynal.NewLicense(id, "text\n")}))
+			if err == nil || !strings.Contains(err.Error(), "same as one of ynal's own pages") {
+				t.Fatalf("expected a reserved ID error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestConflictingRoutes(t *testing.T) {
+	bundled := ynal.NewLicense("Bundled", "text\n")
+	bundled.URL = "/bundle"
+
+	_, err := New(WithLicenses([]ynal.LicenseData{bundled}))
+	if err == nil || !strings.Contains(err.Error(), "could not route licenses") {
+		t.Fatalf("expected a routing error, got: %v", err)
+	}
+}
+
+func TestWithTemplates(t *testing.T) {
+	h, err := New(WithTemplates(fstest.MapFS{
+		"index.html.tmpl": {Data: []byte(`<h1>Corp licenses</h1>{{ range .Other }}<a href="{{ .URL }}">{{ .Title }}</a>{{ end }}`)},
+		"README.md":       {Data: []byte("not a template")},
+	}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	get := func(path string) string {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		return w.Body.String()
+	}
+
+	if body := get("/"); !strings.HasPrefix(body, "<h1>Corp licenses</h1>") || !strings.Contains(body, `<a href="/mit">MIT</a>`) {
+		t.Fatalf("expected the overridden index, got:\n%s", body)
+	}
+
+	if body := get("/mit"); !strings.Contains(body, "<html") {
+		t.Fatalf("expected the embedded license template, got:\n%s", body)
+	}
+
+	_, err = New(WithTemplates(fstest.MapFS{"index.html.tmpl": {Data: []byte("{{ .Broken")}}))
+	if err == nil || !strings.Contains(err.Error(), "could not parse template overrides") {
+		t.Fatalf("expected a parse error, got: %v", err)
+	}
+}
+
+func TestTemplateLayout(t *testing.T) {
+	h, err := New(
+		WithLicenses([]ynal.LicenseData{ynal.NewLicense("MIT", "mit text\n")}),
+		WithTemplates(fstest.MapFS{
+			"footer.html.tmpl": {Data: []byte(`<footer>Corp</footer>`)},
+			"search.html.tmpl": {Data: []byte(`{{ template "layout.html.tmpl" . }}{{ define "heading" }}Find a license{{ end }}{{ define "content" }}<p>{{ .Query }}</p>{{ end }}`)},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		path     string
+		expected []string
+		missing  []string
+	}{
+		{
+			name:     "shared footer",
+			path:     "/",
+			expected: []string{"<h2>YNAL: You Need A License</h2>", "<footer>Corp</footer>", "github.com/packrat386/ynal"},
+			missing:  []string{`class="theme"`},
+		},
+		{
+			name:     "blocks",
+			path:     "/search?q=mit",
+			expected: []string{"<title>YNAL: You Need A License</title>", "<h2>Find a license</h2>", "<p>mit</p>", `<a href="/">`, "<footer>Corp</footer>"},
+		},
+		{
+			// each page's blocks are its own, whatever else defines them
+			name:     "other pages",
+			path:     "/mit",
+			expected: []string{"<h2>License: MIT</h2>", "curl -s", "<footer>Corp</footer>"},
+			missing:  []string{"Find a license"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			for _, want := range tc.expected {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("expected the page to contain %q, got:\n%s", want, w.Body.String())
+				}
+			}
+
+			for _, unwanted := range tc.missing {
+				if strings.Contains(w.Body.String(), unwanted) {
+					t.Errorf("expected the page not to contain %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestLicenseTemplate(t *testing.T) {
+	h, err := New(
+		WithLicenseFS(fstest.MapFS{
+			"CC_BY_4.txt":  {Data: []byte("Attribution 4.0 International\n")},
+			"CC_BY_4.json": {Data: []byte(`{"template": "license_cc.html.tmpl"}`)},
+			"Corp.txt":     {Data: []byte("Corp license text\n")},
+		}),
+		WithTemplates(fstest.MapFS{
+			"license_cc.html.tmpl": {Data: []byte(`<h1 class="cc">{{ .Title }}</h1>{{ template "license_text.html.tmpl" . }}`)},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	tt := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "own template", path: "/cc_by_4", expected: `<h1 class="cc">CC_BY_4</h1>`},
+		{name: "print", path: "/cc_by_4?print=1", expected: "<h1>CC_BY_4</h1>"},
+		{name: "default template", path: "/corp", expected: "curl -s"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", "text/html")
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			if !strings.Contains(w.Body.String(), tc.expected) {
+				t.Fatalf("expected the page to contain %q, got:\n%s", tc.expected, w.Body.String())
+			}
+		})
+	}
+
+	_, err = New(WithLicenseFS(fstest.MapFS{
+		"CC_BY_4.txt":  {Data: []byte("Attribution 4.0 International\n")},
+		"CC_BY_4.json": {Data: []byte(`{"template": "license_cc.html.tmpl"}`)},
+	}))
+	if err == nil || !strings.Contains(err.Error(), `cc_by_4: unknown template "license_cc.html.tmpl"`) {
+		t.Fatalf("expected an unknown template error, got: %v", err)
+	}
+}
+
+func TestWithLicenseFS(t *testing.T) {
+	h, err := New(WithLicenseFS(fstest.MapFS{
+		"Corp.txt":  {Data: []byte("Corp license text\n")},
+		"Corp.json": {Data: []byte(`{"spdx": "LicenseRef-Corp"}`)},
+	}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	for path, code := range map[string]int{"/corp": http.StatusOK, "/mit": http.StatusNotFound} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.Header.Set("Accept", "text/plain")
+		w := httptest.NewRecorder()
+
+		h.ServeHTTP(w, r)
+
+		if w.Code != code {
+			t.Errorf("expected %d for %s, got %d", code, path, w.Code)
+		}
+	}
+
+	if _, err := New(WithLicenseFS(fstest.MapFS{}), WithLicenses(nil)); err == nil {
+		t.Errorf("expected an error for two sources of licenses")
+	}
+
+	if _, err := New(WithLicenseFS(fstest.MapFS{"Blank.txt": {Data: []byte("")}})); err == nil {
+		t.Errorf("expected an error for an invalid license")
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	h, err := New(WithLogger(log.New(buf, "", 0)), WithTemplates(fstest.MapFS{
+		"error.html.tmpl": {Data: []byte(`{{ template "missing" }}`)},
+	}))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	r := httptest.NewRequest("GET", "/nope", nil)
+	r.Header.Set("Accept", "text/html")
+
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if !strings.Contains(buf.String(), "could not render error template") {
+		t.Errorf("expected the error in the log, got %q", buf.String())
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	h, err := New(WithClock(func() time.Time { return time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC) }))
+	if err != nil {
+		t.Fatalf("could not initialize app handler: %s", err)
+	}
+
+	r := httptest.NewRequest("POST", "/api/v1/sbom", strings.NewReader(`{"name": "x", "components": []}`))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+
+	if !strings.Contains(w.Body.String(), "2001-02-03T04:05:06Z") {
+		t.Errorf("expected the clock's time in the SBOM, got %s", w.Body.String())
+	}
+}
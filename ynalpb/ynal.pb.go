@@ -0,0 +1,403 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: ynalpb/ynal.proto
+
+package ynalpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type License struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	Url           string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *License) Reset() {
+	*x = License{}
+	mi := &file_ynalpb_ynal_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *License) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*License) ProtoMessage() {}
+
+func (x *License) ProtoReflect() protoreflect.Message {
+	mi := &file_ynalpb_ynal_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use License.ProtoReflect.Descriptor instead.
+func (*License) Descriptor() ([]byte, []int) {
+	return file_ynalpb_ynal_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *License) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *License) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *License) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *License) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+type ListLicensesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLicensesRequest) Reset() {
+	*x = ListLicensesRequest{}
+	mi := &file_ynalpb_ynal_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLicensesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLicensesRequest) ProtoMessage() {}
+
+func (x *ListLicensesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ynalpb_ynal_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLicensesRequest.ProtoReflect.Descriptor instead.
+func (*ListLicensesRequest) Descriptor() ([]byte, []int) {
+	return file_ynalpb_ynal_proto_rawDescGZIP(), []int{1}
+}
+
+type ListLicensesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Licenses      []*License             `protobuf:"bytes,1,rep,name=licenses,proto3" json:"licenses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLicensesResponse) Reset() {
+	*x = ListLicensesResponse{}
+	mi := &file_ynalpb_ynal_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLicensesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLicensesResponse) ProtoMessage() {}
+
+func (x *ListLicensesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ynalpb_ynal_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLicensesResponse.ProtoReflect.Descriptor instead.
+func (*ListLicensesResponse) Descriptor() ([]byte, []int) {
+	return file_ynalpb_ynal_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListLicensesResponse) GetLicenses() []*License {
+	if x != nil {
+		return x.Licenses
+	}
+	return nil
+}
+
+type GetLicenseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLicenseRequest) Reset() {
+	*x = GetLicenseRequest{}
+	mi := &file_ynalpb_ynal_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLicenseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLicenseRequest) ProtoMessage() {}
+
+func (x *GetLicenseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ynalpb_ynal_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLicenseRequest.ProtoReflect.Descriptor instead.
+func (*GetLicenseRequest) Descriptor() ([]byte, []int) {
+	return file_ynalpb_ynal_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetLicenseRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RenderLicenseRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// substitutions maps placeholder names, without the surrounding angle
+	// brackets, to their replacements. For example {"YEAR": "2024"}.
+	Substitutions map[string]string `protobuf:"bytes,2,rep,name=substitutions,proto3" json:"substitutions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderLicenseRequest) Reset() {
+	*x = RenderLicenseRequest{}
+	mi := &file_ynalpb_ynal_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderLicenseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderLicenseRequest) ProtoMessage() {}
+
+func (x *RenderLicenseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ynalpb_ynal_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderLicenseRequest.ProtoReflect.Descriptor instead.
+func (*RenderLicenseRequest) Descriptor() ([]byte, []int) {
+	return file_ynalpb_ynal_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RenderLicenseRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RenderLicenseRequest) GetSubstitutions() map[string]string {
+	if x != nil {
+		return x.Substitutions
+	}
+	return nil
+}
+
+type RenderLicenseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderLicenseResponse) Reset() {
+	*x = RenderLicenseResponse{}
+	mi := &file_ynalpb_ynal_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderLicenseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderLicenseResponse) ProtoMessage() {}
+
+func (x *RenderLicenseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ynalpb_ynal_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderLicenseResponse.ProtoReflect.Descriptor instead.
+func (*RenderLicenseResponse) Descriptor() ([]byte, []int) {
+	return file_ynalpb_ynal_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RenderLicenseResponse) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+var File_ynalpb_ynal_proto protoreflect.FileDescriptor
+
+const file_ynalpb_ynal_proto_rawDesc = "" +
+	"\n" +
+	"\x11ynalpb/ynal.proto\x12\aynal.v1\"[\n" +
+	"\aLicense\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x12\x10\n" +
+	"\x03url\x18\x04 \x01(\tR\x03url\"\x15\n" +
+	"\x13ListLicensesRequest\"D\n" +
+	"\x14ListLicensesResponse\x12,\n" +
+	"\blicenses\x18\x01 \x03(\v2\x10.ynal.v1.LicenseR\blicenses\"#\n" +
+	"\x11GetLicenseRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xc0\x01\n" +
+	"\x14RenderLicenseRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12V\n" +
+	"\rsubstitutions\x18\x02 \x03(\v20.ynal.v1.RenderLicenseRequest.SubstitutionsEntryR\rsubstitutions\x1a@\n" +
+	"\x12SubstitutionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"1\n" +
+	"\x15RenderLicenseResponse\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent2\xe9\x01\n" +
+	"\x0eLicenseService\x12K\n" +
+	"\fListLicenses\x12\x1c.ynal.v1.ListLicensesRequest\x1a\x1d.ynal.v1.ListLicensesResponse\x12:\n" +
+	"\n" +
+	"GetLicense\x12\x1a.ynal.v1.GetLicenseRequest\x1a\x10.ynal.v1.License\x12N\n" +
+	"\rRenderLicense\x12\x1d.ynal.v1.RenderLicenseRequest\x1a\x1e.ynal.v1.RenderLicenseResponseB#Z!github.com/packrat386/ynal/ynalpbb\x06proto3"
+
+var (
+	file_ynalpb_ynal_proto_rawDescOnce sync.Once
+	file_ynalpb_ynal_proto_rawDescData []byte
+)
+
+func file_ynalpb_ynal_proto_rawDescGZIP() []byte {
+	file_ynalpb_ynal_proto_rawDescOnce.Do(func() {
+		file_ynalpb_ynal_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ynalpb_ynal_proto_rawDesc), len(file_ynalpb_ynal_proto_rawDesc)))
+	})
+	return file_ynalpb_ynal_proto_rawDescData
+}
+
+var file_ynalpb_ynal_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_ynalpb_ynal_proto_goTypes = []any{
+	(*License)(nil),               // 0: ynal.v1.License
+	(*ListLicensesRequest)(nil),   // 1: ynal.v1.ListLicensesRequest
+	(*ListLicensesResponse)(nil),  // 2: ynal.v1.ListLicensesResponse
+	(*GetLicenseRequest)(nil),     // 3: ynal.v1.GetLicenseRequest
+	(*RenderLicenseRequest)(nil),  // 4: ynal.v1.RenderLicenseRequest
+	(*RenderLicenseResponse)(nil), // 5: ynal.v1.RenderLicenseResponse
+	nil,                           // 6: ynal.v1.RenderLicenseRequest.SubstitutionsEntry
+}
+var file_ynalpb_ynal_proto_depIdxs = []int32{
+	0, // 0: ynal.v1.ListLicensesResponse.licenses:type_name -> ynal.v1.License
+	6, // 1: ynal.v1.RenderLicenseRequest.substitutions:type_name -> ynal.v1.RenderLicenseRequest.SubstitutionsEntry
+	1, // 2: ynal.v1.LicenseService.ListLicenses:input_type -> ynal.v1.ListLicensesRequest
+	3, // 3: ynal.v1.LicenseService.GetLicense:input_type -> ynal.v1.GetLicenseRequest
+	4, // 4: ynal.v1.LicenseService.RenderLicense:input_type -> ynal.v1.RenderLicenseRequest
+	2, // 5: ynal.v1.LicenseService.ListLicenses:output_type -> ynal.v1.ListLicensesResponse
+	0, // 6: ynal.v1.LicenseService.GetLicense:output_type -> ynal.v1.License
+	5, // 7: ynal.v1.LicenseService.RenderLicense:output_type -> ynal.v1.RenderLicenseResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_ynalpb_ynal_proto_init() }
+func file_ynalpb_ynal_proto_init() {
+	if File_ynalpb_ynal_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ynalpb_ynal_proto_rawDesc), len(file_ynalpb_ynal_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ynalpb_ynal_proto_goTypes,
+		DependencyIndexes: file_ynalpb_ynal_proto_depIdxs,
+		MessageInfos:      file_ynalpb_ynal_proto_msgTypes,
+	}.Build()
+	File_ynalpb_ynal_proto = out.File
+	file_ynalpb_ynal_proto_goTypes = nil
+	file_ynalpb_ynal_proto_depIdxs = nil
+}
@@ -0,0 +1,209 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             (unknown)
+// source: ynalpb/ynal.proto
+
+package ynalpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LicenseService_ListLicenses_FullMethodName  = "/ynal.v1.LicenseService/ListLicenses"
+	LicenseService_GetLicense_FullMethodName    = "/ynal.v1.LicenseService/GetLicense"
+	LicenseService_RenderLicense_FullMethodName = "/ynal.v1.LicenseService/RenderLicense"
+)
+
+// LicenseServiceClient is the client API for LicenseService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LicenseService exposes the same license catalog that is served over HTTP.
+type LicenseServiceClient interface {
+	// ListLicenses returns every license in the catalog.
+	ListLicenses(ctx context.Context, in *ListLicensesRequest, opts ...grpc.CallOption) (*ListLicensesResponse, error)
+	// GetLicense returns a single license by its ID (e.g. "mit").
+	GetLicense(ctx context.Context, in *GetLicenseRequest, opts ...grpc.CallOption) (*License, error)
+	// RenderLicense returns the plain text of a license with placeholders like
+	// <YEAR> or <COPYRIGHT HOLDER> filled in.
+	RenderLicense(ctx context.Context, in *RenderLicenseRequest, opts ...grpc.CallOption) (*RenderLicenseResponse, error)
+}
+
+type licenseServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLicenseServiceClient(cc grpc.ClientConnInterface) LicenseServiceClient {
+	return &licenseServiceClient{cc}
+}
+
+func (c *licenseServiceClient) ListLicenses(ctx context.Context, in *ListLicensesRequest, opts ...grpc.CallOption) (*ListLicensesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLicensesResponse)
+	err := c.cc.Invoke(ctx, LicenseService_ListLicenses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *licenseServiceClient) GetLicense(ctx context.Context, in *GetLicenseRequest, opts ...grpc.CallOption) (*License, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(License)
+	err := c.cc.Invoke(ctx, LicenseService_GetLicense_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *licenseServiceClient) RenderLicense(ctx context.Context, in *RenderLicenseRequest, opts ...grpc.CallOption) (*RenderLicenseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenderLicenseResponse)
+	err := c.cc.Invoke(ctx, LicenseService_RenderLicense_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LicenseServiceServer is the server API for LicenseService service.
+// All implementations must embed UnimplementedLicenseServiceServer
+// for forward compatibility.
+//
+// LicenseService exposes the same license catalog that is served over HTTP.
+type LicenseServiceServer interface {
+	// ListLicenses returns every license in the catalog.
+	ListLicenses(context.Context, *ListLicensesRequest) (*ListLicensesResponse, error)
+	// GetLicense returns a single license by its ID (e.g. "mit").
+	GetLicense(context.Context, *GetLicenseRequest) (*License, error)
+	// RenderLicense returns the plain text of a license with placeholders like
+	// <YEAR> or <COPYRIGHT HOLDER> filled in.
+	RenderLicense(context.Context, *RenderLicenseRequest) (*RenderLicenseResponse, error)
+	mustEmbedUnimplementedLicenseServiceServer()
+}
+
+// UnimplementedLicenseServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLicenseServiceServer struct{}
+
+func (UnimplementedLicenseServiceServer) ListLicenses(context.Context, *ListLicensesRequest) (*ListLicensesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListLicenses not implemented")
+}
+func (UnimplementedLicenseServiceServer) GetLicense(context.Context, *GetLicenseRequest) (*License, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLicense not implemented")
+}
+func (UnimplementedLicenseServiceServer) RenderLicense(context.Context, *RenderLicenseRequest) (*RenderLicenseResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RenderLicense not implemented")
+}
+func (UnimplementedLicenseServiceServer) mustEmbedUnimplementedLicenseServiceServer() {}
+func (UnimplementedLicenseServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeLicenseServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LicenseServiceServer will
+// result in compilation errors.
+type UnsafeLicenseServiceServer interface {
+	mustEmbedUnimplementedLicenseServiceServer()
+}
+
+func RegisterLicenseServiceServer(s grpc.ServiceRegistrar, srv LicenseServiceServer) {
+	// If the following call panics, it indicates UnimplementedLicenseServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LicenseService_ServiceDesc, srv)
+}
+
+func _LicenseService_ListLicenses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLicensesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LicenseServiceServer).ListLicenses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LicenseService_ListLicenses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LicenseServiceServer).ListLicenses(ctx, req.(*ListLicensesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LicenseService_GetLicense_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLicenseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LicenseServiceServer).GetLicense(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LicenseService_GetLicense_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LicenseServiceServer).GetLicense(ctx, req.(*GetLicenseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LicenseService_RenderLicense_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenderLicenseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LicenseServiceServer).RenderLicense(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LicenseService_RenderLicense_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LicenseServiceServer).RenderLicense(ctx, req.(*RenderLicenseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LicenseService_ServiceDesc is the grpc.ServiceDesc for LicenseService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LicenseService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ynal.v1.LicenseService",
+	HandlerType: (*LicenseServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLicenses",
+			Handler:    _LicenseService_ListLicenses_Handler,
+		},
+		{
+			MethodName: "GetLicense",
+			Handler:    _LicenseService_GetLicense_Handler,
+		},
+		{
+			MethodName: "RenderLicense",
+			Handler:    _LicenseService_RenderLicense_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ynalpb/ynal.proto",
+}